@@ -25,6 +25,7 @@ import (
 
 	"github.com/gocrane/crane/cmd/crane-agent/app/options"
 	"github.com/gocrane/crane/pkg/agent"
+	"github.com/gocrane/crane/pkg/ensurance/executor"
 	"github.com/gocrane/crane/pkg/metrics"
 )
 
@@ -99,6 +100,13 @@ func Run(ctx context.Context, opts *options.Options) error {
 	podInformer := podInformerFactory.Core().V1().Pods()
 	nodeInformer := nodeInformerFactory.Core().V1().Nodes()
 
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, informerSyncPeriod)
+	namespaceInformer := kubeInformerFactory.Core().V1().Namespaces()
+
+	// the executor doesn't wait for the PodDisruptionBudgets to sync, the evictions are limited until they are synced
+	pdbInformerFactory := informers.NewSharedInformerFactory(kubeClient, informerSyncPeriod)
+	pdbLister := executor.NewPodDisruptionBudgetLister(kubeClient, pdbInformerFactory)
+
 	craneInformerFactory := craneinformers.NewSharedInformerFactory(craneClient, informerSyncPeriod)
 	nodeQOSInformer := craneInformerFactory.Ensurance().V1alpha1().NodeQOSs()
 	podQOSInformer := craneInformerFactory.Ensurance().V1alpha1().PodQOSs()
@@ -115,7 +123,9 @@ func Run(ctx context.Context, opts *options.Options) error {
 	newAgent, err := agent.NewAgent(ctx, hostname, opts.RuntimeEndpoint, opts.CgroupDriver, opts.SysPath,
 		opts.KubeletRootPath, kubeClient, craneClient, podInformer, nodeInformer, nodeQOSInformer, podQOSInformer,
 		actionInformer, tspInformer, nrtInformer, opts.NodeResourceReserved, opts.Ifaces, healthCheck,
-		opts.CollectInterval, opts.ExecuteExcess, opts.CPUManagerReconcilePeriod, opts.DefaultCPUPolicy)
+		opts.CollectInterval, opts.ExecuteExcess, opts.CPUManagerReconcilePeriod, opts.CPUManagerPolicy, opts.DefaultCPUPolicy, opts.BestEffortLLCDomains,
+		opts.OnlineCPUWatermark, opts.MinOfflineCPUs,
+		opts.ForecastSource, opts.ForecastHorizon, pdbLister, opts.EvictionLimits, namespaceInformer, opts.ActionHistorySize, opts.ExportActionHistory)
 
 	if err != nil {
 		return err
@@ -123,11 +133,14 @@ func Run(ctx context.Context, opts *options.Options) error {
 
 	podInformerFactory.Start(ctx.Done())
 	nodeInformerFactory.Start(ctx.Done())
+	kubeInformerFactory.Start(ctx.Done())
+	pdbInformerFactory.Start(ctx.Done())
 	craneInformerFactory.Start(ctx.Done())
 	nrtInformerFactory.Start(ctx.Done())
 
 	podInformerFactory.WaitForCacheSync(ctx.Done())
 	nodeInformerFactory.WaitForCacheSync(ctx.Done())
+	kubeInformerFactory.WaitForCacheSync(ctx.Done())
 	craneInformerFactory.WaitForCacheSync(ctx.Done())
 	nrtInformerFactory.WaitForCacheSync(ctx.Done())
 
@@ -1,12 +1,18 @@
 package options
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/pflag"
 	cliflag "k8s.io/component-base/cli/flag"
 
 	topologyapi "github.com/gocrane/api/topology/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/ensurance/analyzer"
+	"github.com/gocrane/crane/pkg/ensurance/cm/cpumanager"
+	"github.com/gocrane/crane/pkg/ensurance/executor"
+	"github.com/gocrane/crane/pkg/utils"
 )
 
 // Options hold the command-line options about crane manager
@@ -38,6 +44,28 @@ type Options struct {
 	CPUManagerReconcilePeriod time.Duration
 	// DefaultCPUPolicy is the default cpu policy, default to exclusive.
 	DefaultCPUPolicy string
+	// CPUManagerPolicy is the policy of the cpu manager, static or interference-aware.
+	CPUManagerPolicy string
+	// BestEffortLLCDomains is the number of the LLC domains which the best-effort pods are packed onto with the
+	// interference-aware cpu manager policy.
+	BestEffortLLCDomains int
+	// OnlineCPUWatermark is the max utilization of the online cpus by the online pods, the offline cpuset is resized
+	// to keep it when the CPUSetRebalance feature is enabled.
+	OnlineCPUWatermark string
+	// MinOfflineCPUs is the min number of the offline cpus when the offline cpuset is resized.
+	MinOfflineCPUs int
+	// ForecastSource is the source of the node usage forecast to trigger the avoidance actions in advance, tsp or local.
+	// The forecast is disabled if it's empty.
+	ForecastSource string
+	// ForecastHorizon is how far the node usage is forecast
+	ForecastHorizon time.Duration
+	// EvictionLimits caps the evictions of the action executor
+	EvictionLimits executor.EvictionLimits
+	// ActionHistorySize is the max number of the actions kept in the action history
+	ActionHistorySize int
+	// ExportActionHistory exports the action history of the pods to the configmaps in their namespaces in background,
+	// which needs the rbac to create and update the configmaps in the namespaces
+	ExportActionHistory bool
 }
 
 // NewOptions builds an empty options.
@@ -52,6 +80,38 @@ func (o *Options) Complete() error {
 
 // Validate all required options.
 func (o *Options) Validate() error {
+	switch o.ForecastSource {
+	case "", analyzer.ForecastSourceTsp, analyzer.ForecastSourceLocal:
+	default:
+		return fmt.Errorf("forecast source should be one of tsp or local, got %s", o.ForecastSource)
+	}
+	if o.ForecastSource != "" && o.ForecastHorizon <= 0 {
+		return fmt.Errorf("forecast horizon should be positive, got %s", o.ForecastHorizon)
+	}
+	if o.EvictionLimits.WorkloadLimit < 0 || o.EvictionLimits.NodeLimitPerMinute < 0 ||
+		o.EvictionLimits.ClusterLimitPerMinute < 0 || o.EvictionLimits.ClusterBurst < 0 {
+		return fmt.Errorf("eviction limits should not be negative")
+	}
+	if o.EvictionLimits.WorkloadLimit > 0 && o.EvictionLimits.WorkloadWindow <= 0 {
+		return fmt.Errorf("eviction workload window should be positive, got %s", o.EvictionLimits.WorkloadWindow)
+	}
+	switch o.CPUManagerPolicy {
+	case cpumanager.PolicyNameStatic, cpumanager.PolicyNameInterferenceAware:
+	default:
+		return fmt.Errorf("cpu manager policy should be one of static or interference-aware, got %s", o.CPUManagerPolicy)
+	}
+	if o.BestEffortLLCDomains < 0 {
+		return fmt.Errorf("best-effort llc domains should not be negative, got %d", o.BestEffortLLCDomains)
+	}
+	if watermark, err := utils.ParsePercentage(o.OnlineCPUWatermark); err != nil || watermark <= 0 || watermark > 1 {
+		return fmt.Errorf("online cpu watermark should be a percentage in (0%%, 100%%], got %s", o.OnlineCPUWatermark)
+	}
+	if o.MinOfflineCPUs < 0 {
+		return fmt.Errorf("min offline cpus should not be negative, got %d", o.MinOfflineCPUs)
+	}
+	if o.ActionHistorySize < 0 {
+		return fmt.Errorf("action history size should not be negative, got %d", o.ActionHistorySize)
+	}
 	return nil
 }
 
@@ -63,12 +123,25 @@ func (o *Options) AddFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&o.SysPath, "sys-path", "/sys", "Path to /sys dir.")
 	flags.StringVar(&o.KubeletRootPath, "kubelet-root-path", "/var/lib/kubelet", "Path to the kubelet root directory.")
 	flags.Bool("enable-profiling", false, "Is debug/pprof endpoint enabled, default: false")
-	flags.StringVar(&o.BindAddr, "bind-address", "0.0.0.0:8081", "The address the agent binds to for metrics, health-check and pprof, default: 0.0.0.0:8081.")
+	flags.StringVar(&o.BindAddr, "bind-address", "0.0.0.0:8081", "The address the agent binds to for metrics, health-check, dry run report, action history and pprof, default: 0.0.0.0:8081.")
 	flags.DurationVar(&o.CollectInterval, "collect-interval", 10*time.Second, "Period for the state collector to collect metrics, default: 10s")
 	flags.StringArrayVar(&o.Ifaces, "ifaces", []string{"eth0"}, "The network devices to collect metric, use comma to separated, default: eth0")
 	flags.Var(cliflag.NewMapStringString(&o.NodeResourceReserved), "node-resource-reserved", "A set of ResourceName=Percent (e.g. cpu=40%,memory=40%)")
 	flags.DurationVar(&o.MaxInactivity, "max-inactivity", 5*time.Minute, "Maximum time from last recorded activity before automatic restart, default: 5min")
 	flags.StringVar(&o.ExecuteExcess, "execute-excess", "10%", "The percentage of executions that exceed the gap between current usage and watermarks, default: 10%.")
 	flags.DurationVar(&o.CPUManagerReconcilePeriod, "cpu-manager-reconcile-period", 5*time.Second, "Specifies how often cpu manager reconciles.")
+	flags.StringVar(&o.ForecastSource, "forecast-source", "", "The source of the node usage forecast to trigger the throttle and schedule actions before the watermarks are crossed, tsp or local, disabled if empty.")
+	flags.DurationVar(&o.ForecastHorizon, "forecast-horizon", 5*time.Minute, "How far the node usage is forecast, default: 5min")
+	flags.IntVar(&o.EvictionLimits.WorkloadLimit, "eviction-workload-limit", 0, "The max evictions of the pods of a workload on the node in the eviction workload window, no limit if it's 0.")
+	flags.DurationVar(&o.EvictionLimits.WorkloadWindow, "eviction-workload-window", 10*time.Minute, "The window of the eviction workload limit, default: 10min")
+	flags.IntVar(&o.EvictionLimits.NodeLimitPerMinute, "eviction-node-limit-per-minute", 0, "The max evictions on the node per minute, no limit if it's 0.")
+	flags.IntVar(&o.EvictionLimits.ClusterLimitPerMinute, "eviction-cluster-limit-per-minute", 0, "The refill rate per minute of the eviction token bucket shared by all the nodes in the configmap eviction-token-bucket, no limit if it's 0.")
+	flags.IntVar(&o.EvictionLimits.ClusterBurst, "eviction-cluster-burst", 0, "The capacity of the cluster eviction token bucket, default to the eviction cluster limit per minute.")
+	flags.IntVar(&o.ActionHistorySize, "action-history-size", 1000, "The max number of the throttle and evict actions kept in the action history, default: 1000")
+	flags.BoolVar(&o.ExportActionHistory, "export-action-history", false, "Export the action history of the pods to the configmap crane-action-history-<node name> owned by the node in their namespaces, the agent must be granted to create and update the configmaps in the namespaces, which is not in the default rbac, default: false")
 	flags.StringVar(&o.DefaultCPUPolicy, "default-cpu-policy", topologyapi.AnnotationPodCPUPolicyExclusive, "The default cpu policy if pod does not specify, should be one of none, exclusive, numa or immovable, default to exclusive.")
+	flags.StringVar(&o.CPUManagerPolicy, "cpu-manager-policy", cpumanager.PolicyNameStatic, "The policy of the cpu manager, should be one of static or interference-aware. The interference-aware policy dedicates physical cores to the latency critical pods and packs the best-effort pods onto separate LLC domains, default to static.")
+	flags.IntVar(&o.BestEffortLLCDomains, "best-effort-llc-domains", 1, "The number of the last LLC domains, or NUMA nodes if the last level cache is shared by the whole node, which the best-effort pods are packed onto with the interference-aware cpu manager policy, default: 1.")
+	flags.StringVar(&o.OnlineCPUWatermark, "online-cpu-watermark", "70%", "The max utilization of the online cpus by the online pods, the offline cpuset is shrunk when it's exceeded and grown when it falls with the CPUSetRebalance feature, default: 70%.")
+	flags.IntVar(&o.MinOfflineCPUs, "min-offline-cpus", 1, "The min number of the offline cpus when the offline cpuset is resized with the CPUSetRebalance feature, default: 1.")
 }
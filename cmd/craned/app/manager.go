@@ -39,6 +39,8 @@ import (
 	"github.com/gocrane/crane/pkg/known"
 	"github.com/gocrane/crane/pkg/metrics"
 	"github.com/gocrane/crane/pkg/oom"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
+	"github.com/gocrane/crane/pkg/prediction/external"
 	"github.com/gocrane/crane/pkg/predictor"
 	prometheus_adapter "github.com/gocrane/crane/pkg/prometheus-adapter"
 	"github.com/gocrane/crane/pkg/providers"
@@ -125,7 +127,7 @@ func Run(ctx context.Context, opts *options.Options) error {
 	}
 	// initialize data sources and predictor
 	realtimeDataSources, historyDataSources, dataSourceProviders := initDataSources(mgr, opts)
-	predictorMgr := initPredictorManager(opts, realtimeDataSources, historyDataSources)
+	predictorMgr := initPredictorManager(mgr, opts, realtimeDataSources, historyDataSources)
 
 	initScheme()
 	initFieldIndexer(mgr)
@@ -264,8 +266,29 @@ func initDataSources(mgr ctrl.Manager, opts *options.Options) (map[providers.Dat
 	return realtimeDataSources, historyDataSources, hybridDataSources
 }
 
-func initPredictorManager(opts *options.Options, realtimeDataSources map[providers.DataSourceType]providers.RealTime, historyDataSources map[providers.DataSourceType]providers.History) predictor.Manager {
-	return predictor.NewManager(realtimeDataSources, historyDataSources, predictor.DefaultPredictorsConfig(opts.AlgorithmModelConfig))
+func initPredictorManager(mgr ctrl.Manager, opts *options.Options, realtimeDataSources map[providers.DataSourceType]providers.RealTime, historyDataSources map[providers.DataSourceType]providers.History) predictor.Manager {
+	modelConfig := opts.AlgorithmModelConfig
+	switch checkpoint.StoreType(opts.CheckpointStoreType) {
+	case checkpoint.StoreTypeConfigMap:
+		modelConfig.CheckpointStore = checkpoint.NewConfigMapStore(mgr.GetClient(), known.CraneSystemNamespace)
+	case checkpoint.StoreTypeLocalFile:
+		store, err := checkpoint.NewFileStore(opts.CheckpointDir)
+		if err != nil {
+			klog.Exitf("unable to create checkpoint store, err: %v", err)
+		}
+		modelConfig.CheckpointStore = store
+	case checkpoint.StoreTypeNone:
+	default:
+		klog.Exitf("unknown checkpoint store %v", opts.CheckpointStoreType)
+	}
+	predictorsConfig := predictor.DefaultPredictorsConfig(modelConfig)
+	if opts.ExternalPredictorConfig.Address != "" {
+		predictorsConfig[external.AlgorithmTypeExternal] = predictor.Config{
+			ModelConfig: modelConfig,
+			External:    opts.ExternalPredictorConfig,
+		}
+	}
+	return predictor.NewManager(realtimeDataSources, historyDataSources, predictorsConfig)
 }
 
 // initControllers setup controllers with manager
@@ -361,6 +384,7 @@ func initControllers(ctx context.Context, oomRecorder oom.Recorder, mgr ctrl.Man
 			opts.PredictionUpdateFrequency,
 			predictorMgr,
 			targetSelectorFetcher,
+			historyDataSource,
 		)
 		if err := tspController.SetupWithManager(mgr, opts.TimeSeriesPredictionMaxConcurrentReconciles); err != nil {
 			klog.Exit(err, "unable to create controller", "controller", "TspController")
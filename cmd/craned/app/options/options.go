@@ -8,6 +8,7 @@ import (
 
 	"github.com/gocrane/crane/pkg/controller/ehpa"
 	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/external"
 	"github.com/gocrane/crane/pkg/providers"
 	serverconfig "github.com/gocrane/crane/pkg/server/config"
 	"github.com/gocrane/crane/pkg/webhooks"
@@ -39,6 +40,14 @@ type Options struct {
 	// AlgorithmModelConfig
 	AlgorithmModelConfig config.AlgorithmModelConfig
 
+	// ExternalPredictorConfig is the config for the external predictor, it is enabled if the address is not empty
+	ExternalPredictorConfig external.Config
+
+	// CheckpointStoreType is the type of the prediction model checkpoint store, configmap or file, empty means disabled
+	CheckpointStoreType string
+	// CheckpointDir is the directory for the file checkpoint store
+	CheckpointDir string
+
 	// WebhookConfig
 	WebhookConfig webhooks.WebhookConfig
 
@@ -134,7 +143,18 @@ func (o *Options) AddFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&o.DataSourceMockConfig.SeedFile, "seed-file", "", "mock provider seed file")
 	flags.StringVar(&o.DataSourceGrpcConfig.Address, "grpc-ds-address", "localhost:50051", "grpc data source server address")
 	flags.DurationVar(&o.DataSourceGrpcConfig.Timeout, "grpc-ds-timeout", time.Minute, "grpc timeout")
+	flags.StringVar(&o.ExternalPredictorConfig.Address, "external-predictor-address", "", "grpc address of the external predictor, empty means the external algorithm is disabled")
+	flags.DurationVar(&o.ExternalPredictorConfig.Timeout, "external-predictor-timeout", time.Minute, "timeout of each request to the external predictor")
+	flags.DurationVar(&o.ExternalPredictorConfig.HistoryLength, "external-predictor-history-length", 7*24*time.Hour, "length of the history time series sent to the external predictor")
+	flags.DurationVar(&o.ExternalPredictorConfig.SampleInterval, "external-predictor-sample-interval", time.Minute, "sample interval of the time series of the external predictor")
+	flags.DurationVar(&o.ExternalPredictorConfig.Horizon, "external-predictor-horizon", 24*time.Hour, "how far the external predictor predicts after each model update")
+	flags.StringVar(&o.ExternalPredictorConfig.CAFile, "external-predictor-ca-file", "", "CA certificate file to verify the external predictor, empty means the connection is insecure")
+	flags.StringVar(&o.ExternalPredictorConfig.CertFile, "external-predictor-cert-file", "", "client certificate file for mutual TLS with the external predictor")
+	flags.StringVar(&o.ExternalPredictorConfig.KeyFile, "external-predictor-key-file", "", "client key file for mutual TLS with the external predictor")
 	flags.DurationVar(&o.AlgorithmModelConfig.UpdateInterval, "model-update-interval", 12*time.Hour, "algorithm model update interval, now used for dsp model update interval")
+	flags.DurationVar(&o.AlgorithmModelConfig.CheckpointInterval, "model-checkpoint-interval", 10*time.Minute, "algorithm model checkpoint interval, used for the models whose init mode is checkpoint")
+	flags.StringVar(&o.CheckpointStoreType, "model-checkpoint-store", "", "algorithm model checkpoint store, configmap or file is available, empty means checkpoint is disabled")
+	flags.StringVar(&o.CheckpointDir, "model-checkpoint-dir", "/var/lib/craned/checkpoints", "algorithm model checkpoint directory, used for the file checkpoint store")
 	flags.BoolVar(&o.WebhookConfig.Enabled, "webhook-enabled", true, "whether enable webhook or not, default to true")
 	flags.StringVar(&o.RecommendationConfigFile, "recommendation-config-file", "", "recommendation configuration file")
 	flags.StringVar(&o.RecommendationConfiguration, "recommendation-configuration-file", "/tmp/recommendation-framework/recommendation_configuration.yaml", "recommendation configuration file")
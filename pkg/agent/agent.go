@@ -57,6 +57,10 @@ type Agent struct {
 	kubeClient  kubernetes.Interface
 	craneClient craneclientset.Interface
 	managers    []manager.Manager
+	// analyzer serves the dry run report
+	analyzer *analyzer.AnomalyAnalyzer
+	// actionExecutor serves the action history
+	actionExecutor *executor.ActionExecutor
 }
 
 func NewAgent(ctx context.Context,
@@ -76,7 +80,18 @@ func NewAgent(ctx context.Context,
 	collectInterval time.Duration,
 	executeExcess string,
 	cpuManagerReconcilePeriod time.Duration,
+	cpuManagerPolicy string,
 	defaultCPUPolicy string,
+	bestEffortLLCDomains int,
+	onlineCPUWatermark string,
+	minOfflineCPUs int,
+	forecastSource string,
+	forecastHorizon time.Duration,
+	pdbLister executor.PodDisruptionBudgetLister,
+	evictionLimits executor.EvictionLimits,
+	namespaceInformer coreinformers.NamespaceInformer,
+	actionHistorySize int,
+	exportActionHistory bool,
 ) (*Agent, error) {
 	var managers []manager.Manager
 	var noticeCh = make(chan executor.AvoidanceExecutor)
@@ -97,29 +112,57 @@ func NewAgent(ctx context.Context,
 	utilruntime.Must(topologyapi.AddToScheme(scheme.Scheme))
 	cadvisorManager := cadvisor.NewCadvisorManager(cgroupDriver)
 	exclusiveCPUSet := cpumanager.DefaultExclusiveCPUSet
+	cpuLayout := cpumanager.DefaultCPULayout
+	var cpuManager cpumanager.CPUManager
 	if utilfeature.DefaultFeatureGate.Enabled(features.CraneNodeResourceTopology) {
 		if err := agent.CreateNodeResourceTopology(sysPath); err != nil {
 			return nil, err
 		}
 		if utilfeature.DefaultFeatureGate.Enabled(features.CraneCPUManager) {
-			cpuManager, err := cpumanager.NewCPUManager(nodeName, defaultCPUPolicy, cpuManagerReconcilePeriod, cadvisorManager, runtimeService, kubeletRootPath, podInformer, nrtInformer)
+			cpuManager, err = cpumanager.NewCPUManager(nodeName, cpuManagerPolicy, defaultCPUPolicy, bestEffortLLCDomains, cpuManagerReconcilePeriod, cadvisorManager, runtimeService, kubeletRootPath, podInformer, nrtInformer)
 			if err != nil {
 				return nil, fmt.Errorf("failed to new cpumanager: %v", err)
 			}
 			exclusiveCPUSet = cpuManager.GetExclusiveCPUSet
+			cpuLayout = cpuManager.GetCPULayout
 			managers = appendManagerIfNotNil(managers, cpuManager)
 		}
 	}
 
-	stateCollector := collector.NewStateCollector(nodeName, sysPath, kubeClient, craneClient, nodeQOSInformer.Lister(), nrtInformer.Lister(), podInformer.Lister(), nodeInformer.Lister(), ifaces, healthCheck, collectInterval, exclusiveCPUSet, cadvisorManager)
+	stateCollector := collector.NewStateCollector(nodeName, sysPath, kubeClient, craneClient, nodeQOSInformer.Lister(), nrtInformer.Lister(), podInformer.Lister(), nodeInformer.Lister(), ifaces, healthCheck, collectInterval, exclusiveCPUSet, cpuLayout, cadvisorManager)
 	managers = appendManagerIfNotNil(managers, stateCollector)
-	analyzerManager := analyzer.NewAnomalyAnalyzer(kubeClient, nodeName, podInformer, nodeInformer, nodeQOSInformer, podQOSInformer, actionInformer, stateCollector.AnalyzerChann, noticeCh)
+	if cpuManager != nil && utilfeature.DefaultFeatureGate.Enabled(features.CraneCPUSetRebalance) {
+		watermark, err := utils.ParsePercentage(onlineCPUWatermark)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse online cpu watermark: %v", err)
+		}
+		cpuSetRebalancer := cpumanager.NewCPUSetRebalancer(cpuManager, podInformer.Lister(), stateCollector.CPUSetChann, watermark, minOfflineCPUs)
+		managers = appendManagerIfNotNil(managers, cpuSetRebalancer)
+	}
+	nodeResource := utilfeature.DefaultFeatureGate.Enabled(features.CraneNodeResource)
+	var tspName string
+	if nodeResource || forecastSource == analyzer.ForecastSourceTsp {
+		tspName = agent.CreateNodeResourceTsp()
+	}
+	forecaster, err := analyzer.NewForecaster(forecastSource, forecastHorizon, tspInformer.Lister(), tspName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to new forecaster: %v", err)
+	}
+	executeExcessPercent, err := utils.ParsePercentage(executeExcess)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse execute excess: %v", err)
+	}
+	if executeExcessPercent > 100 {
+		return nil, fmt.Errorf("execute excess %s is more than 100%%", executeExcess)
+	}
+	analyzerManager := analyzer.NewAnomalyAnalyzer(kubeClient, nodeName, podInformer, nodeInformer, namespaceInformer, nodeQOSInformer, podQOSInformer, actionInformer, stateCollector.AnalyzerChann, noticeCh, forecaster, executeExcessPercent)
 	managers = appendManagerIfNotNil(managers, analyzerManager)
-	avoidanceManager := executor.NewActionExecutor(kubeClient, nodeName, podInformer, nodeInformer, noticeCh, runtimeEndpoint, stateCollector.State, executeExcess)
+	agent.analyzer = analyzerManager
+	avoidanceManager := executor.NewActionExecutor(kubeClient, nodeName, podInformer, nodeInformer, pdbLister, noticeCh, runtimeEndpoint, cgroupDriver, sysPath, stateCollector.State, executeExcessPercent, evictionLimits, actionHistorySize, exportActionHistory)
 	managers = appendManagerIfNotNil(managers, avoidanceManager)
+	agent.actionExecutor = avoidanceManager
 
-	if nodeResource := utilfeature.DefaultFeatureGate.Enabled(features.CraneNodeResource); nodeResource {
-		tspName := agent.CreateNodeResourceTsp()
+	if nodeResource {
 		nodeResourceManager, err := resource.NewNodeResourceManager(kubeClient, nodeName, nodeResourceReserved, tspName, nodeInformer, tspInformer, stateCollector.NodeResourceChann)
 		if err != nil {
 			return agent, err
@@ -128,7 +171,7 @@ func NewAgent(ctx context.Context,
 	}
 
 	if podResource := utilfeature.DefaultFeatureGate.Enabled(features.CranePodResource); podResource {
-		podResourceManager := resource.NewPodResourceManager(kubeClient, nodeName, podInformer, runtimeEndpoint, stateCollector.PodResourceChann, stateCollector.GetCadvisorManager())
+		podResourceManager := resource.NewPodResourceManager(kubeClient, nodeName, podInformer, runtimeEndpoint, sysPath, stateCollector.PodResourceChann, stateCollector.GetCadvisorManager())
 		managers = appendManagerIfNotNil(managers, podResourceManager)
 	}
 
@@ -154,6 +197,10 @@ func (a *Agent) Run(healthCheck *metrics.HealthCheck, enableProfiling bool, bind
 		})
 
 		pathRecorderMux.HandleFunc("/health-check", healthCheck.ServeHTTP)
+		pathRecorderMux.HandleFunc("/dry-run", a.analyzer.ServeDryRun)
+		if a.actionExecutor != nil {
+			pathRecorderMux.HandleFunc("/action-history", a.actionExecutor.ServeActionHistory)
+		}
 		if enableProfiling {
 			routes.Profiling{}.Install(pathRecorderMux)
 		}
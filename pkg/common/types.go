@@ -12,6 +12,8 @@ const (
 	LabelNameContainerName = "ContainerName"
 	LabelNameContainerId   = "ContainerId"
 	LabelNameHasExtRes     = "HasExtRes"
+	// LabelNameDevice is the major:minor of the block device of the disk io metrics
+	LabelNameDevice = "Device"
 )
 
 // TimeSeries is a stream of samples that belong to a metric with a set of labels
@@ -279,11 +279,22 @@ func (c *EffectiveHPAController) GetHPAMetrics(ctx context.Context, ehpa *autosc
 				continue
 			}
 
-			if _, err := utils.GetReadyPredictionMetric(name, metricIdentifier, tsp); err != nil {
+			band := utils.GetEHPAPredictionBand(ehpa)
+			if _, err := utils.GetReadyPredictionMetricOfBand(name, metricIdentifier, band, tsp); err != nil {
 				// metric is not predictable
 				continue
 			}
 
+			matchLabels := map[string]string{
+				"targetKind":         ehpa.Spec.ScaleTargetRef.Kind,
+				"targetName":         ehpa.Spec.ScaleTargetRef.Name,
+				"targetNamespace":    ehpa.Namespace,
+				"resourceIdentifier": metricIdentifier,
+			}
+			if band != "" {
+				matchLabels["predictionBand"] = band
+			}
+
 			// generate an external metric for Prediction metric
 			external := &autoscalingv2.ExternalMetricSource{
 				Metric: autoscalingv2.MetricIdentifier{
@@ -291,12 +302,7 @@ func (c *EffectiveHPAController) GetHPAMetrics(ctx context.Context, ehpa *autosc
 					// add known.EffectiveHorizontalPodAutoscalerUidLabel=uid in metric.selector
 					// MetricAdapter use label selector to match the matching TimeSeriesPrediction to return metrics
 					Selector: &metav1.LabelSelector{
-						MatchLabels: map[string]string{
-							"targetKind":         ehpa.Spec.ScaleTargetRef.Kind,
-							"targetName":         ehpa.Spec.ScaleTargetRef.Name,
-							"targetNamespace":    ehpa.Namespace,
-							"resourceIdentifier": metricIdentifier,
-						},
+						MatchLabels: matchLabels,
 					},
 				},
 				Target: autoscalingv2.MetricTarget{
@@ -57,6 +57,15 @@ func (c *EffectiveHPAController) GetPredication(ctx context.Context, ehpa *autos
 	return &predictionList.Items[0], nil
 }
 
+// propagatedPredictionAnnotations are the annotations of the ehpa which configure its TimeSeriesPrediction.
+var propagatedPredictionAnnotations = []string{
+	known.TimeSeriesPredictionCalendarAnnotation,
+	known.TimeSeriesPredictionOutlierRemovalAnnotation,
+	known.TimeSeriesPredictionParametersAnnotation,
+	known.TimeSeriesPredictionModelInitModeAnnotation,
+	known.TimeSeriesPredictionHoltWintersAnnotation,
+}
+
 func (c *EffectiveHPAController) CreatePrediction(ctx context.Context, ehpa *autoscalingapi.EffectiveHorizontalPodAutoscaler) (*predictionapi.TimeSeriesPrediction, error) {
 	prediction, err := c.NewPredictionObject(ehpa)
 	if err != nil {
@@ -86,8 +95,25 @@ func (c *EffectiveHPAController) UpdatePredictionIfNeed(ctx context.Context, ehp
 		return nil, err
 	}
 
-	if !equality.Semantic.DeepEqual(&predictionExist.Spec, &prediction.Spec) {
+	annotationsChanged := false
+	for _, key := range propagatedPredictionAnnotations {
+		if prediction.Annotations[key] != predictionExist.Annotations[key] {
+			annotationsChanged = true
+		}
+	}
+	if !equality.Semantic.DeepEqual(&predictionExist.Spec, &prediction.Spec) || annotationsChanged {
 		predictionExist.Spec = prediction.Spec
+		for _, key := range propagatedPredictionAnnotations {
+			value := prediction.Annotations[key]
+			if value == "" {
+				delete(predictionExist.Annotations, key)
+				continue
+			}
+			if predictionExist.Annotations == nil {
+				predictionExist.Annotations = map[string]string{}
+			}
+			predictionExist.Annotations[key] = value
+		}
 		err := c.Update(ctx, predictionExist)
 		if err != nil {
 			c.Recorder.Event(ehpa, v1.EventTypeWarning, "FailedUpdatePrediction", err.Error())
@@ -128,6 +154,15 @@ func (c *EffectiveHPAController) NewPredictionObject(ehpa *autoscalingapi.Effect
 		},
 	}
 
+	for _, key := range propagatedPredictionAnnotations {
+		if value, ok := ehpa.Annotations[key]; ok {
+			if prediction.Annotations == nil {
+				prediction.Annotations = map[string]string{}
+			}
+			prediction.Annotations[key] = value
+		}
+	}
+
 	// get MetricRules
 	mrs := prometheus_adapter.GetMetricRules()
 
@@ -0,0 +1,310 @@
+package timeseriesprediction
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/metrics"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/accuracy"
+	"github.com/gocrane/crane/pkg/providers"
+)
+
+const (
+	// TimeSeriesPredictionConditionAccuracy reports the rolling accuracy of the earlier predictions, the api has no
+	// dedicated field for it, so it is reported as a condition of the status.
+	TimeSeriesPredictionConditionAccuracy predictionapi.PredictionConditionType = "Accuracy"
+
+	// defaultAccuracyWindow is the rolling window of the evaluated predictions
+	defaultAccuracyWindow = 7 * 24 * time.Hour
+	// defaultAccuracyEvaluationInterval is the minimal interval between two evaluations of a metric
+	defaultAccuracyEvaluationInterval = 10 * time.Minute
+	// actual data is scraped and ingested with a delay, so the latest predictions are evaluated later
+	defaultAccuracyEvaluationDelay = 5 * time.Minute
+	defaultAccuracyResolution      = time.Minute
+	// defaultAccuracyGracePeriod is how long a predicted point waits for its actual data after the evaluation delay, it
+	// is dropped if the actual data is still missing, e.g. the pod has gone, so that it doesn't hold the query start back
+	defaultAccuracyGracePeriod = 3 * defaultAccuracyResolution
+)
+
+type predictedPoint struct {
+	predicted float64
+	actual    float64
+	evaluated bool
+}
+
+type seriesPredictions struct {
+	// points is keyed by the timestamp truncated to defaultAccuracyResolution
+	points map[int64]*predictedPoint
+}
+
+type metricPredictions struct {
+	namer         metricnaming.MetricNamer
+	algorithm     string
+	series        map[string]*seriesPredictions
+	lastEvaluated time.Time
+}
+
+// metricAccuracy is the rolling accuracy of a metric.
+type metricAccuracy struct {
+	resourceIdentifier string
+	algorithm          string
+	mape               float64
+	mapeErr            error
+	mae                float64
+	samples            int
+}
+
+func (m metricAccuracy) String() string {
+	if m.samples == 0 {
+		return fmt.Sprintf("%s: unknown", m.resourceIdentifier)
+	}
+	if m.mapeErr != nil {
+		return fmt.Sprintf("%s: mae=%.4f, samples=%d", m.resourceIdentifier, m.mae, m.samples)
+	}
+	return fmt.Sprintf("%s: mape=%.4f, mae=%.4f, samples=%d", m.resourceIdentifier, m.mape, m.mae, m.samples)
+}
+
+// accuracyTracker keeps the earlier predicted windows of TimeSeriesPredictions and compares them with the actual data
+// once it arrives. For every timestamp only the first prediction is kept, so that the accuracy reflects how the
+// forecast looked like in advance.
+type accuracyTracker struct {
+	lock sync.Mutex
+	// predictions is keyed by tsp key, then resource identifier
+	predictions map[string]map[string]*metricPredictions
+}
+
+func newAccuracyTracker() *accuracyTracker {
+	return &accuracyTracker{
+		predictions: map[string]map[string]*metricPredictions{},
+	}
+}
+
+// record keeps the predicted time series of a metric.
+func (t *accuracyTracker) record(key string, metric *predictionapi.PredictionMetric, namer metricnaming.MetricNamer, tsList []*common.TimeSeries, now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	metricsMap, ok := t.predictions[key]
+	if !ok {
+		metricsMap = map[string]*metricPredictions{}
+		t.predictions[key] = metricsMap
+	}
+	mp, ok := metricsMap[metric.ResourceIdentifier]
+	if !ok || mp.algorithm != string(metric.Algorithm.AlgorithmType) {
+		mp = &metricPredictions{
+			algorithm: string(metric.Algorithm.AlgorithmType),
+			series:    map[string]*seriesPredictions{},
+		}
+		metricsMap[metric.ResourceIdentifier] = mp
+	}
+	mp.namer = namer
+
+	for _, ts := range tsList {
+		seriesKey := prediction.AggregateSignalKey(ts.Labels)
+		sp, ok := mp.series[seriesKey]
+		if !ok {
+			sp = &seriesPredictions{points: map[int64]*predictedPoint{}}
+			mp.series[seriesKey] = sp
+		}
+		for _, sample := range ts.Samples {
+			timestamp := time.Unix(sample.Timestamp, 0).Truncate(defaultAccuracyResolution).Unix()
+			if _, exists := sp.points[timestamp]; !exists {
+				sp.points[timestamp] = &predictedPoint{predicted: sample.Value}
+			}
+		}
+	}
+
+	mp.prune(now)
+}
+
+// prune removes the predicted points out of the rolling window.
+func (mp *metricPredictions) prune(now time.Time) {
+	oldest := now.Add(-defaultAccuracyWindow).Unix()
+	for key, sp := range mp.series {
+		for timestamp := range sp.points {
+			if timestamp < oldest {
+				delete(sp.points, timestamp)
+			}
+		}
+		if len(sp.points) == 0 {
+			delete(mp.series, key)
+		}
+	}
+}
+
+// expire removes the predicted points which are not evaluated until the time, since their actual data is missing.
+func (mp *metricPredictions) expire(until time.Time) {
+	for key, sp := range mp.series {
+		for timestamp, point := range sp.points {
+			if !point.evaluated && timestamp <= until.Unix() {
+				delete(sp.points, timestamp)
+			}
+		}
+		if len(sp.points) == 0 {
+			delete(mp.series, key)
+		}
+	}
+}
+
+// pendingRange returns the time range of the predicted points which are not evaluated yet and whose actual data should
+// have arrived.
+func (mp *metricPredictions) pendingRange(now time.Time) (time.Time, time.Time, bool) {
+	end := now.Add(-defaultAccuracyEvaluationDelay).Unix()
+	start := int64(0)
+	for _, sp := range mp.series {
+		for timestamp, point := range sp.points {
+			if !point.evaluated && timestamp <= end && (start == 0 || timestamp < start) {
+				start = timestamp
+			}
+		}
+	}
+	if start == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	return time.Unix(start, 0), time.Unix(end, 0), true
+}
+
+// match fills the actual values of the predicted points.
+func (mp *metricPredictions) match(actual []*common.TimeSeries) {
+	for _, ts := range actual {
+		sp, ok := mp.series[prediction.AggregateSignalKey(ts.Labels)]
+		if !ok {
+			// the labels of an aggregated prediction may differ from the actual time series
+			if len(mp.series) != 1 || len(actual) != 1 {
+				continue
+			}
+			for _, only := range mp.series {
+				sp = only
+			}
+		}
+		for _, sample := range ts.Samples {
+			timestamp := time.Unix(sample.Timestamp, 0).Truncate(defaultAccuracyResolution).Unix()
+			if point, exists := sp.points[timestamp]; exists && !point.evaluated {
+				point.actual = sample.Value
+				point.evaluated = true
+			}
+		}
+	}
+}
+
+// accuracy returns the rolling accuracy of all the evaluated points, the samples is zero if none of the points is
+// evaluated.
+func (mp *metricPredictions) accuracy(resourceIdentifier string) metricAccuracy {
+	var actual, predicted []float64
+	for _, sp := range mp.series {
+		for _, point := range sp.points {
+			if point.evaluated {
+				actual = append(actual, point.actual)
+				predicted = append(predicted, point.predicted)
+			}
+		}
+	}
+
+	result := metricAccuracy{resourceIdentifier: resourceIdentifier, algorithm: mp.algorithm, samples: len(actual)}
+	if len(actual) == 0 {
+		return result
+	}
+	result.mape, result.mapeErr = accuracy.MAPE(actual, predicted)
+	result.mae, _ = accuracy.MAE(actual, predicted)
+	return result
+}
+
+// evaluate compares the predicted points of the TimeSeriesPrediction with the actual data from the history provider,
+// it returns the rolling accuracy of the metrics which are evaluated this time.
+func (t *accuracyTracker) evaluate(key string, history providers.History, now time.Time) []metricAccuracy {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var results []metricAccuracy
+	for resourceIdentifier, mp := range t.predictions[key] {
+		if now.Sub(mp.lastEvaluated) < defaultAccuracyEvaluationInterval {
+			continue
+		}
+		mp.prune(now)
+
+		if start, end, ok := mp.pendingRange(now); ok {
+			actual, err := history.QueryTimeSeries(mp.namer, start, end, defaultAccuracyResolution)
+			if err != nil {
+				klog.ErrorS(err, "Failed to query actual time series for accuracy.", "tsp", key, "metric", resourceIdentifier)
+				continue
+			}
+			mp.match(actual)
+			mp.expire(end.Add(-defaultAccuracyGracePeriod))
+		}
+		mp.lastEvaluated = now
+
+		results = append(results, mp.accuracy(resourceIdentifier))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].resourceIdentifier < results[j].resourceIdentifier
+	})
+	return results
+}
+
+// delete removes all the predictions of the TimeSeriesPrediction and returns them.
+func (t *accuracyTracker) delete(key string) map[string]*metricPredictions {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	removed := t.predictions[key]
+	delete(t.predictions, key)
+	return removed
+}
+
+// syncAccuracy evaluates the accuracy of the TimeSeriesPrediction and reports it as the Accuracy condition and
+// Prometheus metrics. It returns true if the condition is updated.
+func (tc *Controller) syncAccuracy(tsPrediction *predictionapi.TimeSeriesPrediction, status *predictionapi.TimeSeriesPredictionStatus) bool {
+	if tc.HistoryProvider == nil {
+		return false
+	}
+
+	results := tc.accuracyTracker.evaluate(GetTimeSeriesPredictionKey(tsPrediction), tc.HistoryProvider, time.Now())
+	if len(results) == 0 {
+		return false
+	}
+
+	var messages []string
+	evaluated := false
+	for _, result := range results {
+		messages = append(messages, result.String())
+		if result.samples == 0 {
+			continue
+		}
+		evaluated = true
+		if result.mapeErr == nil {
+			metrics.TimeSeriesPredictionError.WithLabelValues(tsPrediction.Namespace, tsPrediction.Name, result.resourceIdentifier, result.algorithm, "mape").Set(result.mape)
+		}
+		metrics.TimeSeriesPredictionError.WithLabelValues(tsPrediction.Namespace, tsPrediction.Name, result.resourceIdentifier, result.algorithm, "mae").Set(result.mae)
+		metrics.TimeSeriesPredictionEvaluatedSamples.WithLabelValues(tsPrediction.Namespace, tsPrediction.Name, result.resourceIdentifier, result.algorithm).Set(float64(result.samples))
+	}
+
+	message := fmt.Sprintf("accuracy of the last %v: %s", defaultAccuracyWindow, strings.Join(messages, "; "))
+	if !evaluated {
+		// none of the predicted points has the actual data yet
+		setCondition(status, TimeSeriesPredictionConditionAccuracy, metav1.ConditionUnknown, known.ReasonTimeSeriesPredictionAccuracyUnknown, message)
+		return true
+	}
+	setCondition(status, TimeSeriesPredictionConditionAccuracy, metav1.ConditionTrue, known.ReasonTimeSeriesPredictionAccuracyEvaluated, message)
+	return true
+}
+
+// removeAccuracy removes the tracked predictions and Prometheus metrics of the TimeSeriesPrediction.
+func (tc *Controller) removeAccuracy(tsPrediction *predictionapi.TimeSeriesPrediction) {
+	for resourceIdentifier, mp := range tc.accuracyTracker.delete(GetTimeSeriesPredictionKey(tsPrediction)) {
+		metrics.TimeSeriesPredictionError.DeleteLabelValues(tsPrediction.Namespace, tsPrediction.Name, resourceIdentifier, mp.algorithm, "mape")
+		metrics.TimeSeriesPredictionError.DeleteLabelValues(tsPrediction.Namespace, tsPrediction.Name, resourceIdentifier, mp.algorithm, "mae")
+		metrics.TimeSeriesPredictionEvaluatedSamples.DeleteLabelValues(tsPrediction.Namespace, tsPrediction.Name, resourceIdentifier, mp.algorithm)
+	}
+}
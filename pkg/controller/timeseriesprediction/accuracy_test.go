@@ -0,0 +1,110 @@
+package timeseriesprediction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/metricnaming"
+)
+
+type fakeHistory struct {
+	tsList []*common.TimeSeries
+}
+
+func (f *fakeHistory) QueryTimeSeries(metricNamer metricnaming.MetricNamer, startTime time.Time, endTime time.Time, step time.Duration) ([]*common.TimeSeries, error) {
+	return f.tsList, nil
+}
+
+func TestAccuracyTracker(t *testing.T) {
+	tracker := newAccuracyTracker()
+	metric := &predictionapi.PredictionMetric{
+		ResourceIdentifier: "cpu",
+		Algorithm:          predictionapi.Algorithm{AlgorithmType: predictionapi.AlgorithmTypeDSP},
+	}
+	now := time.Now().Truncate(time.Minute)
+
+	var predicted, actual []common.Sample
+	for i := 0; i < 60; i++ {
+		timestamp := now.Add(time.Duration(i-90) * time.Minute).Unix()
+		predicted = append(predicted, common.Sample{Timestamp: timestamp, Value: 2})
+		actual = append(actual, common.Sample{Timestamp: timestamp, Value: 1})
+	}
+	tracker.record("default/tsp", metric, nil, []*common.TimeSeries{{Samples: predicted}}, now)
+
+	// a later prediction does not overwrite the earlier one
+	later := []common.Sample{{Timestamp: predicted[0].Timestamp, Value: 100}}
+	tracker.record("default/tsp", metric, nil, []*common.TimeSeries{{Samples: later}}, now)
+
+	history := &fakeHistory{tsList: []*common.TimeSeries{{Labels: []common.Label{{Name: "pod", Value: "a"}}, Samples: actual}}}
+	results := tracker.evaluate("default/tsp", history, now)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "cpu", results[0].resourceIdentifier)
+	assert.Equal(t, 60, results[0].samples)
+	assert.InDelta(t, 1.0, results[0].mape, 1e-9)
+	assert.InDelta(t, 1.0, results[0].mae, 1e-9)
+
+	// throttled by the evaluation interval
+	assert.Empty(t, tracker.evaluate("default/tsp", history, now.Add(time.Minute)))
+
+	removed := tracker.delete("default/tsp")
+	assert.Len(t, removed, 1)
+	assert.Empty(t, tracker.evaluate("default/tsp", history, now.Add(time.Hour)))
+}
+
+func TestAccuracyTrackerMissingActual(t *testing.T) {
+	tracker := newAccuracyTracker()
+	metric := &predictionapi.PredictionMetric{
+		ResourceIdentifier: "cpu",
+		Algorithm:          predictionapi.Algorithm{AlgorithmType: predictionapi.AlgorithmTypeDSP},
+	}
+	now := time.Now().Truncate(time.Minute)
+
+	a := []common.Label{{Name: "pod", Value: "a"}}
+	b := []common.Label{{Name: "pod", Value: "b"}}
+	var predicted, actual []common.Sample
+	for i := 0; i < 60; i++ {
+		timestamp := now.Add(time.Duration(i-60) * time.Minute).Unix()
+		predicted = append(predicted, common.Sample{Timestamp: timestamp, Value: 2})
+		actual = append(actual, common.Sample{Timestamp: timestamp, Value: 1})
+	}
+	// the pod b has gone, there is no actual data of its predicted points
+	tracker.record("default/tsp", metric, nil, []*common.TimeSeries{{Labels: a, Samples: predicted}, {Labels: b, Samples: predicted}}, now)
+
+	history := &fakeHistory{tsList: []*common.TimeSeries{{Labels: a, Samples: actual}}}
+	results := tracker.evaluate("default/tsp", history, now)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 60, results[0].samples)
+
+	// the points of pod b out of the grace period are dropped instead of holding the query start back
+	mp := tracker.predictions["default/tsp"]["cpu"]
+	start, end, ok := mp.pendingRange(now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(-defaultAccuracyEvaluationDelay-defaultAccuracyGracePeriod+time.Minute), start)
+	assert.Equal(t, now.Add(-defaultAccuracyEvaluationDelay), end)
+}
+
+func TestSyncAccuracyUnknown(t *testing.T) {
+	tc := &Controller{HistoryProvider: &fakeHistory{}, accuracyTracker: newAccuracyTracker()}
+	tsp := &predictionapi.TimeSeriesPrediction{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "tsp"}}
+	metric := &predictionapi.PredictionMetric{
+		ResourceIdentifier: "cpu",
+		Algorithm:          predictionapi.Algorithm{AlgorithmType: predictionapi.AlgorithmTypeDSP},
+	}
+	now := time.Now().Truncate(time.Minute)
+	predicted := []common.Sample{{Timestamp: now.Add(-time.Hour).Unix(), Value: 1}}
+	tc.accuracyTracker.record(GetTimeSeriesPredictionKey(tsp), metric, nil, []*common.TimeSeries{{Samples: predicted}}, now)
+
+	// the actual data is missing, so the accuracy can't be computed
+	status := &predictionapi.TimeSeriesPredictionStatus{}
+	assert.True(t, tc.syncAccuracy(tsp, status))
+	assert.Len(t, status.Conditions, 1)
+	assert.Equal(t, metav1.ConditionUnknown, status.Conditions[0].Status)
+	assert.Equal(t, known.ReasonTimeSeriesPredictionAccuracyUnknown, status.Conditions[0].Reason)
+}
@@ -1,6 +1,7 @@
 package timeseriesprediction
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
 
+	"github.com/gocrane/crane/pkg/known"
 	"github.com/gocrane/crane/pkg/metricnaming"
 	"github.com/gocrane/crane/pkg/metricquery"
 	predconf "github.com/gocrane/crane/pkg/prediction/config"
@@ -189,7 +191,86 @@ func (c *MetricContext) ConvertApiMetrics2InternalConfigs(metrics []predictionap
 // ConvertApiMetric2InternalConfig
 func (c *MetricContext) ConvertApiMetric2InternalConfig(metric *predictionapi.PredictionMetric) *predconf.Config {
 	return &predconf.Config{
-		DSP:        metric.Algorithm.DSP,
-		Percentile: metric.Algorithm.Percentile,
+		DSP:                   metric.Algorithm.DSP,
+		Percentile:            metric.Algorithm.Percentile,
+		Calendar:              c.getCalendar(),
+		OutlierRemoval:        c.getOutlierRemoval(),
+		Parameters:            c.getParameters(),
+		InitMode:              c.getInitMode(),
+		HoltWintersEstimators: c.getHoltWintersEstimators(),
 	}
 }
+
+// getHoltWintersEstimators returns the Holt-Winters estimators from the annotation of the TimeSeriesPrediction, invalid
+// estimators are ignored.
+func (c *MetricContext) getHoltWintersEstimators() []predconf.HoltWintersEstimator {
+	data, ok := c.SeriesPrediction.Annotations[known.TimeSeriesPredictionHoltWintersAnnotation]
+	if !ok {
+		return nil
+	}
+	estimators, err := predconf.ParseHoltWintersEstimators(data)
+	if err != nil {
+		klog.ErrorS(err, "Failed to parse holt-winters estimators, ignore them.", "tsp", klog.KObj(c.SeriesPrediction))
+		return nil
+	}
+	return estimators
+}
+
+// getInitMode returns the model init mode from the annotation of the TimeSeriesPrediction, an unknown mode is ignored.
+func (c *MetricContext) getInitMode() *predconf.ModelInitMode {
+	mode, ok := c.SeriesPrediction.Annotations[known.TimeSeriesPredictionModelInitModeAnnotation]
+	if !ok {
+		return nil
+	}
+	switch initMode := predconf.ModelInitMode(mode); initMode {
+	case predconf.ModelInitModeHistory, predconf.ModelInitModeLazyTraining, predconf.ModelInitModeCheckpoint:
+		return &initMode
+	default:
+		klog.ErrorS(fmt.Errorf("unknown model init mode %q", mode), "Failed to parse model init mode, ignore it.", "tsp", klog.KObj(c.SeriesPrediction))
+		return nil
+	}
+}
+
+// getCalendar returns the calendar from the annotation of the TimeSeriesPrediction, an invalid calendar is ignored.
+func (c *MetricContext) getCalendar() *predconf.Calendar {
+	data, ok := c.SeriesPrediction.Annotations[known.TimeSeriesPredictionCalendarAnnotation]
+	if !ok {
+		return nil
+	}
+	calendar, err := predconf.ParseCalendar(data)
+	if err != nil {
+		klog.ErrorS(err, "Failed to parse calendar, ignore it.", "tsp", klog.KObj(c.SeriesPrediction))
+		return nil
+	}
+	return calendar
+}
+
+// getOutlierRemoval returns the outlier removal from the annotation of the TimeSeriesPrediction, an invalid one is
+// ignored.
+func (c *MetricContext) getOutlierRemoval() *predconf.OutlierRemoval {
+	data, ok := c.SeriesPrediction.Annotations[known.TimeSeriesPredictionOutlierRemovalAnnotation]
+	if !ok {
+		return nil
+	}
+	outlierRemoval, err := predconf.ParseOutlierRemoval(data)
+	if err != nil {
+		klog.ErrorS(err, "Failed to parse outlier removal, ignore it.", "tsp", klog.KObj(c.SeriesPrediction))
+		return nil
+	}
+	return outlierRemoval
+}
+
+// getParameters returns the parameters for the external predictor from the annotation of the TimeSeriesPrediction,
+// invalid parameters are ignored.
+func (c *MetricContext) getParameters() map[string]string {
+	data, ok := c.SeriesPrediction.Annotations[known.TimeSeriesPredictionParametersAnnotation]
+	if !ok {
+		return nil
+	}
+	parameters := map[string]string{}
+	if err := json.Unmarshal([]byte(data), &parameters); err != nil {
+		klog.ErrorS(err, "Failed to parse parameters, ignore them.", "tsp", klog.KObj(c.SeriesPrediction))
+		return nil
+	}
+	return parameters
+}
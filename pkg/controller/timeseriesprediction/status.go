@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -13,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -22,6 +24,7 @@ import (
 
 	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/metricnaming"
 	"github.com/gocrane/crane/pkg/prediction"
 )
 
@@ -50,6 +53,8 @@ func (tc *Controller) syncPredictionStatus(ctx context.Context, tsPrediction *pr
 	windowStart := time.Now()
 	windowEnd := windowStart.Add(time.Duration(tsPrediction.Spec.PredictionWindowSeconds) * time.Second)
 	warnings := tc.isPredictionDataOutDated(windowStart, windowEnd, tsPrediction.Status.PredictionMetrics)
+	// evaluate the earlier predictions against the actual data, it is throttled by the accuracy tracker
+	accuracyUpdated := tc.syncAccuracy(tsPrediction, newStatus)
 	// force predict and update the status
 	if len(warnings) > 0 {
 		klog.V(4).Infof("Check status predict data is out of date. range: %v, key: %v", fmt.Sprintf("[%v, %v]", windowStart, windowEnd), key)
@@ -57,7 +62,7 @@ func (tc *Controller) syncPredictionStatus(ctx context.Context, tsPrediction *pr
 		// double the time to predict so that crd consumer always see time series range [now, now + PredictionWindowSeconds] in PredictionWindowSeconds window
 		predictionEnd := predictionStart.Add(time.Duration(tsPrediction.Spec.PredictionWindowSeconds) * time.Second * 2)
 
-		predictedData, err := tc.doPredict(tsPrediction, predictionStart, predictionEnd)
+		predictedData, models, err := tc.doPredict(tsPrediction, predictionStart, predictionEnd)
 		newStatus.PredictionMetrics = predictedData
 		if len(tsPrediction.Spec.PredictionMetrics) != len(predictedData) || err != nil {
 			klog.V(4).Infof("DoPredict predict data is partial, predictedDataLen: %v, key: %v", len(predictedData), key)
@@ -72,7 +77,12 @@ func (tc *Controller) syncPredictionStatus(ctx context.Context, tsPrediction *pr
 
 		klog.V(4).Infof("DoPredict predict data is complete, range: %v, key: %v", fmt.Sprintf("[%v, %v]", windowStart, windowEnd), key)
 		// status.conditions.reason in body should be at least 1 chars long
-		setCondition(newStatus, predictionapi.TimeSeriesPredictionConditionReady, metav1.ConditionTrue, known.ReasonTimeSeriesPredictSucceed, "")
+		// the message tells which model family is used for each metric if the predictor is able to describe it
+		var message string
+		if len(models) > 0 {
+			message = "model families: " + strings.Join(models, "; ")
+		}
+		setCondition(newStatus, predictionapi.TimeSeriesPredictionConditionReady, metav1.ConditionTrue, known.ReasonTimeSeriesPredictSucceed, message)
 
 		err = tc.UpdateStatus(ctx, tsPrediction, newStatus)
 		if err != nil {
@@ -82,6 +92,11 @@ func (tc *Controller) syncPredictionStatus(ctx context.Context, tsPrediction *pr
 		return ctrl.Result{RequeueAfter: tc.UpdatePeriod}, nil
 
 	}
+	if accuracyUpdated {
+		if err := tc.UpdateStatus(ctx, tsPrediction, newStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 	return ctrl.Result{RequeueAfter: tc.UpdatePeriod}, nil
 }
 
@@ -109,11 +124,12 @@ func (tc *Controller) getPredictor(algorithmType predictionapi.AlgorithmType) pr
 	return tc.predictorMgr.GetPredictor(algorithmType)
 }
 
-func (tc *Controller) doPredict(tsPrediction *predictionapi.TimeSeriesPrediction, start, end time.Time) ([]predictionapi.PredictionMetricStatus, error) {
+func (tc *Controller) doPredict(tsPrediction *predictionapi.TimeSeriesPrediction, start, end time.Time) ([]predictionapi.PredictionMetricStatus, []string, error) {
 	var result []predictionapi.PredictionMetricStatus
+	var models []string
 	c, err := NewMetricContext(tc.TargetFetcher, tsPrediction, tc.predictorMgr)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var errs []error
@@ -162,6 +178,10 @@ func (tc *Controller) doPredict(tsPrediction *predictionapi.TimeSeriesPrediction
 			errs = append(errs, err)
 		} else {
 			status.Ready = true
+			tc.accuracyTracker.record(GetTimeSeriesPredictionKey(tsPrediction), &metric, namer, prediction.FilterBand(data, ""), time.Now())
+			if families := describeModelFamilies(predictor, namer); families != "" {
+				models = append(models, fmt.Sprintf("%s: %s", metric.ResourceIdentifier, families))
+			}
 		}
 
 		result = append(result, status)
@@ -170,7 +190,29 @@ func (tc *Controller) doPredict(tsPrediction *predictionapi.TimeSeriesPrediction
 	if len(errs) != 0 {
 		err = utilerrors.NewAggregate(errs)
 	}
-	return result, err
+	return result, models, err
+}
+
+// describeModelFamilies returns the sorted model families used for the time series of the metric, or an empty string
+// if the predictor can not describe its models. The seasonal periods follow the family if any, e.g. periodic(24h0m0s).
+func describeModelFamilies(predictor prediction.Interface, namer metricnaming.MetricNamer) string {
+	describer, ok := predictor.(prediction.ModelDescriber)
+	if !ok {
+		return ""
+	}
+	families := sets.NewString()
+	for _, model := range describer.DescribeModels(namer) {
+		if len(model.Periods) == 0 {
+			families.Insert(model.Family)
+			continue
+		}
+		periods := make([]string, 0, len(model.Periods))
+		for _, p := range model.Periods {
+			periods = append(periods, p.String())
+		}
+		families.Insert(fmt.Sprintf("%s(%s)", model.Family, strings.Join(periods, ",")))
+	}
+	return strings.Join(families.List(), ",")
 }
 
 func (tc *Controller) UpdateStatus(ctx context.Context, tsPrediction *predictionapi.TimeSeriesPrediction, newStatus *predictionapi.TimeSeriesPredictionStatus) error {
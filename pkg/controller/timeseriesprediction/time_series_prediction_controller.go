@@ -21,6 +21,7 @@ import (
 
 	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
 	predictormgr "github.com/gocrane/crane/pkg/predictor"
+	"github.com/gocrane/crane/pkg/providers"
 	"github.com/gocrane/crane/pkg/utils/target"
 )
 
@@ -32,6 +33,9 @@ type Controller struct {
 	Scheme        *runtime.Scheme
 	RestMapper    meta.RESTMapper
 	ScaleClient   scale.ScalesGetter
+	// HistoryProvider provides the actual data to evaluate the accuracy of the earlier predictions, the accuracy is not
+	// evaluated if it is nil
+	HistoryProvider providers.History
 
 	// Per tsPredictionMap map stores last observed prediction together with a local time when it was observed.
 	tsPredictionMap sync.Map
@@ -39,6 +43,8 @@ type Controller struct {
 	lock sync.Mutex
 	// predictors used to do predict and config, maybe the predictor should running as a independent system not as a built-in goroutines evaluator
 	predictorMgr predictormgr.Manager
+
+	accuracyTracker *accuracyTracker
 }
 
 func NewController(
@@ -47,13 +53,16 @@ func NewController(
 	updatePeriod time.Duration,
 	predictorMgr predictormgr.Manager,
 	targetFetcher target.SelectorFetcher,
+	historyProvider providers.History,
 ) *Controller {
 	return &Controller{
-		Client:        client,
-		Recorder:      recorder,
-		UpdatePeriod:  updatePeriod,
-		predictorMgr:  predictorMgr,
-		TargetFetcher: targetFetcher,
+		Client:          client,
+		Recorder:        recorder,
+		UpdatePeriod:    updatePeriod,
+		predictorMgr:    predictorMgr,
+		TargetFetcher:   targetFetcher,
+		HistoryProvider: historyProvider,
+		accuracyTracker: newAccuracyTracker(),
 	}
 }
 
@@ -147,6 +156,7 @@ func (tc *Controller) removeTimeSeriesPrediction(tsp *predictionapi.TimeSeriesPr
 	c.DeleteApiConfigs(tsp.Spec.PredictionMetrics)
 	key := GetTimeSeriesPredictionKey(tsp)
 	tc.tsPredictionMap.Delete(key)
+	tc.removeAccuracy(tsp)
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"container/heap"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -42,6 +43,10 @@ type AnomalyAnalyzer struct {
 	nodeLister corelisters.NodeLister
 	nodeSynced cache.InformerSynced
 
+	// namespaceLister gets the ranking weights in the labels of the namespaces
+	namespaceLister corelisters.NamespaceLister
+	namespaceSynced cache.InformerSynced
+
 	nodeQOSLister ensurancelisters.NodeQOSLister
 	nodeQOSSynced cache.InformerSynced
 
@@ -56,10 +61,26 @@ type AnomalyAnalyzer struct {
 	actionCh   chan<- executor.AvoidanceExecutor
 
 	evaluator         evaluator.Evaluator
+	policyEvaluator   evaluator.Evaluator
 	triggered         map[string]uint64
 	restored          map[string]uint64
 	actionEventStatus map[string]ecache.DetectionStatus
 	lastTriggeredTime time.Time
+
+	// triggerExpressions are the parsed trigger expressions of the rules, keyed by the expressions
+	triggerExpressions map[string]*evaluator.TriggerExpression
+	history            *metricHistory
+	// forecaster triggers the rules if the usage is predicted to cross the watermarks, nil if disabled
+	forecaster Forecaster
+
+	// executeExcessPercent is the same as the executor's, to simulate the dry run actions
+	executeExcessPercent float64
+	dryRunLock           sync.RWMutex
+	// dryRunReport is the simulation of the latest dry run actions, nil before the first analysis
+	dryRunReport *executor.SimulationReport
+	// dryRunSimulated are the rules and pods of the latest simulation events, keyed by the action and the metric, so
+	// that the events are only recorded if they change
+	dryRunSimulated map[string]string
 }
 
 // NewAnomalyAnalyzer create an analyzer manager
@@ -67,11 +88,14 @@ func NewAnomalyAnalyzer(kubeClient kubernetes.Interface,
 	nodeName string,
 	podInformer coreinformers.PodInformer,
 	nodeInformer coreinformers.NodeInformer,
+	namespaceInformer coreinformers.NamespaceInformer,
 	nodeQOSInformer v1alpha1.NodeQOSInformer,
 	podQOSInformer v1alpha1.PodQOSInformer,
 	actionInformer v1alpha1.AvoidanceActionInformer,
 	stateChann chan map[string][]common.TimeSeries,
 	noticeCh chan<- executor.AvoidanceExecutor,
+	forecaster Forecaster,
+	executeExcessPercent float64,
 ) *AnomalyAnalyzer {
 
 	expressionEvaluator := evaluator.NewExpressionEvaluator()
@@ -83,12 +107,19 @@ func NewAnomalyAnalyzer(kubeClient kubernetes.Interface,
 	return &AnomalyAnalyzer{
 		nodeName:              nodeName,
 		evaluator:             expressionEvaluator,
+		policyEvaluator:       evaluator.NewOpaEvaluator(),
+		triggerExpressions:    make(map[string]*evaluator.TriggerExpression),
+		history:               newMetricHistory(evaluator.MaxTriggerWindow),
+		forecaster:            forecaster,
+		executeExcessPercent:  executeExcessPercent,
 		actionCh:              noticeCh,
 		recorder:              recorder,
 		podLister:             podInformer.Lister(),
 		podSynced:             podInformer.Informer().HasSynced,
 		nodeLister:            nodeInformer.Lister(),
 		nodeSynced:            nodeInformer.Informer().HasSynced,
+		namespaceLister:       namespaceInformer.Lister(),
+		namespaceSynced:       namespaceInformer.Informer().HasSynced,
 		nodeQOSLister:         nodeQOSInformer.Lister(),
 		nodeQOSSynced:         nodeQOSInformer.Informer().HasSynced,
 		podQOSLister:          podQOSInformer.Lister(),
@@ -114,6 +145,7 @@ func (s *AnomalyAnalyzer) Run(stop <-chan struct{}) {
 		stop,
 		s.podSynced,
 		s.nodeSynced,
+		s.namespaceSynced,
 		s.nodeQOSSynced,
 		s.avoidanceActionSynced,
 	) {
@@ -161,6 +193,9 @@ func (s *AnomalyAnalyzer) Analyze(state map[string][]common.TimeSeries) {
 		nodeQOSs = append(nodeQOSs, nodeQOS.DeepCopy())
 	}
 
+	s.updateHistory(nodeQOSs, state)
+	s.updateForecaster(nodeQOSs, state)
+
 	var actionMap = make(map[string]*ensuranceapi.AvoidanceAction)
 	allAvoidance, err := s.avoidanceActionLister.List(labels.Everything())
 	if err != nil {
@@ -180,7 +215,7 @@ func (s *AnomalyAnalyzer) Analyze(state map[string][]common.TimeSeries) {
 		for _, r := range n.Spec.Rules {
 			var key = strings.Join([]string{n.Name, r.Name}, ".")
 			klog.V(6).Infof("Processing Rule %s", key)
-			actionContext, err := s.analyze(key, r, state)
+			actionContext, err := s.analyze(key, n, r, node, state)
 			if err != nil {
 				metrics.UpdateAnalyzerWithKeyStatus(metrics.AnalyzeTypeAnalyzeError, key, 1.0)
 				klog.Errorf("Failed to analyze, %v.", err)
@@ -246,10 +281,28 @@ func (s *AnomalyAnalyzer) trigger(series []common.TimeSeries, object ensuranceap
 	return aboveThreshold
 }
 
-func (s *AnomalyAnalyzer) analyze(key string, rule ensuranceapi.Rule, stateMap map[string][]common.TimeSeries) (ecache.ActionContext, error) {
+func (s *AnomalyAnalyzer) analyze(key string, nodeQOS *ensuranceapi.NodeQOS, rule ensuranceapi.Rule, node *v1.Node, stateMap map[string][]common.TimeSeries) (ecache.ActionContext, error) {
 	klog.V(4).Infof("Starting analyze")
 	var actionContext = ecache.ActionContext{Strategy: rule.Strategy, RuleName: rule.Name, ActionName: rule.AvoidanceActionName}
 
+	// the policy or the trigger expression of the rule decides whether it's triggered instead of the metric rule watermark
+	if policy := getRulePolicy(nodeQOS, rule); policy != "" {
+		triggered, err := s.triggerWithPolicy(rule, policy, node, stateMap)
+		if err != nil {
+			return actionContext, err
+		}
+		s.computeActionContext(triggered, key, rule, &actionContext)
+		return actionContext, nil
+	}
+	if expr := getRuleTriggerExpression(nodeQOS, rule); expr != "" {
+		triggered, err := s.triggerWithExpression(rule, expr)
+		if err != nil {
+			return actionContext, err
+		}
+		s.computeActionContext(triggered, key, rule, &actionContext)
+		return actionContext, nil
+	}
+
 	state, ok := stateMap[rule.MetricRule.Name]
 	if !ok {
 		return actionContext, fmt.Errorf("metric %s not found", rule.MetricRule.Name)
@@ -264,8 +317,16 @@ func (s *AnomalyAnalyzer) analyze(key string, rule ensuranceapi.Rule, stateMap m
 	//step2: check if triggered for NodeQOSEnsurance
 	aboveThreshold := s.trigger(series, rule)
 
+	//step2.1: check if the usage is predicted to cross the watermark
+	var predicted bool
+	if !aboveThreshold {
+		predicted, actionContext.Forecast = s.triggerWithForecast(rule, stateMap)
+		aboveThreshold = predicted
+	}
+
 	//step3: check is triggered action or restored, set the detection
 	s.computeActionContext(aboveThreshold, key, rule, &actionContext)
+	actionContext.Predicted = predicted && actionContext.Triggered
 
 	return actionContext, nil
 }
@@ -305,16 +366,18 @@ func (s *AnomalyAnalyzer) computeActionContext(aboveThreshold bool, key string,
 	}
 }
 
-func (s *AnomalyAnalyzer) filterDryRun(actionContexts []ecache.ActionContext) []ecache.ActionContext {
-	var dcsFiltered []ecache.ActionContext
+func (s *AnomalyAnalyzer) filterDryRun(actionContexts []ecache.ActionContext) ([]ecache.ActionContext, []ecache.ActionContext) {
+	var dcsFiltered, dcsDryRun []ecache.ActionContext
 	now := time.Now()
 	for _, actionContext := range actionContexts {
 		s.logEvent(actionContext, now)
 		if !(actionContext.Strategy == ensuranceapi.AvoidanceActionStrategyPreview) {
 			dcsFiltered = append(dcsFiltered, actionContext)
+		} else {
+			dcsDryRun = append(dcsDryRun, actionContext)
 		}
 	}
-	return dcsFiltered
+	return dcsFiltered, dcsDryRun
 }
 
 func (s *AnomalyAnalyzer) merge(stateMap map[string][]common.TimeSeries, actionMap map[string]*ensuranceapi.AvoidanceAction, actionContexts []ecache.ActionContext) executor.AvoidanceExecutor {
@@ -322,13 +385,25 @@ func (s *AnomalyAnalyzer) merge(stateMap map[string][]common.TimeSeries, actionM
 
 	var executor executor.AvoidanceExecutor
 
-	//step1 filter dry run ActionContext
-	filteredActionContext := s.filterDryRun(actionContexts)
+	//step1 filter dry run ActionContext, and simulate the actions of them
+	filteredActionContext, dryRunActionContext := s.filterDryRun(actionContexts)
+	s.dryRun(stateMap, actionMap, dryRunActionContext)
 
 	//step2 do DisableScheduled merge
 	s.mergeSchedulingActions(filteredActionContext, actionMap, &executor)
 
-	for _, context := range filteredActionContext {
+	s.mergeActions(stateMap, actionMap, filteredActionContext, &executor)
+	executor.StateMap = stateMap
+
+	klog.V(6).Infof("ThrottleExecutor is %#v, EvictExecutor is %#v", executor.ThrottleExecutor, executor.EvictExecutor)
+
+	return executor
+}
+
+// mergeActions combines the throttle and evict actions of the contexts into the executor
+func (s *AnomalyAnalyzer) mergeActions(stateMap map[string][]common.TimeSeries, actionMap map[string]*ensuranceapi.AvoidanceAction, actionContexts []ecache.ActionContext, avoidanceExecutor *executor.AvoidanceExecutor) {
+	throttleRanking, evictRanking := newRankingSources(), newRankingSources()
+	for _, context := range actionContexts {
 		action, ok := actionMap[context.ActionName]
 		if !ok {
 			klog.Warningf("Action %s is triggered, but the AvoidanceAction is not defined.", context.ActionName)
@@ -340,26 +415,35 @@ func (s *AnomalyAnalyzer) merge(stateMap map[string][]common.TimeSeries, actionM
 			throttlePods, throttleUpPods := s.getThrottlePods(context, action, stateMap)
 
 			// combine the throttle watermark
-			combineThrottleWatermark(&executor.ThrottleExecutor, context)
+			combineThrottleWatermark(&avoidanceExecutor.ThrottleExecutor, context)
+			// combine the forecast
+			combineThrottleForecast(&avoidanceExecutor.ThrottleExecutor, context)
 			// combine the replicated pod
-			combineThrottleDuplicate(&executor.ThrottleExecutor, throttlePods, throttleUpPods)
+			combineThrottleDuplicate(&avoidanceExecutor.ThrottleExecutor, throttlePods, throttleUpPods)
+			if context.Triggered || context.Restored {
+				throttleRanking.add(context.NodeQOS, action.Name)
+			}
+			combineThrottleRules(&avoidanceExecutor.ThrottleExecutor, context)
 		}
 
-		//step4 get and deduplicate evictPods
-		if action.Spec.Eviction != nil {
+		//step4 get and deduplicate evictPods, the pods are not evicted by the forecast
+		if action.Spec.Eviction != nil && !context.Predicted {
 			evictPods := s.getEvictPods(context.Triggered, action, stateMap)
 
 			// combine the evict watermark
-			combineEvictWatermark(&executor.EvictExecutor, context)
+			combineEvictWatermark(&avoidanceExecutor.EvictExecutor, context)
 			// combine the replicated pod
-			combineEvictDuplicate(&executor.EvictExecutor, evictPods)
+			combineEvictDuplicate(&avoidanceExecutor.EvictExecutor, evictPods)
+			if context.Triggered {
+				evictRanking.add(context.NodeQOS, action.Name)
+				avoidanceExecutor.EvictExecutor.Rules = append(avoidanceExecutor.EvictExecutor.Rules, ruleKey(context))
+			}
 		}
 	}
-	executor.StateMap = stateMap
 
-	klog.V(6).Infof("ThrottleExecutor is %#v, EvictExecutor is %#v", executor.ThrottleExecutor, executor.EvictExecutor)
-
-	return executor
+	//step5 get the ranking policies to sort the pods
+	avoidanceExecutor.ThrottleExecutor.RankingPolicy = s.getRankingPolicy(throttleRanking)
+	avoidanceExecutor.EvictExecutor.RankingPolicy = s.getRankingPolicy(evictRanking)
 }
 
 func (s *AnomalyAnalyzer) logEvent(ac ecache.ActionContext, now time.Time) {
@@ -377,7 +461,11 @@ func (s *AnomalyAnalyzer) logEvent(ac ecache.ActionContext, now time.Time) {
 		klog.V(4).Infof("LOG: %s triggered action %s", key, ac.ActionName)
 
 		// record an event about the objective ensurance triggered
-		s.recorder.Event(nodeRef, v1.EventTypeWarning, "AvoidanceTriggered", fmt.Sprintf("%s triggered action %s", key, ac.ActionName))
+		if ac.Predicted {
+			s.recorder.Event(nodeRef, v1.EventTypeWarning, "AvoidanceTriggered", fmt.Sprintf("%s triggered action %s by forecast %f", key, ac.ActionName, ac.Forecast))
+		} else {
+			s.recorder.Event(nodeRef, v1.EventTypeWarning, "AvoidanceTriggered", fmt.Sprintf("%s triggered action %s", key, ac.ActionName))
+		}
 		s.actionEventStatus[key] = ecache.DetectionStatus{IsTriggered: true, LastTime: now}
 	}
 
@@ -450,10 +538,10 @@ func (s *AnomalyAnalyzer) getThrottlePods(actionCtx ecache.ActionContext, action
 	}
 	for _, pod := range filteredPods {
 		if actionCtx.Triggered {
-			throttlePods = append(throttlePods, podinfo.BuildPodActionContext(pod, stateMap, action, podinfo.ThrottleDown))
+			throttlePods = append(throttlePods, s.buildPodActionContext(pod, stateMap, action, podinfo.ThrottleDown))
 		}
 		if actionCtx.Restored {
-			throttleUpPods = append(throttleUpPods, podinfo.BuildPodActionContext(pod, stateMap, action, podinfo.ThrottleUp))
+			throttleUpPods = append(throttleUpPods, s.buildPodActionContext(pod, stateMap, action, podinfo.ThrottleUp))
 		}
 	}
 
@@ -475,7 +563,7 @@ func (s *AnomalyAnalyzer) getEvictPods(triggered bool, action *ensuranceapi.Avoi
 			return evictPods
 		}
 		for _, pod := range filteredPods {
-			evictPods = append(evictPods, podinfo.BuildPodActionContext(pod, stateMap, action, podinfo.Evict))
+			evictPods = append(evictPods, s.buildPodActionContext(pod, stateMap, action, podinfo.Evict))
 		}
 	}
 	return evictPods
@@ -647,6 +735,40 @@ func combineThrottleWatermark(e *executor.ThrottleExecutor, ac ecache.ActionCont
 	}
 }
 
+// combineThrottleForecast keeps the max forecast of the metric which triggered the action by the forecast
+func combineThrottleForecast(e *executor.ThrottleExecutor, ac ecache.ActionContext) {
+	if !ac.Predicted {
+		return
+	}
+
+	for _, ensurance := range ac.NodeQOS.Spec.Rules {
+		if ensurance.Name == ac.RuleName {
+			if e.ThrottleDownForecasts == nil {
+				e.ThrottleDownForecasts = make(map[executor.WatermarkMetric]float64)
+			}
+			m := executor.WatermarkMetric(ensurance.MetricRule.Name)
+			if forecast, ok := e.ThrottleDownForecasts[m]; !ok || ac.Forecast > forecast {
+				e.ThrottleDownForecasts[m] = ac.Forecast
+			}
+		}
+	}
+}
+
+// combineThrottleRules records the rules which the pods are throttled or restored for
+func combineThrottleRules(e *executor.ThrottleExecutor, ac ecache.ActionContext) {
+	if ac.Triggered {
+		e.ThrottleDownRules = append(e.ThrottleDownRules, ruleKey(ac))
+	}
+	if ac.Restored {
+		e.ThrottleUpRules = append(e.ThrottleUpRules, ruleKey(ac))
+	}
+}
+
+// ruleKey is the rule of the context in the format of <NodeQOS name>.<rule name>
+func ruleKey(ac ecache.ActionContext) string {
+	return strings.Join([]string{ac.NodeQOS.Name, ac.RuleName}, ".")
+}
+
 func combineEvictWatermark(e *executor.EvictExecutor, ac ecache.ActionContext) {
 	if !ac.Triggered {
 		return
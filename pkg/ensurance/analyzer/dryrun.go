@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	ecache "github.com/gocrane/crane/pkg/ensurance/cache"
+	"github.com/gocrane/crane/pkg/ensurance/executor"
+	"github.com/gocrane/crane/pkg/metrics"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// dryRun simulates the actions of the triggered dry run rules, the pods to be throttled or evicted, their order and the
+// released usage are recorded in metrics, and the report is served by ServeDryRun. An event is recorded only if the
+// simulated rules or pods of a metric change, so that the events are not flooded every cycle.
+func (s *AnomalyAnalyzer) dryRun(stateMap map[string][]common.TimeSeries, actionMap map[string]*ensuranceapi.AvoidanceAction, actionContexts []ecache.ActionContext) {
+	var triggered []ecache.ActionContext
+	var rules []string
+	for _, ac := range actionContexts {
+		if ac.Triggered {
+			triggered = append(triggered, ac)
+			rules = append(rules, ruleKey(ac))
+		}
+	}
+
+	var avoidanceExecutor executor.AvoidanceExecutor
+	s.mergeActions(stateMap, actionMap, triggered, &avoidanceExecutor)
+	avoidanceExecutor.StateMap = stateMap
+
+	report := executor.Simulate(avoidanceExecutor, s.executeExcessPercent)
+	report.Time = time.Now()
+	report.Rules = rules
+
+	var statuses []metrics.DryRunStatus
+	simulated := make(map[string]string)
+	for _, simulation := range report.Throttle {
+		statuses = append(statuses, s.recordSimulation(metrics.SubComponentThrottle, rules, simulation, simulated))
+	}
+	for _, simulation := range report.Evict {
+		statuses = append(statuses, s.recordSimulation(metrics.SubComponentEvict, rules, simulation, simulated))
+	}
+	metrics.UpdateDryRunStatus(statuses)
+	s.dryRunSimulated = simulated
+
+	s.dryRunLock.Lock()
+	defer s.dryRunLock.Unlock()
+	s.dryRunReport = &report
+}
+
+func (s *AnomalyAnalyzer) recordSimulation(subComponent metrics.SubComponent, rules []string, simulation executor.MetricSimulation, simulated map[string]string) metrics.DryRunStatus {
+	var pods []string
+	for _, pod := range simulation.Pods {
+		pods = append(pods, pod.Pod)
+	}
+	key := fmt.Sprintf("%s/%s", subComponent, simulation.Metric)
+	simulated[key] = fmt.Sprintf("%s/%s", strings.Join(rules, ","), strings.Join(pods, " "))
+	message := fmt.Sprintf("Dry run %s would %s pods [%s] on metric %s, releasing %f", strings.Join(rules, ","), subComponent,
+		strings.Join(pods, " "), simulation.Metric, simulation.Released)
+	if simulation.Quantified {
+		message += fmt.Sprintf(" of gap %f, gap closed: %v", simulation.Gap, simulation.GapClosed)
+	} else {
+		message += ", the gap can't be quantified"
+	}
+	klog.V(4).Info(message)
+	if s.dryRunSimulated[key] != simulated[key] {
+		s.recorder.Event(utils.GetNodeRef(s.nodeName), v1.EventTypeNormal, "DryRunSimulated", message)
+	}
+
+	return metrics.DryRunStatus{
+		SubComponent: subComponent,
+		Metric:       string(simulation.Metric),
+		Pods:         len(simulation.Pods),
+		Released:     simulation.Released,
+		Gap:          simulation.Gap,
+		GapClosed:    simulation.GapClosed,
+	}
+}
+
+// ServeDryRun serves the simulation report of the latest dry run actions in json
+func (s *AnomalyAnalyzer) ServeDryRun(w http.ResponseWriter, _ *http.Request) {
+	s.dryRunLock.RLock()
+	report := s.dryRunReport
+	s.dryRunLock.RUnlock()
+
+	if report == nil {
+		http.Error(w, "the node is not analyzed yet", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		klog.Errorf("Failed to encode dry run report: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+	ensurancelisters "github.com/gocrane/api/pkg/generated/listers/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	ecache "github.com/gocrane/crane/pkg/ensurance/cache"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/ensurance/executor"
+)
+
+func TestDryRun(t *testing.T) {
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	state := map[string][]common.TimeSeries{
+		string(types.MetricNameCpuTotalUsage): {{Samples: []common.Sample{{Value: 5000}}}},
+	}
+	for name, usage := range map[string]float64{"offline-1": 0.5, "offline-2": 2} {
+		assert.NoError(t, podIndexer.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: k8stypes.UID("uid-" + name), Labels: map[string]string{"app": "offline"}}}))
+		state[string(types.MetricNameContainerCpuTotalUsage)] = append(state[string(types.MetricNameContainerCpuTotalUsage)], common.TimeSeries{
+			Labels:  []common.Label{{Name: common.LabelNamePodName, Value: name}, {Name: common.LabelNamePodNamespace, Value: "default"}, {Name: common.LabelNamePodUid, Value: "uid-" + name}},
+			Samples: []common.Sample{{Value: usage}},
+		})
+	}
+	podQOSIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.NoError(t, podQOSIndexer.Add(&ensuranceapi.PodQOS{
+		ObjectMeta: metav1.ObjectMeta{Name: "offline"},
+		Spec: ensuranceapi.PodQOSSpec{
+			LabelSelector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "offline"}},
+			AllowedActions: []string{"eviction"},
+		},
+	}))
+
+	recorder := record.NewFakeRecorder(10)
+	s := &AnomalyAnalyzer{
+		nodeName:          "node-1",
+		podLister:         corelisters.NewPodLister(podIndexer),
+		podQOSLister:      ensurancelisters.NewPodQOSLister(podQOSIndexer),
+		recorder:          recorder,
+		actionEventStatus: make(map[string]ecache.DetectionStatus),
+	}
+
+	// no report before the node is analyzed
+	w := httptest.NewRecorder()
+	s.ServeDryRun(w, httptest.NewRequest(http.MethodGet, "/dry-run", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	rule := ensuranceapi.Rule{
+		Name:                "cpu-usage",
+		Strategy:            ensuranceapi.AvoidanceActionStrategyPreview,
+		AvoidanceActionName: "eviction",
+		MetricRule:          &ensuranceapi.MetricRule{Name: string(types.MetricNameCpuTotalUsage), Value: resource.MustParse("4000")},
+	}
+	nodeQOS := &ensuranceapi.NodeQOS{ObjectMeta: metav1.ObjectMeta{Name: "cpu"}, Spec: ensuranceapi.NodeQOSSpec{Rules: []ensuranceapi.Rule{rule}}}
+	actionMap := map[string]*ensuranceapi.AvoidanceAction{
+		"eviction": {ObjectMeta: metav1.ObjectMeta{Name: "eviction"}, Spec: ensuranceapi.AvoidanceActionSpec{Eviction: &ensuranceapi.EvictionAction{}}},
+	}
+	actionContexts := []ecache.ActionContext{{RuleName: rule.Name, Strategy: rule.Strategy, ActionName: rule.AvoidanceActionName, Triggered: true, NodeQOS: nodeQOS}}
+
+	// the dry run actions are not executed
+	ae := s.merge(state, actionMap, actionContexts)
+	assert.Empty(t, ae.EvictExecutor.EvictPods)
+
+	w = httptest.NewRecorder()
+	s.ServeDryRun(w, httptest.NewRequest(http.MethodGet, "/dry-run", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var report executor.SimulationReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, []string{"cpu.cpu-usage"}, report.Rules)
+	assert.Empty(t, report.Throttle)
+	assert.Equal(t, []executor.MetricSimulation{{
+		Metric:     executor.CpuUsage,
+		Quantified: true,
+		Gap:        1000,
+		Released:   2000,
+		GapClosed:  true,
+		Pods:       []executor.SimulatedPod{{Pod: "default/offline-2", Released: 2000}},
+	}}, report.Evict)
+
+	// the triggered event and the simulation event
+	assert.Len(t, recorder.Events, 2)
+	<-recorder.Events
+	assert.Equal(t, "Normal DryRunSimulated Dry run cpu.cpu-usage would evict pods [default/offline-2] on metric cpu_total_usage, releasing 2000.000000 of gap 1000.000000, gap closed: true", <-recorder.Events)
+
+	// only the triggered event is recorded again if the simulated pods don't change, but the report is still updated
+	state[string(types.MetricNameCpuTotalUsage)] = []common.TimeSeries{{Samples: []common.Sample{{Value: 5500}}}}
+	s.merge(state, actionMap, actionContexts)
+	assert.Len(t, recorder.Events, 1)
+	<-recorder.Events
+	w = httptest.NewRecorder()
+	s.ServeDryRun(w, httptest.NewRequest(http.MethodGet, "/dry-run", nil))
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 1500.0, report.Evict[0].Gap)
+
+	// it's recorded once the pods change
+	state[string(types.MetricNameCpuTotalUsage)] = []common.TimeSeries{{Samples: []common.Sample{{Value: 6500}}}}
+	s.merge(state, actionMap, actionContexts)
+	assert.Len(t, recorder.Events, 2)
+	<-recorder.Events
+	assert.Contains(t, <-recorder.Events, "would evict pods [default/offline-2 default/offline-1]")
+}
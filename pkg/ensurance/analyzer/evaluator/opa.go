@@ -1,23 +1,125 @@
 package evaluator
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// triggerRule is the rule in the package of the policy which decides whether to trigger
+	triggerRule = "trigger"
+	// maxCachedPolicies bounds the compiled policies, the cache is reset once it's full
+	maxCachedPolicies = 128
+
+	// metricPolicy is the policy of EvalWithMetric, the same as the expression evaluator
+	metricPolicy = `package crane.ensurance
+
+default trigger = false
+
+trigger {
+	input.value > input.target
+}
+`
+)
+
+// OpaEvaluator evaluates the Rego policies, the policy must define a rule named trigger in its package, and the
+// input is decoded from json. The compiled policies are cached by the policy text.
 type OpaEvaluator struct {
+	lock    sync.Mutex
+	queries map[string]*rego.PreparedEvalQuery
 }
 
 func NewOpaEvaluator() Evaluator {
-	return &OpaEvaluator{}
+	return &OpaEvaluator{queries: make(map[string]*rego.PreparedEvalQuery)}
 }
 
 func (c *OpaEvaluator) EvalWithMetric(metricName string, targetValue float64, value float64) bool {
+	input := map[string]interface{}{
+		"metric": metricName,
+		"target": targetValue,
+		"value":  value,
+	}
+	triggered, err := c.eval(metricPolicy, input)
+	if err != nil {
+		klog.Errorf("Failed to evaluate metric %s: %v", metricName, err)
+		return false
+	}
+	return triggered
+}
 
-	// step1 splice policy and input strings
-	// step2 opa.New().WithPolicyBytes
-	// step3 rego.Eval
-	// step4 transfer opa.result to bool
-	// return
+func (c *OpaEvaluator) EvalWithRawQuery(input string, rule string) bool {
+	var in interface{}
+	if err := json.Unmarshal([]byte(input), &in); err != nil {
+		klog.Errorf("Failed to decode the input of policy: %v", err)
+		return false
+	}
+	triggered, err := c.eval(rule, in)
+	if err != nil {
+		klog.Errorf("Failed to evaluate policy: %v", err)
+		return false
+	}
+	return triggered
+}
 
-	return false
+func (c *OpaEvaluator) eval(policy string, input interface{}) (bool, error) {
+	query, err := c.getQuery(policy)
+	if err != nil {
+		return false, err
+	}
+	rs, err := query.Eval(context.TODO(), rego.EvalInput(input))
+	if err != nil {
+		return false, err
+	}
+	// The trigger rule is undefined if none of its bodies is satisfied
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return false, nil
+	}
+	triggered, ok := rs[0].Expressions[0].Value.(bool)
+	if !ok {
+		return false, fmt.Errorf("rule %s is %v, it should be a boolean", triggerRule, rs[0].Expressions[0].Value)
+	}
+	return triggered, nil
 }
 
-func (c *OpaEvaluator) EvalWithRawQuery(input string, rule string) bool {
-	return false
+func (c *OpaEvaluator) getQuery(policy string) (*rego.PreparedEvalQuery, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if query, ok := c.queries[policy]; ok {
+		return query, nil
+	}
+	query, err := PreparePolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.queries) >= maxCachedPolicies {
+		c.queries = make(map[string]*rego.PreparedEvalQuery)
+	}
+	c.queries[policy] = query
+	return query, nil
+}
+
+// PreparePolicy compiles the policy and prepares the query of its trigger rule.
+func PreparePolicy(policy string) (*rego.PreparedEvalQuery, error) {
+	module, err := ast.ParseModule("policy.rego", policy)
+	if err != nil {
+		return nil, err
+	}
+	if module == nil {
+		return nil, fmt.Errorf("policy is empty")
+	}
+	query, err := rego.New(
+		rego.Query(module.Package.Path.String()+"."+triggerRule),
+		rego.ParsedModule(module),
+	).PrepareForEval(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	return &query, nil
 }
@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const compoundPolicy = `package crane.ensurance
+
+default trigger = false
+
+trigger {
+	input.metrics.cpu_total_utilization[_].value > 80
+	input.metrics.cpu_load_5_min[_].value > input.node.capacity.cpu
+}
+`
+
+func TestOpaEvalWithMetric(t *testing.T) {
+	e := NewOpaEvaluator()
+	assert.True(t, e.EvalWithMetric("cpu_total_usage", 6000, 6500))
+	assert.False(t, e.EvalWithMetric("cpu_total_usage", 6000, 6000))
+}
+
+func TestOpaEvalWithRawQuery(t *testing.T) {
+	cases := map[string]struct {
+		input  string
+		policy string
+		expect bool
+	}{
+		"both conditions are met": {
+			input:  `{"node": {"capacity": {"cpu": 8}}, "metrics": {"cpu_total_utilization": [{"value": 85}], "cpu_load_5_min": [{"value": 9.5}]}}`,
+			policy: compoundPolicy,
+			expect: true,
+		},
+		"load is below the cores": {
+			input:  `{"node": {"capacity": {"cpu": 8}}, "metrics": {"cpu_total_utilization": [{"value": 85}], "cpu_load_5_min": [{"value": 6}]}}`,
+			policy: compoundPolicy,
+			expect: false,
+		},
+		"metric is missing": {
+			input:  `{"node": {"capacity": {"cpu": 8}}, "metrics": {"cpu_load_5_min": [{"value": 9.5}]}}`,
+			policy: compoundPolicy,
+			expect: false,
+		},
+		"trigger is undefined": {
+			input:  `{"value": 1}`,
+			policy: "package crane.ensurance\n\ntrigger {\n\tinput.value > 1\n}\n",
+			expect: false,
+		},
+		"trigger is not a boolean": {
+			input:  `{}`,
+			policy: "package crane.ensurance\n\ntrigger = \"yes\"\n",
+			expect: false,
+		},
+		"policy is invalid": {
+			input:  `{}`,
+			policy: "package crane.ensurance\n\ntrigger {\n",
+			expect: false,
+		},
+		"input is invalid": {
+			input:  `{`,
+			policy: compoundPolicy,
+			expect: false,
+		},
+	}
+
+	e := NewOpaEvaluator()
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.expect, e.EvalWithRawQuery(c.input, c.policy))
+		})
+	}
+}
+
+func TestOpaEvaluatorCache(t *testing.T) {
+	e := NewOpaEvaluator().(*OpaEvaluator)
+	input := `{"node": {"capacity": {"cpu": 8}}, "metrics": {"cpu_total_utilization": [{"value": 85}], "cpu_load_5_min": [{"value": 9.5}]}}`
+
+	assert.True(t, e.EvalWithRawQuery(input, compoundPolicy))
+	query := e.queries[compoundPolicy]
+	assert.NotNil(t, query)
+	assert.True(t, e.EvalWithRawQuery(input, compoundPolicy))
+	assert.Same(t, query, e.queries[compoundPolicy])
+	assert.Len(t, e.queries, 1)
+}
@@ -0,0 +1,602 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+// MaxTriggerWindow is the max range of the functions in the trigger expressions, it bounds the history the analyzer
+// keeps for the metrics
+const MaxTriggerWindow = 30 * time.Minute
+
+// History gives the samples of the metrics collected by the analyzer
+type History interface {
+	// Range returns the samples of each series of the metric which matches the labels, in the window before the latest
+	// sample. Only the latest sample of each series is returned if the window is zero.
+	Range(metricName string, matchLabels map[string]string, window time.Duration) [][]common.Sample
+}
+
+// rangeFunctions aggregate the samples of a series in the window, they return NaN if there are not enough samples
+var rangeFunctions = map[string]func(samples []common.Sample) float64{
+	"avg_over_time": avgOverTime,
+	"max_over_time": maxOverTime,
+	"min_over_time": minOverTime,
+	"delta":         delta,
+	"deriv":         deriv,
+}
+
+// TriggerExpression is a boolean expression over the metrics, such as
+//
+//	cpu_total_utilization > 80 && cpu_load_5_min > cpu_core_numbers
+//	deriv(memory_total_usage[5m]) * 60 > 200Mi || avg_over_time(memory_total_utilization[10m]) > 90
+//
+// Conditions are combined by && and ||, and compared by >, >=, <, <=, == and !=. Values are numbers or quantities,
+// metrics and the functions over a range of metric history, which are avg_over_time, max_over_time, min_over_time,
+// delta and deriv(per second), they can be computed by + - * /. The series of a metric are selected by the labels in
+// braces, such as pod_cpu_pressure_some_avg10{pod_name="foo"}, and the max of them is taken if there are several.
+// A metric without samples makes the comparisons false.
+type TriggerExpression struct {
+	expr        string
+	root        node
+	metricNames []string
+	maxWindow   time.Duration
+}
+
+// ParseTriggerExpression parses the expression and checks the types of its operands.
+func ParseTriggerExpression(expr string) (*TriggerExpression, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, metricNames: make(map[string]bool)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	if !root.boolean() {
+		return nil, fmt.Errorf("expression %q is not a condition", expr)
+	}
+
+	var metricNames []string
+	for name := range p.metricNames {
+		metricNames = append(metricNames, name)
+	}
+	sort.Strings(metricNames)
+	return &TriggerExpression{expr: expr, root: root, metricNames: metricNames, maxWindow: p.maxWindow}, nil
+}
+
+// Eval returns whether the expression is satisfied by the history.
+func (e *TriggerExpression) Eval(h History) bool {
+	return e.root.eval(h) == 1
+}
+
+// MetricNames returns the metrics referred by the expression.
+func (e *TriggerExpression) MetricNames() []string {
+	return e.metricNames
+}
+
+// MaxWindow returns the longest range of the functions in the expression.
+func (e *TriggerExpression) MaxWindow() time.Duration {
+	return e.maxWindow
+}
+
+func (e *TriggerExpression) String() string {
+	return e.expr
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var operators = []string{"&&", "||", ">=", "<=", "==", "!=", ">", "<", "+", "-", "*", "/", "(", ")", "[", "]", "{", "}", ",", "="}
+
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case isIdentStart(c):
+			start := i
+			for i < len(expr) && (isIdentStart(rune(expr[i])) || unicode.IsDigit(rune(expr[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: expr[start:i], pos: start})
+		case unicode.IsDigit(c) || c == '.':
+			// numbers may have suffixes, such as the quantity 100Mi and the duration 5m
+			start := i
+			for i < len(expr) && (unicode.IsDigit(rune(expr[i])) || expr[i] == '.' || unicode.IsLetter(rune(expr[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: expr[start:i], pos: start})
+		case c == '"':
+			end := strings.IndexByte(expr[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: expr[i+1 : i+1+end], pos: i})
+			i += end + 2
+		default:
+			var op string
+			for _, o := range operators {
+				if strings.HasPrefix(expr[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+func isIdentStart(c rune) bool {
+	return unicode.IsLetter(c) || c == '_' || c == ':'
+}
+
+type parser struct {
+	tokens      []token
+	pos         int
+	metricNames map[string]bool
+	maxWindow   time.Duration
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokenOperator && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		return fmt.Errorf("expect %q at %d, got %q", op, t.pos, t.text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if !left.boolean() || !right.boolean() {
+			return nil, fmt.Errorf("operands of || must be conditions")
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		if !left.boolean() || !right.boolean() {
+			return nil, fmt.Errorf("operands of && must be conditions")
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{">=", "<=", "==", "!=", ">", "<"} {
+		if p.accept(op) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			if left.boolean() || right.boolean() {
+				return nil, fmt.Errorf("operands of %s must be values", op)
+			}
+			return &comparisonNode{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op string
+		if p.accept("+") {
+			op = "+"
+		} else if p.accept("-") {
+			op = "-"
+		} else {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		if left.boolean() || right.boolean() {
+			return nil, fmt.Errorf("operands of %s must be values", op)
+		}
+		left = &arithmeticNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op string
+		if p.accept("*") {
+			op = "*"
+		} else if p.accept("/") {
+			op = "/"
+		} else {
+			return left, nil
+		}
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		if left.boolean() || right.boolean() {
+			return nil, fmt.Errorf("operands of %s must be values", op)
+		}
+		left = &arithmeticNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	if p.accept("(") {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	}
+	if p.accept("-") {
+		n, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		if n.boolean() {
+			return nil, fmt.Errorf("operand of - must be a value")
+		}
+		return &arithmeticNode{op: "-", left: &numberNode{}, right: n}, nil
+	}
+
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		q, err := resource.ParseQuantity(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d: %v", t.text, t.pos, err)
+		}
+		return &numberNode{value: q.AsApproximateFloat64()}, nil
+	case tokenIdent:
+		if fn, ok := rangeFunctions[t.text]; ok && p.accept("(") {
+			m, err := p.parseMetric(p.next())
+			if err != nil {
+				return nil, err
+			}
+			if m.window == 0 {
+				return nil, fmt.Errorf("%s requires a range of %s, such as %s[5m]", t.text, m.name, m.name)
+			}
+			return &functionNode{fn: fn, metric: m}, p.expect(")")
+		}
+		m, err := p.parseMetric(t)
+		if err != nil {
+			return nil, err
+		}
+		if m.window != 0 {
+			return nil, fmt.Errorf("range of %s must be in a function", m.name)
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+// parseMetric parses metric{label="value"}[window], both the labels and the window are optional
+func (p *parser) parseMetric(t token) (*metricNode, error) {
+	if t.kind != tokenIdent {
+		return nil, fmt.Errorf("expect metric at %d, got %q", t.pos, t.text)
+	}
+	m := &metricNode{name: t.text}
+	p.metricNames[m.name] = true
+
+	if p.accept("{") {
+		m.labels = make(map[string]string)
+		for !p.accept("}") {
+			if len(m.labels) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			name := p.next()
+			if name.kind != tokenIdent {
+				return nil, fmt.Errorf("expect label at %d, got %q", name.pos, name.text)
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			value := p.next()
+			if value.kind != tokenString {
+				return nil, fmt.Errorf("expect quoted label value at %d, got %q", value.pos, value.text)
+			}
+			m.labels[name.text] = value.text
+		}
+	}
+
+	if p.accept("[") {
+		d := p.next()
+		window, err := time.ParseDuration(d.text)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("invalid range %q at %d", d.text, d.pos)
+		}
+		if window > MaxTriggerWindow {
+			return nil, fmt.Errorf("range %s is longer than %s", window, MaxTriggerWindow)
+		}
+		if window > p.maxWindow {
+			p.maxWindow = window
+		}
+		m.window = window
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// node is the node of the expression, a boolean node evaluates to 1 or 0
+type node interface {
+	eval(h History) float64
+	boolean() bool
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(h History) float64 {
+	left := n.left.eval(h) == 1
+	if n.op == "&&" && !left || n.op == "||" && left {
+		return bool2Float(left)
+	}
+	return bool2Float(n.right.eval(h) == 1)
+}
+
+func (n *logicalNode) boolean() bool {
+	return true
+}
+
+type comparisonNode struct {
+	op          string
+	left, right node
+}
+
+func (n *comparisonNode) eval(h History) float64 {
+	left, right := n.left.eval(h), n.right.eval(h)
+	// the comparisons with NaN are false
+	if math.IsNaN(left) || math.IsNaN(right) {
+		return 0
+	}
+	switch n.op {
+	case ">":
+		return bool2Float(left > right)
+	case ">=":
+		return bool2Float(left >= right)
+	case "<":
+		return bool2Float(left < right)
+	case "<=":
+		return bool2Float(left <= right)
+	case "==":
+		return bool2Float(left == right)
+	default:
+		return bool2Float(left != right)
+	}
+}
+
+func (n *comparisonNode) boolean() bool {
+	return true
+}
+
+type arithmeticNode struct {
+	op          string
+	left, right node
+}
+
+func (n *arithmeticNode) eval(h History) float64 {
+	left, right := n.left.eval(h), n.right.eval(h)
+	switch n.op {
+	case "+":
+		return left + right
+	case "-":
+		return left - right
+	case "*":
+		return left * right
+	default:
+		if right == 0 {
+			return math.NaN()
+		}
+		return left / right
+	}
+}
+
+func (n *arithmeticNode) boolean() bool {
+	return false
+}
+
+type numberNode struct {
+	value float64
+}
+
+func (n *numberNode) eval(h History) float64 {
+	return n.value
+}
+
+func (n *numberNode) boolean() bool {
+	return false
+}
+
+type metricNode struct {
+	name   string
+	labels map[string]string
+	window time.Duration
+}
+
+// eval returns the max of the latest values of the series
+func (n *metricNode) eval(h History) float64 {
+	return maxOfSeries(h.Range(n.name, n.labels, 0), func(samples []common.Sample) float64 {
+		return samples[len(samples)-1].Value
+	})
+}
+
+func (n *metricNode) boolean() bool {
+	return false
+}
+
+type functionNode struct {
+	fn     func(samples []common.Sample) float64
+	metric *metricNode
+}
+
+// eval returns the max of the function results of the series
+func (n *functionNode) eval(h History) float64 {
+	return maxOfSeries(h.Range(n.metric.name, n.metric.labels, n.metric.window), n.fn)
+}
+
+func (n *functionNode) boolean() bool {
+	return false
+}
+
+func maxOfSeries(series [][]common.Sample, fn func(samples []common.Sample) float64) float64 {
+	result := math.NaN()
+	for _, samples := range series {
+		if len(samples) == 0 {
+			continue
+		}
+		if v := fn(samples); !math.IsNaN(v) && (math.IsNaN(result) || v > result) {
+			result = v
+		}
+	}
+	return result
+}
+
+func avgOverTime(samples []common.Sample) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += s.Value
+	}
+	return sum / float64(len(samples))
+}
+
+func maxOverTime(samples []common.Sample) float64 {
+	max := samples[0].Value
+	for _, s := range samples[1:] {
+		max = math.Max(max, s.Value)
+	}
+	return max
+}
+
+func minOverTime(samples []common.Sample) float64 {
+	min := samples[0].Value
+	for _, s := range samples[1:] {
+		min = math.Min(min, s.Value)
+	}
+	return min
+}
+
+// delta is the difference between the first and the last samples
+func delta(samples []common.Sample) float64 {
+	if len(samples) < 2 {
+		return math.NaN()
+	}
+	return samples[len(samples)-1].Value - samples[0].Value
+}
+
+// deriv is the per second derivative by the simple linear regression, which is less sensitive to the noise than delta
+func deriv(samples []common.Sample) float64 {
+	if len(samples) < 2 {
+		return math.NaN()
+	}
+	// use the offsets to the first sample to keep the precision
+	var sumX, sumY, sumXY, sumX2 float64
+	for _, s := range samples {
+		x := float64(s.Timestamp - samples[0].Timestamp)
+		sumX += x
+		sumY += s.Value
+		sumXY += x * s.Value
+		sumX2 += x * x
+	}
+	n := float64(len(samples))
+	d := n*sumX2 - sumX*sumX
+	if d == 0 {
+		return math.NaN()
+	}
+	return (n*sumXY - sumX*sumY) / d
+}
+
+func bool2Float(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
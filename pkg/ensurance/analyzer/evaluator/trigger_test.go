@@ -0,0 +1,181 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+type fakeSeries struct {
+	labels  map[string]string
+	samples []common.Sample
+}
+
+type fakeHistory map[string][]fakeSeries
+
+func (h fakeHistory) Range(metricName string, matchLabels map[string]string, window time.Duration) [][]common.Sample {
+	var result [][]common.Sample
+	for _, s := range h[metricName] {
+		matched := true
+		for k, v := range matchLabels {
+			if s.labels[k] != v {
+				matched = false
+			}
+		}
+		if !matched {
+			continue
+		}
+		last := s.samples[len(s.samples)-1]
+		if window == 0 {
+			result = append(result, []common.Sample{last})
+			continue
+		}
+		var samples []common.Sample
+		for _, sample := range s.samples {
+			if sample.Timestamp >= last.Timestamp-int64(window.Seconds()) {
+				samples = append(samples, sample)
+			}
+		}
+		result = append(result, samples)
+	}
+	return result
+}
+
+// linear returns the samples every 10 seconds in the duration, which grow by the slope per second
+func linear(start, slope float64, duration time.Duration) []common.Sample {
+	var samples []common.Sample
+	for t := int64(0); t <= int64(duration.Seconds()); t += 10 {
+		samples = append(samples, common.Sample{Value: start + slope*float64(t), Timestamp: 1000 + t})
+	}
+	return samples
+}
+
+func TestParseTriggerExpression(t *testing.T) {
+	cases := map[string]struct {
+		expr        string
+		metricNames []string
+		maxWindow   time.Duration
+		expectErr   bool
+	}{
+		"compound": {
+			expr:        "cpu_total_utilization > 80 && cpu_load_5_min > cpu_core_numbers",
+			metricNames: []string{"cpu_core_numbers", "cpu_load_5_min", "cpu_total_utilization"},
+		},
+		"functions": {
+			expr:        `deriv(memory_total_usage[5m]) * 60 > 200Mi || (avg_over_time(pod_cpu_pressure_some_avg10{pod_name="foo"}[10m]) >= 20 && -delta(memory_total_usage[1m]) < 0)`,
+			metricNames: []string{"memory_total_usage", "pod_cpu_pressure_some_avg10"},
+			maxWindow:   10 * time.Minute,
+		},
+		"not a condition": {
+			expr:      "cpu_total_utilization + 1",
+			expectErr: true,
+		},
+		"value operand of and": {
+			expr:      "cpu_total_utilization > 80 && cpu_load_5_min",
+			expectErr: true,
+		},
+		"condition operand of comparison": {
+			expr:      "(cpu_total_utilization > 80) > 1",
+			expectErr: true,
+		},
+		"function without range": {
+			expr:      "deriv(memory_total_usage) > 0",
+			expectErr: true,
+		},
+		"range without function": {
+			expr:      "memory_total_usage[5m] > 0",
+			expectErr: true,
+		},
+		"range too long": {
+			expr:      "avg_over_time(memory_total_usage[1h]) > 0",
+			expectErr: true,
+		},
+		"invalid number": {
+			expr:      "memory_total_usage > 1xyz",
+			expectErr: true,
+		},
+		"unquoted label": {
+			expr:      "pod_cpu_pressure_some_avg10{pod_name=foo} > 1",
+			expectErr: true,
+		},
+		"unbalanced parentheses": {
+			expr:      "(cpu_total_utilization > 80",
+			expectErr: true,
+		},
+		"trailing tokens": {
+			expr:      "cpu_total_utilization > 80 80",
+			expectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			e, err := ParseTriggerExpression(c.expr)
+			if c.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.metricNames, e.MetricNames())
+			assert.Equal(t, c.maxWindow, e.MaxWindow())
+		})
+	}
+}
+
+func TestTriggerExpressionEval(t *testing.T) {
+	const mi = 1024 * 1024.0
+	history := fakeHistory{
+		"cpu_total_utilization": {{samples: linear(85, 0, 0)}},
+		"cpu_load_5_min":        {{samples: linear(9.5, 0, 0)}},
+		"cpu_core_numbers":      {{samples: linear(8, 0, 0)}},
+		// the memory grows by 5Mi per second, far below the watermark
+		"memory_total_usage": {{samples: linear(4096*mi, 5*mi, 10*time.Minute)}},
+		"pod_cpu_pressure_some_avg10": {
+			{labels: map[string]string{"pod_name": "foo"}, samples: []common.Sample{{Value: 10, Timestamp: 1000}, {Value: 30, Timestamp: 1010}}},
+			{labels: map[string]string{"pod_name": "bar"}, samples: []common.Sample{{Value: 50, Timestamp: 1000}, {Value: 60, Timestamp: 1010}}},
+		},
+	}
+
+	cases := map[string]struct {
+		expr   string
+		expect bool
+	}{
+		"both conditions are met":        {expr: "cpu_total_utilization > 80 && cpu_load_5_min > cpu_core_numbers", expect: true},
+		"one condition is not met":       {expr: "cpu_total_utilization > 90 && cpu_load_5_min > cpu_core_numbers", expect: false},
+		"either condition is met":        {expr: "cpu_total_utilization > 90 || cpu_load_5_min > cpu_core_numbers", expect: true},
+		"arithmetic":                     {expr: "cpu_load_5_min / cpu_core_numbers > 1.1 && cpu_load_5_min - 1.5 == cpu_core_numbers", expect: true},
+		"fast memory growth":             {expr: "deriv(memory_total_usage[5m]) * 60 > 200Mi", expect: true},
+		"slow memory growth":             {expr: "deriv(memory_total_usage[5m]) * 60 > 500Mi", expect: false},
+		"delta":                          {expr: "delta(memory_total_usage[1m]) == 300Mi", expect: true},
+		"moving average":                 {expr: "avg_over_time(memory_total_usage[10m]) < memory_total_usage", expect: true},
+		"max and min":                    {expr: "max_over_time(memory_total_usage[10m]) - min_over_time(memory_total_usage[10m]) == 3000Mi", expect: true},
+		"max of the series":              {expr: "pod_cpu_pressure_some_avg10 == 60", expect: true},
+		"series selected by labels":      {expr: `pod_cpu_pressure_some_avg10{pod_name="foo"} == 30`, expect: true},
+		"no series matches the labels":   {expr: `pod_cpu_pressure_some_avg10{pod_name="baz"} >= 0`, expect: false},
+		"metric without samples":         {expr: "cpu_load_1_min > 0 || cpu_load_1_min <= 0", expect: false},
+		"not enough samples":             {expr: "deriv(cpu_total_utilization[5m]) >= 0", expect: false},
+		"division by zero":               {expr: "cpu_total_utilization / 0 > 0", expect: false},
+		"parentheses change precedence":  {expr: "(cpu_total_utilization > 90 || cpu_load_5_min > 9) && cpu_core_numbers == 8", expect: true},
+		"and binds tighter than or":      {expr: "cpu_total_utilization > 90 && cpu_load_5_min > 9 || cpu_core_numbers == 8", expect: true},
+		"multiplication binds tighter":   {expr: "2 + cpu_core_numbers * 2 == 18", expect: true},
+		"negative and quantity literals": {expr: "-cpu_core_numbers < 0 && cpu_core_numbers == 8000m", expect: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			e, err := ParseTriggerExpression(c.expr)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expect, e.Eval(history))
+		})
+	}
+}
+
+func TestDeriv(t *testing.T) {
+	assert.InDelta(t, 2.5, deriv(linear(100, 2.5, time.Minute)), 1e-9)
+	assert.True(t, math.IsNaN(deriv(linear(100, 2.5, 0))))
+	assert.True(t, math.IsNaN(deriv([]common.Sample{{Value: 1, Timestamp: 10}, {Value: 2, Timestamp: 10}})))
+}
@@ -0,0 +1,239 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+	predictionlisters "github.com/gocrane/api/pkg/generated/listers/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/analyzer/evaluator"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const (
+	// ForecastSourceTsp forecasts by the node resource TimeSeriesPrediction produced by craned
+	ForecastSourceTsp = "tsp"
+	// ForecastSourceLocal forecasts by the linear extrapolation of the recent node usage
+	ForecastSourceLocal = "local"
+
+	// minForecastSamples is the least samples in the lookback for the local extrapolation
+	minForecastSamples = 3
+)
+
+// Forecaster predicts the node usage in a horizon, the actions are triggered if the usage is predicted to cross the
+// watermarks, before the current usage does.
+type Forecaster interface {
+	// Update records the latest state of the metrics which may be forecast
+	Update(state map[string][]common.TimeSeries, metricNames map[string]bool, now time.Time)
+	// Forecast returns the max value of the node metric predicted in the horizon, false if it can't be predicted
+	Forecast(metricName string, state map[string][]common.TimeSeries, now time.Time) (float64, bool)
+}
+
+// NewForecaster creates the forecaster of the source, nil if the source is empty
+func NewForecaster(source string, horizon time.Duration, tspLister predictionlisters.TimeSeriesPredictionLister, tspName string) (Forecaster, error) {
+	switch source {
+	case "":
+		return nil, nil
+	case ForecastSourceLocal:
+		return newLocalForecaster(horizon), nil
+	case ForecastSourceTsp:
+		if tspName == "" {
+			return nil, fmt.Errorf("node resource tsp is not found")
+		}
+		return &tspForecaster{horizon: horizon, tspLister: tspLister, tspName: tspName}, nil
+	}
+	return nil, fmt.Errorf("unknown forecast source %s", source)
+}
+
+// localForecaster extrapolates the node metrics by the linear regression of their samples in the lookback, which is
+// the same as the horizon and no longer than MaxTriggerWindow
+type localForecaster struct {
+	horizon time.Duration
+	history *metricHistory
+}
+
+func newLocalForecaster(horizon time.Duration) *localForecaster {
+	lookback := horizon
+	if lookback > evaluator.MaxTriggerWindow {
+		lookback = evaluator.MaxTriggerWindow
+	}
+	return &localForecaster{horizon: horizon, history: newMetricHistory(lookback)}
+}
+
+func (f *localForecaster) Update(state map[string][]common.TimeSeries, metricNames map[string]bool, now time.Time) {
+	f.history.update(state, metricNames, now)
+}
+
+func (f *localForecaster) Forecast(metricName string, _ map[string][]common.TimeSeries, _ time.Time) (float64, bool) {
+	var forecast = math.NaN()
+	for key, s := range f.history.series[metricName] {
+		// only the node metrics are forecast, they have no labels
+		if key != "" || !s.updated || len(s.samples) < minForecastSamples {
+			continue
+		}
+		slope, intercept, ok := linearRegression(s.samples)
+		if !ok {
+			continue
+		}
+		last := s.samples[len(s.samples)-1]
+		value := intercept + slope*(float64(last.Timestamp-s.samples[0].Timestamp)+f.horizon.Seconds())
+		// the forecast of a decreasing usage is the current usage
+		if value < last.Value {
+			value = last.Value
+		}
+		if math.IsNaN(forecast) || value > forecast {
+			forecast = value
+		}
+	}
+	return forecast, !math.IsNaN(forecast)
+}
+
+// linearRegression returns the slope per second and the intercept at the first sample
+func linearRegression(samples []common.Sample) (slope, intercept float64, ok bool) {
+	var sumX, sumY, sumXY, sumX2 float64
+	for _, s := range samples {
+		x := float64(s.Timestamp - samples[0].Timestamp)
+		sumX += x
+		sumY += s.Value
+		sumXY += x * s.Value
+		sumX2 += x * x
+	}
+	n := float64(len(samples))
+	d := n*sumX2 - sumX*sumX
+	if d == 0 {
+		return 0, 0, false
+	}
+	slope = (n*sumXY - sumX*sumY) / d
+	intercept = (sumY - slope*sumX) / n
+	return slope, intercept, true
+}
+
+// tspForecaster takes the max of the node resource TimeSeriesPrediction in the horizon, the cpu cores and memory
+// bytes are converted to the node usage and utilization metrics.
+type tspForecaster struct {
+	horizon   time.Duration
+	tspLister predictionlisters.TimeSeriesPredictionLister
+	tspName   string
+}
+
+func (f *tspForecaster) Update(map[string][]common.TimeSeries, map[string]bool, time.Time) {
+}
+
+func (f *tspForecaster) Forecast(metricName string, state map[string][]common.TimeSeries, now time.Time) (float64, bool) {
+	var resourceName v1.ResourceName
+	switch types.MetricName(metricName) {
+	case types.MetricNameCpuTotalUsage, types.MetricNameCpuTotalUtilization:
+		resourceName = v1.ResourceCPU
+	case types.MetricNameMemoryTotalUsage, types.MetricNameMemoryTotalUtilization:
+		resourceName = v1.ResourceMemory
+	default:
+		return 0, false
+	}
+
+	predicted, ok := f.predict(resourceName, now)
+	if !ok {
+		return 0, false
+	}
+
+	switch types.MetricName(metricName) {
+	case types.MetricNameCpuTotalUsage:
+		// the usage is in milli cores
+		return predicted * 1000, true
+	case types.MetricNameCpuTotalUtilization:
+		return toUtilization(predicted, state, types.MetricNameCpuCoreNumbers)
+	case types.MetricNameMemoryTotalUtilization:
+		return toUtilization(predicted, state, types.MetricNameMemoryTotal)
+	}
+	return predicted, true
+}
+
+// predict returns the max predicted value of the resource in the horizon
+func (f *tspForecaster) predict(resourceName v1.ResourceName, now time.Time) (float64, bool) {
+	tsp, err := f.tspLister.TimeSeriesPredictions(known.CraneSystemNamespace).Get(f.tspName)
+	if err != nil {
+		klog.V(4).Infof("Failed to get tsp %s: %v", f.tspName, err)
+		return 0, false
+	}
+
+	var predicted = math.NaN()
+	start, end := now.Unix(), now.Add(f.horizon).Unix()
+	for _, predictionMetric := range tsp.Status.PredictionMetrics {
+		if predictionMetric.ResourceIdentifier != resourceName.String() {
+			continue
+		}
+		for _, timeSeries := range predictionMetric.Prediction {
+			if utils.GetPredictionBand(timeSeries.Labels) != "" {
+				continue
+			}
+			for _, sample := range timeSeries.Samples {
+				if sample.Timestamp < start || sample.Timestamp > end {
+					continue
+				}
+				value, err := strconv.ParseFloat(sample.Value, 64)
+				if err != nil {
+					klog.Errorf("Failed to parse predicted value %v: %v", sample.Value, err)
+					continue
+				}
+				if math.IsNaN(predicted) || value > predicted {
+					predicted = value
+				}
+			}
+		}
+	}
+	return predicted, !math.IsNaN(predicted)
+}
+
+// toUtilization converts the predicted value to the percentage of the total in the state
+func toUtilization(predicted float64, state map[string][]common.TimeSeries, total types.MetricName) (float64, bool) {
+	series, ok := state[string(total)]
+	if !ok || len(series) == 0 || len(series[0].Samples) == 0 || series[0].Samples[0].Value == 0 {
+		return 0, false
+	}
+	return predicted / series[0].Samples[0].Value * types.MaxPercentage, true
+}
+
+// updateForecaster records the metrics of the rules which are triggered by the metric rule watermarks
+func (s *AnomalyAnalyzer) updateForecaster(nodeQOSs []*ensuranceapi.NodeQOS, state map[string][]common.TimeSeries) {
+	if s.forecaster == nil {
+		return
+	}
+	var metricNames = make(map[string]bool)
+	for _, n := range nodeQOSs {
+		for _, r := range n.Spec.Rules {
+			if r.MetricRule == nil || getRulePolicy(n, r) != "" || getRuleTriggerExpression(n, r) != "" {
+				continue
+			}
+			metricNames[r.MetricRule.Name] = true
+		}
+	}
+	s.forecaster.Update(state, metricNames, time.Now())
+}
+
+// triggerWithForecast checks whether the metric of the rule is predicted to cross the watermark
+func (s *AnomalyAnalyzer) triggerWithForecast(rule ensuranceapi.Rule, stateMap map[string][]common.TimeSeries) (bool, float64) {
+	if s.forecaster == nil {
+		return false, 0
+	}
+	forecast, ok := s.forecaster.Forecast(rule.MetricRule.Name, stateMap, time.Now())
+	if !ok {
+		return false, 0
+	}
+
+	triggered := s.evaluator.EvalWithMetric(rule.MetricRule.Name, float64(rule.MetricRule.Value.Value()), forecast)
+	klog.V(4).Infof("Evaluation result of the forecast is %v, rule: %s, watermark: %f, forecast: %f",
+		triggered, rule.Name+"."+rule.MetricRule.Name, float64(rule.MetricRule.Value.Value()), forecast)
+	if triggered {
+		klog.Warningf("Rule %s is predicted to be triggered, watermark: %f, forecast: %f",
+			rule.Name+"."+rule.MetricRule.Name, float64(rule.MetricRule.Value.Value()), forecast)
+	}
+	return triggered, forecast
+}
@@ -0,0 +1,175 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+	predictionlisters "github.com/gocrane/api/pkg/generated/listers/prediction/v1alpha1"
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/analyzer/evaluator"
+	ecache "github.com/gocrane/crane/pkg/ensurance/cache"
+	"github.com/gocrane/crane/pkg/ensurance/executor"
+	"github.com/gocrane/crane/pkg/known"
+)
+
+func TestLocalForecaster(t *testing.T) {
+	f := newLocalForecaster(5 * time.Minute)
+	keep := map[string]bool{"memory_total_usage": true, "cpu_total_usage": true}
+	start := time.Unix(1000, 0)
+
+	for i := 0; i < 30; i++ {
+		now := start.Add(time.Duration(i*10) * time.Second)
+		f.Update(map[string][]common.TimeSeries{
+			// the memory grows by 1Mi per second
+			"memory_total_usage": {{Samples: []common.Sample{{Value: float64(4096+i*10) * 1024 * 1024, Timestamp: now.Unix()}}}},
+			// the cpu usage goes down
+			"cpu_total_usage": {{Samples: []common.Sample{{Value: float64(4000 - i*10), Timestamp: now.Unix()}}}},
+		}, keep, now)
+
+		// the forecast requires enough samples
+		if i < minForecastSamples-1 {
+			_, ok := f.Forecast("memory_total_usage", nil, now)
+			assert.False(t, ok)
+		}
+	}
+
+	forecast, ok := f.Forecast("memory_total_usage", nil, start)
+	assert.True(t, ok)
+	assert.InDelta(t, float64(4096+290+300)*1024*1024, forecast, 1)
+
+	// the forecast of a decreasing usage is the current usage
+	forecast, ok = f.Forecast("cpu_total_usage", nil, start)
+	assert.True(t, ok)
+	assert.Equal(t, 3710.0, forecast)
+
+	_, ok = f.Forecast("cpu_total_utilization", nil, start)
+	assert.False(t, ok)
+}
+
+func TestTspForecaster(t *testing.T) {
+	now := time.Unix(10000, 0)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.NoError(t, indexer.Add(&predictionapi.TimeSeriesPrediction{
+		ObjectMeta: metav1.ObjectMeta{Name: "noderesource-node-1", Namespace: known.CraneSystemNamespace},
+		Status: predictionapi.TimeSeriesPredictionStatus{PredictionMetrics: []predictionapi.PredictionMetricStatus{
+			{
+				ResourceIdentifier: "cpu",
+				Prediction: []*predictionapi.MetricTimeSeries{{Samples: []predictionapi.Sample{
+					{Value: "3", Timestamp: now.Unix() - 60},
+					{Value: "5.5", Timestamp: now.Unix() + 60},
+					{Value: "6", Timestamp: now.Unix() + 240},
+					// out of the horizon
+					{Value: "7", Timestamp: now.Unix() + 600},
+				}}},
+			},
+			{
+				ResourceIdentifier: "memory",
+				Prediction: []*predictionapi.MetricTimeSeries{{Samples: []predictionapi.Sample{
+					{Value: "8589934592", Timestamp: now.Unix() + 60},
+				}}},
+			},
+		}},
+	}))
+
+	f, err := NewForecaster(ForecastSourceTsp, 5*time.Minute, predictionlisters.NewTimeSeriesPredictionLister(indexer), "noderesource-node-1")
+	assert.NoError(t, err)
+	state := map[string][]common.TimeSeries{
+		"cpu_core_numbers": {{Samples: []common.Sample{{Value: 8}}}},
+		"memory_total":     {{Samples: []common.Sample{{Value: 16 * 1024 * 1024 * 1024}}}},
+	}
+
+	cases := map[string]struct {
+		metric   string
+		forecast float64
+		ok       bool
+	}{
+		"cpu usage in milli cores":   {metric: "cpu_total_usage", forecast: 6000, ok: true},
+		"cpu utilization":            {metric: "cpu_total_utilization", forecast: 75, ok: true},
+		"memory usage in bytes":      {metric: "memory_total_usage", forecast: 8589934592, ok: true},
+		"memory utilization":         {metric: "memory_total_utilization", forecast: 50, ok: true},
+		"metric without prediction":  {metric: "disk_read_kibps", ok: false},
+		"utilization without totals": {metric: "cpu_total_utilization", ok: false},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := state
+			if name == "utilization without totals" {
+				s = nil
+			}
+			forecast, ok := f.Forecast(c.metric, s, now)
+			assert.Equal(t, c.ok, ok)
+			if ok {
+				assert.InDelta(t, c.forecast, forecast, 1e-6)
+			}
+		})
+	}
+
+	_, err = NewForecaster(ForecastSourceTsp, 5*time.Minute, predictionlisters.NewTimeSeriesPredictionLister(indexer), "")
+	assert.Error(t, err)
+	f, err = NewForecaster("", 5*time.Minute, nil, "")
+	assert.NoError(t, err)
+	assert.Nil(t, f)
+}
+
+type fakeForecaster map[string]float64
+
+func (f fakeForecaster) Update(map[string][]common.TimeSeries, map[string]bool, time.Time) {
+}
+
+func (f fakeForecaster) Forecast(metricName string, _ map[string][]common.TimeSeries, _ time.Time) (float64, bool) {
+	v, ok := f[metricName]
+	return v, ok
+}
+
+func TestAnalyzeWithForecast(t *testing.T) {
+	s := &AnomalyAnalyzer{
+		evaluator:  evaluator.NewExpressionEvaluator(),
+		triggered:  make(map[string]uint64),
+		restored:   make(map[string]uint64),
+		forecaster: fakeForecaster{"memory_total_usage": 9000},
+	}
+	rule := ensuranceapi.Rule{
+		Name:                "memory-usage",
+		AvoidanceThreshold:  1,
+		RestoreThreshold:    1,
+		AvoidanceActionName: "throttle",
+		MetricRule:          &ensuranceapi.MetricRule{Name: "memory_total_usage", Value: resource.MustParse("8000")},
+	}
+	nodeQOS := &ensuranceapi.NodeQOS{ObjectMeta: metav1.ObjectMeta{Name: "memory"}, Spec: ensuranceapi.NodeQOSSpec{Rules: []ensuranceapi.Rule{rule}}}
+	state := map[string][]common.TimeSeries{"memory_total_usage": {{Samples: []common.Sample{{Value: 7000}}}}}
+
+	ac, err := s.analyze("memory.memory-usage", nodeQOS, rule, nil, state)
+	assert.NoError(t, err)
+	assert.True(t, ac.Triggered)
+	assert.True(t, ac.Predicted)
+	assert.Equal(t, 9000.0, ac.Forecast)
+
+	ac.NodeQOS = nodeQOS
+	var e executor.ThrottleExecutor
+	combineThrottleForecast(&e, ac)
+	combineThrottleForecast(&e, ecache.ActionContext{NodeQOS: nodeQOS, RuleName: rule.Name, Triggered: true, Predicted: true, Forecast: 8500})
+	assert.Equal(t, map[executor.WatermarkMetric]float64{"memory_total_usage": 9000}, e.ThrottleDownForecasts)
+
+	// the current usage crossing the watermark is not a forecast
+	state["memory_total_usage"][0].Samples[0].Value = 8500
+	ac, err = s.analyze("memory.memory-usage", nodeQOS, rule, nil, state)
+	assert.NoError(t, err)
+	assert.True(t, ac.Triggered)
+	assert.False(t, ac.Predicted)
+
+	// restored if neither the current usage nor the forecast crosses the watermark
+	s.forecaster = fakeForecaster{"memory_total_usage": 7500}
+	state["memory_total_usage"][0].Samples[0].Value = 7000
+	ac, err = s.analyze("memory.memory-usage", nodeQOS, rule, nil, state)
+	assert.NoError(t, err)
+	assert.False(t, ac.Triggered)
+	assert.True(t, ac.Restored)
+}
@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+// metricHistory keeps the samples of the metrics referred by the trigger expressions, the samples older than the
+// window are dropped, and so are the series without samples in the window
+type metricHistory struct {
+	window time.Duration
+	// metric name -> labels key -> series
+	series map[string]map[string]*historySeries
+}
+
+type historySeries struct {
+	labels  map[string]string
+	samples []common.Sample
+	// updated is whether the series is in the latest state
+	updated bool
+}
+
+func newMetricHistory(window time.Duration) *metricHistory {
+	return &metricHistory{window: window, series: make(map[string]map[string]*historySeries)}
+}
+
+// update appends the latest samples of the metrics in the state, and drops the metrics which are not kept
+func (h *metricHistory) update(state map[string][]common.TimeSeries, keep map[string]bool, now time.Time) {
+	for name := range h.series {
+		if !keep[name] {
+			delete(h.series, name)
+		}
+	}
+
+	for name := range keep {
+		series, ok := h.series[name]
+		if !ok {
+			series = make(map[string]*historySeries)
+			h.series[name] = series
+		}
+		for _, s := range series {
+			s.updated = false
+		}
+		for _, ts := range state[name] {
+			if len(ts.Samples) == 0 {
+				continue
+			}
+			key := labelsKey(ts.Labels)
+			s, ok := series[key]
+			if !ok {
+				s = &historySeries{labels: common.Labels2Maps(ts.Labels)}
+				series[key] = s
+			}
+			s.updated = true
+			for _, sample := range ts.Samples {
+				// the collectors may give the same sample again if the metric is not refreshed
+				if n := len(s.samples); n > 0 && sample.Timestamp <= s.samples[n-1].Timestamp {
+					continue
+				}
+				s.samples = append(s.samples, sample)
+			}
+		}
+
+		start := now.Add(-h.window).Unix()
+		for key, s := range series {
+			i := sort.Search(len(s.samples), func(i int) bool { return s.samples[i].Timestamp >= start })
+			if i == len(s.samples) {
+				delete(series, key)
+				continue
+			}
+			s.samples = s.samples[i:]
+		}
+	}
+}
+
+// Range implements evaluator.History
+func (h *metricHistory) Range(metricName string, matchLabels map[string]string, window time.Duration) [][]common.Sample {
+	var result [][]common.Sample
+	for _, s := range h.series[metricName] {
+		if !matchSeriesLabels(s.labels, matchLabels) || len(s.samples) == 0 {
+			continue
+		}
+		last := s.samples[len(s.samples)-1]
+		if window == 0 {
+			// the series which is gone, such as the series of a deleted pod, has no latest value
+			if s.updated {
+				result = append(result, []common.Sample{last})
+			}
+			continue
+		}
+		start := last.Timestamp - int64(window.Seconds())
+		i := sort.Search(len(s.samples), func(i int) bool { return s.samples[i].Timestamp >= start })
+		result = append(result, s.samples[i:])
+	}
+	return result
+}
+
+func matchSeriesLabels(labels, matchLabels map[string]string) bool {
+	for k, v := range matchLabels {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func labelsKey(labels []common.Label) string {
+	var pairs []string
+	for _, l := range labels {
+		pairs = append(pairs, l.Name+"="+l.Value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
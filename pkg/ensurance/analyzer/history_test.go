@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/analyzer/evaluator"
+)
+
+func TestMetricHistory(t *testing.T) {
+	h := newMetricHistory(time.Minute)
+	keep := map[string]bool{"memory_total_usage": true, "pod_memory_pressure_some_avg10": true}
+	start := time.Unix(1000, 0)
+
+	for i := 0; i <= 12; i++ {
+		now := start.Add(time.Duration(i*10) * time.Second)
+		state := map[string][]common.TimeSeries{
+			"memory_total_usage": {{Samples: []common.Sample{{Value: float64(100 + i), Timestamp: now.Unix()}}}},
+			"cpu_total_usage":    {{Samples: []common.Sample{{Value: 1, Timestamp: now.Unix()}}}},
+			"pod_memory_pressure_some_avg10": {
+				{Labels: []common.Label{{Name: common.LabelNamePodName, Value: "foo"}}, Samples: []common.Sample{{Value: 10, Timestamp: now.Unix()}}},
+			},
+		}
+		// the pod is deleted
+		if i > 6 {
+			delete(state, "pod_memory_pressure_some_avg10")
+		}
+		h.update(state, keep, now)
+	}
+
+	// the metrics not referred are not kept
+	assert.Empty(t, h.Range("cpu_total_usage", nil, 0))
+
+	// the samples older than the window are dropped
+	series := h.Range("memory_total_usage", nil, time.Hour)
+	assert.Len(t, series, 1)
+	assert.Len(t, series[0], 7)
+	assert.Equal(t, 106.0, series[0][0].Value)
+	assert.Equal(t, []common.Sample{{Value: 112, Timestamp: 1120}}, h.Range("memory_total_usage", nil, 0)[0])
+	assert.Len(t, h.Range("memory_total_usage", nil, 30*time.Second)[0], 4)
+
+	// the series which is gone has no latest value, but its samples in the window are kept
+	assert.Empty(t, h.Range("pod_memory_pressure_some_avg10", nil, 0))
+	assert.Len(t, h.Range("pod_memory_pressure_some_avg10", map[string]string{common.LabelNamePodName: "foo"}, time.Minute), 1)
+	assert.Empty(t, h.Range("pod_memory_pressure_some_avg10", map[string]string{common.LabelNamePodName: "bar"}, time.Minute))
+
+	// the same sample is not appended again
+	h.update(map[string][]common.TimeSeries{"memory_total_usage": {{Samples: []common.Sample{{Value: 112, Timestamp: 1120}}}}}, keep, start.Add(120*time.Second))
+	assert.Len(t, h.Range("memory_total_usage", nil, time.Hour)[0], 7)
+
+	// the series without samples in the window are dropped
+	h.update(map[string][]common.TimeSeries{}, keep, start.Add(time.Hour))
+	assert.Empty(t, h.Range("pod_memory_pressure_some_avg10", nil, time.Minute))
+	assert.Empty(t, h.Range("memory_total_usage", nil, time.Minute))
+
+	// the history is a source of the trigger expressions
+	var _ evaluator.History = h
+}
@@ -0,0 +1,145 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/known"
+)
+
+// policyInput is the input of the Rego policy of a rule, for example the policy below is triggered if the cpu
+// utilization is above 80% and the load5 is above the cpu cores:
+//
+//	package crane.ensurance
+//
+//	default trigger = false
+//
+//	trigger {
+//		input.metrics.cpu_total_utilization[_].value > 80
+//		input.metrics.cpu_load_5_min[_].value > input.node.capacity.cpu
+//	}
+type policyInput struct {
+	Rule    policyRule                      `json:"rule"`
+	Node    policyNode                      `json:"node"`
+	Pods    []policyPod                     `json:"pods"`
+	Metrics map[string][]policyMetricSeries `json:"metrics"`
+}
+
+type policyRule struct {
+	Name   string  `json:"name"`
+	Metric string  `json:"metric,omitempty"`
+	Value  float64 `json:"value,omitempty"`
+}
+
+type policyNode struct {
+	Name        string             `json:"name"`
+	Labels      map[string]string  `json:"labels,omitempty"`
+	Capacity    map[string]float64 `json:"capacity,omitempty"`
+	Allocatable map[string]float64 `json:"allocatable,omitempty"`
+}
+
+type policyPod struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	UID       string            `json:"uid"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	QOSClass  string            `json:"qosClass"`
+	Priority  int32             `json:"priority"`
+}
+
+type policyMetricSeries struct {
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+// getRulePolicy returns the Rego policy of the rule defined in the annotations of NodeQOS
+func getRulePolicy(nodeQOS *ensuranceapi.NodeQOS, rule ensuranceapi.Rule) string {
+	return nodeQOS.Annotations[known.NodeQOSRulePolicyAnnotationPrefix+"/"+rule.Name]
+}
+
+// triggerWithPolicy evaluates the policy with the latest samples of all the metrics and the context of the node and
+// its pods
+func (s *AnomalyAnalyzer) triggerWithPolicy(rule ensuranceapi.Rule, policy string, node *v1.Node, stateMap map[string][]common.TimeSeries) (bool, error) {
+	pods, err := s.podLister.List(labels.Everything())
+	if err != nil {
+		return false, fmt.Errorf("failed to list pods: %v", err)
+	}
+
+	input, err := json.Marshal(buildPolicyInput(rule, node, pods, stateMap))
+	if err != nil {
+		return false, fmt.Errorf("failed to encode the input of policy: %v", err)
+	}
+
+	triggered := s.policyEvaluator.EvalWithRawQuery(string(input), policy)
+	klog.V(4).Infof("Evaluation result of the policy of rule %s is %v", rule.Name, triggered)
+	if triggered {
+		klog.Warningf("Rule %s is triggered by policy", rule.Name)
+	}
+	return triggered, nil
+}
+
+func buildPolicyInput(rule ensuranceapi.Rule, node *v1.Node, pods []*v1.Pod, stateMap map[string][]common.TimeSeries) policyInput {
+	input := policyInput{
+		Rule: policyRule{Name: rule.Name},
+		Node: policyNode{
+			Name:        node.Name,
+			Labels:      node.Labels,
+			Capacity:    resourceListToMap(node.Status.Capacity),
+			Allocatable: resourceListToMap(node.Status.Allocatable),
+		},
+		Pods:    []policyPod{},
+		Metrics: make(map[string][]policyMetricSeries),
+	}
+	if rule.MetricRule != nil {
+		input.Rule.Metric = rule.MetricRule.Name
+		input.Rule.Value = float64(rule.MetricRule.Value.Value())
+	}
+
+	for _, pod := range pods {
+		var priority int32
+		if pod.Spec.Priority != nil {
+			priority = *pod.Spec.Priority
+		}
+		input.Pods = append(input.Pods, policyPod{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			UID:       string(pod.UID),
+			Labels:    pod.Labels,
+			QOSClass:  string(pod.Status.QOSClass),
+			Priority:  priority,
+		})
+	}
+
+	for name, series := range stateMap {
+		for _, ts := range series {
+			if len(ts.Samples) == 0 {
+				continue
+			}
+			input.Metrics[name] = append(input.Metrics[name], policyMetricSeries{
+				Labels: common.Labels2Maps(ts.Labels),
+				Value:  ts.Samples[len(ts.Samples)-1].Value,
+			})
+		}
+	}
+	return input
+}
+
+// resourceListToMap converts the quantities to numbers, cpu is in cores
+func resourceListToMap(list v1.ResourceList) map[string]float64 {
+	m := make(map[string]float64, len(list))
+	for name, quantity := range list {
+		if name == v1.ResourceCPU {
+			m[string(name)] = float64(quantity.MilliValue()) / 1000
+		} else {
+			m[string(name)] = float64(quantity.Value())
+		}
+	}
+	return m
+}
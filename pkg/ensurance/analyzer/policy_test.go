@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/analyzer/evaluator"
+	"github.com/gocrane/crane/pkg/known"
+)
+
+func TestPolicyInput(t *testing.T) {
+	policy := `package crane.ensurance
+
+default trigger = false
+
+trigger {
+	input.metrics.cpu_total_utilization[_].value > 80
+	input.metrics.cpu_load_5_min[_].value > input.node.capacity.cpu
+	count([p | p := input.pods[_]; p.qosClass == "BestEffort"]) > 0
+}
+`
+	rule := ensuranceapi.Rule{Name: "cpu-busy", MetricRule: &ensuranceapi.MetricRule{Name: "cpu_total_utilization", Value: resource.MustParse("80")}}
+	nodeQOS := &ensuranceapi.NodeQOS{ObjectMeta: metav1.ObjectMeta{
+		Name:        "busy",
+		Annotations: map[string]string{known.NodeQOSRulePolicyAnnotationPrefix + "/cpu-busy": policy},
+	}}
+	assert.Equal(t, policy, getRulePolicy(nodeQOS, rule))
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status:     v1.NodeStatus{Capacity: v1.ResourceList{v1.ResourceCPU: resource.MustParse("8"), v1.ResourceMemory: resource.MustParse("16Gi")}},
+	}
+	pods := []*v1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "default", UID: "uid-1"},
+		Status:     v1.PodStatus{QOSClass: v1.PodQOSBestEffort},
+	}}
+	stateMap := map[string][]common.TimeSeries{
+		"cpu_total_utilization": {{Samples: []common.Sample{{Value: 85}}}},
+		"cpu_load_5_min":        {{Samples: []common.Sample{{Value: 9.5}}}},
+	}
+
+	input := buildPolicyInput(rule, node, pods, stateMap)
+	assert.Equal(t, 8.0, input.Node.Capacity["cpu"])
+	assert.Equal(t, float64(16<<30), input.Node.Capacity["memory"])
+	assert.Equal(t, "cpu_total_utilization", input.Rule.Metric)
+	assert.Equal(t, 80.0, input.Rule.Value)
+
+	e := evaluator.NewOpaEvaluator()
+	raw, err := json.Marshal(input)
+	assert.NoError(t, err)
+	assert.True(t, e.EvalWithRawQuery(string(raw), policy))
+
+	stateMap["cpu_load_5_min"][0].Samples[0].Value = 7.5
+	raw, err = json.Marshal(buildPolicyInput(rule, node, pods, stateMap))
+	assert.NoError(t, err)
+	assert.False(t, e.EvalWithRawQuery(string(raw), policy))
+}
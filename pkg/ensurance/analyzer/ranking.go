@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	execsort "github.com/gocrane/crane/pkg/ensurance/executor/sort"
+	"github.com/gocrane/crane/pkg/known"
+)
+
+// rankingSources are the NodeQOS and the actions which contribute the pods to an executor, their ranking policies
+// decide the order of the pods
+type rankingSources struct {
+	nodeQOSs map[string]*ensuranceapi.NodeQOS
+	actions  sets.String
+}
+
+func newRankingSources() *rankingSources {
+	return &rankingSources{nodeQOSs: make(map[string]*ensuranceapi.NodeQOS), actions: sets.NewString()}
+}
+
+func (r *rankingSources) add(nodeQOS *ensuranceapi.NodeQOS, actionName string) {
+	r.nodeQOSs[nodeQOS.Name] = nodeQOS
+	r.actions.Insert(actionName)
+}
+
+// getRankingPolicy merges the ranking policies of the NodeQOS, if none of them declares a policy, the policies of
+// the PodQOS which allow the actions are merged instead. The pods are sorted by the metrics if no policy is declared.
+func (s *AnomalyAnalyzer) getRankingPolicy(sources *rankingSources) execsort.RankingPolicy {
+	var policy execsort.RankingPolicy
+	for _, nodeQOS := range sources.nodeQOSs {
+		policy = policy.Merge(parseRankingPolicy(nodeQOS.Annotations, "NodeQOS", nodeQOS.Name))
+	}
+	if len(policy) != 0 || sources.actions.Len() == 0 {
+		return policy
+	}
+
+	podQOSList, err := s.podQOSLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list PodQOS: %v", err)
+		return nil
+	}
+	for _, qos := range podQOSList {
+		if sources.actions.HasAny(qos.Spec.AllowedActions...) {
+			policy = policy.Merge(parseRankingPolicy(qos.Annotations, "PodQOS", qos.Name))
+		}
+	}
+	return policy
+}
+
+func parseRankingPolicy(annotations map[string]string, kind, name string) execsort.RankingPolicy {
+	data, ok := annotations[known.RankingPolicyAnnotation]
+	if !ok {
+		return nil
+	}
+	policy, err := execsort.ParseRankingPolicy(data)
+	if err != nil {
+		klog.Warningf("Failed to parse the ranking policy of %s %s: %v", kind, name, err)
+		return nil
+	}
+	return policy
+}
+
+// buildPodActionContext builds the context of the pod with the ranking weight of its namespace
+func (s *AnomalyAnalyzer) buildPodActionContext(pod *v1.Pod, stateMap map[string][]common.TimeSeries, action *ensuranceapi.AvoidanceAction, actionType podinfo.ActionType) podinfo.PodContext {
+	podContext := podinfo.BuildPodActionContext(pod, stateMap, action, actionType)
+	podContext.NamespaceWeight = s.getNamespaceWeight(pod.Namespace)
+	return podContext
+}
+
+func (s *AnomalyAnalyzer) getNamespaceWeight(namespace string) float64 {
+	if s.namespaceLister == nil {
+		return 0
+	}
+	ns, err := s.namespaceLister.Get(namespace)
+	if err != nil {
+		klog.V(4).Infof("Failed to get namespace %s: %v", namespace, err)
+		return 0
+	}
+	value, ok := ns.Labels[known.EnsuranceRankingWeightLabel]
+	if !ok {
+		return 0
+	}
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		klog.Warningf("Failed to parse the ranking weight %s of namespace %s: %v", value, namespace, err)
+		return 0
+	}
+	return weight
+}
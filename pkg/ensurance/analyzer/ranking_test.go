@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+	ensurancelisters "github.com/gocrane/api/pkg/generated/listers/ensurance/v1alpha1"
+
+	execsort "github.com/gocrane/crane/pkg/ensurance/executor/sort"
+	"github.com/gocrane/crane/pkg/known"
+)
+
+func TestGetRankingPolicy(t *testing.T) {
+	podQOSIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for name, policy := range map[string]string{"throttle": `{"Priority": 1}`, "eviction": `{"StartupTime": 1}`, "broken": `{"Unknown": 1}`} {
+		assert.NoError(t, podQOSIndexer.Add(&ensuranceapi.PodQOS{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{known.RankingPolicyAnnotation: policy}},
+			Spec:       ensuranceapi.PodQOSSpec{AllowedActions: []string{name}},
+		}))
+	}
+	s := &AnomalyAnalyzer{podQOSLister: ensurancelisters.NewPodQOSLister(podQOSIndexer)}
+
+	// the policies of PodQOS which allow the actions are used if NodeQOS declares no policy
+	sources := newRankingSources()
+	sources.add(&ensuranceapi.NodeQOS{ObjectMeta: metav1.ObjectMeta{Name: "cpu"}}, "eviction")
+	sources.add(&ensuranceapi.NodeQOS{ObjectMeta: metav1.ObjectMeta{Name: "cpu"}}, "broken")
+	assert.Equal(t, execsort.RankingPolicy{"StartupTime": 1}, s.getRankingPolicy(sources))
+
+	// the policies of NodeQOS take precedence, and they are merged
+	for _, name := range []string{"cpu", "memory"} {
+		sources.add(&ensuranceapi.NodeQOS{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{known.RankingPolicyAnnotation: `{"Priority": 1}`}}}, "throttle")
+	}
+	assert.Equal(t, execsort.RankingPolicy{"Priority": 2}, s.getRankingPolicy(sources))
+
+	// no policy if there is no action
+	assert.Empty(t, s.getRankingPolicy(newRankingSources()))
+}
+
+func TestGetNamespaceWeight(t *testing.T) {
+	namespaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.NoError(t, namespaceIndexer.Add(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "online", Labels: map[string]string{known.EnsuranceRankingWeightLabel: "10"}}}))
+	assert.NoError(t, namespaceIndexer.Add(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "broken", Labels: map[string]string{known.EnsuranceRankingWeightLabel: "high"}}}))
+	assert.NoError(t, namespaceIndexer.Add(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}))
+	s := &AnomalyAnalyzer{namespaceLister: corelisters.NewNamespaceLister(namespaceIndexer)}
+
+	assert.Equal(t, 10.0, s.getNamespaceWeight("online"))
+	assert.Equal(t, 0.0, s.getNamespaceWeight("broken"))
+	assert.Equal(t, 0.0, s.getNamespaceWeight("default"))
+	assert.Equal(t, 0.0, s.getNamespaceWeight("missing"))
+}
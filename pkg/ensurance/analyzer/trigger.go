@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/analyzer/evaluator"
+	"github.com/gocrane/crane/pkg/known"
+)
+
+// getRuleTriggerExpression returns the trigger expression of the rule defined in the annotations of NodeQOS
+func getRuleTriggerExpression(nodeQOS *ensuranceapi.NodeQOS, rule ensuranceapi.Rule) string {
+	return nodeQOS.Annotations[known.NodeQOSRuleTriggerAnnotationPrefix+"/"+rule.Name]
+}
+
+// updateHistory parses the trigger expressions of the rules, and keeps the history of the metrics they refer
+func (s *AnomalyAnalyzer) updateHistory(nodeQOSs []*ensuranceapi.NodeQOS, state map[string][]common.TimeSeries) {
+	var triggerExpressions = make(map[string]*evaluator.TriggerExpression)
+	var metricNames = make(map[string]bool)
+	for _, n := range nodeQOSs {
+		for _, r := range n.Spec.Rules {
+			expr := getRuleTriggerExpression(n, r)
+			if expr == "" {
+				continue
+			}
+			parsed, ok := s.triggerExpressions[expr]
+			if !ok {
+				var err error
+				if parsed, err = evaluator.ParseTriggerExpression(expr); err != nil {
+					klog.Errorf("Failed to parse trigger expression of rule %s.%s: %v", n.Name, r.Name, err)
+					continue
+				}
+			}
+			triggerExpressions[expr] = parsed
+			for _, name := range parsed.MetricNames() {
+				metricNames[name] = true
+			}
+		}
+	}
+
+	s.triggerExpressions = triggerExpressions
+	s.history.update(state, metricNames, time.Now())
+}
+
+// triggerWithExpression evaluates the trigger expression with the history of the metrics
+func (s *AnomalyAnalyzer) triggerWithExpression(rule ensuranceapi.Rule, expr string) (bool, error) {
+	parsed, ok := s.triggerExpressions[expr]
+	if !ok {
+		// the expression failed to parse when the history is updated
+		_, err := evaluator.ParseTriggerExpression(expr)
+		return false, fmt.Errorf("invalid trigger expression of rule %s: %v", rule.Name, err)
+	}
+
+	triggered := parsed.Eval(s.history)
+	klog.V(4).Infof("Evaluation result of the trigger expression of rule %s is %v, expression: %s", rule.Name, triggered, expr)
+	if triggered {
+		klog.Warningf("Rule %s is triggered by expression %s", rule.Name, expr)
+	}
+	return triggered, nil
+}
@@ -0,0 +1,139 @@
+//go:build linux
+// +build linux
+
+package bandwidth
+
+import (
+	"fmt"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+const (
+	// podInterfaceName is the interface created by the cni plugins in the pod network namespace
+	podInterfaceName = "eth0"
+	// latencyInMillis is the max time a packet can sit in the tbf queue
+	latencyInMillis = 25.0
+	// minBurstInBytes keeps the burst larger than the mtu
+	minBurstInBytes = 64 * 1024
+)
+
+// GetLimit returns the bandwidth limit of the pod in bits per second, zero means unlimited. The pid can be any process in
+// the pod network namespace.
+func GetLimit(pid int, direction Direction) (uint64, error) {
+	handle, link, err := podLink(pid, direction)
+	if err != nil {
+		return 0, err
+	}
+	defer handle.Delete()
+
+	tbf, err := rootTbf(handle, link)
+	if err != nil || tbf == nil {
+		return 0, err
+	}
+	return tbf.Rate * 8, nil
+}
+
+// SetLimit shapes the bandwidth of the pod by a tbf qdisc in bits per second, zero removes the limit. The pid can be any
+// process in the pod network namespace.
+func SetLimit(pid int, direction Direction, bitsPerSecond uint64) error {
+	handle, link, err := podLink(pid, direction)
+	if err != nil {
+		return err
+	}
+	defer handle.Delete()
+
+	if bitsPerSecond == 0 {
+		tbf, err := rootTbf(handle, link)
+		if err != nil || tbf == nil {
+			return err
+		}
+		return handle.QdiscDel(tbf)
+	}
+
+	rateInBytes := bitsPerSecond / 8
+	burstInBytes := uint32(rateInBytes / 10)
+	if burstInBytes < minBurstInBytes {
+		burstInBytes = minBurstInBytes
+	}
+	tbf := &netlink.Tbf{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(1, 0),
+			Parent:    netlink.HANDLE_ROOT,
+		},
+		Rate:   rateInBytes,
+		Limit:  limit(rateInBytes, burstInBytes),
+		Buffer: buffer(rateInBytes, burstInBytes),
+	}
+	return handle.QdiscReplace(tbf)
+}
+
+// podLink returns the netlink handle and the link to shape the traffic of the direction, the handle should be deleted
+// after use.
+func podLink(pid int, direction Direction) (*netlink.Handle, netlink.Link, error) {
+	ns, err := netns.GetFromPid(pid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get network namespace of pid %d: %v", pid, err)
+	}
+	defer ns.Close()
+
+	podHandle, err := netlink.NewHandleAt(ns)
+	if err != nil {
+		return nil, nil, err
+	}
+	podLink, err := podHandle.LinkByName(podInterfaceName)
+	if err != nil {
+		podHandle.Delete()
+		return nil, nil, fmt.Errorf("failed to get %s of pid %d: %v", podInterfaceName, pid, err)
+	}
+	if direction == Egress {
+		return podHandle, podLink, nil
+	}
+	podHandle.Delete()
+
+	// The parent index of a veth is the index of its peer
+	peerIndex := podLink.Attrs().ParentIndex
+	if podLink.Type() != "veth" || peerIndex == 0 {
+		return nil, nil, fmt.Errorf("%s of pid %d is a %s, not a veth", podInterfaceName, pid, podLink.Type())
+	}
+	// The agent is not in the host network namespace, which is the one of the init process
+	hostNs, err := netns.GetFromPid(1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get host network namespace: %v", err)
+	}
+	defer hostNs.Close()
+
+	hostHandle, err := netlink.NewHandleAt(hostNs)
+	if err != nil {
+		return nil, nil, err
+	}
+	hostLink, err := hostHandle.LinkByIndex(peerIndex)
+	if err != nil {
+		hostHandle.Delete()
+		return nil, nil, fmt.Errorf("failed to get the host veth of pid %d: %v", pid, err)
+	}
+	return hostHandle, hostLink, nil
+}
+
+func rootTbf(handle *netlink.Handle, link netlink.Link) (*netlink.Tbf, error) {
+	qdiscs, err := handle.QdiscList(link)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range qdiscs {
+		if tbf, ok := q.(*netlink.Tbf); ok && tbf.Attrs().Parent == netlink.HANDLE_ROOT {
+			return tbf, nil
+		}
+	}
+	return nil, nil
+}
+
+func buffer(rateInBytes uint64, burstInBytes uint32) uint32 {
+	return uint32(float64(burstInBytes) * float64(netlink.TIME_UNITS_PER_SEC) / float64(rateInBytes) * netlink.TickInUsec())
+}
+
+func limit(rateInBytes uint64, burstInBytes uint32) uint32 {
+	return uint32(float64(rateInBytes)*latencyInMillis/1000) + burstInBytes
+}
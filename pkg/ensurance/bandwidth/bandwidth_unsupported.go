@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package bandwidth
+
+import "fmt"
+
+func GetLimit(pid int, direction Direction) (uint64, error) {
+	return 0, fmt.Errorf("bandwidth limit is not supported")
+}
+
+func SetLimit(pid int, direction Direction, bitsPerSecond uint64) error {
+	return fmt.Errorf("bandwidth limit is not supported")
+}
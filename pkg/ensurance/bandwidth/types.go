@@ -0,0 +1,11 @@
+package bandwidth
+
+// Direction is the direction of the pod traffic
+type Direction string
+
+const (
+	// Ingress is the traffic received by the pod, it's shaped on the host side veth of the pod
+	Ingress Direction = "ingress"
+	// Egress is the traffic sent by the pod, it's shaped on the interface in the pod network namespace
+	Egress Direction = "egress"
+)
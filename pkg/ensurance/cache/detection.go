@@ -15,6 +15,10 @@ type ActionContext struct {
 	Strategy ensuranceapi.AvoidanceActionStrategy
 	// if the policy triggered action
 	Triggered bool
+	// if the action is triggered by the forecast but not the current usage, only throttle and schedule actions are taken
+	Predicted bool
+	// the forecast of the metric which triggered the action
+	Forecast float64
 	// if the policy triggered restored action
 	Restored bool
 	// action name
@@ -0,0 +1,19 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// DefaultCgroupRoot is the mount point of the cgroup file system
+	DefaultCgroupRoot = "/sys/fs/cgroup"
+
+	unifiedControllersFile = "cgroup.controllers"
+)
+
+// IsUnified returns whether the cgroup root is mounted as cgroup v2
+func IsUnified(cgroupRoot string) bool {
+	_, err := os.Stat(filepath.Join(cgroupRoot, unifiedControllersFile))
+	return err == nil
+}
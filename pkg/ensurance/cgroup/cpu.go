@@ -0,0 +1,160 @@
+package cgroup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	cpuQuotaFile  = "cpu.cfs_quota_us"
+	cpuPeriodFile = "cpu.cfs_period_us"
+	cpuSharesFile = "cpu.shares"
+
+	cpuMaxFile    = "cpu.max"
+	cpuWeightFile = "cpu.weight"
+
+	// defaultCPUPeriod is the cfs period of the kernel if not specified
+	defaultCPUPeriod = 100000
+)
+
+// GetCPUQuota returns the cfs quota and period of the cgroup in microseconds, the quota is -1 if unlimited. It reads
+// cpu.cfs_quota_us and cpu.cfs_period_us for cgroup v1, and cpu.max for cgroup v2.
+func GetCPUQuota(cgroupRoot, cgroupPath string) (quota int64, period uint64, err error) {
+	if IsUnified(cgroupRoot) {
+		content, err := readFile(filepath.Join(cgroupRoot, cgroupPath), cpuMaxFile)
+		if err != nil {
+			return 0, 0, err
+		}
+		return parseCPUMax(content)
+	}
+
+	dir := filepath.Join(cgroupRoot, cpuSubsystem, cgroupPath)
+	content, err := readFile(dir, cpuQuotaFile)
+	if err != nil {
+		return 0, 0, err
+	}
+	if quota, err = strconv.ParseInt(content, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid %s %q: %v", cpuQuotaFile, content, err)
+	}
+	content, err = readFile(dir, cpuPeriodFile)
+	if err != nil {
+		return 0, 0, err
+	}
+	if period, err = strconv.ParseUint(content, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid %s %q: %v", cpuPeriodFile, content, err)
+	}
+	return quota, period, nil
+}
+
+// SetCPUQuota sets the cfs quota of the cgroup in microseconds, -1 removes the quota, the period is kept if it's zero.
+func SetCPUQuota(cgroupRoot, cgroupPath string, quota int64, period uint64) error {
+	if IsUnified(cgroupRoot) {
+		max := unlimited
+		if quota > 0 {
+			max = strconv.FormatInt(quota, 10)
+		}
+		if period > 0 {
+			max += " " + strconv.FormatUint(period, 10)
+		}
+		return writeFile(filepath.Join(cgroupRoot, cgroupPath), cpuMaxFile, max)
+	}
+
+	dir := filepath.Join(cgroupRoot, cpuSubsystem, cgroupPath)
+	if period > 0 {
+		if err := writeFile(dir, cpuPeriodFile, strconv.FormatUint(period, 10)); err != nil {
+			return err
+		}
+	}
+	if quota <= 0 {
+		quota = -1
+	}
+	return writeFile(dir, cpuQuotaFile, strconv.FormatInt(quota, 10))
+}
+
+// GetCPUShares returns the cpu shares of the cgroup, cpu.weight of cgroup v2 is converted to shares.
+func GetCPUShares(cgroupRoot, cgroupPath string) (uint64, error) {
+	if IsUnified(cgroupRoot) {
+		content, err := readFile(filepath.Join(cgroupRoot, cgroupPath), cpuWeightFile)
+		if err != nil {
+			return 0, err
+		}
+		weight, err := strconv.ParseUint(content, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q: %v", cpuWeightFile, content, err)
+		}
+		return convertCPUWeightToShares(weight), nil
+	}
+
+	content, err := readFile(filepath.Join(cgroupRoot, cpuSubsystem, cgroupPath), cpuSharesFile)
+	if err != nil {
+		return 0, err
+	}
+	shares, err := strconv.ParseUint(content, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", cpuSharesFile, content, err)
+	}
+	return shares, nil
+}
+
+// SetCPUShares sets the cpu shares of the cgroup, the shares are converted to cpu.weight for cgroup v2.
+func SetCPUShares(cgroupRoot, cgroupPath string, shares uint64) error {
+	if IsUnified(cgroupRoot) {
+		return writeFile(filepath.Join(cgroupRoot, cgroupPath), cpuWeightFile, strconv.FormatUint(convertCPUSharesToWeight(shares), 10))
+	}
+	return writeFile(filepath.Join(cgroupRoot, cpuSubsystem, cgroupPath), cpuSharesFile, strconv.FormatUint(shares, 10))
+}
+
+// parseCPUMax parses cpu.max, such as "max 100000" or "50000 100000"
+func parseCPUMax(content string) (quota int64, period uint64, err error) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, 0, fmt.Errorf("invalid %s %q", cpuMaxFile, content)
+	}
+	quota = -1
+	if fields[0] != unlimited {
+		if quota, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid %s %q: %v", cpuMaxFile, content, err)
+		}
+	}
+	period = defaultCPUPeriod
+	if len(fields) == 2 {
+		if period, err = strconv.ParseUint(fields[1], 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid %s %q: %v", cpuMaxFile, content, err)
+		}
+	}
+	return quota, period, nil
+}
+
+// convertCPUSharesToWeight converts the shares in [2, 262144] to the weight in [1, 10000], same as runc
+func convertCPUSharesToWeight(shares uint64) uint64 {
+	if shares == 0 {
+		return 0
+	}
+	if shares < 2 {
+		shares = 2
+	}
+	return 1 + ((shares-2)*9999)/262142
+}
+
+// convertCPUWeightToShares converts the weight in [1, 10000] to the shares in [2, 262144], same as cadvisor
+func convertCPUWeightToShares(weight uint64) uint64 {
+	if weight < 1 {
+		weight = 1
+	}
+	return 2 + ((weight-1)*262142)/9999
+}
+
+func readFile(dir, file string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func writeFile(dir, file, content string) error {
+	return os.WriteFile(filepath.Join(dir, file), []byte(content), 0644)
+}
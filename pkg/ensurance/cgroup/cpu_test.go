@@ -0,0 +1,80 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCPUQuotaV1(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, cpuSubsystem, "kubepods", "burstable", "pod1", "container1")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, cpuQuotaFile), []byte("-1\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, cpuPeriodFile), []byte("100000\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, cpuSharesFile), []byte("1024\n"), 0644))
+
+	quota, period, err := GetCPUQuota(root, "/kubepods/burstable/pod1/container1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), quota)
+	assert.Equal(t, uint64(100000), period)
+
+	assert.NoError(t, SetCPUQuota(root, "/kubepods/burstable/pod1/container1", 50000, 0))
+	quota, period, err = GetCPUQuota(root, "/kubepods/burstable/pod1/container1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50000), quota)
+	assert.Equal(t, uint64(100000), period)
+
+	shares, err := GetCPUShares(root, "/kubepods/burstable/pod1/container1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1024), shares)
+
+	assert.NoError(t, SetCPUShares(root, "/kubepods/burstable/pod1/container1", 2))
+	content, _ := os.ReadFile(filepath.Join(dir, cpuSharesFile))
+	assert.Equal(t, "2", string(content))
+}
+
+func TestCPUQuotaV2(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(root, unifiedControllersFile), []byte("cpuset cpu io memory pids"), 0644))
+	path := "/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1.slice/cri-containerd-abc.scope"
+	dir := filepath.Join(root, path)
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, cpuMaxFile), []byte("max 100000\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, cpuWeightFile), []byte("39\n"), 0644))
+
+	quota, period, err := GetCPUQuota(root, path)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), quota)
+	assert.Equal(t, uint64(100000), period)
+
+	assert.NoError(t, SetCPUQuota(root, path, 50000, 0))
+	content, _ := os.ReadFile(filepath.Join(dir, cpuMaxFile))
+	assert.Equal(t, "50000", string(content))
+	quota, period, err = GetCPUQuota(root, path)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50000), quota)
+	assert.Equal(t, uint64(defaultCPUPeriod), period)
+
+	assert.NoError(t, SetCPUQuota(root, path, -1, 100000))
+	content, _ = os.ReadFile(filepath.Join(dir, cpuMaxFile))
+	assert.Equal(t, "max 100000", string(content))
+
+	// The conversion between shares and weight is lossy
+	shares, err := GetCPUShares(root, path)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(998), shares)
+
+	assert.NoError(t, SetCPUShares(root, path, 1024))
+	content, _ = os.ReadFile(filepath.Join(dir, cpuWeightFile))
+	assert.Equal(t, "39", string(content))
+}
+
+func TestParseCPUMax(t *testing.T) {
+	for _, content := range []string{"", "abc 100000", "50000 abc", "50000 100000 1"} {
+		_, _, err := parseCPUMax(content)
+		assert.Error(t, err, content)
+	}
+}
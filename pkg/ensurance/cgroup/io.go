@@ -0,0 +1,238 @@
+package cgroup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	blkioSubsystem = "blkio"
+
+	blkioServiceBytesFile = "blkio.throttle.io_service_bytes"
+	blkioReadBpsFile      = "blkio.throttle.read_bps_device"
+	blkioWriteBpsFile     = "blkio.throttle.write_bps_device"
+
+	ioStatFile = "io.stat"
+	ioMaxFile  = "io.max"
+)
+
+// DeviceIOLimit is the io bandwidth limit of a block device in a cgroup, zero means unlimited. ReadBytes and WriteBytes
+// are the bytes read and written on the device by the cgroup so far, which are ignored when setting the limit.
+type DeviceIOLimit struct {
+	Major      uint64
+	Minor      uint64
+	ReadBps    uint64
+	WriteBps   uint64
+	ReadBytes  uint64
+	WriteBytes uint64
+}
+
+func (d DeviceIOLimit) Device() string {
+	return fmt.Sprintf("%d:%d", d.Major, d.Minor)
+}
+
+// GetIOLimits returns the io limits of the block devices which the cgroup has accessed, cgroupPath is relative to the
+// cgroup root, such as /kubepods/besteffort/pod<uid>
+func GetIOLimits(cgroupRoot, cgroupPath string) ([]DeviceIOLimit, error) {
+	if IsUnified(cgroupRoot) {
+		dir := filepath.Join(cgroupRoot, cgroupPath)
+		stat, err := os.ReadFile(filepath.Join(dir, ioStatFile))
+		if err != nil {
+			return nil, err
+		}
+		max, err := os.ReadFile(filepath.Join(dir, ioMaxFile))
+		if err != nil {
+			return nil, err
+		}
+		return parseIOMax(string(stat), string(max))
+	}
+
+	dir := filepath.Join(cgroupRoot, blkioSubsystem, cgroupPath)
+	serviceBytes, err := os.ReadFile(filepath.Join(dir, blkioServiceBytesFile))
+	if err != nil {
+		return nil, err
+	}
+	readBps, err := os.ReadFile(filepath.Join(dir, blkioReadBpsFile))
+	if err != nil {
+		return nil, err
+	}
+	writeBps, err := os.ReadFile(filepath.Join(dir, blkioWriteBpsFile))
+	if err != nil {
+		return nil, err
+	}
+	return parseBlkioThrottle(string(serviceBytes), string(readBps), string(writeBps))
+}
+
+// SetIOLimit sets the io limit of the block device in the cgroup, zero removes the limit
+func SetIOLimit(cgroupRoot, cgroupPath string, limit DeviceIOLimit) error {
+	if IsUnified(cgroupRoot) {
+		return os.WriteFile(filepath.Join(cgroupRoot, cgroupPath, ioMaxFile), []byte(formatIOMax(limit)), 0644)
+	}
+
+	dir := filepath.Join(cgroupRoot, blkioSubsystem, cgroupPath)
+	if err := os.WriteFile(filepath.Join(dir, blkioReadBpsFile), []byte(fmt.Sprintf("%s %d", limit.Device(), limit.ReadBps)), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, blkioWriteBpsFile), []byte(fmt.Sprintf("%s %d", limit.Device(), limit.WriteBps)), 0644)
+}
+
+// parseBlkioThrottle parses the devices and their bytes from blkio.throttle.io_service_bytes, whose lines are like
+// "8:0 Read 4096", and their limits from blkio.throttle.read_bps_device and blkio.throttle.write_bps_device, whose lines
+// are like "8:0 1048576"
+func parseBlkioThrottle(serviceBytes, readBps, writeBps string) ([]DeviceIOLimit, error) {
+	limits := map[string]*DeviceIOLimit{}
+	for _, line := range strings.Split(serviceBytes, "\n") {
+		fields := strings.Fields(line)
+		// skip the Total line
+		if len(fields) != 3 {
+			continue
+		}
+		d, err := getOrAddDevice(limits, fields[0])
+		if err != nil {
+			return nil, err
+		}
+		v, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bytes %q of device %s: %v", fields[2], fields[0], err)
+		}
+		switch fields[1] {
+		case "Read":
+			d.ReadBytes = v
+		case "Write":
+			d.WriteBytes = v
+		}
+	}
+
+	for _, f := range []struct {
+		content string
+		set     func(d *DeviceIOLimit, v uint64)
+	}{
+		{readBps, func(d *DeviceIOLimit, v uint64) { d.ReadBps = v }},
+		{writeBps, func(d *DeviceIOLimit, v uint64) { d.WriteBps = v }},
+	} {
+		for _, line := range strings.Split(f.content, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				continue
+			}
+			d, err := getOrAddDevice(limits, fields[0])
+			if err != nil {
+				return nil, err
+			}
+			v, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid limit %q of device %s: %v", fields[1], fields[0], err)
+			}
+			f.set(d, v)
+		}
+	}
+	return sortedLimits(limits), nil
+}
+
+// parseIOMax parses the devices and their bytes from io.stat, whose lines are like "8:0 rbytes=4096 wbytes=0 ...", and their limits
+// from io.max, whose lines are like "8:0 rbps=1048576 wbps=max riops=max wiops=max"
+func parseIOMax(stat, max string) ([]DeviceIOLimit, error) {
+	limits := map[string]*DeviceIOLimit{}
+	for _, line := range strings.Split(stat, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		d, err := getOrAddDevice(limits, fields[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 || (kv[0] != "rbytes" && kv[0] != "wbytes") {
+				continue
+			}
+			v, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid stat %q of device %s: %v", field, fields[0], err)
+			}
+			if kv[0] == "rbytes" {
+				d.ReadBytes = v
+			} else {
+				d.WriteBytes = v
+			}
+		}
+	}
+
+	for _, line := range strings.Split(max, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		d, err := getOrAddDevice(limits, fields[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 || kv[1] == "max" {
+				continue
+			}
+			v, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid limit %q of device %s: %v", field, fields[0], err)
+			}
+			switch kv[0] {
+			case "rbps":
+				d.ReadBps = v
+			case "wbps":
+				d.WriteBps = v
+			}
+		}
+	}
+	return sortedLimits(limits), nil
+}
+
+func formatIOMax(limit DeviceIOLimit) string {
+	format := func(v uint64) string {
+		if v == 0 {
+			return "max"
+		}
+		return strconv.FormatUint(v, 10)
+	}
+	return fmt.Sprintf("%s rbps=%s wbps=%s", limit.Device(), format(limit.ReadBps), format(limit.WriteBps))
+}
+
+func getOrAddDevice(limits map[string]*DeviceIOLimit, device string) (*DeviceIOLimit, error) {
+	if d, ok := limits[device]; ok {
+		return d, nil
+	}
+	numbers := strings.SplitN(device, ":", 2)
+	if len(numbers) != 2 {
+		return nil, fmt.Errorf("invalid device %q", device)
+	}
+	major, err := strconv.ParseUint(numbers[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid device %q: %v", device, err)
+	}
+	minor, err := strconv.ParseUint(numbers[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid device %q: %v", device, err)
+	}
+	d := &DeviceIOLimit{Major: major, Minor: minor}
+	limits[device] = d
+	return d, nil
+}
+
+func sortedLimits(limits map[string]*DeviceIOLimit) []DeviceIOLimit {
+	result := make([]DeviceIOLimit, 0, len(limits))
+	for _, d := range limits {
+		result = append(result, *d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Major != result[j].Major {
+			return result[i].Major < result[j].Major
+		}
+		return result[i].Minor < result[j].Minor
+	})
+	return result
+}
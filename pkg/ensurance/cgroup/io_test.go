@@ -0,0 +1,56 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIOLimitsV1(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, blkioSubsystem, "kubepods", "besteffort", "pod1")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, blkioServiceBytesFile), []byte("8:16 Read 4096\n8:16 Write 8192\n8:0 Read 0\n8:0 Write 1024\nTotal 13312\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, blkioReadBpsFile), []byte(""), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, blkioWriteBpsFile), []byte("8:16 1048576\n"), 0644))
+
+	limits, err := GetIOLimits(root, "/kubepods/besteffort/pod1")
+	assert.NoError(t, err)
+	assert.Equal(t, []DeviceIOLimit{
+		{Major: 8, Minor: 0, WriteBytes: 1024},
+		{Major: 8, Minor: 16, WriteBps: 1048576, ReadBytes: 4096, WriteBytes: 8192},
+	}, limits)
+
+	assert.NoError(t, SetIOLimit(root, "/kubepods/besteffort/pod1", DeviceIOLimit{Major: 8, Minor: 0, ReadBps: 2048}))
+	readBps, _ := os.ReadFile(filepath.Join(dir, blkioReadBpsFile))
+	assert.Equal(t, "8:0 2048", string(readBps))
+	writeBps, _ := os.ReadFile(filepath.Join(dir, blkioWriteBpsFile))
+	assert.Equal(t, "8:0 0", string(writeBps))
+}
+
+func TestIOLimitsV2(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(root, unifiedControllersFile), []byte("cpuset cpu io memory pids"), 0644))
+	dir := filepath.Join(root, "kubepods.slice", "kubepods-pod1.slice")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ioStatFile), []byte("259:0 rbytes=4096 wbytes=8192 rios=1 wios=2 dbytes=0 dios=0\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ioMaxFile), []byte("259:0 rbps=max wbps=1048576 riops=max wiops=max\n"), 0644))
+
+	limits, err := GetIOLimits(root, "/kubepods.slice/kubepods-pod1.slice")
+	assert.NoError(t, err)
+	assert.Equal(t, []DeviceIOLimit{{Major: 259, Minor: 0, WriteBps: 1048576, ReadBytes: 4096, WriteBytes: 8192}}, limits)
+
+	assert.NoError(t, SetIOLimit(root, "/kubepods.slice/kubepods-pod1.slice", DeviceIOLimit{Major: 259, Minor: 0, ReadBps: 2048}))
+	max, _ := os.ReadFile(filepath.Join(dir, ioMaxFile))
+	assert.Equal(t, "259:0 rbps=2048 wbps=max", string(max))
+}
+
+func TestParseInvalidDevice(t *testing.T) {
+	_, err := parseBlkioThrottle("sda Read 4096\n", "", "")
+	assert.Error(t, err)
+
+	_, err = parseIOMax("8:0 rbytes=1\n", "8:0 rbps=abc\n")
+	assert.Error(t, err)
+}
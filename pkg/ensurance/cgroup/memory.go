@@ -0,0 +1,97 @@
+package cgroup
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	memorySubsystem = "memory"
+	memoryLimitFile = "memory.limit_in_bytes"
+
+	memoryMaxFile  = "memory.max"
+	memoryHighFile = "memory.high"
+
+	unlimited = "max"
+
+	pageSize = 4096
+)
+
+// GetMemoryHigh returns the memory.high of the cgroup in bytes, zero means unlimited. memory.high is only supported by
+// cgroup v2.
+func GetMemoryHigh(cgroupRoot, cgroupPath string) (uint64, error) {
+	if !IsUnified(cgroupRoot) {
+		return 0, fmt.Errorf("memory.high is not supported by cgroup v1")
+	}
+	content, err := os.ReadFile(filepath.Join(cgroupRoot, cgroupPath, memoryHighFile))
+	if err != nil {
+		return 0, err
+	}
+	value := strings.TrimSpace(string(content))
+	if value == unlimited {
+		return 0, nil
+	}
+	high, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory.high %q: %v", value, err)
+	}
+	return high, nil
+}
+
+// SetMemoryHigh sets the memory.high of the cgroup in bytes, zero removes the limit. The processes of the cgroup are
+// throttled and forced to reclaim above memory.high, but they are never killed by oom.
+func SetMemoryHigh(cgroupRoot, cgroupPath string, high uint64) error {
+	if !IsUnified(cgroupRoot) {
+		return fmt.Errorf("memory.high is not supported by cgroup v1")
+	}
+	value := unlimited
+	if high > 0 {
+		value = strconv.FormatUint(high, 10)
+	}
+	return os.WriteFile(filepath.Join(cgroupRoot, cgroupPath, memoryHighFile), []byte(value), 0644)
+}
+
+// GetMemoryLimit returns the memory limit of the cgroup in bytes, zero means unlimited. It reads memory.limit_in_bytes
+// for cgroup v1, and memory.max for cgroup v2.
+func GetMemoryLimit(cgroupRoot, cgroupPath string) (uint64, error) {
+	dir, file := filepath.Join(cgroupRoot, memorySubsystem, cgroupPath), memoryLimitFile
+	if IsUnified(cgroupRoot) {
+		dir, file = filepath.Join(cgroupRoot, cgroupPath), memoryMaxFile
+	}
+	value, err := readFile(dir, file)
+	if err != nil {
+		return 0, err
+	}
+	if value == unlimited {
+		return 0, nil
+	}
+	limit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", file, value, err)
+	}
+	// The cgroup v1 unlimited value is the max int64 rounded down to the page size
+	if limit >= math.MaxInt64/pageSize*pageSize {
+		return 0, nil
+	}
+	return limit, nil
+}
+
+// SetMemoryLimit sets the memory limit of the cgroup in bytes, zero removes the limit.
+func SetMemoryLimit(cgroupRoot, cgroupPath string, limit uint64) error {
+	if IsUnified(cgroupRoot) {
+		value := unlimited
+		if limit > 0 {
+			value = strconv.FormatUint(limit, 10)
+		}
+		return writeFile(filepath.Join(cgroupRoot, cgroupPath), memoryMaxFile, value)
+	}
+	value := "-1"
+	if limit > 0 {
+		value = strconv.FormatUint(limit, 10)
+	}
+	return writeFile(filepath.Join(cgroupRoot, memorySubsystem, cgroupPath), memoryLimitFile, value)
+}
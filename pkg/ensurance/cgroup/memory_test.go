@@ -0,0 +1,63 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryHigh(t *testing.T) {
+	root := t.TempDir()
+	_, err := GetMemoryHigh(root, "/kubepods/pod1")
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(root, unifiedControllersFile), []byte("cpu io memory"), 0644))
+	dir := filepath.Join(root, "kubepods", "pod1")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, memoryHighFile), []byte("max\n"), 0644))
+
+	high, err := GetMemoryHigh(root, "/kubepods/pod1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), high)
+
+	assert.NoError(t, SetMemoryHigh(root, "/kubepods/pod1", 1<<30))
+	high, err = GetMemoryHigh(root, "/kubepods/pod1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1<<30), high)
+
+	assert.NoError(t, SetMemoryHigh(root, "/kubepods/pod1", 0))
+	content, _ := os.ReadFile(filepath.Join(dir, memoryHighFile))
+	assert.Equal(t, "max", string(content))
+}
+
+func TestMemoryLimit(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, memorySubsystem, "kubepods", "pod1")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, memoryLimitFile), []byte("9223372036854771712\n"), 0644))
+
+	limit, err := GetMemoryLimit(root, "/kubepods/pod1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), limit)
+
+	assert.NoError(t, SetMemoryLimit(root, "/kubepods/pod1", 1<<30))
+	limit, err = GetMemoryLimit(root, "/kubepods/pod1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1<<30), limit)
+
+	root = t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(root, unifiedControllersFile), []byte("cpu io memory"), 0644))
+	dir = filepath.Join(root, "kubepods.slice", "kubepods-pod1.slice")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, memoryMaxFile), []byte("1073741824\n"), 0644))
+
+	limit, err = GetMemoryLimit(root, "/kubepods.slice/kubepods-pod1.slice")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1<<30), limit)
+
+	assert.NoError(t, SetMemoryLimit(root, "/kubepods.slice/kubepods-pod1.slice", 0))
+	content, _ := os.ReadFile(filepath.Join(dir, memoryMaxFile))
+	assert.Equal(t, "max", string(content))
+}
@@ -0,0 +1,84 @@
+package cgroup
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const (
+	cpuSubsystem = "cpu"
+
+	procsFile = "cgroup.procs"
+)
+
+// errFound stops walking the cgroup tree once a process is found
+var errFound = fmt.Errorf("found")
+
+// GetFirstPid returns a process in the cgroup or its descendants, such as a container process of a pod, which can be used
+// to enter the namespaces of the pod
+func GetFirstPid(cgroupRoot, cgroupPath string) (int, error) {
+	dir := filepath.Join(cgroupRoot, cgroupPath)
+	if !IsUnified(cgroupRoot) {
+		dir = filepath.Join(cgroupRoot, cpuSubsystem, cgroupPath)
+	}
+
+	var pid int
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != procsFile {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			if pid, err = strconv.Atoi(line); err != nil {
+				return fmt.Errorf("invalid pid %q in %s: %v", line, path, err)
+			}
+			return errFound
+		}
+		return nil
+	})
+	if err == errFound {
+		return pid, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no process in cgroup %s", cgroupPath)
+}
+
+// GetContainerCgroupPath returns the cgroup path of a container under the pod cgroup, the container cgroup is named by
+// the container id with the cgroupfs driver, or a scope such as cri-containerd-<id>.scope with the systemd driver.
+func GetContainerCgroupPath(cgroupRoot, podCgroupPath, containerId string) (string, error) {
+	dir := filepath.Join(cgroupRoot, podCgroupPath)
+	if !IsUnified(cgroupRoot) {
+		dir = filepath.Join(cgroupRoot, cpuSubsystem, podCgroupPath)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if utils.GetContainerIdFromKey(entry.Name()) == containerId {
+			return filepath.Join(podCgroupPath, entry.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("no cgroup of container %s in %s", containerId, podCgroupPath)
+}
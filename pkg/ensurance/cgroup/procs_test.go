@@ -0,0 +1,47 @@
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFirstPid(t *testing.T) {
+	root := t.TempDir()
+	pod := filepath.Join(root, cpuSubsystem, "kubepods", "pod1")
+	assert.NoError(t, os.MkdirAll(filepath.Join(pod, "container1"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(pod, procsFile), []byte(""), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(pod, "container1", procsFile), []byte("1234\n1235\n"), 0644))
+
+	pid, err := GetFirstPid(root, "/kubepods/pod1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1234, pid)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(pod, "container1", procsFile), []byte(""), 0644))
+	_, err = GetFirstPid(root, "/kubepods/pod1")
+	assert.Error(t, err)
+}
+
+func TestGetContainerCgroupPath(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, cpuSubsystem, "kubepods", "pod1", "abc"), 0755))
+
+	path, err := GetContainerCgroupPath(root, "/kubepods/pod1", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "/kubepods/pod1/abc", path)
+
+	root = t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(root, unifiedControllersFile), []byte("cpu io memory"), 0644))
+	pod := "/kubepods.slice/kubepods-pod1.slice"
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, pod, "cri-containerd-abc.scope"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, pod, "cri-containerd-abcd.scope"), 0755))
+
+	path, err = GetContainerCgroupPath(root, pod, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, pod+"/cri-containerd-abc.scope", path)
+
+	_, err = GetContainerCgroupPath(root, pod, "def")
+	assert.Error(t, err)
+}
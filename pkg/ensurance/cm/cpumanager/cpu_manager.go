@@ -31,6 +31,7 @@ import (
 
 	"github.com/gocrane/crane/pkg/ensurance/collector/cadvisor"
 	"github.com/gocrane/crane/pkg/ensurance/manager"
+	"github.com/gocrane/crane/pkg/known"
 	"github.com/gocrane/crane/pkg/utils"
 )
 
@@ -50,18 +51,33 @@ var DefaultExclusiveCPUSet = func() cpuset.CPUSet {
 	return cpuset.NewCPUSet()
 }
 
+var DefaultCPULayout = func() map[string]string {
+	return nil
+}
+
 type CPUManager interface {
 	manager.Manager
 
 	GetExclusiveCPUSet() cpuset.CPUSet
 
 	GetSharedCPUs() cpuset.CPUSet
+
+	// GetCPULayout returns the layout of CPUs to publish in the attributes of NodeResourceTopology.
+	GetCPULayout() map[string]string
+
+	// GetOfflineCPUs returns the CPUs of the offline pods, it's empty if the offline cpuset is not rebalanced.
+	GetOfflineCPUs() cpuset.CPUSet
+
+	// ResizeOfflineCPUs resizes the CPUs of the offline pods and returns them, once it's called the shared CPUs are
+	// split into the online and offline ones.
+	ResizeOfflineCPUs(size int) cpuset.CPUSet
 }
 
 type cpuManager struct {
 	sync.Mutex
 	nodeName  string
 	policy    Policy
+	topology  *topology.CPUTopology
 	workqueue workqueue.RateLimitingInterface
 	podLister corelisters.PodLister
 	nrtLister topologylisters.NodeResourceTopologyLister
@@ -92,11 +108,18 @@ type cpuManager struct {
 	// containerMap provides a mapping from (pod, container) -> containerID
 	// for all containers whose cpuset is updated in reconcileState.
 	containerMap containermap.ContainerMap
+
+	// offlineCPUs are the shared CPUs for the offline pods, which are resized by the CPUSetRebalancer.
+	offlineCPUs cpuset.CPUSet
+	// rebalanceOffline indicates whether the offline pods are bound to the offline CPUs.
+	rebalanceOffline bool
 }
 
 func NewCPUManager(
 	nodeName string,
+	policyName string,
 	defaultCPUPolicy string,
+	bestEffortDomains int,
 	reconcilePeriod time.Duration,
 	cadvisorManager cadvisor.Manager,
 	containerRuntime criapis.RuntimeService,
@@ -121,6 +144,7 @@ func NewCPUManager(
 
 	cm := &cpuManager{
 		nodeName:         nodeName,
+		topology:         topo,
 		workqueue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "cpumanager"),
 		podLister:        podInformer.Lister(),
 		nrtLister:        nrtInformer.Lister(),
@@ -183,7 +207,16 @@ func NewCPUManager(
 		return activePods, nil
 	}
 
-	cm.policy = NewStaticPolicy(topo, getPodFunc)
+	switch policyName {
+	case PolicyNameStatic:
+		cm.policy = NewStaticPolicy(topo, getPodFunc)
+	case PolicyNameInterferenceAware:
+		llcDomains := DiscoverLLCDomains(machineInfo)
+		klog.InfoS("Detected LLC domains", "domains", llcDomains)
+		cm.policy = NewInterferenceAwarePolicy(topo, llcDomains, bestEffortDomains, getPodFunc)
+	default:
+		return nil, fmt.Errorf("unknown cpu manager policy %s", policyName)
+	}
 
 	cm.state, err = cpumanagerstate.NewCheckpointState(
 		stateFileDirectory,
@@ -268,6 +301,60 @@ func (cm *cpuManager) GetSharedCPUs() cpuset.CPUSet {
 	return cm.policy.GetSharedCPUs(cm.state)
 }
 
+func (cm *cpuManager) GetCPULayout() map[string]string {
+	layout := cm.policy.GetCPULayout(cm.state)
+	if offlineCPUs := cm.GetOfflineCPUs(); !offlineCPUs.IsEmpty() {
+		if layout == nil {
+			layout = make(map[string]string)
+		}
+		layout[known.BestEffortCPUsAttribute] = offlineCPUs.String()
+	}
+	return layout
+}
+
+func (cm *cpuManager) GetOfflineCPUs() cpuset.CPUSet {
+	cm.Lock()
+	defer cm.Unlock()
+	return cm.getOfflineCPUs()
+}
+
+func (cm *cpuManager) ResizeOfflineCPUs(size int) cpuset.CPUSet {
+	cm.Lock()
+	defer cm.Unlock()
+	cm.offlineCPUs = takeOfflineCPUs(cm.topology, cm.policy.GetSharedCPUs(cm.state), size)
+	cm.rebalanceOffline = true
+	return cm.offlineCPUs
+}
+
+// getOfflineCPUs returns the offline CPUs which are still shared, some of them may be allocated exclusively since
+// they were resized.
+func (cm *cpuManager) getOfflineCPUs() cpuset.CPUSet {
+	if !cm.rebalanceOffline {
+		return cpuset.NewCPUSet()
+	}
+	return cm.offlineCPUs.Intersection(cm.policy.GetSharedCPUs(cm.state))
+}
+
+// getContainerCPUSet returns the CPUs which the container is bound to, the shared containers of the offline pods are
+// bound to the offline CPUs, and the others are bound to the rest if the offline cpuset is rebalanced.
+func (cm *cpuManager) getContainerCPUSet(pod *corev1.Pod, containerName string) cpuset.CPUSet {
+	cset := cm.policy.GetContainerCPUSet(cm.state, pod, containerName)
+	if _, ok := cm.state.GetCPUSet(string(pod.UID), containerName); ok {
+		return cset
+	}
+	offlineCPUs := cm.getOfflineCPUs()
+	if offlineCPUs.IsEmpty() {
+		return cset
+	}
+	if isBestEffortPod(pod) {
+		return offlineCPUs
+	}
+	if online := cset.Difference(offlineCPUs); !online.IsEmpty() {
+		return online
+	}
+	return cset
+}
+
 func (cm *cpuManager) updateContainerCPUSet(containerID string, cpus cpuset.CPUSet) error {
 	return cm.containerRuntime.UpdateContainerResources(
 		containerID,
@@ -475,7 +562,7 @@ func (cm *cpuManager) reconcileState() (success []reconciledContainer, failure [
 
 			excludeReservedCPUs := utils.PodExcludeReservedCPUs(pod)
 
-			cset := cm.state.GetCPUSetOrDefault(string(pod.UID), container.Name)
+			cset := cm.getContainerCPUSet(pod, container.Name)
 			if excludeReservedCPUs {
 				cset = cset.Difference(cm.policy.GetReservedCPUSet())
 			}
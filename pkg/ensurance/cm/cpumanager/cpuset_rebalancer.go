@@ -0,0 +1,184 @@
+package cpumanager
+
+import (
+	"math"
+	"sort"
+
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/topology"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/metrics"
+)
+
+const (
+	cpuSetRebalancerName = "CPUSetRebalancer"
+
+	CPUSetOnline    = "online"
+	CPUSetOffline   = "offline"
+	CPUSetExclusive = "exclusive"
+
+	RebalanceShrink = "shrink"
+	RebalanceGrow   = "grow"
+)
+
+// CPUSetRebalancer resizes the cpuset of the offline pods by the usage of the online pods in the shared CPUs. The
+// cores are pulled away from the offline pods at once when the online usage climbs, and given back step by step when
+// it falls.
+type CPUSetRebalancer struct {
+	cpuManager CPUManager
+	podLister  corelisters.PodLister
+	stateChann chan map[string][]common.TimeSeries
+
+	// onlineWatermark is the max utilization of the online CPUs by the online pods, in (0, 1].
+	onlineWatermark float64
+	// minOfflineCPUs is the min number of the offline CPUs, so that the offline pods are not starved.
+	minOfflineCPUs int
+	// rebalanced is whether the offline cpuset has been resized, the offline pods share all the shared CPUs before.
+	rebalanced bool
+}
+
+func NewCPUSetRebalancer(cpuManager CPUManager, podLister corelisters.PodLister, stateChann chan map[string][]common.TimeSeries,
+	onlineWatermark float64, minOfflineCPUs int) *CPUSetRebalancer {
+	return &CPUSetRebalancer{
+		cpuManager:      cpuManager,
+		podLister:       podLister,
+		stateChann:      stateChann,
+		onlineWatermark: onlineWatermark,
+		minOfflineCPUs:  minOfflineCPUs,
+	}
+}
+
+func (r *CPUSetRebalancer) Name() string {
+	return cpuSetRebalancerName
+}
+
+func (r *CPUSetRebalancer) Run(stop <-chan struct{}) {
+	klog.Infof("Starting cpuset rebalancer.")
+
+	go func() {
+		for {
+			select {
+			case state := <-r.stateChann:
+				r.rebalance(state)
+			case <-stop:
+				klog.Infof("CPUSet rebalancer exit")
+				return
+			}
+		}
+	}()
+}
+
+func (r *CPUSetRebalancer) rebalance(state map[string][]common.TimeSeries) {
+	exclusiveCPUs := r.cpuManager.GetExclusiveCPUSet()
+	onlineUsage, ok := r.getOnlineUsage(state, exclusiveCPUs.Size())
+	if !ok {
+		return
+	}
+
+	sharedCPUs := r.cpuManager.GetSharedCPUs()
+	current := sharedCPUs
+	if r.rebalanced {
+		current = r.cpuManager.GetOfflineCPUs()
+	}
+	size := targetOfflineCPUs(sharedCPUs.Size(), current.Size(), onlineUsage, r.onlineWatermark, r.minOfflineCPUs)
+	offline := r.cpuManager.ResizeOfflineCPUs(size)
+	r.rebalanced = true
+
+	if offline.Size() < current.Size() {
+		klog.InfoS("Shrink the offline cpuset", "onlineUsage", onlineUsage, "from", current, "to", offline)
+		metrics.CPUSetRebalanceCountsInc(RebalanceShrink)
+	} else if offline.Size() > current.Size() {
+		klog.InfoS("Grow the offline cpuset", "onlineUsage", onlineUsage, "from", current, "to", offline)
+		metrics.CPUSetRebalanceCountsInc(RebalanceGrow)
+	}
+	metrics.UpdateCPUSetCPUs(CPUSetOnline, sharedCPUs.Size()-offline.Size())
+	metrics.UpdateCPUSetCPUs(CPUSetOffline, offline.Size())
+	metrics.UpdateCPUSetCPUs(CPUSetExclusive, exclusiveCPUs.Size())
+}
+
+// getOnlineUsage returns the cpu usage in cores of the online pods and the system in the shared CPUs, which is the
+// node usage minus the busy exclusive CPUs and the usage of the offline pods.
+func (r *CPUSetRebalancer) getOnlineUsage(state map[string][]common.TimeSeries, exclusiveCPUs int) (float64, bool) {
+	nodeCpuUsageTotal, ok := latestValue(state, types.MetricNameCpuTotalUsage)
+	if !ok {
+		klog.V(4).Infof("Can't get %s from the state, skip rebalancing the offline cpuset", types.MetricNameCpuTotalUsage)
+		return 0, false
+	}
+	exclusiveCPUIdle, _ := latestValue(state, types.MetricNameExclusiveCPUIdle)
+
+	var offlineUsage float64
+	for _, ts := range state[string(types.MetricNameContainerCpuTotalUsage)] {
+		if len(ts.Samples) == 0 {
+			continue
+		}
+		var namespace, name string
+		for _, label := range ts.Labels {
+			switch label.Name {
+			case common.LabelNamePodNamespace:
+				namespace = label.Value
+			case common.LabelNamePodName:
+				name = label.Value
+			}
+		}
+		pod, err := r.podLister.Pods(namespace).Get(name)
+		if err != nil || !isBestEffortPod(pod) {
+			continue
+		}
+		offlineUsage += ts.Samples[0].Value
+	}
+
+	// the node usage and the exclusive idle are in milli cores
+	onlineUsage := (nodeCpuUsageTotal+exclusiveCPUIdle)/1000 - float64(exclusiveCPUs) - offlineUsage
+	return math.Max(onlineUsage, 0), true
+}
+
+func latestValue(state map[string][]common.TimeSeries, metricName types.MetricName) (float64, bool) {
+	series, ok := state[string(metricName)]
+	if !ok || len(series) == 0 || len(series[0].Samples) == 0 {
+		return 0, false
+	}
+	return series[0].Samples[0].Value, true
+}
+
+// targetOfflineCPUs returns the number of the offline CPUs, so that the utilization of the online CPUs is no more than
+// the watermark. At least one CPU is left online, and the offline CPUs grow by at most half of the gap each time.
+func targetOfflineCPUs(sharedCPUs, offlineCPUs int, onlineUsage, onlineWatermark float64, minOfflineCPUs int) int {
+	target := sharedCPUs - int(math.Ceil(onlineUsage/onlineWatermark))
+	if target < minOfflineCPUs {
+		target = minOfflineCPUs
+	}
+	if target > sharedCPUs-1 {
+		target = sharedCPUs - 1
+	}
+	if target < 0 {
+		target = 0
+	}
+	if gap := target - offlineCPUs; gap > 1 {
+		target = offlineCPUs + int(math.Ceil(float64(gap)/2))
+	}
+	return target
+}
+
+// takeOfflineCPUs takes the CPUs from the last physical core of the shared CPUs, so that the offline pods are packed
+// onto the last cores and don't share the cores with the online pods as far as possible.
+func takeOfflineCPUs(topo *topology.CPUTopology, sharedCPUs cpuset.CPUSet, size int) cpuset.CPUSet {
+	cpus := sharedCPUs.ToSlice()
+	sort.SliceStable(cpus, func(i, j int) bool {
+		coreI, coreJ := topo.CPUDetails[cpus[i]].CoreID, topo.CPUDetails[cpus[j]].CoreID
+		if coreI != coreJ {
+			return coreI > coreJ
+		}
+		return cpus[i] < cpus[j]
+	})
+	if size > len(cpus) {
+		size = len(cpus)
+	}
+	if size < 0 {
+		size = 0
+	}
+	return cpuset.NewCPUSet(cpus[:size]...)
+}
@@ -0,0 +1,107 @@
+package cpumanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	cpumanagerstate "k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/known"
+)
+
+func TestTargetOfflineCPUs(t *testing.T) {
+	// 6 cores are used by the online pods, which need 8 cpus under the watermark
+	assert.Equal(t, 8, targetOfflineCPUs(16, 10, 6, 0.75, 1))
+	// grow by half of the gap
+	assert.Equal(t, 5, targetOfflineCPUs(16, 2, 6, 0.75, 1))
+	assert.Equal(t, 8, targetOfflineCPUs(16, 7, 6, 0.75, 1))
+	// capped by the min offline cpus and one online cpu
+	assert.Equal(t, 2, targetOfflineCPUs(16, 10, 15, 0.75, 2))
+	assert.Equal(t, 15, targetOfflineCPUs(16, 15, 0, 0.75, 1))
+}
+
+func TestTakeOfflineCPUs(t *testing.T) {
+	topo := newTestTopology()
+	assert.Equal(t, "12-15", takeOfflineCPUs(topo, topo.CPUDetails.CPUs(), 4).String())
+	assert.Equal(t, "8,10-11", takeOfflineCPUs(topo, cpuset.MustParse("0-11"), 3).String())
+	assert.Equal(t, "0-3", takeOfflineCPUs(topo, cpuset.MustParse("0-3"), 8).String())
+}
+
+func TestGetOnlineUsage(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.NoError(t, indexer.Add(newTestPod("batch", nil, corev1.PodQOSBestEffort)))
+	assert.NoError(t, indexer.Add(newTestPod("web", nil, corev1.PodQOSBurstable)))
+	r := NewCPUSetRebalancer(nil, corelisters.NewPodLister(indexer), nil, 0.7, 1)
+
+	containerUsage := func(pod string, usage float64) common.TimeSeries {
+		return common.TimeSeries{
+			Labels:  []common.Label{{Name: common.LabelNamePodName, Value: pod}, {Name: common.LabelNamePodNamespace, Value: "default"}},
+			Samples: []common.Sample{{Value: usage}},
+		}
+	}
+	state := map[string][]common.TimeSeries{
+		string(types.MetricNameCpuTotalUsage):          {{Samples: []common.Sample{{Value: 9000}}}},
+		string(types.MetricNameExclusiveCPUIdle):       {{Samples: []common.Sample{{Value: 500}}}},
+		string(types.MetricNameContainerCpuTotalUsage): {containerUsage("batch", 3), containerUsage("web", 2)},
+	}
+	// 9 cores used, 1.5 of the 2 exclusive cpus are busy and 3 cores are used by the offline pod
+	usage, ok := r.getOnlineUsage(state, 2)
+	assert.True(t, ok)
+	assert.InDelta(t, 4.5, usage, 1e-9)
+
+	_, ok = r.getOnlineUsage(map[string][]common.TimeSeries{}, 2)
+	assert.False(t, ok)
+}
+
+func TestGetContainerCPUSetWithOfflineCPUs(t *testing.T) {
+	topo := newTestTopology()
+	s := cpumanagerstate.NewMemoryState()
+	policy := NewStaticPolicy(topo, func(string) ([]*corev1.Pod, error) { return nil, nil })
+	assert.NoError(t, policy.Start(s, NewTopologyResult()))
+	s.SetDefaultCPUSet(cpuset.MustParse("0-13"))
+	s.SetCPUSet("uid-exclusive", "app", cpuset.MustParse("14-15"))
+	cm := &cpuManager{policy: policy, topology: topo, state: s}
+
+	online := newTestPod("web", nil, corev1.PodQOSBurstable)
+	offline := newTestPod("batch", nil, corev1.PodQOSBestEffort)
+	assert.Equal(t, "0-13", cm.getContainerCPUSet(offline, "app").String())
+	assert.Nil(t, cm.GetCPULayout())
+
+	// the exclusive cpus are not offline
+	assert.Equal(t, "10-13", cm.ResizeOfflineCPUs(4).String())
+	assert.Equal(t, "10-13", cm.getContainerCPUSet(offline, "app").String())
+	assert.Equal(t, "0-9", cm.getContainerCPUSet(online, "app").String())
+	assert.Equal(t, map[string]string{known.BestEffortCPUsAttribute: "10-13"}, cm.GetCPULayout())
+
+	// the offline cpus allocated exclusively are not shared any more
+	s.SetDefaultCPUSet(cpuset.MustParse("0-11"))
+	assert.Equal(t, "10-11", cm.GetOfflineCPUs().String())
+}
+
+func TestRebalanceFirstTick(t *testing.T) {
+	topo := newTestTopology()
+	s := cpumanagerstate.NewMemoryState()
+	policy := NewStaticPolicy(topo, func(string) ([]*corev1.Pod, error) { return nil, nil })
+	assert.NoError(t, policy.Start(s, NewTopologyResult()))
+	s.SetDefaultCPUSet(cpuset.MustParse("0-15"))
+	cm := &cpuManager{policy: policy, topology: topo, state: s}
+	r := NewCPUSetRebalancer(cm, nil, nil, 0.75, 1)
+
+	nodeUsage := func(usage float64) map[string][]common.TimeSeries {
+		return map[string][]common.TimeSeries{
+			string(types.MetricNameCpuTotalUsage): {{Samples: []common.Sample{{Value: usage}}}},
+		}
+	}
+	// the offline pods share all the 16 CPUs before, so the first tick shrinks to 8 at once rather than growing from 0
+	r.rebalance(nodeUsage(6000))
+	assert.Equal(t, "8-15", cm.GetOfflineCPUs().String())
+	// and grows by half of the gap after
+	r.rebalance(nodeUsage(0))
+	assert.Equal(t, "4-15", cm.GetOfflineCPUs().String())
+}
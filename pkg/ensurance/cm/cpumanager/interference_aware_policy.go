@@ -0,0 +1,287 @@
+package cpumanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	cadvisorapi "github.com/google/cadvisor/info/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	cpumanagerstate "k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
+	cputopo "k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/topology"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	topologyapi "github.com/gocrane/api/topology/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const (
+	PolicyNameInterferenceAware = "interference-aware"
+)
+
+// interferenceAwarePolicy is the static policy which isolates the latency critical pods from the noisy neighbours on
+// the shared caches. The exclusive CPUs of the latency critical pods are taken from dedicated physical cores which are
+// not shared with any other pod, the SMT siblings beyond the requested CPUs are left idle. The best-effort pods are
+// packed onto the last LLC domains, which are the NUMA nodes if the last level cache is shared by the whole node.
+type interferenceAwarePolicy struct {
+	*staticPolicy
+	// llcDomains are the sets of CPUs sharing a last level cache, sorted by the first CPU.
+	llcDomains []cpuset.CPUSet
+	// llcDomainIndex maps the CPU to the index of its LLC domain.
+	llcDomainIndex map[int]int
+	// bestEffortCPUs are the CPUs of the LLC domains for the best-effort pods.
+	bestEffortCPUs cpuset.CPUSet
+}
+
+// NewInterferenceAwarePolicy builds a new interferenceAwarePolicy, the best-effort pods are packed onto the last
+// bestEffortDomains LLC domains. They share the default cpuset if the node doesn't have more domains than that.
+func NewInterferenceAwarePolicy(topology *cputopo.CPUTopology, llcDomains []cpuset.CPUSet, bestEffortDomains int,
+	getPodFunc ActivePodsByPolicyFunc) Policy {
+	p := &interferenceAwarePolicy{
+		staticPolicy:   NewStaticPolicy(topology, getPodFunc).(*staticPolicy),
+		llcDomains:     llcDomains,
+		llcDomainIndex: make(map[int]int),
+		bestEffortCPUs: cpuset.NewCPUSet(),
+	}
+	for i, domain := range llcDomains {
+		for _, cpu := range domain.ToSlice() {
+			p.llcDomainIndex[cpu] = i
+		}
+		if bestEffortDomains > 0 && len(llcDomains) > bestEffortDomains && i >= len(llcDomains)-bestEffortDomains {
+			p.bestEffortCPUs = p.bestEffortCPUs.Union(domain)
+		}
+	}
+	return p
+}
+
+func (p *interferenceAwarePolicy) Name() string {
+	return PolicyNameInterferenceAware
+}
+
+func (p *interferenceAwarePolicy) Allocate(
+	s cpumanagerstate.State,
+	pod *corev1.Pod,
+	ctr *corev1.Container,
+	mode string,
+	tr TopologyResult,
+) error {
+	if !isLatencyCriticalPod(pod) ||
+		(mode != topologyapi.AnnotationPodCPUPolicyExclusive && mode != topologyapi.AnnotationPodCPUPolicyImmovable) {
+		return p.staticPolicy.Allocate(s, pod, ctr, mode, tr)
+	}
+
+	klog.V(3).InfoS("Interference-aware policy: Allocate", "pod", klog.KObj(pod), "containerName", ctr.Name)
+	if _, ok := s.GetCPUSet(string(pod.UID), ctr.Name); ok {
+		klog.V(4).InfoS("Container already present in state, skipping",
+			"pod", klog.KObj(pod), "containerName", ctr.Name)
+		return nil
+	}
+	// The containers of a pod share the same dedicated cores.
+	if cset := getAssignedCPUsOfSiblings(s, string(pod.UID), ctr.Name); !cset.IsEmpty() {
+		s.SetCPUSet(string(pod.UID), ctr.Name, cset)
+		return nil
+	}
+
+	allocatable, err := p.GetAllocatableCPUs(s)
+	if err != nil {
+		return err
+	}
+	result := cpuset.NewCPUSet()
+	for id, info := range tr {
+		available := allocatable.Intersection(p.topology.CPUDetails.CPUsInNUMANodes(id))
+		numCores := (info.CPUs + p.topology.CPUsPerCore() - 1) / p.topology.CPUsPerCore()
+		cores, err := p.takeDedicatedCores(available, numCores)
+		if err != nil {
+			return err
+		}
+		result = result.Union(cores)
+	}
+	klog.V(3).InfoS("AllocateDedicatedCores", "result", result)
+
+	// The dedicated cores are removed from the shared CPUs for immovable pods as well, otherwise the idle siblings
+	// are used by the shared pods.
+	removeSharedCPUs(s, result)
+	s.SetCPUSet(string(pod.UID), ctr.Name, result)
+	return nil
+}
+
+// takeDedicatedCores takes the physical cores whose CPUs are all available. The cores out of the best-effort domains
+// are preferred, and the cores in the same LLC domain are taken together.
+func (p *interferenceAwarePolicy) takeDedicatedCores(availableCPUs cpuset.CPUSet, numCores int) (cpuset.CPUSet, error) {
+	details := p.topology.CPUDetails
+	var cores []int
+	for _, core := range details.KeepOnly(availableCPUs).Cores().ToSlice() {
+		if details.CPUsInCores(core).IsSubsetOf(availableCPUs) {
+			cores = append(cores, core)
+		}
+	}
+	if len(cores) < numCores {
+		return cpuset.NewCPUSet(), fmt.Errorf("not enough dedicated cores available: requested %d, available %d", numCores, len(cores))
+	}
+
+	sort.SliceStable(cores, func(i, j int) bool {
+		bestEffortI, bestEffortJ := p.bestEffortCPUs.Contains(cores[i]), p.bestEffortCPUs.Contains(cores[j])
+		if bestEffortI != bestEffortJ {
+			return bestEffortJ
+		}
+		return p.llcDomainIndex[cores[i]] < p.llcDomainIndex[cores[j]]
+	})
+	result := cpuset.NewCPUSet()
+	for _, core := range cores[:numCores] {
+		if p.bestEffortCPUs.Contains(core) {
+			klog.InfoS("Dedicated core is taken from the best-effort LLC domains", "core", core)
+		}
+		result = result.Union(details.CPUsInCores(core))
+	}
+	return result, nil
+}
+
+// GetContainerCPUSet returns the requested CPUs of the dedicated cores for the latency critical containers, and the CPUs
+// of the best-effort domains for the best-effort containers.
+func (p *interferenceAwarePolicy) GetContainerCPUSet(s cpumanagerstate.State, pod *corev1.Pod, containerName string) cpuset.CPUSet {
+	if cset, ok := s.GetCPUSet(string(pod.UID), containerName); ok {
+		// The dedicated cores are not in the default set, while the numa-aware CPUs are.
+		if isLatencyCriticalPod(pod) && s.GetDefaultCPUSet().Intersection(cset).IsEmpty() {
+			cpus, _ := p.splitIdleSiblings(cset, requestedCPUs(pod))
+			return cpus
+		}
+		return cset
+	}
+	if isBestEffortPod(pod) {
+		if cset := p.getBestEffortCPUs(s); !cset.IsEmpty() {
+			return cset
+		}
+	}
+	return s.GetDefaultCPUSet()
+}
+
+// GetCPULayout returns the LLC domains, the CPUs of the latency critical containers and their idle siblings, and the
+// CPUs of the best-effort containers.
+func (p *interferenceAwarePolicy) GetCPULayout(s cpumanagerstate.State) map[string]string {
+	latencyCriticalCPUs, idleSiblingCPUs := p.getLatencyCriticalCPUs(s)
+	domains := make([]string, 0, len(p.llcDomains))
+	for _, domain := range p.llcDomains {
+		domains = append(domains, domain.String())
+	}
+	bestEffortCPUs := p.getBestEffortCPUs(s)
+	if bestEffortCPUs.IsEmpty() {
+		bestEffortCPUs = p.GetSharedCPUs(s)
+	}
+	return map[string]string{
+		known.LLCDomainsAttribute:          strings.Join(domains, ";"),
+		known.LatencyCriticalCPUsAttribute: latencyCriticalCPUs.String(),
+		known.IdleSiblingCPUsAttribute:     idleSiblingCPUs.String(),
+		known.BestEffortCPUsAttribute:      bestEffortCPUs.String(),
+	}
+}
+
+// getBestEffortCPUs returns the shared CPUs in the best-effort domains.
+func (p *interferenceAwarePolicy) getBestEffortCPUs(s cpumanagerstate.State) cpuset.CPUSet {
+	return p.GetSharedCPUs(s).Intersection(p.bestEffortCPUs)
+}
+
+// getLatencyCriticalCPUs returns the CPUs bound to the latency critical containers and their idle siblings.
+func (p *interferenceAwarePolicy) getLatencyCriticalCPUs(s cpumanagerstate.State) (cpuset.CPUSet, cpuset.CPUSet) {
+	cpus, idle := cpuset.NewCPUSet(), cpuset.NewCPUSet()
+	for _, mode := range []string{topologyapi.AnnotationPodCPUPolicyExclusive, topologyapi.AnnotationPodCPUPolicyImmovable} {
+		pods, err := p.getPodFunc(mode)
+		if err != nil {
+			klog.ErrorS(err, "Failed to get pods", "cpuPolicy", mode)
+			continue
+		}
+		for _, pod := range pods {
+			if !isLatencyCriticalPod(pod) {
+				continue
+			}
+			for _, container := range pod.Spec.Containers {
+				cset, ok := s.GetCPUSet(string(pod.UID), container.Name)
+				if !ok || !s.GetDefaultCPUSet().Intersection(cset).IsEmpty() {
+					continue
+				}
+				threads, siblings := p.splitIdleSiblings(cset, requestedCPUs(pod))
+				cpus = cpus.Union(threads)
+				idle = idle.Union(siblings)
+			}
+		}
+	}
+	return cpus, idle
+}
+
+// splitIdleSiblings splits the dedicated cores into the requested CPUs, which fill up the cores one after another, and
+// the idle siblings of the last core. All the CPUs are returned if the request is unknown.
+func (p *interferenceAwarePolicy) splitIdleSiblings(cset cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, cpuset.CPUSet) {
+	if numCPUs <= 0 || numCPUs >= cset.Size() {
+		return cset, cpuset.NewCPUSet()
+	}
+	details := p.topology.CPUDetails
+	cpus := cset.ToSlice()
+	sort.SliceStable(cpus, func(i, j int) bool {
+		coreI, coreJ := details[cpus[i]].CoreID, details[cpus[j]].CoreID
+		if coreI != coreJ {
+			return coreI < coreJ
+		}
+		return cpus[i] < cpus[j]
+	})
+	return cpuset.NewCPUSet(cpus[:numCPUs]...), cpuset.NewCPUSet(cpus[numCPUs:]...)
+}
+
+// requestedCPUs returns the number of CPUs assigned to the pod by the scheduler, zero if unknown.
+func requestedCPUs(pod *corev1.Pod) int {
+	tr, err := fromZoneListToTopologyResult(GetPodNUMANodeResult(pod), nil)
+	if err != nil {
+		return 0
+	}
+	return tr.CPUs()
+}
+
+// DiscoverLLCDomains returns the sets of CPUs sharing a last level cache, the NUMA node is taken as the domain if the
+// last level cache is shared by the whole node.
+func DiscoverLLCDomains(machineInfo *cadvisorapi.MachineInfo) []cpuset.CPUSet {
+	domains := make(map[string]cpuset.CPUSet)
+	for _, node := range machineInfo.Topology {
+		for _, core := range node.Cores {
+			key := fmt.Sprintf("node%d", node.Id)
+			if level, id := lastLevelCache(core.UncoreCaches); level > 0 {
+				key = fmt.Sprintf("l%d-%d", level, id)
+			}
+			if _, ok := domains[key]; !ok {
+				domains[key] = cpuset.NewCPUSet()
+			}
+			domains[key] = domains[key].Union(cpuset.NewCPUSet(core.Threads...))
+		}
+	}
+
+	result := make([]cpuset.CPUSet, 0, len(domains))
+	for _, domain := range domains {
+		if !domain.IsEmpty() {
+			result = append(result, domain)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ToSlice()[0] < result[j].ToSlice()[0]
+	})
+	return result
+}
+
+// lastLevelCache returns the level and the id of the cache with the highest level, the level is zero if none.
+func lastLevelCache(caches []cadvisorapi.Cache) (int, int) {
+	var level, id int
+	for _, cache := range caches {
+		if cache.Level > level {
+			level, id = cache.Level, cache.Id
+		}
+	}
+	return level, id
+}
+
+func isLatencyCriticalPod(pod *corev1.Pod) bool {
+	return pod.Annotations[known.PodLatencyCriticalAnnotation] == "true"
+}
+
+// isBestEffortPod returns whether the pod is best-effort or uses the elastic cpu.
+func isBestEffortPod(pod *corev1.Pod) bool {
+	return pod.Status.QOSClass == corev1.PodQOSBestEffort || utils.GetElasticResourceLimit(pod, corev1.ResourceCPU) > 0
+}
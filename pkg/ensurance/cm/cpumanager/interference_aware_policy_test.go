@@ -0,0 +1,121 @@
+package cpumanager
+
+import (
+	"encoding/json"
+	"testing"
+
+	cadvisorapi "github.com/google/cadvisor/info/v1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	cpumanagerstate "k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
+	cputopo "k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/topology"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	topologyapi "github.com/gocrane/api/topology/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/known"
+)
+
+// newTestTopology returns 2 NUMA nodes of 4 cores with 2 threads, the threads of core 2n are 2n and 2n+1
+func newTestTopology() *cputopo.CPUTopology {
+	details := cputopo.CPUDetails{}
+	for cpu := 0; cpu < 16; cpu++ {
+		details[cpu] = cputopo.CPUInfo{NUMANodeID: cpu / 8, SocketID: cpu / 8, CoreID: cpu / 2 * 2}
+	}
+	return &cputopo.CPUTopology{NumCPUs: 16, NumSockets: 2, NumCores: 8, CPUDetails: details}
+}
+
+func newTestPod(name string, annotations map[string]string, qosClass corev1.PodQOSClass) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name), Annotations: annotations},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		Status:     corev1.PodStatus{QOSClass: qosClass},
+	}
+}
+
+// newLatencyCriticalPod returns a latency critical pod with the CPUs assigned in the NUMA node 1 by the scheduler
+func newLatencyCriticalPod(t *testing.T, name string, cpus int64) *corev1.Pod {
+	zones, err := json.Marshal(topologyapi.ZoneList{{
+		Name:      "node1",
+		Type:      topologyapi.ZoneTypeNode,
+		Resources: &topologyapi.ResourceInfo{Capacity: corev1.ResourceList{corev1.ResourceCPU: *resource.NewQuantity(cpus, resource.DecimalSI)}},
+	}})
+	assert.NoError(t, err)
+	return newTestPod(name, map[string]string{
+		known.PodLatencyCriticalAnnotation:         "true",
+		topologyapi.AnnotationPodTopologyResultKey: string(zones),
+	}, corev1.PodQOSGuaranteed)
+}
+
+func TestInterferenceAwarePolicy(t *testing.T) {
+	latencyCritical := newLatencyCriticalPod(t, "web", 3)
+	pods := map[string][]*corev1.Pod{topologyapi.AnnotationPodCPUPolicyExclusive: {latencyCritical}}
+	getPodFunc := func(policy string) ([]*corev1.Pod, error) {
+		return pods[policy], nil
+	}
+	llcDomains := []cpuset.CPUSet{
+		cpuset.MustParse("0-3"), cpuset.MustParse("4-7"), cpuset.MustParse("8-11"), cpuset.MustParse("12-15"),
+	}
+	p := NewInterferenceAwarePolicy(newTestTopology(), llcDomains, 1, getPodFunc)
+	s := cpumanagerstate.NewMemoryState()
+	assert.NoError(t, p.Start(s, NewTopologyResult()))
+
+	// two dedicated cores are taken for 3 cpus out of the best-effort domain 12-15
+	tr := TopologyResult{1: NodeInfo{CPUs: 3}}
+	assert.NoError(t, p.Allocate(s, latencyCritical, &latencyCritical.Spec.Containers[0], topologyapi.AnnotationPodCPUPolicyExclusive, tr))
+	cset, _ := s.GetCPUSet(string(latencyCritical.UID), "app")
+	assert.Equal(t, "8-11", cset.String())
+	assert.Equal(t, "0-7,12-15", s.GetDefaultCPUSet().String())
+	// the sibling of the last core is left idle
+	assert.Equal(t, "8-10", p.GetContainerCPUSet(s, latencyCritical, "app").String())
+
+	bestEffort := newTestPod("batch", nil, corev1.PodQOSBestEffort)
+	assert.Equal(t, "12-15", p.GetContainerCPUSet(s, bestEffort, "app").String())
+	burstable := newTestPod("api", nil, corev1.PodQOSBurstable)
+	assert.Equal(t, "0-7,12-15", p.GetContainerCPUSet(s, burstable, "app").String())
+
+	assert.Equal(t, map[string]string{
+		known.LLCDomainsAttribute:          "0-3;4-7;8-11;12-15",
+		known.LatencyCriticalCPUsAttribute: "8-10",
+		known.IdleSiblingCPUsAttribute:     "11",
+		known.BestEffortCPUsAttribute:      "12-15",
+	}, p.GetCPULayout(s))
+
+	// only two dedicated cores are left in the NUMA node 1
+	another := newLatencyCriticalPod(t, "cache", 5)
+	assert.Error(t, p.Allocate(s, another, &another.Spec.Containers[0], topologyapi.AnnotationPodCPUPolicyExclusive, TopologyResult{1: NodeInfo{CPUs: 5}}))
+	// a whole core is taken for 2 cpus without idle siblings
+	db := newLatencyCriticalPod(t, "db", 2)
+	assert.NoError(t, p.Allocate(s, db, &db.Spec.Containers[0], topologyapi.AnnotationPodCPUPolicyExclusive, TopologyResult{1: NodeInfo{CPUs: 2}}))
+	assert.Equal(t, "12-13", p.GetContainerCPUSet(s, db, "app").String())
+	p.RemoveContainer(s, string(db.UID), "app")
+
+	// the cores are released to the default set
+	p.RemoveContainer(s, string(latencyCritical.UID), "app")
+	assert.Equal(t, "0-15", s.GetDefaultCPUSet().String())
+}
+
+func TestDiscoverLLCDomains(t *testing.T) {
+	machineInfo := &cadvisorapi.MachineInfo{Topology: []cadvisorapi.Node{
+		{Id: 0, Cores: []cadvisorapi.Core{
+			{Id: 0, Threads: []int{0, 4}, UncoreCaches: []cadvisorapi.Cache{{Id: 0, Level: 3}}},
+			{Id: 1, Threads: []int{1, 5}, UncoreCaches: []cadvisorapi.Cache{{Id: 0, Level: 3}}},
+			{Id: 2, Threads: []int{2, 6}, UncoreCaches: []cadvisorapi.Cache{{Id: 1, Level: 3}}},
+			{Id: 3, Threads: []int{3, 7}, UncoreCaches: []cadvisorapi.Cache{{Id: 1, Level: 3}}},
+		}},
+		// the last level cache is shared by the whole node
+		{Id: 1, Cores: []cadvisorapi.Core{
+			{Id: 0, Threads: []int{8, 10}},
+			{Id: 1, Threads: []int{9, 11}},
+		}, Caches: []cadvisorapi.Cache{{Id: 2, Level: 3}}},
+	}}
+
+	var domains []string
+	for _, domain := range DiscoverLLCDomains(machineInfo) {
+		domains = append(domains, domain.String())
+	}
+	assert.Equal(t, []string{"0-1,4-5", "2-3,6-7", "8-11"}, domains)
+}
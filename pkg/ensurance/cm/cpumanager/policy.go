@@ -32,6 +32,10 @@ type Policy interface {
 	GetExclusiveCPUSet(s cpumanagerstate.State) cpuset.CPUSet
 	// GetReservedCPUSet returns the set of reserved CPUs
 	GetReservedCPUSet() cpuset.CPUSet
+	// GetContainerCPUSet returns the set of CPUs which the container is bound to.
+	GetContainerCPUSet(s cpumanagerstate.State, pod *corev1.Pod, containerName string) cpuset.CPUSet
+	// GetCPULayout returns the layout of CPUs to publish in the attributes of NodeResourceTopology.
+	GetCPULayout(s cpumanagerstate.State) map[string]string
 }
 
 type staticPolicy struct {
@@ -144,17 +148,7 @@ func (p *staticPolicy) allocateCPUs(
 		}
 
 		if mode == topologyapi.AnnotationPodCPUPolicyExclusive {
-			// Remove allocated CPUs from the shared CPUSet: default and numa-aware.
-			s.SetDefaultCPUSet(s.GetDefaultCPUSet().Difference(result))
-			assignments := s.GetCPUAssignments()
-			for pod := range assignments {
-				for container, cset := range assignments[pod] {
-					// Remove the result from numa-aware container assignments.
-					if !result.Intersection(cset).IsEmpty() {
-						s.SetCPUSet(pod, container, cset.Difference(result))
-					}
-				}
-			}
+			removeSharedCPUs(s, result)
 		}
 
 	case topologyapi.AnnotationPodCPUPolicyNUMA:
@@ -253,6 +247,16 @@ func (p *staticPolicy) GetExclusiveCPUSet(s cpumanagerstate.State) cpuset.CPUSet
 	return p.topology.CPUDetails.CPUs().Difference(s.GetDefaultCPUSet())
 }
 
+// GetContainerCPUSet returns the assigned CPUs of the container, or the default set if not assigned.
+func (p *staticPolicy) GetContainerCPUSet(s cpumanagerstate.State, pod *corev1.Pod, containerName string) cpuset.CPUSet {
+	return s.GetCPUSetOrDefault(string(pod.UID), containerName)
+}
+
+// GetCPULayout returns nil, the static policy has nothing to publish besides the exclusive CPUs.
+func (p *staticPolicy) GetCPULayout(s cpumanagerstate.State) map[string]string {
+	return nil
+}
+
 func (p *staticPolicy) updateCPUsToReuse(pod *corev1.Pod, container *corev1.Container, cset cpuset.CPUSet) {
 	// If pod entries to m.cpusToReuse other than the current pod exist, delete them.
 	for podUID := range p.cpusToReuse {
@@ -294,6 +298,20 @@ func buildReservedCPUSet(topology *cputopo.CPUTopology, tr TopologyResult) (cpus
 	return res, nil
 }
 
+// removeSharedCPUs removes the allocated CPUs from the shared CPUSet: default and numa-aware.
+func removeSharedCPUs(s cpumanagerstate.State, result cpuset.CPUSet) {
+	s.SetDefaultCPUSet(s.GetDefaultCPUSet().Difference(result))
+	assignments := s.GetCPUAssignments()
+	for pod := range assignments {
+		for container, cset := range assignments[pod] {
+			// Remove the result from numa-aware container assignments.
+			if !result.Intersection(cset).IsEmpty() {
+				s.SetCPUSet(pod, container, cset.Difference(result))
+			}
+		}
+	}
+}
+
 // getAssignedCPUsOfSiblings returns assigned cpus of given container's siblings(all containers other than the given container) in the given pod `podUID`.
 func getAssignedCPUsOfSiblings(s cpumanagerstate.State, podUID string, containerName string) cpuset.CPUSet {
 	assignments := s.GetCPUAssignments()
@@ -4,6 +4,7 @@
 package cadvisor
 
 import (
+	"fmt"
 	"math"
 	"net/http"
 	"reflect"
@@ -32,6 +33,12 @@ var cadvisorMetrics = []types.MetricName{
 	types.MetricNameContainerCpuLimit,
 	types.MetricNameContainerCpuQuota,
 	types.MetricNameContainerCpuPeriod,
+	types.MetricNameContainerDiskReadKiBPS,
+	types.MetricNameContainerDiskWriteKiBPS,
+	types.MetricNameContainerDiskDeviceReadKiBPS,
+	types.MetricNameContainerDiskDeviceWriteKiBPS,
+	types.MetricNamePodNetworkReceiveKiBPS,
+	types.MetricNamePodNetworkSentKiBPS,
 }
 
 type ContainerState struct {
@@ -70,6 +77,8 @@ func NewCadvisorManager(cgroupDriver string) Manager {
 	var includedMetrics = cadvisorcontainer.MetricSet{
 		cadvisorcontainer.CpuUsageMetrics:         struct{}{},
 		cadvisorcontainer.ProcessSchedulerMetrics: struct{}{},
+		cadvisorcontainer.DiskIOMetrics:           struct{}{},
+		cadvisorcontainer.NetworkUsageMetrics:     struct{}{},
 	}
 
 	allowDynamic := true
@@ -132,7 +141,18 @@ func (c *CadvisorCollector) Collect() (map[string][]common.TimeSeries, error) {
 			klog.Errorf("GetContainerInfoV2 failed: %v", err)
 			continue
 		}
+		// The containers of a pod share the network namespace, so the pod network usage is the max of the containers
+		var podNetworkReceiveKibps, podNetworkSentKibps float64
+		var hasPodNetwork bool
 		for key, v := range containers {
+			if state, ok := c.latestContainersStates[key]; ok && !pod.Spec.HostNetwork {
+				if receiveKibps, sentKibps, ok := calculateNetworkIO(&v, &state); ok {
+					hasPodNetwork = true
+					podNetworkReceiveKibps = math.Max(podNetworkReceiveKibps, receiveKibps)
+					podNetworkSentKibps = math.Max(podNetworkSentKibps, sentKibps)
+				}
+			}
+
 			containerId := utils.GetContainerIdFromKey(key)
 			containerName := utils.GetContainerNameFromPod(pod, containerId)
 			klog.V(6).Infof("Key is %s, containerId is %s, containerName is %s", key, containerId, containerName)
@@ -177,6 +197,18 @@ func (c *CadvisorCollector) Collect() (map[string][]common.TimeSeries, error) {
 			if state, ok := c.latestContainersStates[key]; ok {
 				klog.V(6).Infof("For key %s, LatestContainersStates exist", key)
 
+				if diskReadKiBps, diskWriteKiBps, ok := calculateDiskIO(&v, &state); ok {
+					addSampleToStateMap(types.MetricNameContainerDiskReadKiBPS, composeSample(containerLabels, diskReadKiBps, now), stateMap)
+					addSampleToStateMap(types.MetricNameContainerDiskWriteKiBPS, composeSample(containerLabels, diskWriteKiBps, now), stateMap)
+				}
+				if diskReadKiBps, diskWriteKiBps, ok := calculateDiskIOByDevice(&v, &state); ok {
+					for device := range diskReadKiBps {
+						deviceLabels := append(append([]common.Label{}, containerLabels...), common.Label{Name: common.LabelNameDevice, Value: device})
+						addSampleToStateMap(types.MetricNameContainerDiskDeviceReadKiBPS, composeSample(deviceLabels, diskReadKiBps[device], now), stateMap)
+						addSampleToStateMap(types.MetricNameContainerDiskDeviceWriteKiBPS, composeSample(deviceLabels, diskWriteKiBps[device], now), stateMap)
+					}
+				}
+
 				cpuUsageSample, schedRunqueueTime := caculateCPUUsage(&v, &state)
 
 				if cpuUsageSample == 0 && schedRunqueueTime == 0 || math.IsNaN(cpuUsageSample) {
@@ -195,6 +227,12 @@ func (c *CadvisorCollector) Collect() (map[string][]common.TimeSeries, error) {
 			}
 			containerStates[key] = ContainerState{stat: v, timestamp: now}
 		}
+
+		if hasPodNetwork {
+			var podLabels = GetContainerLabels(pod, "", "", false)
+			addSampleToStateMap(types.MetricNamePodNetworkReceiveKiBPS, composeSample(podLabels, podNetworkReceiveKibps, now), stateMap)
+			addSampleToStateMap(types.MetricNamePodNetworkSentKiBPS, composeSample(podLabels, podNetworkSentKibps, now), stateMap)
+		}
 	}
 	addSampleToStateMap(types.MetricNameExtResContainerCpuTotalUsage, composeSample(make([]common.Label, 0), extResCpuUse, time.Now()), stateMap)
 	addSampleToStateMap(types.MetricNameExtResContainerMemTotalUsage, composeSample(make([]common.Label, 0), extResMemUse, time.Now()), stateMap)
@@ -246,6 +284,101 @@ func caculateCPUUsage(info *cadvisorapiv2.ContainerInfo, state *ContainerState)
 	return cpuUsageSample, schedRunqueueTime
 }
 
+// calculateDiskIO returns the read and write KiB per second of all the disks since the last state
+func calculateDiskIO(info *cadvisorapiv2.ContainerInfo, state *ContainerState) (float64, float64, bool) {
+	if info == nil ||
+		state == nil ||
+		len(info.Stats) == 0 ||
+		info.Stats[0].DiskIo == nil || len(state.stat.Stats) == 0 || state.stat.Stats[0].DiskIo == nil {
+		return 0, 0, false
+	}
+	timeIncrease := info.Stats[0].Timestamp.Sub(state.stat.Stats[0].Timestamp).Seconds()
+	if timeIncrease <= 0 {
+		return 0, 0, false
+	}
+
+	readBytes, writeBytes := sumDiskIOBytes(info.Stats[0].DiskIo)
+	lastReadBytes, lastWriteBytes := sumDiskIOBytes(state.stat.Stats[0].DiskIo)
+	// the counters are reset if the container is restarted
+	if readBytes < lastReadBytes || writeBytes < lastWriteBytes {
+		return 0, 0, false
+	}
+
+	return float64(readBytes-lastReadBytes) / 1024 / timeIncrease, float64(writeBytes-lastWriteBytes) / 1024 / timeIncrease, true
+}
+
+// calculateDiskIOByDevice returns the read and write KiB per second of every device since the last state, the keys are
+// the major:minor of the devices
+func calculateDiskIOByDevice(info *cadvisorapiv2.ContainerInfo, state *ContainerState) (map[string]float64, map[string]float64, bool) {
+	if info == nil ||
+		state == nil ||
+		len(info.Stats) == 0 ||
+		info.Stats[0].DiskIo == nil || len(state.stat.Stats) == 0 || state.stat.Stats[0].DiskIo == nil {
+		return nil, nil, false
+	}
+	timeIncrease := info.Stats[0].Timestamp.Sub(state.stat.Stats[0].Timestamp).Seconds()
+	if timeIncrease <= 0 {
+		return nil, nil, false
+	}
+
+	lastStats := make(map[string]map[string]uint64, len(state.stat.Stats[0].DiskIo.IoServiceBytes))
+	for _, d := range state.stat.Stats[0].DiskIo.IoServiceBytes {
+		lastStats[fmt.Sprintf("%d:%d", d.Major, d.Minor)] = d.Stats
+	}
+
+	readKiBps, writeKiBps := make(map[string]float64), make(map[string]float64)
+	for _, d := range info.Stats[0].DiskIo.IoServiceBytes {
+		device := fmt.Sprintf("%d:%d", d.Major, d.Minor)
+		last, ok := lastStats[device]
+		// the counters are reset if the container is restarted
+		if !ok || d.Stats["Read"] < last["Read"] || d.Stats["Write"] < last["Write"] {
+			continue
+		}
+		readKiBps[device] = float64(d.Stats["Read"]-last["Read"]) / 1024 / timeIncrease
+		writeKiBps[device] = float64(d.Stats["Write"]-last["Write"]) / 1024 / timeIncrease
+	}
+	return readKiBps, writeKiBps, len(readKiBps) > 0
+}
+
+func sumDiskIOBytes(diskIo *info.DiskIoStats) (readBytes uint64, writeBytes uint64) {
+	for _, d := range diskIo.IoServiceBytes {
+		readBytes += d.Stats["Read"]
+		writeBytes += d.Stats["Write"]
+	}
+	return
+}
+
+// calculateNetworkIO returns the receive and sent kilobits per second of all the interfaces since the last state
+func calculateNetworkIO(info *cadvisorapiv2.ContainerInfo, state *ContainerState) (float64, float64, bool) {
+	if info == nil ||
+		state == nil ||
+		len(info.Stats) == 0 ||
+		info.Stats[0].Network == nil || len(state.stat.Stats) == 0 || state.stat.Stats[0].Network == nil {
+		return 0, 0, false
+	}
+	timeIncrease := info.Stats[0].Timestamp.Sub(state.stat.Stats[0].Timestamp).Seconds()
+	if timeIncrease <= 0 {
+		return 0, 0, false
+	}
+
+	rxBytes, txBytes := sumNetworkBytes(info.Stats[0].Network)
+	lastRxBytes, lastTxBytes := sumNetworkBytes(state.stat.Stats[0].Network)
+	// the counters are reset if the network namespace is recreated
+	if rxBytes < lastRxBytes || txBytes < lastTxBytes {
+		return 0, 0, false
+	}
+
+	return float64(rxBytes-lastRxBytes) * 8 / 1000 / timeIncrease, float64(txBytes-lastTxBytes) * 8 / 1000 / timeIncrease, true
+}
+
+func sumNetworkBytes(network *cadvisorapiv2.NetworkStats) (rxBytes uint64, txBytes uint64) {
+	for _, i := range network.Interfaces {
+		rxBytes += i.RxBytes
+		txBytes += i.TxBytes
+	}
+	return
+}
+
 func GetContainerLabels(pod *v1.Pod, containerId, containerName string, hasExtRes bool) []common.Label {
 	return []common.Label{
 		{Name: common.LabelNamePodName, Value: pod.Name},
@@ -17,9 +17,11 @@ import (
 
 	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/crane/pkg/ensurance/collector/cadvisor"
+	"github.com/gocrane/crane/pkg/ensurance/collector/ebpf"
 	"github.com/gocrane/crane/pkg/ensurance/collector/nodelocal"
 	"github.com/gocrane/crane/pkg/ensurance/collector/noderesource"
 	"github.com/gocrane/crane/pkg/ensurance/collector/noderesourcetopology"
+	"github.com/gocrane/crane/pkg/ensurance/collector/psi"
 	"github.com/gocrane/crane/pkg/ensurance/collector/types"
 	"github.com/gocrane/crane/pkg/features"
 	"github.com/gocrane/crane/pkg/known"
@@ -40,11 +42,13 @@ type StateCollector struct {
 	collectInterval   time.Duration
 	ifaces            []string
 	exclusiveCPUSet   func() cpuset.CPUSet
+	cpuLayout         func() map[string]string
 	collectors        *sync.Map
 	cadvisorManager   cadvisor.Manager
 	AnalyzerChann     chan map[string][]common.TimeSeries
 	NodeResourceChann chan map[string][]common.TimeSeries
 	PodResourceChann  chan map[string][]common.TimeSeries
+	CPUSetChann       chan map[string][]common.TimeSeries
 	State             map[string][]common.TimeSeries
 	rw                sync.RWMutex
 }
@@ -53,11 +57,12 @@ func NewStateCollector(nodeName, sysPath string, kubeClient kubernetes.Interface
 	nodeQOSLister ensuranceListers.NodeQOSLister, nrtLister topologylisters.NodeResourceTopologyLister,
 	podLister corelisters.PodLister, nodeLister corelisters.NodeLister, ifaces []string,
 	healthCheck *metrics.HealthCheck, collectInterval time.Duration, exclusiveCPUSet func() cpuset.CPUSet,
-	manager cadvisor.Manager,
+	cpuLayout func() map[string]string, manager cadvisor.Manager,
 ) *StateCollector {
 	analyzerChann := make(chan map[string][]common.TimeSeries)
 	nodeResourceChann := make(chan map[string][]common.TimeSeries)
 	podResourceChann := make(chan map[string][]common.TimeSeries)
+	cpuSetChann := make(chan map[string][]common.TimeSeries)
 	State := make(map[string][]common.TimeSeries)
 	return &StateCollector{
 		nodeName:          nodeName,
@@ -74,9 +79,11 @@ func NewStateCollector(nodeName, sysPath string, kubeClient kubernetes.Interface
 		AnalyzerChann:     analyzerChann,
 		NodeResourceChann: nodeResourceChann,
 		PodResourceChann:  podResourceChann,
+		CPUSetChann:       cpuSetChann,
 		collectors:        &sync.Map{},
 		cadvisorManager:   manager,
 		exclusiveCPUSet:   exclusiveCPUSet,
+		cpuLayout:         cpuLayout,
 		State:             State,
 	}
 }
@@ -92,7 +99,12 @@ func (s *StateCollector) Run(stop <-chan struct{}) {
 	if utilfeature.DefaultFeatureGate.Enabled(features.CraneNodeResourceTopology) {
 		if _, exists := s.collectors.Load(types.NodeResourceTopologyCollectorType); !exists {
 			s.collectors.Store(types.NodeResourceTopologyCollectorType,
-				noderesourcetopology.NewNodeResourceTopology(s.nodeName, s.sysPath, s.nrtLister, s.nodeLister, s.kubeClient, s.craneClient))
+				noderesourcetopology.NewNodeResourceTopology(s.nodeName, s.sysPath, s.nrtLister, s.nodeLister, s.kubeClient, s.craneClient, s.cpuLayout))
+		}
+	}
+	if utilfeature.DefaultFeatureGate.Enabled(features.CraneEBPFCollector) {
+		if _, exists := s.collectors.Load(types.EbpfCollectorType); !exists {
+			s.collectors.Store(types.EbpfCollectorType, ebpf.NewEBPF(s.podLister, s.GetCadvisorManager().GetCgroupDriver(), ebpf.DefaultProcPath, s.sysPath))
 		}
 	}
 
@@ -176,6 +188,13 @@ func (s *StateCollector) Collect() {
 	if podResource := utilfeature.DefaultFeatureGate.Enabled(features.CranePodResource); podResource {
 		s.PodResourceChann <- s.State
 	}
+
+	// the cpuset rebalancer runs with the cpu manager
+	if utilfeature.DefaultFeatureGate.Enabled(features.CraneNodeResourceTopology) &&
+		utilfeature.DefaultFeatureGate.Enabled(features.CraneCPUManager) &&
+		utilfeature.DefaultFeatureGate.Enabled(features.CraneCPUSetRebalance) {
+		s.CPUSetChann <- s.State
+	}
 }
 
 func (s *StateCollector) UpdateCollectors() {
@@ -210,6 +229,10 @@ func (s *StateCollector) UpdateCollectors() {
 			s.collectors.Store(types.CadvisorCollectorType, cadvisor.NewCadvisorCollector(s.podLister, s.GetCadvisorManager()))
 		}
 
+		if _, exists := s.collectors.Load(types.PSICollectorType); !exists {
+			s.collectors.Store(types.PSICollectorType, psi.NewPSICollector(s.podLister, s.GetCadvisorManager().GetCgroupDriver(), psi.DefaultProcPath, s.sysPath))
+		}
+
 		break
 	}
 	// if node resource controller is enabled, it indicates local metrics need to be collected no matter nodeqos is defined or not
@@ -231,7 +254,7 @@ func (s *StateCollector) UpdateCollectors() {
 		nodeLocal = true
 	}
 	if !nodeLocal {
-		stopCollectors := []types.CollectType{types.NodeLocalCollectorType, types.CadvisorCollectorType}
+		stopCollectors := []types.CollectType{types.NodeLocalCollectorType, types.CadvisorCollectorType, types.PSICollectorType}
 
 		for _, collector := range stopCollectors {
 			if value, exists := s.collectors.Load(collector); exists {
@@ -266,6 +289,13 @@ func (s *StateCollector) StopCollectors() {
 				klog.Errorf("Failed to stop the cadvisor manager.")
 			}
 		}
+		// Detach the ebpf programs and close the perf events
+		if key == types.EbpfCollectorType {
+			c := value.(Collector)
+			if err := c.Stop(); err != nil {
+				klog.Errorf("Failed to stop the ebpf collector.")
+			}
+		}
 		return true
 	})
 
@@ -281,6 +311,14 @@ func CheckMetricNameExist(name string) bool {
 		return true
 	}
 
+	if psi.CheckMetricNameExist(name) {
+		return true
+	}
+
+	if ebpf.CheckMetricNameExist(name) {
+		return true
+	}
+
 	return false
 }
 
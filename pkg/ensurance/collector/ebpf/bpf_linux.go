@@ -0,0 +1,355 @@
+//go:build linux
+// +build linux
+
+package ebpf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/link"
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// btfFile exists if the kernel is built with CONFIG_DEBUG_INFO_BTF, the kernels without it are mostly too old for
+	// the helpers and map types used by the programs
+	btfFile = "kernel/btf/vmlinux"
+
+	// taskReportMax is the prev_state of sched_switch for a preempted task since kernel 4.14, which is still running.
+	// The states of the sleeping tasks are in the bits below it, and the older kernels report the preempted tasks by
+	// TASK_RUNNING | TASK_STATE_MAX, which has no bits below it either.
+	taskReportMax = 0x100
+
+	// maxTasks is the max entries of the per task maps, the least recently used entries are evicted
+	maxTasks = 65536
+)
+
+// taskStat is the value of the task stat map, same layout as the stat updated by the programs
+type taskStat struct {
+	RunQueueTime  uint64
+	RunQueueCount uint64
+	// OffCPUTime includes the run queue wait, which is excluded when reading
+	OffCPUTime uint64
+}
+
+// bpfSource attaches programs to the sched tracepoints, and accumulates the run queue wait and off-cpu time per task,
+// the stat of a cgroup is the sum of its tasks
+type bpfSource struct {
+	cgroupRoot string
+
+	wakeupTime *ebpf.Map
+	switchTime *ebpf.Map
+	taskStats  *ebpf.Map
+	programs   []*ebpf.Program
+	links      []link.Link
+}
+
+func newBPFSource(sysPath, cgroupRoot string) (source *bpfSource, err error) {
+	if _, err := os.Stat(filepath.Join(sysPath, btfFile)); err != nil {
+		return nil, fmt.Errorf("kernel without BTF: %v", err)
+	}
+	// The bpf memory is charged to the memlock rlimit before kernel 5.11
+	if err := unix.Setrlimit(unix.RLIMIT_MEMLOCK, &unix.Rlimit{Cur: unix.RLIM_INFINITY, Max: unix.RLIM_INFINITY}); err != nil {
+		return nil, fmt.Errorf("failed to remove memlock rlimit: %v", err)
+	}
+
+	switchFormat, err := readTracepointFormat(sysPath, "sched", "sched_switch")
+	if err != nil {
+		return nil, err
+	}
+	wakeupFormat, err := readTracepointFormat(sysPath, "sched", "sched_wakeup")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &bpfSource{cgroupRoot: cgroupRoot}
+	defer func() {
+		if err != nil {
+			s.Close()
+		}
+	}()
+
+	if s.wakeupTime, err = newTaskMap("wakeup_time", 8); err != nil {
+		return nil, err
+	}
+	if s.switchTime, err = newTaskMap("switch_time", 8); err != nil {
+		return nil, err
+	}
+	if s.taskStats, err = newTaskMap("task_stats", 24); err != nil {
+		return nil, err
+	}
+
+	wakeupInstructions, err := wakeupProgram(wakeupFormat, s.wakeupTime.FD())
+	if err != nil {
+		return nil, err
+	}
+	switchInstructions, err := switchProgram(switchFormat, s.wakeupTime.FD(), s.switchTime.FD(), s.taskStats.FD())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tp := range []struct {
+		name         string
+		instructions asm.Instructions
+	}{
+		{"sched_wakeup", wakeupInstructions},
+		{"sched_wakeup_new", wakeupInstructions},
+		{"sched_switch", switchInstructions},
+	} {
+		prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+			Name:         tp.name,
+			Type:         ebpf.TracePoint,
+			Instructions: tp.instructions,
+			License:      "GPL",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load program %s: %v", tp.name, err)
+		}
+		s.programs = append(s.programs, prog)
+
+		l, err := link.Tracepoint("sched", tp.name, prog)
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach program %s: %v", tp.name, err)
+		}
+		s.links = append(s.links, l)
+	}
+
+	klog.V(2).Infof("Attached ebpf programs to the sched tracepoints")
+	return s, nil
+}
+
+func (s *bpfSource) Name() string {
+	return "bpf"
+}
+
+func (s *bpfSource) Stat(cgroupPath string) (CgroupStat, error) {
+	tids, err := listTasks(s.cgroupRoot, cgroupPath)
+	if err != nil {
+		return CgroupStat{}, err
+	}
+
+	var stat CgroupStat
+	for _, tid := range tids {
+		var ts taskStat
+		// The task is not switched in since the programs are attached
+		if err := s.taskStats.Lookup(uint32(tid), &ts); err != nil {
+			continue
+		}
+		stat.RunQueueTime += ts.RunQueueTime
+		stat.RunQueueCount += ts.RunQueueCount
+		if ts.OffCPUTime > ts.RunQueueTime {
+			stat.OffCPUTime += ts.OffCPUTime - ts.RunQueueTime
+		}
+	}
+	return stat, nil
+}
+
+func (s *bpfSource) Close() error {
+	for _, l := range s.links {
+		l.Close()
+	}
+	for _, prog := range s.programs {
+		prog.Close()
+	}
+	for _, m := range []*ebpf.Map{s.wakeupTime, s.switchTime, s.taskStats} {
+		if m != nil {
+			m.Close()
+		}
+	}
+	return nil
+}
+
+func newTaskMap(name string, valueSize uint32) (*ebpf.Map, error) {
+	m, err := ebpf.NewMap(&ebpf.MapSpec{
+		Name:       name,
+		Type:       ebpf.LRUHash,
+		KeySize:    4,
+		ValueSize:  valueSize,
+		MaxEntries: maxTasks,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create map %s: %v", name, err)
+	}
+	return m, nil
+}
+
+// wakeupProgram records the time a task is woken up
+//
+//	wakeup_time[pid] = bpf_ktime_get_ns();
+func wakeupProgram(format map[string]tracepointField, wakeupTimeFD int) (asm.Instructions, error) {
+	pid, err := loadField(format, "pid", asm.R1, asm.R6)
+	if err != nil {
+		return nil, err
+	}
+	return asm.Instructions{
+		asm.Mov.Reg(asm.R6, asm.R1),
+		asm.FnKtimeGetNs.Call(),
+		asm.StoreMem(asm.RFP, -16, asm.R0, asm.DWord),
+		pid,
+		asm.StoreMem(asm.RFP, -4, asm.R1, asm.Word),
+		asm.LoadMapPtr(asm.R1, wakeupTimeFD),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -4),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -16),
+		asm.Mov.Imm(asm.R4, 0),
+		asm.FnMapUpdateElem.Call(),
+		asm.Mov.Imm(asm.R0, 0),
+		asm.Return(),
+	}, nil
+}
+
+// switchProgram accumulates the run queue wait and off-cpu time of the task switched in
+//
+//	switch_time[prev_pid] = now;
+//	if (!(prev_state & (TASK_REPORT_MAX - 1))) wakeup_time[prev_pid] = now;  // preempted, still on the run queue
+//	stat = task_stats[next_pid];
+//	if (wakeup_time[next_pid]) { stat.run_queue_time += now - wakeup_time[next_pid]; stat.run_queue_count++; }
+//	if (switch_time[next_pid]) { stat.off_cpu_time += now - switch_time[next_pid]; }
+func switchProgram(format map[string]tracepointField, wakeupTimeFD, switchTimeFD, taskStatsFD int) (asm.Instructions, error) {
+	prevPid, err := loadField(format, "prev_pid", asm.R1, asm.R6)
+	if err != nil {
+		return nil, err
+	}
+	prevState, err := loadField(format, "prev_state", asm.R1, asm.R6)
+	if err != nil {
+		return nil, err
+	}
+	nextPid, err := loadField(format, "next_pid", asm.R1, asm.R6)
+	if err != nil {
+		return nil, err
+	}
+
+	// The key is at fp-4, the time is at fp-16, and the zero stat is at fp-40
+	update := func(fd int, flags int32) asm.Instructions {
+		return asm.Instructions{
+			asm.LoadMapPtr(asm.R1, fd),
+			asm.Mov.Reg(asm.R2, asm.RFP),
+			asm.Add.Imm(asm.R2, -4),
+			asm.Mov.Reg(asm.R3, asm.RFP),
+			asm.Add.Imm(asm.R3, -16),
+			asm.Mov.Imm(asm.R4, flags),
+			asm.FnMapUpdateElem.Call(),
+		}
+	}
+	lookup := func(fd int) asm.Instructions {
+		return asm.Instructions{
+			asm.LoadMapPtr(asm.R1, fd),
+			asm.Mov.Reg(asm.R2, asm.RFP),
+			asm.Add.Imm(asm.R2, -4),
+			asm.FnMapLookupElem.Call(),
+		}
+	}
+	remove := func(fd int) asm.Instructions {
+		return asm.Instructions{
+			asm.LoadMapPtr(asm.R1, fd),
+			asm.Mov.Reg(asm.R2, asm.RFP),
+			asm.Add.Imm(asm.R2, -4),
+			asm.FnMapDeleteElem.Call(),
+		}
+	}
+
+	var insns asm.Instructions
+	insns = append(insns,
+		asm.Mov.Reg(asm.R6, asm.R1),
+		asm.FnKtimeGetNs.Call(),
+		asm.Mov.Reg(asm.R7, asm.R0),
+		asm.StoreMem(asm.RFP, -16, asm.R7, asm.DWord),
+		prevPid,
+		asm.StoreMem(asm.RFP, -4, asm.R1, asm.Word),
+	)
+	insns = append(insns, update(switchTimeFD, 0)...)
+	insns = append(insns,
+		prevState,
+		asm.And.Imm(asm.R1, taskReportMax-1),
+		asm.JNE.Imm(asm.R1, 0, "next"),
+	)
+	insns = append(insns, update(wakeupTimeFD, 0)...)
+
+	insns = append(insns,
+		nextPid.Sym("next"),
+		asm.StoreMem(asm.RFP, -4, asm.R1, asm.Word),
+	)
+	insns = append(insns, lookup(taskStatsFD)...)
+	insns = append(insns, asm.JNE.Imm(asm.R0, 0, "stat"))
+	// Insert a zero stat for the task, the key is kept at fp-4
+	insns = append(insns,
+		asm.StoreImm(asm.RFP, -40, 0, asm.DWord),
+		asm.StoreImm(asm.RFP, -32, 0, asm.DWord),
+		asm.StoreImm(asm.RFP, -24, 0, asm.DWord),
+		asm.LoadMapPtr(asm.R1, taskStatsFD),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -4),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -40),
+		asm.Mov.Imm(asm.R4, int32(ebpf.UpdateNoExist)),
+		asm.FnMapUpdateElem.Call(),
+	)
+	insns = append(insns, lookup(taskStatsFD)...)
+	insns = append(insns, asm.JEq.Imm(asm.R0, 0, "exit"))
+
+	insns = append(insns, asm.Mov.Reg(asm.R8, asm.R0).Sym("stat"))
+	insns = append(insns, lookup(wakeupTimeFD)...)
+	insns = append(insns,
+		asm.JEq.Imm(asm.R0, 0, "offcpu"),
+		asm.LoadMem(asm.R1, asm.R0, 0, asm.DWord),
+		asm.Mov.Reg(asm.R2, asm.R7),
+		asm.Sub.Reg(asm.R2, asm.R1),
+		storeXAdd(asm.R8, 0, asm.R2),
+		asm.Mov.Imm(asm.R2, 1),
+		storeXAdd(asm.R8, 8, asm.R2),
+	)
+	insns = append(insns, remove(wakeupTimeFD)...)
+
+	offCPU := lookup(switchTimeFD)
+	offCPU[0] = offCPU[0].Sym("offcpu")
+	insns = append(insns, offCPU...)
+	insns = append(insns,
+		asm.JEq.Imm(asm.R0, 0, "exit"),
+		asm.LoadMem(asm.R1, asm.R0, 0, asm.DWord),
+		asm.Mov.Reg(asm.R2, asm.R7),
+		asm.Sub.Reg(asm.R2, asm.R1),
+		storeXAdd(asm.R8, 16, asm.R2),
+	)
+	insns = append(insns, remove(switchTimeFD)...)
+
+	insns = append(insns,
+		asm.Mov.Imm(asm.R0, 0).Sym("exit"),
+		asm.Return(),
+	)
+	return insns, nil
+}
+
+// loadField loads a field of the tracepoint record in ctx to dst
+func loadField(format map[string]tracepointField, name string, dst, ctx asm.Register) (asm.Instruction, error) {
+	field, ok := format[name]
+	if !ok {
+		return asm.Instruction{}, fmt.Errorf("field %s not found in the tracepoint format", name)
+	}
+	var size asm.Size
+	switch field.Size {
+	case 1:
+		size = asm.Byte
+	case 2:
+		size = asm.Half
+	case 4:
+		size = asm.Word
+	case 8:
+		size = asm.DWord
+	default:
+		return asm.Instruction{}, fmt.Errorf("invalid size %d of field %s", field.Size, name)
+	}
+	return asm.LoadMem(dst, ctx, int16(field.Offset), size), nil
+}
+
+// storeXAdd atomically adds src to the dword at dst+offset
+func storeXAdd(dst asm.Register, offset int16, src asm.Register) asm.Instruction {
+	ins := asm.StoreXAdd(dst, src, asm.DWord)
+	ins.Offset = offset
+	return ins
+}
@@ -0,0 +1,35 @@
+//go:build linux
+// +build linux
+
+package ebpf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrograms(t *testing.T) {
+	switchFormat, err := readTracepointFormat("testdata/sys", "sched", "sched_switch")
+	assert.NoError(t, err)
+	wakeupFormat, err := readTracepointFormat("testdata/sys", "sched", "sched_wakeup")
+	assert.NoError(t, err)
+
+	// The programs are not loaded since it needs privileges, but all the jumps should be resolved
+	insns, err := switchProgram(switchFormat, 3, 4, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, insns.Marshal(&bytes.Buffer{}, binary.LittleEndian))
+	offsets, err := insns.SymbolOffsets()
+	assert.NoError(t, err)
+	assert.Equal(t, int16(56), insns[offsets["next"]].Offset)
+
+	insns, err = wakeupProgram(wakeupFormat, 3)
+	assert.NoError(t, err)
+	assert.NoError(t, insns.Marshal(&bytes.Buffer{}, binary.LittleEndian))
+
+	// The record layout is different
+	_, err = switchProgram(wakeupFormat, 3, 4, 5)
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+//go:build linux && privileged
+// +build linux,privileged
+
+package ebpf
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+// TestLoadPrograms loads the programs into the kernel to check them by the verifier, it needs the bpf privileges and is
+// run by go test -tags privileged as root
+func TestLoadPrograms(t *testing.T) {
+	s, err := newBPFSource("/sys", "/sys/fs/cgroup")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+
+	// The thread is switched out by the sleep and switched in after it
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	for i := 0; i < 10; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var ts taskStat
+	assert.NoError(t, s.taskStats.Lookup(uint32(unix.Gettid()), &ts))
+	assert.Greater(t, ts.OffCPUTime, uint64(50*time.Millisecond))
+	assert.Greater(t, ts.RunQueueCount, uint64(0))
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+// +build !linux
+
+package ebpf
+
+import (
+	"errors"
+)
+
+var errUnsupported = errors.New("ebpf is unsupported in this build")
+
+func newBPFSource(_, _ string) (statSource, error) {
+	return nil, errUnsupported
+}
+
+func newPerfCounters(_, _ string) (counterSource, error) {
+	return nil, errUnsupported
+}
@@ -1,24 +1,82 @@
 package ebpf
 
 import (
-	"sync"
+	"path/filepath"
+	"time"
 
-	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
 
 	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/cgroup"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/utils"
 )
 
+// DefaultProcPath is the path of proc, the agent should run in the host pid namespace to read the tasks of the pods
+const DefaultProcPath = "/proc"
+
+var ebpfMetrics = []types.MetricName{
+	types.MetricNameContainerRunQueueLatency,
+	types.MetricNameContainerOffCPUTime,
+	types.MetricNameContainerCPI,
+}
+
+type containerState struct {
+	stat      CgroupStat
+	timestamp time.Time
+}
+
+// EBPF collects the scheduling signals of the containers which procfs can't give, the run queue latency and off-cpu
+// time are accumulated by the programs attached to the sched tracepoints, and the cpi is counted by the perf events of
+// the container cgroup. On the kernels without BTF or the programs fail to load, it falls back to schedstat of the tasks.
 type EBPF struct {
-	name        types.CollectType
-	StatusCache sync.Map
+	name         types.CollectType
+	podLister    corelisters.PodLister
+	cgroupDriver string
+	cgroupRoot   string
+
+	source   statSource
+	counters counterSource
+
+	latestContainersStates map[string]containerState
 }
 
-func NewEBPF() *EBPF {
-	e := EBPF{
-		name:        types.EbpfCollectorType,
-		StatusCache: sync.Map{},
+func NewEBPF(podLister corelisters.PodLister, cgroupDriver, procPath, sysPath string) *EBPF {
+	cgroupRoot := filepath.Join(sysPath, "fs", "cgroup")
+
+	var source statSource
+	if s, err := newBPFSource(sysPath, cgroupRoot); err == nil {
+		source = s
+	} else {
+		klog.Warningf("Failed to load ebpf programs, fall back to schedstat: %v", err)
+		source = newSchedstatSource(procPath, cgroupRoot)
+	}
+
+	var counters counterSource
+	if c, err := newPerfCounters(sysPath, cgroupRoot); err == nil {
+		counters = c
+	} else {
+		klog.Warningf("Failed to get perf counters, cpi is not collected: %v", err)
+	}
+
+	return newEBPF(podLister, cgroupDriver, cgroupRoot, source, counters)
+}
+
+func newEBPF(podLister corelisters.PodLister, cgroupDriver, cgroupRoot string, source statSource, counters counterSource) *EBPF {
+	klog.V(2).Infof("New ebpf collector in %s mode", source.Name())
+
+	return &EBPF{
+		name:                   types.EbpfCollectorType,
+		podLister:              podLister,
+		cgroupDriver:           cgroupDriver,
+		cgroupRoot:             cgroupRoot,
+		source:                 source,
+		counters:               counters,
+		latestContainersStates: make(map[string]containerState),
 	}
-	return &e
 }
 
 func (e *EBPF) GetType() types.CollectType {
@@ -26,9 +84,119 @@ func (e *EBPF) GetType() types.CollectType {
 }
 
 func (e *EBPF) Collect() (map[string][]common.TimeSeries, error) {
-	return nil, nil
+	klog.V(6).Infof("Ebpf collecting")
+
+	allPods, err := e.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	var now = time.Now()
+	var stateMap = make(map[string][]common.TimeSeries)
+	var containersStates = make(map[string]containerState)
+	var cgroupPaths = make(map[string]bool)
+	for _, pod := range allPods {
+		podCgroupPath := utils.GetCgroupPath(pod, e.cgroupDriver)
+		if podCgroupPath == "" {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Running == nil || cs.ContainerID == "" {
+				continue
+			}
+			containerId := utils.GetContainerIdFromKey(cs.ContainerID)
+			cgroupPath, err := cgroup.GetContainerCgroupPath(e.cgroupRoot, podCgroupPath, containerId)
+			if err != nil {
+				klog.V(4).Infof("Failed to get cgroup of container %s/%s: %v", klog.KObj(pod), cs.Name, err)
+				continue
+			}
+			cgroupPaths[cgroupPath] = true
+
+			stat, err := e.source.Stat(cgroupPath)
+			if err != nil {
+				klog.V(4).Infof("Failed to get %s stat of container %s/%s: %v", e.source.Name(), klog.KObj(pod), cs.Name, err)
+				continue
+			}
+			if e.counters != nil {
+				if stat.Cycles, stat.Instructions, err = e.counters.Counters(cgroupPath); err != nil {
+					klog.V(4).Infof("Failed to get perf counters of container %s/%s: %v", klog.KObj(pod), cs.Name, err)
+				}
+			}
+			state := containerState{stat: stat, timestamp: now}
+			containersStates[cgroupPath] = state
+
+			latest, ok := e.latestContainersStates[cgroupPath]
+			if !ok {
+				continue
+			}
+			containerLabels := getContainerLabels(pod, containerId, cs.Name)
+			if latency, offCPU, ok := calculateSchedStat(&latest, &state); ok {
+				addSample(stateMap, types.MetricNameContainerRunQueueLatency, containerLabels, latency, now)
+				addSample(stateMap, types.MetricNameContainerOffCPUTime, containerLabels, offCPU, now)
+			}
+			if cpi, ok := calculateCPI(&latest, &state); ok {
+				addSample(stateMap, types.MetricNameContainerCPI, containerLabels, cpi, now)
+			}
+		}
+	}
+
+	e.latestContainersStates = containersStates
+	if e.counters != nil {
+		e.counters.Release(cgroupPaths)
+	}
+
+	return stateMap, nil
 }
 
 func (e *EBPF) Stop() error {
-	return nil
+	if e.counters != nil {
+		e.counters.Close()
+	}
+	return e.source.Close()
+}
+
+func CheckMetricNameExist(name string) bool {
+	for _, m := range ebpfMetrics {
+		if string(m) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// calculateSchedStat returns the average run queue latency in microseconds and the off-cpu time in seconds per second,
+// it's not calculated if the tasks exit so that the accumulated stat goes down
+func calculateSchedStat(latest, current *containerState) (runQueueLatency, offCPUTime float64, ok bool) {
+	duration := current.timestamp.Sub(latest.timestamp).Nanoseconds()
+	if duration <= 0 || current.stat.RunQueueTime < latest.stat.RunQueueTime ||
+		current.stat.RunQueueCount < latest.stat.RunQueueCount || current.stat.OffCPUTime < latest.stat.OffCPUTime {
+		return 0, 0, false
+	}
+	if count := current.stat.RunQueueCount - latest.stat.RunQueueCount; count > 0 {
+		runQueueLatency = float64(current.stat.RunQueueTime-latest.stat.RunQueueTime) / float64(count) / 1000
+	}
+	offCPUTime = float64(current.stat.OffCPUTime-latest.stat.OffCPUTime) / float64(duration)
+	return runQueueLatency, offCPUTime, true
+}
+
+// calculateCPI returns the cycles per instruction during the interval
+func calculateCPI(latest, current *containerState) (float64, bool) {
+	if current.stat.Instructions <= latest.stat.Instructions || current.stat.Cycles < latest.stat.Cycles {
+		return 0, false
+	}
+	return float64(current.stat.Cycles-latest.stat.Cycles) / float64(current.stat.Instructions-latest.stat.Instructions), true
+}
+
+func getContainerLabels(pod *v1.Pod, containerId, containerName string) []common.Label {
+	return []common.Label{
+		{Name: common.LabelNamePodName, Value: pod.Name},
+		{Name: common.LabelNamePodNamespace, Value: pod.Namespace},
+		{Name: common.LabelNamePodUid, Value: string(pod.UID)},
+		{Name: common.LabelNameContainerName, Value: containerName},
+		{Name: common.LabelNameContainerId, Value: containerId},
+	}
+}
+
+func addSample(stateMap map[string][]common.TimeSeries, metricName types.MetricName, labels []common.Label, value float64, now time.Time) {
+	stateMap[string(metricName)] = append(stateMap[string(metricName)], common.TimeSeries{Labels: labels, Samples: []common.Sample{{Value: value, Timestamp: now.Unix()}}})
 }
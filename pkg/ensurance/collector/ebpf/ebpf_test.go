@@ -0,0 +1,107 @@
+package ebpf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+)
+
+// replaySource replays the stats recorded in testdata/stats.json, one round for each collect
+type replaySource struct {
+	rounds []map[string]CgroupStat
+	round  int
+}
+
+func (r *replaySource) Name() string {
+	return "replay"
+}
+
+func (r *replaySource) Stat(cgroupPath string) (CgroupStat, error) {
+	stat, ok := r.rounds[r.round][cgroupPath]
+	if !ok {
+		return CgroupStat{}, fmt.Errorf("cgroup %s not recorded", cgroupPath)
+	}
+	return stat, nil
+}
+
+func (r *replaySource) Close() error {
+	return nil
+}
+
+func (r *replaySource) Counters(cgroupPath string) (uint64, uint64, error) {
+	stat, err := r.Stat(cgroupPath)
+	return stat.Cycles, stat.Instructions, err
+}
+
+func (r *replaySource) Release(_ map[string]bool) {}
+
+func TestCollect(t *testing.T) {
+	content, err := os.ReadFile("testdata/stats.json")
+	assert.NoError(t, err)
+	source := &replaySource{}
+	assert.NoError(t, json.Unmarshal(content, &source.rounds))
+
+	cgroupRoot := t.TempDir()
+	for _, c := range []string{"c1", "c2"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(cgroupRoot, cpuSubsystem, "kubepods", "burstable", "poduid-1", c), 0755))
+	}
+	running := v1.ContainerState{Running: &v1.ContainerStateRunning{}}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "uid-1"},
+		Status: v1.PodStatus{
+			QOSClass: v1.PodQOSBurstable,
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "nginx", ContainerID: "containerd://c1", State: running},
+				{Name: "sidecar", ContainerID: "containerd://c2", State: running},
+				{Name: "init", ContainerID: "containerd://c3", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{}}},
+			},
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.NoError(t, indexer.Add(pod))
+
+	e := newEBPF(corelisters.NewPodLister(indexer), "cgroupfs", cgroupRoot, source, source)
+	data, err := e.Collect()
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+
+	// Pretend the second round is collected 10s later
+	for path, state := range e.latestContainersStates {
+		state.timestamp = state.timestamp.Add(-10 * time.Second)
+		e.latestContainersStates[path] = state
+	}
+	source.round++
+	data, err = e.Collect()
+	assert.NoError(t, err)
+
+	// The tasks of the sidecar exit, so only the cpi is missing for nginx
+	latency := data[string(types.MetricNameContainerRunQueueLatency)]
+	assert.Len(t, latency, 1)
+	assert.Contains(t, latency[0].Labels, common.Label{Name: common.LabelNameContainerName, Value: "nginx"})
+	assert.Contains(t, latency[0].Labels, common.Label{Name: common.LabelNameContainerId, Value: "c1"})
+	assert.InDelta(t, 250.0, latency[0].Samples[0].Value, 1e-6)
+
+	offCPU := data[string(types.MetricNameContainerOffCPUTime)]
+	assert.Len(t, offCPU, 1)
+	assert.InDelta(t, 1.0, offCPU[0].Samples[0].Value, 1e-3)
+
+	cpi := data[string(types.MetricNameContainerCPI)]
+	assert.Len(t, cpi, 1)
+	assert.InDelta(t, 3.0, cpi[0].Samples[0].Value, 1e-6)
+
+	for key := range data {
+		assert.True(t, CheckMetricNameExist(key), key)
+	}
+}
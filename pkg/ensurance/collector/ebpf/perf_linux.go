@@ -0,0 +1,140 @@
+//go:build linux
+// +build linux
+
+package ebpf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/gocrane/crane/pkg/ensurance/cgroup"
+)
+
+const (
+	perfEventSubsystem = "perf_event"
+	onlineCPUsFile     = "devices/system/cpu/online"
+)
+
+// cgroupCounters are the per cpu cycles and instructions counters of a cgroup
+type cgroupCounters struct {
+	cycles       []int
+	instructions []int
+}
+
+// perfCounters counts the cycles and instructions of the cgroups by perf events in cgroup mode, which are opened on
+// every cpu when a cgroup is read for the first time
+type perfCounters struct {
+	cgroupRoot string
+	cpus       []int
+	counters   map[string]*cgroupCounters
+}
+
+func newPerfCounters(sysPath, cgroupRoot string) (*perfCounters, error) {
+	content, err := os.ReadFile(filepath.Join(sysPath, onlineCPUsFile))
+	if err != nil {
+		return nil, err
+	}
+	cpus, err := cpuset.Parse(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, err
+	}
+	return &perfCounters{
+		cgroupRoot: cgroupRoot,
+		cpus:       cpus.ToSlice(),
+		counters:   map[string]*cgroupCounters{},
+	}, nil
+}
+
+func (p *perfCounters) Counters(cgroupPath string) (cycles, instructions uint64, err error) {
+	c, ok := p.counters[cgroupPath]
+	if !ok {
+		if c, err = p.open(cgroupPath); err != nil {
+			return 0, 0, err
+		}
+		p.counters[cgroupPath] = c
+	}
+
+	if cycles, err = readCounters(c.cycles); err != nil {
+		return 0, 0, err
+	}
+	if instructions, err = readCounters(c.instructions); err != nil {
+		return 0, 0, err
+	}
+	return cycles, instructions, nil
+}
+
+func (p *perfCounters) Release(keep map[string]bool) {
+	for cgroupPath, c := range p.counters {
+		if !keep[cgroupPath] {
+			c.close()
+			delete(p.counters, cgroupPath)
+		}
+	}
+}
+
+func (p *perfCounters) Close() error {
+	p.Release(nil)
+	return nil
+}
+
+func (p *perfCounters) open(cgroupPath string) (*cgroupCounters, error) {
+	dir := filepath.Join(p.cgroupRoot, cgroupPath)
+	if !cgroup.IsUnified(p.cgroupRoot) {
+		dir = filepath.Join(p.cgroupRoot, perfEventSubsystem, cgroupPath)
+	}
+	cgroupFd, err := unix.Open(dir, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(cgroupFd)
+
+	c := &cgroupCounters{}
+	for _, cpu := range p.cpus {
+		for _, event := range []struct {
+			config uint64
+			fds    *[]int
+		}{
+			{unix.PERF_COUNT_HW_CPU_CYCLES, &c.cycles},
+			{unix.PERF_COUNT_HW_INSTRUCTIONS, &c.instructions},
+		} {
+			attr := &unix.PerfEventAttr{
+				Type:   unix.PERF_TYPE_HARDWARE,
+				Size:   uint32(unsafe.Sizeof(unix.PerfEventAttr{})),
+				Config: event.config,
+			}
+			fd, err := unix.PerfEventOpen(attr, cgroupFd, cpu, -1, unix.PERF_FLAG_PID_CGROUP|unix.PERF_FLAG_FD_CLOEXEC)
+			if err != nil {
+				c.close()
+				return nil, fmt.Errorf("failed to open perf event on cpu %d for cgroup %s: %v", cpu, cgroupPath, err)
+			}
+			*event.fds = append(*event.fds, fd)
+		}
+	}
+	return c, nil
+}
+
+func (c *cgroupCounters) close() {
+	for _, fd := range append(c.cycles, c.instructions...) {
+		unix.Close(fd)
+	}
+}
+
+// readCounters sums the counters of all the cpus
+func readCounters(fds []int) (uint64, error) {
+	var sum uint64
+	for _, fd := range fds {
+		// The counter is read in the native byte order
+		var value uint64
+		if _, err := unix.Read(fd, (*[8]byte)(unsafe.Pointer(&value))[:]); err != nil {
+			return 0, err
+		}
+		sum += value
+	}
+	return sum, nil
+}
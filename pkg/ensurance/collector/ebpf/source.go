@@ -0,0 +1,192 @@
+package ebpf
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gocrane/crane/pkg/ensurance/cgroup"
+)
+
+const (
+	// userHZ is the unit of the start time in /proc/<pid>/stat, which is 100 on all the supported architectures
+	userHZ = 100
+
+	tasksFile         = "tasks"
+	unifiedTasksFile  = "cgroup.threads"
+	cpuSubsystem      = "cpu"
+	schedstatFile     = "schedstat"
+	statFile          = "stat"
+	uptimeFile        = "uptime"
+	statStartTimeItem = 22
+)
+
+// CgroupStat is the accumulated scheduling stat of the tasks in a cgroup
+type CgroupStat struct {
+	// RunQueueTime is the time in nanoseconds the tasks waited on the run queue
+	RunQueueTime uint64
+	// RunQueueCount is the times the tasks were switched in
+	RunQueueCount uint64
+	// OffCPUTime is the time in nanoseconds the tasks were sleeping or blocked, the run queue wait is excluded
+	OffCPUTime uint64
+	// Cycles and Instructions are the hardware counters of the cgroup, zero if not supported
+	Cycles       uint64
+	Instructions uint64
+}
+
+// statSource gets the scheduling stat of the tasks in a cgroup
+type statSource interface {
+	// Name is the mode of the source, such as bpf or schedstat
+	Name() string
+	// Stat returns the accumulated stat of the tasks in the cgroup
+	Stat(cgroupPath string) (CgroupStat, error)
+	// Close detaches the programs and releases the maps
+	Close() error
+}
+
+// counterSource gets the hardware counters of a cgroup
+type counterSource interface {
+	// Counters returns the accumulated cycles and instructions of the cgroup since they are opened
+	Counters(cgroupPath string) (cycles, instructions uint64, err error)
+	// Release closes the counters of the cgroups not in the keep set
+	Release(keep map[string]bool)
+	Close() error
+}
+
+// schedstatSource reads /proc/<tid>/schedstat of the tasks in a cgroup, it's the fallback if the bpf programs can't be
+// loaded. The run queue time is accurate, but the off-cpu time is derived from the lifetime of the tasks.
+type schedstatSource struct {
+	procPath   string
+	cgroupRoot string
+}
+
+func newSchedstatSource(procPath, cgroupRoot string) *schedstatSource {
+	return &schedstatSource{procPath: procPath, cgroupRoot: cgroupRoot}
+}
+
+func (s *schedstatSource) Name() string {
+	return "schedstat"
+}
+
+func (s *schedstatSource) Stat(cgroupPath string) (CgroupStat, error) {
+	tids, err := listTasks(s.cgroupRoot, cgroupPath)
+	if err != nil {
+		return CgroupStat{}, err
+	}
+	uptime, err := readUptime(s.procPath)
+	if err != nil {
+		return CgroupStat{}, err
+	}
+
+	var stat CgroupStat
+	for _, tid := range tids {
+		execTime, runDelay, slices, err := readSchedstat(s.procPath, tid)
+		if err != nil {
+			// The task exits
+			continue
+		}
+		startTime, err := readStartTime(s.procPath, tid)
+		if err != nil {
+			continue
+		}
+		stat.RunQueueTime += runDelay
+		stat.RunQueueCount += slices
+		if uptime > startTime+execTime+runDelay {
+			stat.OffCPUTime += uptime - startTime - execTime - runDelay
+		}
+	}
+	return stat, nil
+}
+
+func (s *schedstatSource) Close() error {
+	return nil
+}
+
+// listTasks returns the thread ids in the cgroup, the tasks are listed by the cpu subsystem for cgroup v1
+func listTasks(cgroupRoot, cgroupPath string) ([]int, error) {
+	file := filepath.Join(cgroupRoot, cgroupPath, unifiedTasksFile)
+	if !cgroup.IsUnified(cgroupRoot) {
+		file = filepath.Join(cgroupRoot, cpuSubsystem, cgroupPath, tasksFile)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var tids []int
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		tid, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tid %q in %s: %v", line, file, err)
+		}
+		tids = append(tids, tid)
+	}
+	return tids, nil
+}
+
+// readSchedstat reads /proc/<tid>/schedstat, which is the time on cpu, the time waited on the run queue in nanoseconds,
+// and the number of timeslices run on the cpu
+func readSchedstat(procPath string, tid int) (execTime, runDelay, slices uint64, err error) {
+	content, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(tid), schedstatFile))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) != 3 {
+		return 0, 0, 0, fmt.Errorf("invalid schedstat %q of task %d", string(content), tid)
+	}
+	var values [3]uint64
+	for i, field := range fields {
+		if values[i], err = strconv.ParseUint(field, 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid schedstat %q of task %d: %v", string(content), tid, err)
+		}
+	}
+	return values[0], values[1], values[2], nil
+}
+
+// readStartTime returns the time in nanoseconds the task started after boot
+func readStartTime(procPath string, tid int) (uint64, error) {
+	content, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(tid), statFile))
+	if err != nil {
+		return 0, err
+	}
+	// The comm in the brackets may contain spaces
+	stat := string(content)
+	end := strings.LastIndex(stat, ")")
+	if end < 0 {
+		return 0, fmt.Errorf("invalid stat of task %d", tid)
+	}
+	// The items after comm start from the state, which is the third item
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < statStartTimeItem-2 {
+		return 0, fmt.Errorf("invalid stat of task %d", tid)
+	}
+	ticks, err := strconv.ParseUint(fields[statStartTimeItem-3], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid start time of task %d: %v", tid, err)
+	}
+	return ticks * (1e9 / userHZ), nil
+}
+
+// readUptime returns the time in nanoseconds since boot
+func readUptime(procPath string) (uint64, error) {
+	content, err := os.ReadFile(filepath.Join(procPath, uptimeFile))
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("invalid uptime %q", string(content))
+	}
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid uptime %q: %v", string(content), err)
+	}
+	return uint64(math.Round(uptime * 1e9)), nil
+}
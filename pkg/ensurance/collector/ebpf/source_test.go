@@ -0,0 +1,39 @@
+package ebpf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedstatSource(t *testing.T) {
+	cgroupRoot := t.TempDir()
+	dir := filepath.Join(cgroupRoot, cpuSubsystem, "kubepods", "burstable", "poduid-1", "c1")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	// The task 1203 exits before reading its schedstat
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, tasksFile), []byte("1201\n1202\n1203\n"), 0644))
+
+	s := newSchedstatSource("testdata/proc", cgroupRoot)
+	stat, err := s.Stat("/kubepods/burstable/poduid-1/c1")
+	assert.NoError(t, err)
+	assert.Equal(t, CgroupStat{
+		RunQueueTime:  600000000,
+		RunQueueCount: 1400,
+		// The lifetime of the tasks are 750.24s and 50.24s
+		OffCPUTime: 750240000000 - 3000000000 + 50240000000 - 1100000000,
+	}, stat)
+
+	_, err = s.Stat("/kubepods/burstable/poduid-1/c2")
+	assert.Error(t, err)
+}
+
+func TestReadStartTime(t *testing.T) {
+	startTime, err := readStartTime("testdata/proc", 1201)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7000000000000), startTime)
+
+	_, err = readStartTime("testdata/proc", 1203)
+	assert.Error(t, err)
+}
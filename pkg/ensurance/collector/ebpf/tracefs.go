@@ -0,0 +1,79 @@
+package ebpf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tracefsDirs are the mount points of tracefs under sys, the debugfs one is for the kernels before 4.1
+var tracefsDirs = []string{"kernel/tracing", "kernel/debug/tracing"}
+
+// tracepointField is the layout of a field in the tracepoint record
+type tracepointField struct {
+	Offset int
+	Size   int
+}
+
+// readTracepointFormat reads the record layout of a tracepoint from tracefs, so the programs don't depend on BTF to
+// find the fields
+func readTracepointFormat(sysPath, group, name string) (map[string]tracepointField, error) {
+	var lastErr error
+	for _, dir := range tracefsDirs {
+		content, err := os.ReadFile(filepath.Join(sysPath, dir, "events", group, name, "format"))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return parseTracepointFormat(string(content))
+	}
+	return nil, lastErr
+}
+
+// parseTracepointFormat parses the format of a tracepoint, the fields are like
+//
+//	field:pid_t prev_pid;	offset:24;	size:4;	signed:1;
+func parseTracepointFormat(content string) (map[string]tracepointField, error) {
+	fields := map[string]tracepointField{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "field:") {
+			continue
+		}
+
+		var name string
+		var field tracepointField
+		for _, item := range strings.Split(line, ";") {
+			kv := strings.SplitN(strings.TrimSpace(item), ":", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			var err error
+			switch kv[0] {
+			case "field":
+				declaration := strings.Fields(kv[1])
+				if len(declaration) == 0 {
+					return nil, fmt.Errorf("invalid tracepoint field %q", line)
+				}
+				name = declaration[len(declaration)-1]
+				if i := strings.Index(name, "["); i >= 0 {
+					name = name[:i]
+				}
+			case "offset":
+				field.Offset, err = strconv.Atoi(kv[1])
+			case "size":
+				field.Size, err = strconv.Atoi(kv[1])
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid tracepoint field %q: %v", line, err)
+			}
+		}
+		if name == "" || field.Size == 0 {
+			return nil, fmt.Errorf("invalid tracepoint field %q", line)
+		}
+		fields[name] = field
+	}
+	return fields, nil
+}
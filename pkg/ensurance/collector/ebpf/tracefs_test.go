@@ -0,0 +1,27 @@
+package ebpf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadTracepointFormat(t *testing.T) {
+	format, err := readTracepointFormat("testdata/sys", "sched", "sched_switch")
+	assert.NoError(t, err)
+	assert.Equal(t, tracepointField{Offset: 24, Size: 4}, format["prev_pid"])
+	assert.Equal(t, tracepointField{Offset: 32, Size: 8}, format["prev_state"])
+	assert.Equal(t, tracepointField{Offset: 40, Size: 16}, format["next_comm"])
+	assert.Equal(t, tracepointField{Offset: 56, Size: 4}, format["next_pid"])
+
+	// The tracefs is mounted under debugfs on the old kernels
+	format, err = readTracepointFormat("testdata/sys", "sched", "sched_wakeup")
+	assert.NoError(t, err)
+	assert.Equal(t, tracepointField{Offset: 24, Size: 4}, format["pid"])
+
+	_, err = readTracepointFormat("testdata/sys", "sched", "sched_wakeup_new")
+	assert.Error(t, err)
+
+	_, err = parseTracepointFormat("\tfield:pid_t pid;\toffset:abc;\tsize:4;\tsigned:1;\n")
+	assert.Error(t, err)
+}
@@ -2,6 +2,8 @@ package nodelocal
 
 import (
 	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/shirou/gopsutil/disk"
@@ -59,11 +61,12 @@ func collectDiskIO(nodeLocalContext *nodeLocalContext) (map[string][]common.Time
 		currentDiskStates[key] = DiskState{stat: v, timestamp: now}
 		if vv, ok := nodeState.latestDiskStates[key]; ok {
 			diskIOUsage := calculateDiskIO(vv, currentDiskStates[key])
-			diskReadKiBpsTimeSeries = append(diskReadKiBpsTimeSeries, common.TimeSeries{Labels: []common.Label{{Name: "diskName", Value: key}}, Samples: []common.Sample{{Value: diskIOUsage.DiskReadKiBps, Timestamp: now.Unix()}}})
-			diskWriteKiBpsTimeSeries = append(diskWriteKiBpsTimeSeries, common.TimeSeries{Labels: []common.Label{{Name: "diskName", Value: key}}, Samples: []common.Sample{{Value: diskIOUsage.DiskWriteKiBps, Timestamp: now.Unix()}}})
-			diskReadIOpsTimeSeries = append(diskReadIOpsTimeSeries, common.TimeSeries{Labels: []common.Label{{Name: "diskName", Value: key}}, Samples: []common.Sample{{Value: diskIOUsage.DiskReadIOps, Timestamp: now.Unix()}}})
-			diskWriteIOpsTimeSeries = append(diskWriteIOpsTimeSeries, common.TimeSeries{Labels: []common.Label{{Name: "diskName", Value: key}}, Samples: []common.Sample{{Value: diskIOUsage.DiskWriteIOps, Timestamp: now.Unix()}}})
-			diskUtilizationTimeSeries = append(diskUtilizationTimeSeries, common.TimeSeries{Labels: []common.Label{{Name: "diskName", Value: key}}, Samples: []common.Sample{{Value: diskIOUsage.Utilization, Timestamp: now.Unix()}}})
+			labels := []common.Label{{Name: "diskName", Value: key}, {Name: common.LabelNameDevice, Value: sysBlockDeviceNumber(sysBlockPath, key)}}
+			diskReadKiBpsTimeSeries = append(diskReadKiBpsTimeSeries, common.TimeSeries{Labels: labels, Samples: []common.Sample{{Value: diskIOUsage.DiskReadKiBps, Timestamp: now.Unix()}}})
+			diskWriteKiBpsTimeSeries = append(diskWriteKiBpsTimeSeries, common.TimeSeries{Labels: labels, Samples: []common.Sample{{Value: diskIOUsage.DiskWriteKiBps, Timestamp: now.Unix()}}})
+			diskReadIOpsTimeSeries = append(diskReadIOpsTimeSeries, common.TimeSeries{Labels: labels, Samples: []common.Sample{{Value: diskIOUsage.DiskReadIOps, Timestamp: now.Unix()}}})
+			diskWriteIOpsTimeSeries = append(diskWriteIOpsTimeSeries, common.TimeSeries{Labels: labels, Samples: []common.Sample{{Value: diskIOUsage.DiskWriteIOps, Timestamp: now.Unix()}}})
+			diskUtilizationTimeSeries = append(diskUtilizationTimeSeries, common.TimeSeries{Labels: labels, Samples: []common.Sample{{Value: diskIOUsage.Utilization, Timestamp: now.Unix()}}})
 		}
 	}
 
@@ -92,6 +95,15 @@ func sysBlockDevices(sysBlockPath string) ([]string, error) {
 	return devices, nil
 }
 
+// sysBlockDeviceNumber reads the major:minor of the device from /sys/block/<dev>/dev, it's empty if unknown.
+func sysBlockDeviceNumber(sysBlockPath string, device string) string {
+	data, err := ioutil.ReadFile(filepath.Join(sysBlockPath, device, "dev"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
 // calculateDiskIO calculate disk io usage
 func calculateDiskIO(stat1 DiskState, stat2 DiskState) DiskIOUsage {
 
@@ -42,11 +42,13 @@ type NodeResourceTopology struct {
 	nodeLister  corelisters.NodeLister
 	client      kubeclient.Interface
 	craneClient craneclientset.Interface
+	// cpuLayout returns the layout of CPUs of the cpu manager, which is published in the attributes
+	cpuLayout func() map[string]string
 }
 
 func NewNodeResourceTopology(nodeName, sysPath string,
 	nrtLister topologylisters.NodeResourceTopologyLister, nodeLister corelisters.NodeLister,
-	client kubeclient.Interface, craneClient craneclientset.Interface,
+	client kubeclient.Interface, craneClient craneclientset.Interface, cpuLayout func() map[string]string,
 ) *NodeResourceTopology {
 	return &NodeResourceTopology{
 		nodeName:    nodeName,
@@ -55,6 +57,7 @@ func NewNodeResourceTopology(nodeName, sysPath string,
 		nodeLister:  nodeLister,
 		client:      client,
 		craneClient: craneClient,
+		cpuLayout:   cpuLayout,
 	}
 }
 
@@ -82,6 +85,11 @@ func (n *NodeResourceTopology) Collect() (map[string][]common.TimeSeries, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build node resource topology: %v", err)
 	}
+	if n.cpuLayout != nil {
+		for key, value := range n.cpuLayout() {
+			newNrt.Attributes[key] = value
+		}
+	}
 
 	if err = CreateOrUpdateNodeResourceTopology(n.craneClient, nrt, newNrt); err != nil {
 		return nil, fmt.Errorf("failed to create or update node resource topology: %v", err)
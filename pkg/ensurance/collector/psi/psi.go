@@ -0,0 +1,210 @@
+package psi
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/cgroup"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const (
+	// DefaultProcPath is the path of proc, /proc/pressure is not namespaced so it's always the pressure of the node
+	DefaultProcPath = "/proc"
+
+	pressureDir        = "pressure"
+	pressureFileSuffix = ".pressure"
+	podMetricPrefix    = "pod_"
+)
+
+var (
+	pressureResources = []string{"cpu", "memory", "io"}
+	pressureKinds     = []string{"some", "full"}
+	pressureWindows   = []string{"avg10", "avg60", "avg300"}
+)
+
+var psiMetrics = []types.MetricName{
+	types.MetricNameCpuPressureSomeAvg10, types.MetricNameCpuPressureSomeAvg60, types.MetricNameCpuPressureSomeAvg300,
+	types.MetricNameCpuPressureFullAvg10, types.MetricNameCpuPressureFullAvg60, types.MetricNameCpuPressureFullAvg300,
+	types.MetricNameMemoryPressureSomeAvg10, types.MetricNameMemoryPressureSomeAvg60, types.MetricNameMemoryPressureSomeAvg300,
+	types.MetricNameMemoryPressureFullAvg10, types.MetricNameMemoryPressureFullAvg60, types.MetricNameMemoryPressureFullAvg300,
+	types.MetricNameIOPressureSomeAvg10, types.MetricNameIOPressureSomeAvg60, types.MetricNameIOPressureSomeAvg300,
+	types.MetricNameIOPressureFullAvg10, types.MetricNameIOPressureFullAvg60, types.MetricNameIOPressureFullAvg300,
+
+	types.MetricNamePodCpuPressureSomeAvg10, types.MetricNamePodCpuPressureSomeAvg60, types.MetricNamePodCpuPressureSomeAvg300,
+	types.MetricNamePodCpuPressureFullAvg10, types.MetricNamePodCpuPressureFullAvg60, types.MetricNamePodCpuPressureFullAvg300,
+	types.MetricNamePodMemoryPressureSomeAvg10, types.MetricNamePodMemoryPressureSomeAvg60, types.MetricNamePodMemoryPressureSomeAvg300,
+	types.MetricNamePodMemoryPressureFullAvg10, types.MetricNamePodMemoryPressureFullAvg60, types.MetricNamePodMemoryPressureFullAvg300,
+	types.MetricNamePodIOPressureSomeAvg10, types.MetricNamePodIOPressureSomeAvg60, types.MetricNamePodIOPressureSomeAvg300,
+	types.MetricNamePodIOPressureFullAvg10, types.MetricNamePodIOPressureFullAvg60, types.MetricNamePodIOPressureFullAvg300,
+}
+
+// Pressure is the share of time in percentage in which some or all tasks stalled on a resource, keyed by the kind
+// (some or full) and then the window (avg10, avg60 or avg300)
+type Pressure map[string]map[string]float64
+
+// PSI collects the pressure stall information of the node from /proc/pressure, and of the pods from the pressure files
+// of the pod cgroups on cgroup v2 nodes
+type PSI struct {
+	name         types.CollectType
+	podLister    corelisters.PodLister
+	cgroupDriver string
+	procPath     string
+	cgroupRoot   string
+	// supported is whether the kernel is built with CONFIG_PSI and not booted with psi=0, it's detected once since
+	// the pressure files never show up after boot
+	supported bool
+}
+
+func NewPSICollector(podLister corelisters.PodLister, cgroupDriver, procPath, sysPath string) *PSI {
+	klog.V(2).Infof("New PSI collector, proc path %s, sys path %s", procPath, sysPath)
+
+	_, err := os.Stat(filepath.Join(procPath, pressureDir))
+	if err != nil {
+		klog.Warningf("PSI is not supported by the kernel, skip collecting the pressure: %v", err)
+	}
+
+	return &PSI{
+		name:         types.PSICollectorType,
+		podLister:    podLister,
+		cgroupDriver: cgroupDriver,
+		procPath:     procPath,
+		cgroupRoot:   filepath.Join(sysPath, "fs", "cgroup"),
+		supported:    err == nil,
+	}
+}
+
+func (p *PSI) GetType() types.CollectType {
+	return p.name
+}
+
+func (p *PSI) Collect() (map[string][]common.TimeSeries, error) {
+	klog.V(6).Infof("PSI collecting")
+
+	var now = time.Now()
+	var data = make(map[string][]common.TimeSeries, len(psiMetrics))
+	if !p.supported {
+		return data, nil
+	}
+	for _, resource := range pressureResources {
+		pressure, err := ReadPressure(filepath.Join(p.procPath, pressureDir, resource))
+		if err != nil {
+			return nil, err
+		}
+		addPressureSamples(data, "", resource, pressure, nil, now)
+	}
+
+	if !cgroup.IsUnified(p.cgroupRoot) {
+		return data, nil
+	}
+
+	allPods, err := p.podLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list pods: %v", err)
+		return data, nil
+	}
+	for _, pod := range allPods {
+		cgroupPath := utils.GetCgroupPath(pod, p.cgroupDriver)
+		if cgroupPath == "" {
+			continue
+		}
+		podLabels := []common.Label{
+			{Name: common.LabelNamePodName, Value: pod.Name},
+			{Name: common.LabelNamePodNamespace, Value: pod.Namespace},
+			{Name: common.LabelNamePodUid, Value: string(pod.UID)},
+		}
+		for _, resource := range pressureResources {
+			pressure, err := ReadPressure(filepath.Join(p.cgroupRoot, cgroupPath, resource+pressureFileSuffix))
+			if err != nil {
+				klog.V(4).Infof("Failed to read %s pressure of pod %s: %v", resource, klog.KObj(pod), err)
+				continue
+			}
+			addPressureSamples(data, podMetricPrefix, resource, pressure, podLabels, now)
+		}
+	}
+
+	return data, nil
+}
+
+func (p *PSI) Stop() error {
+	return nil
+}
+
+func CheckMetricNameExist(name string) bool {
+	for _, m := range psiMetrics {
+		if string(m) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadPressure reads a pressure file, such as /proc/pressure/cpu or cpu.pressure of a cgroup
+func ReadPressure(file string) (Pressure, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePressure(string(content))
+}
+
+// ParsePressure parses the content of a pressure file, which is like
+//
+//	some avg10=0.12 avg60=0.34 avg300=0.56 total=123456
+//	full avg10=0.00 avg60=0.01 avg300=0.02 total=1234
+//
+// The full line of cpu is only provided by kernel 5.13 and later
+func ParsePressure(content string) (Pressure, error) {
+	pressure := Pressure{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		averages := map[string]float64{}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid pressure line %q", line)
+			}
+			// total is the accumulated stall time in microseconds, not an average
+			if kv[0] == "total" {
+				continue
+			}
+			value, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pressure line %q: %v", line, err)
+			}
+			averages[kv[0]] = value
+		}
+		pressure[fields[0]] = averages
+	}
+	return pressure, nil
+}
+
+func addPressureSamples(data map[string][]common.TimeSeries, prefix, resource string, pressure Pressure, labels []common.Label, now time.Time) {
+	for _, kind := range pressureKinds {
+		averages, ok := pressure[kind]
+		if !ok {
+			continue
+		}
+		for _, window := range pressureWindows {
+			value, ok := averages[window]
+			if !ok {
+				continue
+			}
+			key := fmt.Sprintf("%s%s_pressure_%s_%s", prefix, resource, kind, window)
+			data[key] = append(data[key], common.TimeSeries{Labels: labels, Samples: []common.Sample{{Value: value, Timestamp: now.Unix()}}})
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package psi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+)
+
+func TestParsePressure(t *testing.T) {
+	pressure, err := ParsePressure("some avg10=1.50 avg60=0.80 avg300=0.20 total=123456\nfull avg10=0.50 avg60=0.00 avg300=0.00 total=1234\n")
+	assert.NoError(t, err)
+	assert.Equal(t, Pressure{
+		"some": {"avg10": 1.5, "avg60": 0.8, "avg300": 0.2},
+		"full": {"avg10": 0.5, "avg60": 0, "avg300": 0},
+	}, pressure)
+
+	_, err = ParsePressure("some avg10\n")
+	assert.Error(t, err)
+	_, err = ParsePressure("some avg10=abc\n")
+	assert.Error(t, err)
+}
+
+func TestCollect(t *testing.T) {
+	procPath, sysPath := t.TempDir(), t.TempDir()
+	cgroupRoot := filepath.Join(sysPath, "fs", "cgroup")
+	assert.NoError(t, os.MkdirAll(filepath.Join(procPath, pressureDir), 0755))
+	for _, resource := range pressureResources {
+		content := "some avg10=10.00 avg60=5.00 avg300=1.00 total=100\nfull avg10=2.00 avg60=1.00 avg300=0.50 total=10\n"
+		// The cpu full line is missing before kernel 5.13
+		if resource == "cpu" {
+			content = "some avg10=10.00 avg60=5.00 avg300=1.00 total=100\n"
+		}
+		assert.NoError(t, os.WriteFile(filepath.Join(procPath, pressureDir, resource), []byte(content), 0644))
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "default", UID: "uid-1"},
+		Status:     v1.PodStatus{QOSClass: v1.PodQOSBestEffort},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.NoError(t, indexer.Add(pod))
+	p := NewPSICollector(corelisters.NewPodLister(indexer), "systemd", procPath, sysPath)
+
+	// The pod pressure is not collected on cgroup v1 nodes
+	assert.NoError(t, os.MkdirAll(cgroupRoot, 0755))
+	data, err := p.Collect()
+	assert.NoError(t, err)
+	assert.Len(t, data, 15)
+	assert.Equal(t, 10.0, data[string(types.MetricNameCpuPressureSomeAvg10)][0].Samples[0].Value)
+	assert.Equal(t, 0.5, data[string(types.MetricNameIOPressureFullAvg300)][0].Samples[0].Value)
+	_, ok := data[string(types.MetricNameCpuPressureFullAvg10)]
+	assert.False(t, ok)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(cgroupRoot, "cgroup.controllers"), []byte("cpu io memory"), 0644))
+	podDir := filepath.Join(cgroupRoot, "kubepods.slice", "kubepods-besteffort.slice", "kubepods-besteffort-poduid_1.slice")
+	assert.NoError(t, os.MkdirAll(podDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(podDir, "memory.pressure"), []byte("some avg10=30.00 avg60=20.00 avg300=10.00 total=100\nfull avg10=25.00 avg60=15.00 avg300=5.00 total=10\n"), 0644))
+
+	data, err = p.Collect()
+	assert.NoError(t, err)
+	assert.Len(t, data, 21)
+	series := data[string(types.MetricNamePodMemoryPressureFullAvg10)]
+	assert.Len(t, series, 1)
+	assert.Equal(t, 25.0, series[0].Samples[0].Value)
+	assert.Contains(t, series[0].Labels, common.Label{Name: common.LabelNamePodUid, Value: "uid-1"})
+
+	for key := range data {
+		assert.True(t, CheckMetricNameExist(key), key)
+	}
+
+	// The kernel without psi
+	assert.NoError(t, os.RemoveAll(filepath.Join(procPath, pressureDir)))
+	_, err = p.Collect()
+	assert.Error(t, err)
+	p = NewPSICollector(corelisters.NewPodLister(indexer), "systemd", procPath, sysPath)
+	data, err = p.Collect()
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+}
@@ -9,6 +9,7 @@ const (
 	MetricsServerCollectorType        CollectType = "metrics-server"
 	NodeResourceCollectorType         CollectType = "node-resource"
 	NodeResourceTopologyCollectorType CollectType = "node-resource-topology"
+	PSICollectorType                  CollectType = "psi"
 )
 
 type MetricName string
@@ -30,7 +31,7 @@ const (
 	MetricDiskReadKiBPS   MetricName = "disk_read_kibps"
 	MetricDiskWriteKiBPS  MetricName = "disk_write_kibps"
 	MetricDiskReadIOPS    MetricName = "disk_read_iops"
-	MetricDiskWriteIOPS   MetricName = "disk_read_iops"
+	MetricDiskWriteIOPS   MetricName = "disk_write_iops"
 	MetricDiskUtilization MetricName = "disk_read_utilization"
 
 	MetricNetworkReceiveKiBPS MetricName = "network_receive_kibps"
@@ -52,4 +53,69 @@ const (
 
 	MetricNameContainerMemTotalUsage       MetricName = "container_mem_total_usage"
 	MetricNameExtResContainerMemTotalUsage MetricName = "ext_res_container_mem_total_usage"
+
+	// Attention: these values are bytesIncrease/timeIncrease in KiB, summed over all the disks
+	MetricNameContainerDiskReadKiBPS  MetricName = "container_disk_read_kibps"
+	MetricNameContainerDiskWriteKiBPS MetricName = "container_disk_write_kibps"
+	// Attention: these values are the same as above but per block device, labeled by the major:minor of the device
+	MetricNameContainerDiskDeviceReadKiBPS  MetricName = "container_disk_device_read_kibps"
+	MetricNameContainerDiskDeviceWriteKiBPS MetricName = "container_disk_device_write_kibps"
+
+	// Attention: these values are kilobits per second of the pod network namespace, same as the node network metrics
+	MetricNamePodNetworkReceiveKiBPS MetricName = "pod_network_receive_kibps"
+	MetricNamePodNetworkSentKiBPS    MetricName = "pod_network_sent_kibps"
+
+	// Attention: these values are collected by the ebpf collector from the tasks of the container cgroup.
+	// container_runqueue_latency_us is the average time in microseconds the tasks waited on the run queue before running,
+	// container_off_cpu_time is the time in seconds per second the tasks were sleeping or blocked, summed over the tasks,
+	// container_cpi is the cycles per instruction of the container
+	MetricNameContainerRunQueueLatency MetricName = "container_runqueue_latency_us"
+	MetricNameContainerOffCPUTime      MetricName = "container_off_cpu_time"
+	MetricNameContainerCPI             MetricName = "container_cpi"
+
+	// Attention: these values are the percentages of time in which some or all tasks stalled on the resource, read from
+	// /proc/pressure, averaged over 10s, 60s and 300s
+	MetricNameCpuPressureSomeAvg10  MetricName = "cpu_pressure_some_avg10"
+	MetricNameCpuPressureSomeAvg60  MetricName = "cpu_pressure_some_avg60"
+	MetricNameCpuPressureSomeAvg300 MetricName = "cpu_pressure_some_avg300"
+	MetricNameCpuPressureFullAvg10  MetricName = "cpu_pressure_full_avg10"
+	MetricNameCpuPressureFullAvg60  MetricName = "cpu_pressure_full_avg60"
+	MetricNameCpuPressureFullAvg300 MetricName = "cpu_pressure_full_avg300"
+
+	MetricNameMemoryPressureSomeAvg10  MetricName = "memory_pressure_some_avg10"
+	MetricNameMemoryPressureSomeAvg60  MetricName = "memory_pressure_some_avg60"
+	MetricNameMemoryPressureSomeAvg300 MetricName = "memory_pressure_some_avg300"
+	MetricNameMemoryPressureFullAvg10  MetricName = "memory_pressure_full_avg10"
+	MetricNameMemoryPressureFullAvg60  MetricName = "memory_pressure_full_avg60"
+	MetricNameMemoryPressureFullAvg300 MetricName = "memory_pressure_full_avg300"
+
+	MetricNameIOPressureSomeAvg10  MetricName = "io_pressure_some_avg10"
+	MetricNameIOPressureSomeAvg60  MetricName = "io_pressure_some_avg60"
+	MetricNameIOPressureSomeAvg300 MetricName = "io_pressure_some_avg300"
+	MetricNameIOPressureFullAvg10  MetricName = "io_pressure_full_avg10"
+	MetricNameIOPressureFullAvg60  MetricName = "io_pressure_full_avg60"
+	MetricNameIOPressureFullAvg300 MetricName = "io_pressure_full_avg300"
+
+	// Attention: these values are the same as the node pressure metrics, read from the pressure files of the pod cgroup,
+	// which are only supported by cgroup v2
+	MetricNamePodCpuPressureSomeAvg10  MetricName = "pod_cpu_pressure_some_avg10"
+	MetricNamePodCpuPressureSomeAvg60  MetricName = "pod_cpu_pressure_some_avg60"
+	MetricNamePodCpuPressureSomeAvg300 MetricName = "pod_cpu_pressure_some_avg300"
+	MetricNamePodCpuPressureFullAvg10  MetricName = "pod_cpu_pressure_full_avg10"
+	MetricNamePodCpuPressureFullAvg60  MetricName = "pod_cpu_pressure_full_avg60"
+	MetricNamePodCpuPressureFullAvg300 MetricName = "pod_cpu_pressure_full_avg300"
+
+	MetricNamePodMemoryPressureSomeAvg10  MetricName = "pod_memory_pressure_some_avg10"
+	MetricNamePodMemoryPressureSomeAvg60  MetricName = "pod_memory_pressure_some_avg60"
+	MetricNamePodMemoryPressureSomeAvg300 MetricName = "pod_memory_pressure_some_avg300"
+	MetricNamePodMemoryPressureFullAvg10  MetricName = "pod_memory_pressure_full_avg10"
+	MetricNamePodMemoryPressureFullAvg60  MetricName = "pod_memory_pressure_full_avg60"
+	MetricNamePodMemoryPressureFullAvg300 MetricName = "pod_memory_pressure_full_avg300"
+
+	MetricNamePodIOPressureSomeAvg10  MetricName = "pod_io_pressure_some_avg10"
+	MetricNamePodIOPressureSomeAvg60  MetricName = "pod_io_pressure_some_avg60"
+	MetricNamePodIOPressureSomeAvg300 MetricName = "pod_io_pressure_some_avg300"
+	MetricNamePodIOPressureFullAvg10  MetricName = "pod_io_pressure_full_avg10"
+	MetricNamePodIOPressureFullAvg60  MetricName = "pod_io_pressure_full_avg60"
+	MetricNamePodIOPressureFullAvg300 MetricName = "pod_io_pressure_full_avg300"
 )
@@ -2,7 +2,6 @@ package executor
 
 import (
 	"fmt"
-	"sync"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
@@ -10,7 +9,6 @@ import (
 	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
 	"github.com/gocrane/crane/pkg/ensurance/executor/sort"
 	cruntime "github.com/gocrane/crane/pkg/ensurance/runtime"
-	"github.com/gocrane/crane/pkg/metrics"
 	"github.com/gocrane/crane/pkg/utils"
 )
 
@@ -31,7 +29,9 @@ var cpuUsage = metric{
 
 	Evictable:       true,
 	EvictQuantified: true,
-	EvictFunc:       cpuUsageEvictPod,
+	EvictFunc:       evictOnePod(estimateCPUUsage),
+
+	EstimateFunc: estimateCPUUsage,
 }
 
 func throttleOnePodCpu(ctx *ExecuteContext, index int, ThrottleDownPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
@@ -198,58 +198,18 @@ func restoreOnePodCpu(ctx *ExecuteContext, index int, ThrottleUpPods ThrottlePod
 	return
 }
 
-func cpuUsageEvictPod(wg *sync.WaitGroup, ctx *ExecuteContext, index int, totalReleasedResource *ReleaseResource, EvictPods EvictPods) (errPodKeys []string, released ReleaseResource) {
-	wg.Add(1)
-
-	// Calculate release resources
-	released = releaseCPUUsage(EvictPods[index], 0.0, 0.0)
-	totalReleasedResource.Add(released)
-
-	go func(evictPod podinfo.PodContext) {
-		defer wg.Done()
-
-		pod, err := ctx.PodLister.Pods(evictPod.Key.Namespace).Get(evictPod.Key.Name)
-		if err != nil {
-			errPodKeys = append(errPodKeys, "not found ", evictPod.Key.String())
-			return
-		}
-		klog.Warningf("Evicting pod %v", evictPod.Key)
-		err = utils.EvictPodWithGracePeriod(ctx.Client, pod, evictPod.DeletionGracePeriodSeconds)
-		if err != nil {
-			errPodKeys = append(errPodKeys, "evict failed ", evictPod.Key.String())
-			klog.Warningf("Failed to evict pod %s: %v", evictPod.Key.String(), err)
-			return
-		}
-		metrics.ExecutorEvictCountsInc()
-
-		klog.Warningf("Pod %s is evicted", klog.KObj(pod))
-	}(EvictPods[index])
-	return
+// estimateCPUUsage estimates the cpu usage released by the pod, the cpu request and limit of the containers which keep
+// the quota from being lowered in the throttle are not considered
+func estimateCPUUsage(pod podinfo.PodContext) ReleaseResource {
+	if pod.ActionType == podinfo.ThrottleDown {
+		return releaseCPUUsage(pod, pod.PodCPUUsage*(1.0-float64(pod.CPUThrottle.StepCPURatio)/MaxRatio), pod.PodCPUUsage)
+	}
+	return releaseCPUUsage(pod, 0.0, 0.0)
 }
 
 func releaseCPUUsage(pod podinfo.PodContext, containerCPUQuotaNew, currentContainerCpuUsage float64) ReleaseResource {
 	if pod.ActionType == podinfo.Evict {
-		return ReleaseResource{
-			CpuUsage: pod.PodCPUUsage * CpuQuotaCoefficient,
-		}
-	}
-	if pod.ActionType == podinfo.ThrottleDown {
-		reduction := (currentContainerCpuUsage - containerCPUQuotaNew) * CpuQuotaCoefficient
-		if reduction > 0 {
-			return ReleaseResource{
-				CpuUsage: reduction,
-			}
-		}
-		return ReleaseResource{}
-	}
-	if pod.ActionType == podinfo.ThrottleUp {
-		reduction := (containerCPUQuotaNew - currentContainerCpuUsage) * CpuQuotaCoefficient
-		if reduction > 0 {
-			return ReleaseResource{
-				CpuUsage: reduction,
-			}
-		}
-		return ReleaseResource{}
+		currentContainerCpuUsage = pod.PodCPUUsage
 	}
-	return ReleaseResource{}
+	return releaseByLimit(pod, CpuUsage, currentContainerCpuUsage*CpuQuotaCoefficient, containerCPUQuotaNew*CpuQuotaCoefficient)
 }
@@ -0,0 +1,208 @@
+package executor
+
+import (
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/ensurance/cgroup"
+	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	"github.com/gocrane/crane/pkg/ensurance/executor/sort"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const (
+	// DiskIOStepRatio is the step of the disk io limit for once down-size or up-size
+	DiskIOStepRatio = 20.0
+	// MinDiskIOKiBPS is the min disk io limit, so that the throttled pods can make progress
+	MinDiskIOKiBPS = 1024.0
+	// MaxUpDiskIOKiBPS is the disk io limit above which the limit is removed when restoring
+	MaxUpDiskIOKiBPS = 1024 * 1024.0
+)
+
+func init() {
+	registerMetricMap(diskReadKiBPS)
+	registerMetricMap(diskWriteKiBPS)
+}
+
+var diskReadKiBPS = metric{
+	Name:           DiskReadKiBPS,
+	ActionPriority: 3,
+	Sortable:       true,
+	SortFunc:       sort.DiskReadSort,
+
+	Throttleable:       true,
+	ThrottleQuantified: true,
+	ThrottleFunc:       throttleOnePodDiskIO(DiskReadKiBPS),
+	RestoreFunc:        restoreOnePodDiskIO(DiskReadKiBPS),
+
+	Evictable:       true,
+	EvictQuantified: true,
+	EvictFunc:       evictOnePod(estimateDiskIO(DiskReadKiBPS)),
+
+	EstimateFunc: estimateDiskIO(DiskReadKiBPS),
+}
+
+var diskWriteKiBPS = metric{
+	Name:           DiskWriteKiBPS,
+	ActionPriority: 3,
+	Sortable:       true,
+	SortFunc:       sort.DiskWriteSort,
+
+	Throttleable:       true,
+	ThrottleQuantified: true,
+	ThrottleFunc:       throttleOnePodDiskIO(DiskWriteKiBPS),
+	RestoreFunc:        restoreOnePodDiskIO(DiskWriteKiBPS),
+
+	Evictable:       true,
+	EvictQuantified: true,
+	EvictFunc:       evictOnePod(estimateDiskIO(DiskWriteKiBPS)),
+
+	EstimateFunc: estimateDiskIO(DiskWriteKiBPS),
+}
+
+// throttleOnePodDiskIO limits the disk read or write bandwidth of the pod cgroup on the disk which decides the gap to a
+// step below its usage on the disk, the limit is in blkio.throttle.*_bps_device for cgroup v1 and io.max for cgroup v2.
+func throttleOnePodDiskIO(m WatermarkMetric) func(ctx *ExecuteContext, index int, ThrottleDownPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+	return func(ctx *ExecuteContext, index int, ThrottleDownPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+		pod, err := ctx.PodLister.Pods(ThrottleDownPods[index].Key.Namespace).Get(ThrottleDownPods[index].Key.Name)
+		if err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("pod %s not found", ThrottleDownPods[index].Key.String()))
+			return
+		}
+
+		cgroupPath := utils.GetCgroupPath(pod, ctx.CgroupDriver)
+		limits, err := cgroup.GetIOLimits(ctx.CgroupRoot, cgroupPath)
+		if err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("failed to get io limits for %s, error: %v", ThrottleDownPods[index].Key.String(), err))
+			return
+		}
+
+		usages := podDiskIOUsageByDevice(ThrottleDownPods[index], m)
+		device := podinfo.DiskIODevice(ctx.stateMap, string(m), usages)
+		for _, limit := range limits {
+			if limit.Device() != device {
+				continue
+			}
+
+			usage := usages[device]
+			limitNew := usage * (1.0 - DiskIOStepRatio/MaxRatio)
+			if limitNew < MinDiskIOKiBPS {
+				limitNew = MinDiskIOKiBPS
+			}
+			// Nothing to release if the pod is almost idle on the disk
+			if limitNew >= usage {
+				return
+			}
+
+			setDiskIOLimit(&limit, m, uint64(limitNew*1024))
+			if err = cgroup.SetIOLimit(ctx.CgroupRoot, cgroupPath, limit); err != nil {
+				errPodKeys = append(errPodKeys, fmt.Sprintf("failed to set io limit on device %s for %s, error: %v", device, ThrottleDownPods[index].Key.String(), err))
+				return
+			}
+			klog.V(4).Infof("ThrottleExecutor avoid pod %s, set %s limit %.2f KiB/s on device %s", klog.KObj(pod), m, limitNew, device)
+
+			released = releaseByLimit(ThrottleDownPods[index], m, usage, limitNew)
+			if len(released) != 0 {
+				klog.V(6).Infof("For pod %s, release %f %s", ThrottleDownPods[index].Key.String(), released[m], m)
+				totalReleasedResource.Add(released)
+			}
+		}
+		return
+	}
+}
+
+// restoreOnePodDiskIO raises the disk read or write limits of the pod cgroup by a step, the limit is removed if it's
+// raised above MaxUpDiskIOKiBPS. Only the disk which decides the gap counts in the released resource.
+func restoreOnePodDiskIO(m WatermarkMetric) func(ctx *ExecuteContext, index int, ThrottleUpPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+	return func(ctx *ExecuteContext, index int, ThrottleUpPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+		pod, err := ctx.PodLister.Pods(ThrottleUpPods[index].Key.Namespace).Get(ThrottleUpPods[index].Key.Name)
+		if err != nil {
+			errPodKeys = append(errPodKeys, "not found ", ThrottleUpPods[index].Key.String())
+			return
+		}
+
+		cgroupPath := utils.GetCgroupPath(pod, ctx.CgroupDriver)
+		limits, err := cgroup.GetIOLimits(ctx.CgroupRoot, cgroupPath)
+		if err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("failed to get io limits for %s, error: %v", ThrottleUpPods[index].Key.String(), err))
+			return
+		}
+
+		usages := podDiskIOUsageByDevice(ThrottleUpPods[index], m)
+		device := podinfo.DiskIODevice(ctx.stateMap, string(m), usages)
+		for _, limit := range limits {
+			current := diskIOLimit(limit, m)
+			// The device is not throttled
+			if current == 0 {
+				continue
+			}
+
+			limitNew := float64(current) / 1024 * (1.0 + DiskIOStepRatio/MaxRatio)
+			if limitNew > MaxUpDiskIOKiBPS {
+				limitNew = 0
+			}
+			setDiskIOLimit(&limit, m, uint64(limitNew*1024))
+			if err = cgroup.SetIOLimit(ctx.CgroupRoot, cgroupPath, limit); err != nil {
+				errPodKeys = append(errPodKeys, fmt.Sprintf("failed to set io limit on device %s for %s, error: %v", limit.Device(), ThrottleUpPods[index].Key.String(), err))
+				continue
+			}
+			klog.V(4).Infof("ThrottleExecutor restore pod %s, set %s limit %.2f KiB/s on device %s", klog.KObj(pod), m, limitNew, limit.Device())
+
+			// The released resource is unknown if the limit is removed, same as cpu quota
+			if limit.Device() == device && limitNew > 0 {
+				released = releaseByLimit(ThrottleUpPods[index], m, usages[device], limitNew)
+				klog.V(6).Infof("For pod %s, restore %f %s", ThrottleUpPods[index].Key.String(), released[m], m)
+				totalReleasedResource.Add(released)
+			}
+		}
+		return
+	}
+}
+
+func podDiskIOUsage(pod podinfo.PodContext, m WatermarkMetric) float64 {
+	if m == DiskReadKiBPS {
+		return pod.PodDiskReadKiBPS
+	}
+	return pod.PodDiskWriteKiBPS
+}
+
+func podDiskIOUsageByDevice(pod podinfo.PodContext, m WatermarkMetric) map[string]float64 {
+	if m == DiskReadKiBPS {
+		return pod.PodDiskReadKiBPSByDevice
+	}
+	return pod.PodDiskWriteKiBPSByDevice
+}
+
+func diskIOLimit(limit cgroup.DeviceIOLimit, m WatermarkMetric) uint64 {
+	if m == DiskReadKiBPS {
+		return limit.ReadBps
+	}
+	return limit.WriteBps
+}
+
+func setDiskIOLimit(limit *cgroup.DeviceIOLimit, m WatermarkMetric, bps uint64) {
+	if m == DiskReadKiBPS {
+		limit.ReadBps = bps
+	} else {
+		limit.WriteBps = bps
+	}
+}
+
+// estimateDiskIO estimates the disk read or write bandwidth released by the pod
+func estimateDiskIO(m WatermarkMetric) func(pod podinfo.PodContext) ReleaseResource {
+	return func(pod podinfo.PodContext) ReleaseResource {
+		if pod.ActionType == podinfo.ThrottleDown {
+			limitNew := podDiskIOUsage(pod, m) * (1.0 - DiskIOStepRatio/MaxRatio)
+			if limitNew < MinDiskIOKiBPS {
+				limitNew = MinDiskIOKiBPS
+			}
+			return releaseDiskIO(pod, m, limitNew)
+		}
+		return releaseDiskIO(pod, m, 0.0)
+	}
+}
+
+func releaseDiskIO(pod podinfo.PodContext, m WatermarkMetric, limitNew float64) ReleaseResource {
+	return releaseByLimit(pod, m, podDiskIOUsage(pod, m), limitNew)
+}
@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/common"
+	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestThrottleAndRestoreDiskIO(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "default", UID: "uid-1"},
+		Status:     v1.PodStatus{QOSClass: v1.PodQOSBestEffort},
+	}
+	ctx := newTestExecuteContext(t, pod)
+	root := ctx.CgroupRoot
+	dir := filepath.Join(root, "blkio", "kubepods", "besteffort", "poduid-1")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "blkio.throttle.io_service_bytes"), []byte("8:0 Read 0\n8:0 Write 3072\n8:16 Read 0\n8:16 Write 1024\nTotal 4096\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "blkio.throttle.read_bps_device"), []byte(""), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "blkio.throttle.write_bps_device"), []byte(""), 0644))
+
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	// sdb is the busiest disk of the node, which decides the gap
+	ctx.stateMap = map[string][]common.TimeSeries{
+		string(DiskWriteKiBPS): {
+			{Labels: []common.Label{{Name: "diskName", Value: "sda"}, {Name: common.LabelNameDevice, Value: "8:0"}}, Samples: []common.Sample{{Value: 8192}}},
+			{Labels: []common.Label{{Name: "diskName", Value: "sdb"}, {Name: common.LabelNameDevice, Value: "8:16"}}, Samples: []common.Sample{{Value: 10240}}},
+		},
+	}
+	usages := map[string]float64{"8:0": 7680, "8:16": 2560}
+
+	// Only the busiest disk is throttled and counted, though the pod writes more on the other one
+	total := ReleaseResource{}
+	errKeys, released := metricMap[DiskWriteKiBPS].ThrottleFunc(ctx, 0, ThrottlePods{{Key: key, PodDiskWriteKiBPS: 2560, PodDiskWriteKiBPSByDevice: usages, ActionType: podinfo.ThrottleDown}}, &total)
+	assert.Empty(t, errKeys)
+	assert.InDelta(t, 512.0, released[DiskWriteKiBPS], 1e-6)
+	writeBps, _ := os.ReadFile(filepath.Join(dir, "blkio.throttle.write_bps_device"))
+	assert.Equal(t, "8:16 2097152", string(writeBps))
+
+	// An almost idle pod is not throttled
+	errKeys, released = metricMap[DiskReadKiBPS].ThrottleFunc(ctx, 0, ThrottlePods{{Key: key, PodDiskReadKiBPS: 512, PodDiskReadKiBPSByDevice: map[string]float64{"8:0": 512}, ActionType: podinfo.ThrottleDown}}, &total)
+	assert.Empty(t, errKeys)
+	assert.Empty(t, released)
+
+	// 8:16 is throttled now, where the usage is 2048 KiB/s
+	errKeys, released = metricMap[DiskWriteKiBPS].RestoreFunc(ctx, 0, ThrottlePods{{Key: key, PodDiskWriteKiBPS: 2048, PodDiskWriteKiBPSByDevice: map[string]float64{"8:0": 6144, "8:16": 2048}, ActionType: podinfo.ThrottleUp}}, &total)
+	assert.Empty(t, errKeys)
+	assert.InDelta(t, 2048*0.2, released[DiskWriteKiBPS], 1e-6)
+	writeBps, _ = os.ReadFile(filepath.Join(dir, "blkio.throttle.write_bps_device"))
+	assert.Equal(t, "8:16 2516582", string(writeBps))
+
+	// The disk the pod writes most is throttled if the devices of the node disks are unknown
+	ctx.stateMap = nil
+	errKeys, released = metricMap[DiskWriteKiBPS].ThrottleFunc(ctx, 0, ThrottlePods{{Key: key, PodDiskWriteKiBPS: 7680, PodDiskWriteKiBPSByDevice: usages, ActionType: podinfo.ThrottleDown}}, &total)
+	assert.Empty(t, errKeys)
+	assert.InDelta(t, 1536.0, released[DiskWriteKiBPS], 1e-6)
+	writeBps, _ = os.ReadFile(filepath.Join(dir, "blkio.throttle.write_bps_device"))
+	assert.Equal(t, "8:0 6291456", string(writeBps))
+}
+
+func TestCalculateGapsOfDiskIO(t *testing.T) {
+	evictExecutor := &EvictExecutor{EvictWatermark: Watermarks{}}
+	w := &Watermark{}
+	w.Push(*resource.NewQuantity(1024, resource.DecimalSI))
+	evictExecutor.EvictWatermark[DiskWriteKiBPS] = w
+
+	stateMap := map[string][]common.TimeSeries{
+		string(DiskWriteKiBPS): {
+			{Labels: []common.Label{{Name: "diskName", Value: "sda"}}, Samples: []common.Sample{{Value: 512}}},
+			{Labels: []common.Label{{Name: "diskName", Value: "sdb"}}, Samples: []common.Sample{{Value: 4096}}},
+		},
+	}
+
+	// The busiest disk decides the gap, and the disk read usage missed is ignored since it has no watermark
+	gaps := calculateGaps(stateMap, nil, evictExecutor, 0)
+	assert.False(t, gaps.HasUsageMissedMetric())
+	assert.InDelta(t, 3072.0, gaps[DiskWriteKiBPS], 1e-6)
+	_, ok := gaps[DiskReadKiBPS]
+	assert.False(t, ok)
+}
@@ -13,16 +13,34 @@ import (
 	execsort "github.com/gocrane/crane/pkg/ensurance/executor/sort"
 	"github.com/gocrane/crane/pkg/known"
 	"github.com/gocrane/crane/pkg/metrics"
+	"github.com/gocrane/crane/pkg/utils"
 )
 
 type EvictExecutor struct {
 	EvictPods EvictPods
 	// All metrics(not only can be quantified metrics) metioned in triggerd NodeQOS and their corresponding watermarks
 	EvictWatermark Watermarks
+	// RankingPolicy declared by NodeQOS or PodQOS sorts the pods instead of the SortFunc of the metrics
+	RankingPolicy execsort.RankingPolicy
+	// Rules are the rules which the pods are evicted for, in the format of <NodeQOS name>.<rule name>
+	Rules []string
 }
 
 type EvictPods []podinfo.PodContext
 
+// EvictResult is the outcome of evicting a pod
+type EvictResult struct {
+	ErrPodKeys []string
+}
+
+// pendingEviction is a pod being evicted, it's recorded in the action history when the eviction is done
+type pendingEviction struct {
+	pod      podinfo.PodContext
+	metric   WatermarkMetric
+	released ReleaseResource
+	result   *EvictResult
+}
+
 func (e EvictPods) Find(key types.NamespacedName) int {
 	for i, v := range e {
 		if v.Key == key {
@@ -48,15 +66,17 @@ func (e *EvictExecutor) Avoid(ctx *ExecuteContext) error {
 	metrics.UpdateExecutorStatus(metrics.SubComponentEvict, metrics.StepAvoid, 1.0)
 	metrics.ExecutorStatusCounterInc(metrics.SubComponentEvict, metrics.StepAvoid)
 
-	var errPodKeys, errKeys []string
+	var errPodKeys []string
 	// TODO: totalReleasedResource used for prom metrics
 	totalReleased := ReleaseResource{}
+	// the pods admitted for the PodDisruptionBudgets in this round
+	disruptions := make(map[string]int32)
 
 	/* The step to evict:
 	1. If EvictWatermark has metrics that can't be quantified, select a evictable metric which has the highest action priority, use its EvictFunc to evict all selected pods, then return
 	2. Get the gaps between current usage and watermarks
 		2.1 If there is a metric that can't get current usage, select a evictable metric which has the highest action priority, use its EvictFunc to evict all selected pods, then return
-		2.2 Traverse metrics that can be quantified, if there is gap for the metric, then sort candidate pods by its SortFunc if exists, otherwise use GeneralSorter by default.
+		2.2 Traverse metrics that can be quantified, if there is gap for the metric, then sort candidate pods by the RankingPolicy if declared, or by its SortFunc if exists, otherwise use GeneralSorter by default.
 	       Then evict sorted pods one by one util there is no gap to watermark
 	*/
 
@@ -69,7 +89,7 @@ func (e *EvictExecutor) Avoid(ctx *ExecuteContext) error {
 		highestPriorityMetric := e.EvictWatermark.GetHighestPriorityEvictableMetric()
 		if highestPriorityMetric != "" {
 			klog.V(6).Infof("The highestPriorityMetric is %s", highestPriorityMetric)
-			errPodKeys = e.evictPods(ctx, &totalReleased, highestPriorityMetric)
+			errPodKeys = e.evictPods(ctx, &totalReleased, highestPriorityMetric, disruptions)
 		}
 	} else {
 		ctx.ToBeEvict = calculateGaps(ctx.stateMap, nil, e, ctx.executeExcessPercent)
@@ -78,19 +98,15 @@ func (e *EvictExecutor) Avoid(ctx *ExecuteContext) error {
 			klog.V(6).Infof("There is a metric usage missed")
 			highestPriorityMetric := e.EvictWatermark.GetHighestPriorityEvictableMetric()
 			if highestPriorityMetric != "" {
-				errPodKeys = e.evictPods(ctx, &totalReleased, highestPriorityMetric)
+				errPodKeys = e.evictPods(ctx, &totalReleased, highestPriorityMetric, disruptions)
 			}
 		} else {
 			// The metrics in ToBeEvict are can be EvictQuantified and has current usage, then evict precisely
-			var released ReleaseResource
+			var pending []pendingEviction
 			wg := sync.WaitGroup{}
 			for _, m := range quantified {
 				klog.V(6).Infof("Evict precisely on metric %s, and current gaps are %+v", m, ctx.ToBeEvict)
-				if metricMap[m].Sortable {
-					metricMap[m].SortFunc(e.EvictPods)
-				} else {
-					execsort.GeneralSorter(e.EvictPods)
-				}
+				sortPods(m, e.RankingPolicy, e.EvictPods)
 
 				for _, pc := range e.EvictPods {
 					klog.V(6).Info(pc.Key.String())
@@ -99,10 +115,16 @@ func (e *EvictExecutor) Avoid(ctx *ExecuteContext) error {
 					klog.V(2).Infof("For metric %s, there is gap %f to watermarks %s", m, ctx.ToBeEvict[m], m)
 					if podinfo.ContainsNoExecutedPod(e.EvictPods) {
 						index := podinfo.GetFirstPendingPod(e.EvictPods)
-						errKeys, released = metricMap[m].EvictFunc(&wg, ctx, index, &totalReleased, e.EvictPods)
-						errPodKeys = append(errPodKeys, errKeys...)
-						klog.Warningf("Evicted pods %s, released %f of %s", e.EvictPods[index].Key, released[m], m)
 						e.EvictPods[index].Executed = true
+						// the pod releases nothing if it's not admitted, try the next one
+						if err := e.admit(ctx, index, disruptions); err != nil {
+							klog.Warningf("Skip evicting pod %s: %v", e.EvictPods[index].Key, err)
+							ctx.recordAction(e.EvictPods[index], e.Rules, m, nil, []string{err.Error()})
+							continue
+						}
+						result, released := metricMap[m].EvictFunc(&wg, ctx, index, &totalReleased, e.EvictPods)
+						pending = append(pending, pendingEviction{pod: e.EvictPods[index], metric: m, released: released, result: result})
+						klog.Warningf("Evicted pods %s, released %f of %s", e.EvictPods[index].Key, released[m], m)
 						ctx.ToBeEvict[m] -= released[m]
 					} else {
 						klog.V(6).Info("There is no pod that can be evicted")
@@ -111,6 +133,7 @@ func (e *EvictExecutor) Avoid(ctx *ExecuteContext) error {
 				}
 			}
 			wg.Wait()
+			errPodKeys = e.recordEvictions(ctx, pending)
 		}
 	}
 
@@ -125,12 +148,81 @@ func (e *EvictExecutor) Restore(ctx *ExecuteContext) error {
 	return nil
 }
 
-func (e *EvictExecutor) evictPods(ctx *ExecuteContext, totalReleasedResource *ReleaseResource, m WatermarkMetric) (errPodKeys []string) {
+func (e *EvictExecutor) evictPods(ctx *ExecuteContext, totalReleasedResource *ReleaseResource, m WatermarkMetric, disruptions map[string]int32) (errPodKeys []string) {
+	var pending []pendingEviction
 	wg := sync.WaitGroup{}
 	for i := range e.EvictPods {
-		errKeys, _ := metricMap[m].EvictFunc(&wg, ctx, i, totalReleasedResource, e.EvictPods)
-		errPodKeys = append(errPodKeys, errKeys...)
+		if err := e.admit(ctx, i, disruptions); err != nil {
+			klog.Warningf("Skip evicting pod %s: %v", e.EvictPods[i].Key, err)
+			ctx.recordAction(e.EvictPods[i], e.Rules, m, nil, []string{err.Error()})
+			continue
+		}
+		result, released := metricMap[m].EvictFunc(&wg, ctx, i, totalReleasedResource, e.EvictPods)
+		pending = append(pending, pendingEviction{pod: e.EvictPods[i], metric: m, released: released, result: result})
 	}
 	wg.Wait()
+	return e.recordEvictions(ctx, pending)
+}
+
+// recordEvictions records the outcome of the evictions done, and returns the keys of the pods failed. A pod failed to
+// be evicted releases nothing.
+func (e *EvictExecutor) recordEvictions(ctx *ExecuteContext, pending []pendingEviction) (errPodKeys []string) {
+	for _, p := range pending {
+		released := p.released
+		if len(p.result.ErrPodKeys) != 0 {
+			errPodKeys = append(errPodKeys, p.result.ErrPodKeys...)
+			released = nil
+		}
+		ctx.recordAction(p.pod, e.Rules, p.metric, released, p.result.ErrPodKeys)
+	}
 	return
 }
+
+// evictOnePod returns the EvictFunc of a metric, the released resource of the pod is calculated by the release function
+// outside the goroutine which evicts the pod.
+func evictOnePod(release func(pod podinfo.PodContext) ReleaseResource) func(wg *sync.WaitGroup, ctx *ExecuteContext, index int, totalReleasedResource *ReleaseResource, EvictPods EvictPods) (result *EvictResult, released ReleaseResource) {
+	return func(wg *sync.WaitGroup, ctx *ExecuteContext, index int, totalReleasedResource *ReleaseResource, EvictPods EvictPods) (result *EvictResult, released ReleaseResource) {
+		wg.Add(1)
+		result = &EvictResult{}
+
+		// Calculate release resources
+		released = release(EvictPods[index])
+		totalReleasedResource.Add(released)
+
+		go func(evictPod podinfo.PodContext) {
+			defer wg.Done()
+
+			pod, err := ctx.PodLister.Pods(evictPod.Key.Namespace).Get(evictPod.Key.Name)
+			if err != nil {
+				result.ErrPodKeys = append(result.ErrPodKeys, "not found ", evictPod.Key.String())
+				return
+			}
+			klog.Warningf("Evicting pod %v", evictPod.Key)
+			err = utils.EvictPodWithGracePeriod(ctx.Client, pod, evictPod.DeletionGracePeriodSeconds)
+			if err != nil {
+				result.ErrPodKeys = append(result.ErrPodKeys, "evict failed ", evictPod.Key.String())
+				klog.Warningf("Failed to evict pod %s: %v", evictPod.Key.String(), err)
+				if ctx.EvictionLimiter != nil {
+					ctx.EvictionLimiter.Refund(pod)
+				}
+				return
+			}
+			metrics.ExecutorEvictCountsInc()
+
+			klog.Warningf("Pod %s is evicted", klog.KObj(pod))
+		}(EvictPods[index])
+		return
+	}
+}
+
+// admit checks the eviction of the pod by the eviction limiter
+func (e *EvictExecutor) admit(ctx *ExecuteContext, index int, disruptions map[string]int32) error {
+	if ctx.EvictionLimiter == nil {
+		return nil
+	}
+	pod, err := ctx.PodLister.Pods(e.EvictPods[index].Key.Namespace).Get(e.EvictPods[index].Key.Name)
+	if err != nil {
+		return err
+	}
+	return ctx.EvictionLimiter.Admit(pod, disruptions)
+}
@@ -0,0 +1,260 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/metrics"
+)
+
+const (
+	// The keys of the token bucket in the configmap
+	tokenBucketTokensKey         = "tokens"
+	tokenBucketLastRefillTimeKey = "lastRefillTime"
+
+	EvictionLimitReasonPDB      = "pdb"
+	EvictionLimitReasonWorkload = "workload"
+	EvictionLimitReasonNode     = "node"
+	EvictionLimitReasonCluster  = "cluster"
+)
+
+// EvictionLimits are the caps of the evictions, a cap is disabled if it's zero
+type EvictionLimits struct {
+	// WorkloadLimit is the max evictions of the pods of a workload in the WorkloadWindow on the node
+	WorkloadLimit  int
+	WorkloadWindow time.Duration
+	// NodeLimitPerMinute is the max evictions on the node per minute
+	NodeLimitPerMinute int
+	// ClusterLimitPerMinute is the refill rate of the token bucket shared by all the nodes
+	ClusterLimitPerMinute int
+	// ClusterBurst is the capacity of the token bucket, default to ClusterLimitPerMinute
+	ClusterBurst int
+}
+
+// EvictionLimiter admits the evictions of the executor, so that a bad rule can't evict a whole service. The pods are
+// skipped if their PodDisruptionBudgets allow no disruption, or any of the caps is reached.
+type EvictionLimiter struct {
+	client    clientset.Interface
+	pdbLister PodDisruptionBudgetLister
+	limits    EvictionLimits
+
+	lock sync.Mutex
+	// workload key -> the times of the evictions in the window
+	workloadEvictions map[string][]time.Time
+	nodeEvictions     []time.Time
+	now               func() time.Time
+}
+
+func NewEvictionLimiter(client clientset.Interface, pdbLister PodDisruptionBudgetLister, limits EvictionLimits) *EvictionLimiter {
+	if limits.ClusterBurst == 0 {
+		limits.ClusterBurst = limits.ClusterLimitPerMinute
+	}
+	return &EvictionLimiter{
+		client:            client,
+		pdbLister:         pdbLister,
+		limits:            limits,
+		workloadEvictions: make(map[string][]time.Time),
+		now:               time.Now,
+	}
+}
+
+// Admit checks whether the pod can be evicted, the eviction is counted in the caps if it's admitted, and should be
+// refunded if the pod is not evicted at last.
+// disruptions are the pods admitted for the PodDisruptionBudgets in this round, since the status of the budgets is not
+// updated until the evictions are handled by the apiserver.
+func (l *EvictionLimiter) Admit(pod *v1.Pod, disruptions map[string]int32) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	pdbKeys, err := l.admitPDB(pod, disruptions)
+	if err != nil {
+		return l.limited(EvictionLimitReasonPDB, err)
+	}
+
+	now := l.now()
+	workload := workloadKey(pod)
+	if l.limits.WorkloadLimit > 0 {
+		l.workloadEvictions[workload] = evictionsSince(l.workloadEvictions[workload], now.Add(-l.limits.WorkloadWindow))
+		if len(l.workloadEvictions[workload]) >= l.limits.WorkloadLimit {
+			return l.limited(EvictionLimitReasonWorkload, fmt.Errorf("workload %s has %d evictions in %s", workload, len(l.workloadEvictions[workload]), l.limits.WorkloadWindow))
+		}
+	}
+	if l.limits.NodeLimitPerMinute > 0 {
+		l.nodeEvictions = evictionsSince(l.nodeEvictions, now.Add(-time.Minute))
+		if len(l.nodeEvictions) >= l.limits.NodeLimitPerMinute {
+			return l.limited(EvictionLimitReasonNode, fmt.Errorf("node has %d evictions in the last minute", len(l.nodeEvictions)))
+		}
+	}
+	// the cluster token is taken at last, it's not returned if the eviction is not admitted
+	if l.limits.ClusterLimitPerMinute > 0 {
+		if err = l.takeClusterToken(now); err != nil {
+			return l.limited(EvictionLimitReasonCluster, err)
+		}
+	}
+
+	for _, key := range pdbKeys {
+		disruptions[key]++
+	}
+	if l.limits.WorkloadLimit > 0 {
+		l.workloadEvictions[workload] = append(l.workloadEvictions[workload], now)
+	}
+	if l.limits.NodeLimitPerMinute > 0 {
+		l.nodeEvictions = append(l.nodeEvictions, now)
+	}
+	return nil
+}
+
+func (l *EvictionLimiter) limited(reason string, err error) error {
+	metrics.ExecutorEvictLimitedCountsInc(reason)
+	return fmt.Errorf("eviction is limited by %s: %v", reason, err)
+}
+
+// Refund returns the eviction of the pod admitted but failed to the caps. The disruptions of the PodDisruptionBudgets
+// are not returned, since the round may admit the other pods meanwhile.
+func (l *EvictionLimiter) Refund(pod *v1.Pod) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	workload := workloadKey(pod)
+	if n := len(l.workloadEvictions[workload]); l.limits.WorkloadLimit > 0 && n > 0 {
+		l.workloadEvictions[workload] = l.workloadEvictions[workload][:n-1]
+	}
+	if n := len(l.nodeEvictions); l.limits.NodeLimitPerMinute > 0 && n > 0 {
+		l.nodeEvictions = l.nodeEvictions[:n-1]
+	}
+	if l.limits.ClusterLimitPerMinute > 0 {
+		if err := l.returnClusterToken(); err != nil {
+			klog.Warningf("Failed to return the cluster eviction token of pod %s: %v", klog.KObj(pod), err)
+		}
+	}
+}
+
+// admitPDB returns the keys of the PodDisruptionBudgets of the pod if all of them allow a disruption. No pod is
+// admitted until the budgets are synced.
+func (l *EvictionLimiter) admitPDB(pod *v1.Pod, disruptions map[string]int32) ([]string, error) {
+	if l.pdbLister == nil {
+		return nil, nil
+	}
+	if !l.pdbLister.HasSynced() {
+		return nil, fmt.Errorf("PodDisruptionBudgets are not synced yet")
+	}
+	var keys []string
+	for key, allowed := range l.pdbLister.GetPodDisruptionsAllowed(pod) {
+		if allowed-disruptions[key] <= 0 {
+			return nil, fmt.Errorf("PodDisruptionBudget %s allows no more disruption", key)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// takeClusterToken takes a token from the bucket in the configmap shared by all the agents, the tokens are refilled
+// by ClusterLimitPerMinute and capped by ClusterBurst
+func (l *EvictionLimiter) takeClusterToken(now time.Time) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := l.client.CoreV1().ConfigMaps(known.CraneSystemNamespace).Get(context.TODO(), known.EvictionTokenBucketConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			cm = &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: known.EvictionTokenBucketConfigMapName, Namespace: known.CraneSystemNamespace}}
+			cm.Data = tokenBucketData(float64(l.limits.ClusterBurst-1), now)
+			_, err = l.client.CoreV1().ConfigMaps(known.CraneSystemNamespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+			if errors.IsAlreadyExists(err) {
+				// created by another agent, retry with it
+				return errors.NewConflict(schema.GroupResource{Resource: "configmaps"}, cm.Name, err)
+			}
+			return err
+		}
+
+		tokens, lastRefillTime := parseTokenBucket(cm.Data, float64(l.limits.ClusterBurst))
+		if elapsed := now.Sub(lastRefillTime); elapsed > 0 {
+			tokens = math.Min(float64(l.limits.ClusterBurst), tokens+elapsed.Minutes()*float64(l.limits.ClusterLimitPerMinute))
+		} else {
+			// the clocks of the nodes are not the same
+			now = lastRefillTime
+		}
+		if tokens < 1 {
+			return fmt.Errorf("no token left in the cluster eviction bucket")
+		}
+
+		cm = cm.DeepCopy()
+		cm.Data = tokenBucketData(tokens-1, now)
+		_, err = l.client.CoreV1().ConfigMaps(known.CraneSystemNamespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// returnClusterToken puts a token back to the bucket, capped by ClusterBurst
+func (l *EvictionLimiter) returnClusterToken() error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := l.client.CoreV1().ConfigMaps(known.CraneSystemNamespace).Get(context.TODO(), known.EvictionTokenBucketConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		tokens, lastRefillTime := parseTokenBucket(cm.Data, float64(l.limits.ClusterBurst))
+		cm = cm.DeepCopy()
+		cm.Data = tokenBucketData(math.Min(float64(l.limits.ClusterBurst), tokens+1), lastRefillTime)
+		_, err = l.client.CoreV1().ConfigMaps(known.CraneSystemNamespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func tokenBucketData(tokens float64, lastRefillTime time.Time) map[string]string {
+	return map[string]string{
+		tokenBucketTokensKey:         strconv.FormatFloat(tokens, 'f', -1, 64),
+		tokenBucketLastRefillTimeKey: lastRefillTime.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// parseTokenBucket returns the tokens and the last refill time in the data, the bucket is full if it's broken
+func parseTokenBucket(data map[string]string, burst float64) (float64, time.Time) {
+	tokens, err := strconv.ParseFloat(data[tokenBucketTokensKey], 64)
+	if err != nil {
+		klog.Warningf("Failed to parse the tokens of the eviction bucket: %v", err)
+		tokens = burst
+	}
+	lastRefillTime, err := time.Parse(time.RFC3339Nano, data[tokenBucketLastRefillTimeKey])
+	if err != nil {
+		klog.Warningf("Failed to parse the last refill time of the eviction bucket: %v", err)
+		lastRefillTime = time.Time{}
+	}
+	return tokens, lastRefillTime
+}
+
+// evictionsSince drops the evictions no later than the start
+func evictionsSince(evictions []time.Time, start time.Time) []time.Time {
+	var i int
+	for i < len(evictions) && !evictions[i].After(start) {
+		i++
+	}
+	return evictions[i:]
+}
+
+// workloadKey returns the controller of the pod, the replicaset is taken as its deployment by the pod template hash
+func workloadKey(pod *v1.Pod) string {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return pod.Namespace + "/Pod/" + pod.Name
+	}
+	kind, name := owner.Kind, owner.Name
+	if hash, ok := pod.Labels["pod-template-hash"]; ok && kind == "ReplicaSet" && strings.HasSuffix(name, "-"+hash) {
+		kind, name = "Deployment", strings.TrimSuffix(name, "-"+hash)
+	}
+	return pod.Namespace + "/" + kind + "/" + name
+}
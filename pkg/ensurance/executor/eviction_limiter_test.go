@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	policylisters "k8s.io/client-go/listers/policy/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/gocrane/crane/pkg/known"
+)
+
+func newTestPod(name, replicaSet string) *v1.Pod {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "web", "pod-template-hash": "5d4b9c"}}}
+	if replicaSet != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: replicaSet, Controller: &controller}}
+	}
+	return pod
+}
+
+func TestEvictionLimiterPDB(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.NoError(t, indexer.Add(&policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+	}))
+	synced := false
+	pdbs := &podDisruptionBudgetsV1{lister: policylisters.NewPodDisruptionBudgetLister(indexer), synced: func() bool { return synced }}
+	l := NewEvictionLimiter(fake.NewSimpleClientset(), pdbs, EvictionLimits{})
+
+	disruptions := make(map[string]int32)
+	// no pod is admitted until the budgets are synced
+	assert.Error(t, l.Admit(newTestPod("web-1", ""), disruptions))
+	synced = true
+	assert.NoError(t, l.Admit(newTestPod("web-1", ""), disruptions))
+	// the budget is used up by the first pod in this round
+	assert.Error(t, l.Admit(newTestPod("web-2", ""), disruptions))
+	// the pods without budgets are not limited
+	assert.NoError(t, l.Admit(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "default"}}, disruptions))
+}
+
+func TestEvictionLimiterCaps(t *testing.T) {
+	now := time.Unix(10000, 0)
+	l := NewEvictionLimiter(fake.NewSimpleClientset(), nil, EvictionLimits{WorkloadLimit: 2, WorkloadWindow: 10 * time.Minute, NodeLimitPerMinute: 3})
+	l.now = func() time.Time { return now }
+
+	assert.NoError(t, l.Admit(newTestPod("web-5d4b9c-a", "web-5d4b9c"), nil))
+	assert.NoError(t, l.Admit(newTestPod("web-5d4b9c-b", "web-5d4b9c"), nil))
+	// the workload has two evictions in the window
+	assert.Error(t, l.Admit(newTestPod("web-5d4b9c-c", "web-5d4b9c"), nil))
+	assert.NoError(t, l.Admit(newTestPod("api-1", "api-7f8d"), nil))
+	// the node has three evictions in the last minute
+	assert.Error(t, l.Admit(newTestPod("api-2", "api-7f8d"), nil))
+
+	now = now.Add(time.Minute)
+	assert.Error(t, l.Admit(newTestPod("web-5d4b9c-c", "web-5d4b9c"), nil))
+	assert.NoError(t, l.Admit(newTestPod("api-2", "api-7f8d"), nil))
+
+	now = now.Add(10 * time.Minute)
+	assert.NoError(t, l.Admit(newTestPod("web-5d4b9c-c", "web-5d4b9c"), nil))
+	assert.NoError(t, l.Admit(newTestPod("web-5d4b9c-d", "web-5d4b9c"), nil))
+	assert.Error(t, l.Admit(newTestPod("web-5d4b9c-e", "web-5d4b9c"), nil))
+	// the failed eviction is refunded
+	l.Refund(newTestPod("web-5d4b9c-d", "web-5d4b9c"))
+	assert.NoError(t, l.Admit(newTestPod("web-5d4b9c-e", "web-5d4b9c"), nil))
+}
+
+func TestEvictionLimiterClusterTokenBucket(t *testing.T) {
+	now := time.Unix(10000, 0)
+	client := fake.NewSimpleClientset()
+	l := NewEvictionLimiter(client, nil, EvictionLimits{ClusterLimitPerMinute: 2})
+	l.now = func() time.Time { return now }
+
+	assert.NoError(t, l.Admit(newTestPod("web-1", ""), nil))
+	assert.NoError(t, l.Admit(newTestPod("web-2", ""), nil))
+	assert.Error(t, l.Admit(newTestPod("web-3", ""), nil))
+
+	// the bucket is shared with the other nodes
+	other := NewEvictionLimiter(client, nil, EvictionLimits{ClusterLimitPerMinute: 2})
+	other.now = func() time.Time { return now.Add(30 * time.Second) }
+	assert.NoError(t, other.Admit(newTestPod("api-1", ""), nil))
+	assert.Error(t, other.Admit(newTestPod("api-2", ""), nil))
+
+	cm, err := client.CoreV1().ConfigMaps(known.CraneSystemNamespace).Get(context.TODO(), known.EvictionTokenBucketConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "0", cm.Data[tokenBucketTokensKey])
+
+	// the token of the failed eviction is returned
+	other.Refund(newTestPod("api-1", ""))
+	assert.NoError(t, other.Admit(newTestPod("api-2", ""), nil))
+
+	// refilled by the rate, and capped by the burst
+	now = now.Add(10 * time.Minute)
+	assert.NoError(t, l.Admit(newTestPod("web-3", ""), nil))
+	assert.NoError(t, l.Admit(newTestPod("web-4", ""), nil))
+	assert.Error(t, l.Admit(newTestPod("web-5", ""), nil))
+}
+
+func TestWorkloadKey(t *testing.T) {
+	assert.Equal(t, "default/Deployment/web", workloadKey(newTestPod("web-5d4b9c-a", "web-5d4b9c")))
+	assert.Equal(t, "default/ReplicaSet/api-7f8d", workloadKey(newTestPod("api-1", "api-7f8d")))
+	assert.Equal(t, "default/Pod/batch", workloadKey(newTestPod("batch", "")))
+}
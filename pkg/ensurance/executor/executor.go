@@ -1,6 +1,8 @@
 package executor
 
 import (
+	"net/http"
+	"path/filepath"
 	"time"
 
 	"google.golang.org/grpc"
@@ -16,7 +18,6 @@ import (
 	cruntime "github.com/gocrane/crane/pkg/ensurance/runtime"
 	"github.com/gocrane/crane/pkg/known"
 	"github.com/gocrane/crane/pkg/metrics"
-	"github.com/gocrane/crane/pkg/utils"
 )
 
 type ActionExecutor struct {
@@ -32,14 +33,26 @@ type ActionExecutor struct {
 	runtimeClient pb.RuntimeServiceClient
 	runtimeConn   *grpc.ClientConn
 
+	cgroupDriver string
+	cgroupRoot   string
+
 	stateMap map[string][]common.TimeSeries
 
 	executeExcessPercent float64
+
+	evictionLimiter *EvictionLimiter
+
+	actionHistory *ActionHistory
+	// actionHistoryExporter exports the action history of the pods to the configmaps in their namespaces, nil if the
+	// export is disabled
+	actionHistoryExporter *actionHistoryExporter
 }
 
 // NewActionExecutor create enforcer manager
 func NewActionExecutor(client clientset.Interface, nodeName string, podInformer coreinformers.PodInformer, nodeInformer coreinformers.NodeInformer,
-	noticeCh <-chan AvoidanceExecutor, runtimeEndpoint string, stateMap map[string][]common.TimeSeries, executeExcess string) *ActionExecutor {
+	pdbLister PodDisruptionBudgetLister, noticeCh <-chan AvoidanceExecutor, runtimeEndpoint, cgroupDriver, sysPath string,
+	stateMap map[string][]common.TimeSeries, executeExcessPercent float64, evictionLimits EvictionLimits, actionHistorySize int,
+	exportActionHistory bool) *ActionExecutor {
 
 	runtimeClient, runtimeConn, err := cruntime.GetRuntimeClient(runtimeEndpoint)
 	if err != nil {
@@ -47,24 +60,29 @@ func NewActionExecutor(client clientset.Interface, nodeName string, podInformer
 		return nil
 	}
 
-	executeExcessPercent, err := utils.ParsePercentage(executeExcess)
-	if err != nil || executeExcessPercent > 100 {
-		klog.Errorf("Parse executeExcess failed %s", err.Error())
-		return nil
+	actionHistory := NewActionHistory(actionHistorySize)
+	var exporter *actionHistoryExporter
+	if exportActionHistory {
+		exporter = newActionHistoryExporter(client, nodeName, nodeInformer.Lister(), actionHistory)
 	}
 
 	return &ActionExecutor{
-		nodeName:             nodeName,
-		client:               client,
-		noticeCh:             noticeCh,
-		podLister:            podInformer.Lister(),
-		podSynced:            podInformer.Informer().HasSynced,
-		nodeLister:           nodeInformer.Lister(),
-		nodeSynced:           nodeInformer.Informer().HasSynced,
-		runtimeClient:        runtimeClient,
-		runtimeConn:          runtimeConn,
-		stateMap:             stateMap,
-		executeExcessPercent: executeExcessPercent,
+		nodeName:              nodeName,
+		client:                client,
+		noticeCh:              noticeCh,
+		podLister:             podInformer.Lister(),
+		podSynced:             podInformer.Informer().HasSynced,
+		nodeLister:            nodeInformer.Lister(),
+		nodeSynced:            nodeInformer.Informer().HasSynced,
+		runtimeClient:         runtimeClient,
+		runtimeConn:           runtimeConn,
+		cgroupDriver:          cgroupDriver,
+		cgroupRoot:            filepath.Join(sysPath, "fs", "cgroup"),
+		stateMap:              stateMap,
+		executeExcessPercent:  executeExcessPercent,
+		evictionLimiter:       NewEvictionLimiter(client, pdbLister, evictionLimits),
+		actionHistory:         actionHistory,
+		actionHistoryExporter: exporter,
 	}
 }
 
@@ -84,6 +102,10 @@ func (a *ActionExecutor) Run(stop <-chan struct{}) {
 		return
 	}
 
+	if a.actionHistoryExporter != nil {
+		go a.actionHistoryExporter.Run(stop)
+	}
+
 	go func() {
 		for {
 			select {
@@ -117,9 +139,18 @@ func (a *ActionExecutor) execute(ae AvoidanceExecutor, _ <-chan struct{}) error
 		NodeLister:           a.nodeLister,
 		RuntimeClient:        a.runtimeClient,
 		RuntimeConn:          a.runtimeConn,
+		CgroupDriver:         a.cgroupDriver,
+		CgroupRoot:           a.cgroupRoot,
+		EvictionLimiter:      a.evictionLimiter,
+		ActionHistory:        a.actionHistory,
 		stateMap:             ae.StateMap,
 		executeExcessPercent: a.executeExcessPercent,
 	}
+	defer func() {
+		if a.actionHistoryExporter != nil {
+			a.actionHistoryExporter.Enqueue(ctx.records)
+		}
+	}()
 
 	//step1 do enforcer actions
 	if err := avoid(ctx, ae); err != nil {
@@ -134,6 +165,11 @@ func (a *ActionExecutor) execute(ae AvoidanceExecutor, _ <-chan struct{}) error
 	return nil
 }
 
+// ServeActionHistory serves the history of the actions taken on the pods of the node
+func (a *ActionExecutor) ServeActionHistory(w http.ResponseWriter, r *http.Request) {
+	a.actionHistory.ServeHTTP(w, r)
+}
+
 func avoid(ctx *ExecuteContext, ae AvoidanceExecutor) error {
 	var start = time.Now()
 	metrics.UpdateLastTime(string(known.ModuleActionExecutor), metrics.StepAvoid, start)
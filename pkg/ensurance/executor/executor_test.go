@@ -0,0 +1,24 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// newTestExecuteContext returns the context to execute the actions on the pods, with a cgroupfs driver and a cgroup
+// root in a temp dir
+func newTestExecuteContext(t *testing.T, pods ...*v1.Pod) *ExecuteContext {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, pod := range pods {
+		assert.NoError(t, indexer.Add(pod))
+	}
+	return &ExecuteContext{
+		PodLister:    corelisters.NewPodLister(indexer),
+		CgroupDriver: "cgroupfs",
+		CgroupRoot:   t.TempDir(),
+	}
+}
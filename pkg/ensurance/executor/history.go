@@ -0,0 +1,254 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+const (
+	// actionHistoryConfigMapPrefix is the prefix of the configmaps which the summaries of the actions are exported to,
+	// the name is <prefix>-<node name> in the namespace of the pods
+	actionHistoryConfigMapPrefix = "crane-action-history"
+	actionHistoryConfigMapKey    = "history"
+
+	// actionHistoryExportQPS and actionHistoryExportBurst limit the rate to write the configmaps
+	actionHistoryExportQPS   = 2
+	actionHistoryExportBurst = 10
+)
+
+// ActionRecord is an action taken on a pod by the executor
+type ActionRecord struct {
+	Time      time.Time          `json:"time"`
+	Namespace string             `json:"namespace"`
+	Pod       string             `json:"pod"`
+	Action    podinfo.ActionType `json:"action"`
+	// Rules are the triggered rules which the action is taken for, in the format of <NodeQOS name>.<rule name>
+	Rules  []string        `json:"rules,omitempty"`
+	Metric WatermarkMetric `json:"metric"`
+	// Released is the usage of the metric released by the action
+	Released float64 `json:"released"`
+	// Error is why the action failed or is skipped
+	Error string `json:"error,omitempty"`
+}
+
+// ActionHistory is a bounded ring buffer of the latest actions taken on the pods of the node
+type ActionHistory struct {
+	lock    sync.RWMutex
+	records []ActionRecord
+	next    int
+	full    bool
+}
+
+func NewActionHistory(size int) *ActionHistory {
+	return &ActionHistory{records: make([]ActionRecord, size)}
+}
+
+// Add adds the record, the oldest one is dropped if the history is full
+func (h *ActionHistory) Add(record ActionRecord) {
+	if h == nil || len(h.records) == 0 {
+		return
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// List returns the records accepted by the filter, the oldest is the first
+func (h *ActionHistory) List(filter func(record ActionRecord) bool) []ActionRecord {
+	if h == nil {
+		return nil
+	}
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	records := h.records[:h.next]
+	if h.full {
+		records = append(append([]ActionRecord{}, h.records[h.next:]...), h.records[:h.next]...)
+	}
+	var result []ActionRecord
+	for _, record := range records {
+		if filter == nil || filter(record) {
+			result = append(result, record)
+		}
+	}
+	return result
+}
+
+// ServeHTTP serves the records in json, they are filtered by the query parameters namespace, pod, action, rule and
+// since, which is a RFC3339 time or a duration before now, such as /action-history?namespace=default&since=1h
+func (h *ActionHistory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseActionFilter(r, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	records := h.List(filter)
+	if records == nil {
+		records = []ActionRecord{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		klog.Errorf("Failed to encode action history: %v", err)
+	}
+}
+
+func parseActionFilter(r *http.Request, now time.Time) (func(record ActionRecord) bool, error) {
+	query := r.URL.Query()
+	namespace, pod, action, rule := query.Get("namespace"), query.Get("pod"), query.Get("action"), query.Get("rule")
+
+	var since time.Time
+	if value := query.Get("since"); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			since = now.Add(-duration)
+		} else if since, err = time.Parse(time.RFC3339, value); err != nil {
+			return nil, fmt.Errorf("since should be a RFC3339 time or a duration, got %s", value)
+		}
+	}
+
+	return func(record ActionRecord) bool {
+		if (namespace != "" && record.Namespace != namespace) || (pod != "" && record.Pod != pod) ||
+			(action != "" && !strings.EqualFold(string(record.Action), action)) || record.Time.Before(since) {
+			return false
+		}
+		if rule == "" {
+			return true
+		}
+		for _, r := range record.Rules {
+			if r == rule {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// recordAction records the action on the pod in the history of the context
+func (ctx *ExecuteContext) recordAction(pod podinfo.PodContext, rules []string, m WatermarkMetric, released ReleaseResource, errKeys []string) {
+	record := ActionRecord{
+		Time:      time.Now(),
+		Namespace: pod.Key.Namespace,
+		Pod:       pod.Key.Name,
+		Action:    pod.ActionType,
+		Rules:     rules,
+		Metric:    m,
+		Released:  released[m],
+		Error:     strings.Join(errKeys, " "),
+	}
+	ctx.ActionHistory.Add(record)
+	ctx.records = append(ctx.records, record)
+}
+
+// actionHistoryExporter exports the records of the pods in each namespace to the configmap in the namespace, so that
+// the users of the namespace can find what the actions are without access to the agent. The namespaces are queued when
+// there are new records of their pods, and the configmaps are written by a worker at a limited rate.
+type actionHistoryExporter struct {
+	client     clientset.Interface
+	nodeName   string
+	nodeLister corelisters.NodeLister
+	history    *ActionHistory
+	queue      workqueue.RateLimitingInterface
+	limiter    flowcontrol.RateLimiter
+}
+
+func newActionHistoryExporter(client clientset.Interface, nodeName string, nodeLister corelisters.NodeLister, history *ActionHistory) *actionHistoryExporter {
+	return &actionHistoryExporter{
+		client:     client,
+		nodeName:   nodeName,
+		nodeLister: nodeLister,
+		history:    history,
+		queue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "action-history"),
+		limiter:    flowcontrol.NewTokenBucketRateLimiter(actionHistoryExportQPS, actionHistoryExportBurst),
+	}
+}
+
+func (e *actionHistoryExporter) Run(stop <-chan struct{}) {
+	go wait.Until(func() {
+		for e.processNextNamespace() {
+		}
+	}, time.Second, stop)
+
+	<-stop
+	e.queue.ShutDown()
+}
+
+// Enqueue queues the namespaces of the records to export
+func (e *actionHistoryExporter) Enqueue(records []ActionRecord) {
+	for _, record := range records {
+		e.queue.Add(record.Namespace)
+	}
+}
+
+func (e *actionHistoryExporter) processNextNamespace() bool {
+	obj, shutdown := e.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer e.queue.Done(obj)
+
+	e.limiter.Accept()
+	namespace := obj.(string)
+	if err := e.export(namespace); err != nil {
+		klog.Errorf("Failed to export action history to namespace %s: %v", namespace, err)
+		e.queue.AddRateLimited(obj)
+		return true
+	}
+	e.queue.Forget(obj)
+	return true
+}
+
+// export writes the records of the namespace to the configmap, which is owned by the node so that it's deleted
+// together with the node. The configmap is updated at first, and created if not found, which needs no get permission.
+func (e *actionHistoryExporter) export(namespace string) error {
+	records := e.history.List(func(record ActionRecord) bool {
+		return record.Namespace == namespace
+	})
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	node, err := e.nodeLister.Get(e.nodeName)
+	if err != nil {
+		return err
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      actionHistoryConfigMapPrefix + "-" + e.nodeName,
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "Node",
+				Name:       node.Name,
+				UID:        node.UID,
+			}},
+		},
+		Data: map[string]string{actionHistoryConfigMapKey: string(data)},
+	}
+	_, err = e.client.CoreV1().ConfigMaps(namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	if errors.IsNotFound(err) {
+		_, err = e.client.CoreV1().ConfigMaps(namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestActionHistory(t *testing.T) {
+	h := NewActionHistory(3)
+	now := time.Now()
+	for i, name := range []string{"a", "b", "c", "d"} {
+		h.Add(ActionRecord{Time: now.Add(time.Duration(i-4) * time.Hour), Namespace: "default", Pod: name, Action: podinfo.ThrottleDown, Rules: []string{"cpu.cpu-usage"}})
+	}
+	h.Add(ActionRecord{Time: now, Namespace: "offline", Pod: "e", Action: podinfo.Evict, Rules: []string{"memory.mem-usage"}, Error: "eviction is limited"})
+
+	// the oldest records are dropped
+	var pods []string
+	for _, record := range h.List(nil) {
+		pods = append(pods, record.Pod)
+	}
+	assert.Equal(t, []string{"c", "d", "e"}, pods)
+
+	serve := func(query string) []ActionRecord {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/action-history"+query, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		var records []ActionRecord
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &records))
+		return records
+	}
+	assert.Len(t, serve(""), 3)
+	assert.Len(t, serve("?namespace=default"), 2)
+	assert.Len(t, serve("?namespace=default&pod=d"), 1)
+	assert.Len(t, serve("?action=evict"), 1)
+	assert.Len(t, serve("?rule=memory.mem-usage"), 1)
+	assert.Len(t, serve("?since=90m"), 2)
+	assert.Len(t, serve("?since="+now.Add(-time.Minute).Format(time.RFC3339)), 1)
+	assert.Empty(t, serve("?pod=a"))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/action-history?since=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExportActionHistory(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	h := NewActionHistory(10)
+	exporter := newActionHistoryExporter(client, "node-1", corelisters.NewNodeLister(nodeIndexer), h)
+	ctx := &ExecuteContext{ActionHistory: h}
+	pod := podinfo.PodContext{Key: k8stypes.NamespacedName{Namespace: "default", Name: "a"}, ActionType: podinfo.ThrottleDown}
+	ctx.recordAction(pod, []string{"cpu.cpu-usage"}, CpuUsage, ReleaseResource{CpuUsage: 500}, nil)
+	ctx.recordAction(pod, []string{"cpu.cpu-usage"}, CpuUsage, ReleaseResource{CpuUsage: 300}, []string{"failed"})
+
+	// the configmap is owned by the node
+	assert.Error(t, exporter.export("default"))
+	assert.NoError(t, nodeIndexer.Add(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", UID: "node-1-uid"}}))
+
+	// the configmap is created at first and updated later
+	for i := 0; i < 2; i++ {
+		exporter.Enqueue(ctx.records)
+		assert.Equal(t, 1, exporter.queue.Len())
+		assert.True(t, exporter.processNextNamespace())
+		cm, err := client.CoreV1().ConfigMaps("default").Get(context.TODO(), "crane-action-history-node-1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		if assert.Len(t, cm.OwnerReferences, 1) {
+			assert.Equal(t, k8stypes.UID("node-1-uid"), cm.OwnerReferences[0].UID)
+		}
+		var records []ActionRecord
+		assert.NoError(t, json.Unmarshal([]byte(cm.Data[actionHistoryConfigMapKey]), &records))
+		if assert.Len(t, records, 2) {
+			assert.Equal(t, 500.0, records[0].Released)
+			assert.Equal(t, "failed", records[1].Error)
+		}
+	}
+}
+
+func TestRecordEvictions(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}}
+	ctx := newTestExecuteContext(t, pod)
+	ctx.Client = fake.NewSimpleClientset(pod)
+	ctx.ActionHistory = NewActionHistory(10)
+	e := &EvictExecutor{
+		EvictPods: EvictPods{
+			{Key: k8stypes.NamespacedName{Namespace: "default", Name: "a"}, ActionType: podinfo.Evict, PodCPUUsage: 1},
+			{Key: k8stypes.NamespacedName{Namespace: "default", Name: "gone"}, ActionType: podinfo.Evict, PodCPUUsage: 2},
+		},
+		Rules: []string{"cpu.cpu-usage"},
+	}
+
+	// the outcome is recorded after the evictions are done
+	errPodKeys := e.evictPods(ctx, &ReleaseResource{}, CpuUsage, make(map[string]int32))
+	assert.Equal(t, []string{"not found ", "default/gone"}, errPodKeys)
+	records := ctx.ActionHistory.List(nil)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "a", records[0].Pod)
+		assert.Empty(t, records[0].Error)
+		assert.NotZero(t, records[0].Released)
+		assert.Equal(t, "gone", records[1].Pod)
+		assert.Equal(t, "not found  default/gone", records[1].Error)
+		assert.Zero(t, records[1].Released)
+	}
+}
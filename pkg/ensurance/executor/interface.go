@@ -28,6 +28,13 @@ type ExecuteContext struct {
 	NodeLister    corelisters.NodeLister
 	RuntimeClient pb.RuntimeServiceClient
 	RuntimeConn   *grpc.ClientConn
+	// CgroupDriver and CgroupRoot locate the pod cgroups for the actions which are not supported by the runtime, such as disk io throttle
+	CgroupDriver string
+	CgroupRoot   string
+	// EvictionLimiter admits the evictions by the PodDisruptionBudgets and the eviction caps, nil if no limit
+	EvictionLimiter *EvictionLimiter
+	// ActionHistory records the actions taken on the pods, nil if not recorded
+	ActionHistory *ActionHistory
 
 	// Gap for metrics Evictable/ThrottleAble
 	// Key is the metric name, value is (actual used)-(the lowest watermark for NodeQOSEnsurancePolicies which use throttleDown action)
@@ -40,4 +47,7 @@ type ExecuteContext struct {
 	stateMap map[string][]common.TimeSeries
 
 	executeExcessPercent float64
+
+	// records are the actions taken in this execution
+	records []ActionRecord
 }
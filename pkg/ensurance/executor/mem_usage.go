@@ -1,16 +1,28 @@
 package executor
 
 import (
+	"fmt"
 	"sync"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 
+	"github.com/gocrane/crane/pkg/ensurance/cgroup"
 	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
 	"github.com/gocrane/crane/pkg/ensurance/executor/sort"
-	"github.com/gocrane/crane/pkg/metrics"
 	"github.com/gocrane/crane/pkg/utils"
 )
 
+const (
+	// MemoryHighStepRatio is the step of memory.high for once down-size or up-size
+	MemoryHighStepRatio = 20.0
+	// MinMemoryHigh keeps the throttled pods from thrashing, the memory request of the pod is also kept
+	MinMemoryHigh = 64 * 1024 * 1024.0
+)
+
+// memoryHighUnsupported warns once that the memory throttle takes no effect on the cgroup v1 nodes
+var memoryHighUnsupported sync.Once
+
 func init() {
 	registerMetricMap(memUsage)
 }
@@ -21,50 +33,139 @@ var memUsage = metric{
 	Sortable:       true,
 	SortFunc:       sort.MemUsageSort,
 
-	Throttleable:       false,
-	ThrottleQuantified: false,
-	ThrottleFunc:       nil,
-	RestoreFunc:        nil,
+	Throttleable:       true,
+	ThrottleQuantified: true,
+	ThrottleFunc:       throttleOnePodMemory,
+	RestoreFunc:        restoreOnePodMemory,
 
 	Evictable:       true,
 	EvictQuantified: true,
-	EvictFunc:       memUsageEvictPod,
+	EvictFunc:       evictOnePod(estimateMemUsage),
+
+	EstimateFunc: estimateMemUsage,
 }
 
-func memUsageEvictPod(wg *sync.WaitGroup, ctx *ExecuteContext, index int, totalReleasedResource *ReleaseResource, EvictPods EvictPods) (errPodKeys []string, released ReleaseResource) {
-	wg.Add(1)
+// throttleOnePodMemory sets memory.high of the pod cgroup to a step below its current usage, the pod is forced to reclaim
+// its page cache and anonymous memory without oom. memory.high is only supported on cgroup v2 nodes.
+func throttleOnePodMemory(ctx *ExecuteContext, index int, ThrottleDownPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+	pod, err := ctx.PodLister.Pods(ThrottleDownPods[index].Key.Namespace).Get(ThrottleDownPods[index].Key.Name)
+	if err != nil {
+		errPodKeys = append(errPodKeys, fmt.Sprintf("pod %s not found", ThrottleDownPods[index].Key.String()))
+		return
+	}
+	if !cgroup.IsUnified(ctx.CgroupRoot) {
+		memoryHighUnsupported.Do(func() {
+			klog.Warningf("Memory throttle takes no effect since memory.high is not supported by cgroup v1, evict the pods for %s instead", MemUsage)
+		})
+		klog.V(4).Infof("Skip memory throttle for pod %s, memory.high is not supported by cgroup v1", klog.KObj(pod))
+		return
+	}
 
-	// Calculate release resources
-	released = releaseMemUsage(EvictPods[index])
+	usage := ThrottleDownPods[index].PodMemUsage
+	request, _ := podMemoryRequestAndLimit(pod)
+	memoryHighNew := usage * (1.0 - MemoryHighStepRatio/MaxRatio)
+	if memoryHighNew < request {
+		memoryHighNew = request
+	}
+	if memoryHighNew < MinMemoryHigh {
+		memoryHighNew = MinMemoryHigh
+	}
+	// Nothing to release if the pod is already near its memory request
+	if memoryHighNew >= usage {
+		return
+	}
+
+	cgroupPath := utils.GetCgroupPath(pod, ctx.CgroupDriver)
+	if err = cgroup.SetMemoryHigh(ctx.CgroupRoot, cgroupPath, uint64(memoryHighNew)); err != nil {
+		errPodKeys = append(errPodKeys, fmt.Sprintf("failed to set memory.high for %s, error: %v", ThrottleDownPods[index].Key.String(), err))
+		return
+	}
+	klog.V(4).Infof("ThrottleExecutor avoid pod %s, set memory.high %.0f", klog.KObj(pod), memoryHighNew)
+
+	released = releaseMemUsage(ThrottleDownPods[index], memoryHighNew)
+	klog.V(6).Infof("For pod %s, release %f memory usage", ThrottleDownPods[index].Key.String(), released[MemUsage])
 	totalReleasedResource.Add(released)
+	return
+}
 
-	go func(evictPod podinfo.PodContext) {
-		defer wg.Done()
+// restoreOnePodMemory raises memory.high of the pod cgroup by a step, it's removed once it reaches the memory limit of
+// the pod, or twice the usage for the pods without memory limit.
+func restoreOnePodMemory(ctx *ExecuteContext, index int, ThrottleUpPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+	pod, err := ctx.PodLister.Pods(ThrottleUpPods[index].Key.Namespace).Get(ThrottleUpPods[index].Key.Name)
+	if err != nil {
+		errPodKeys = append(errPodKeys, "not found ", ThrottleUpPods[index].Key.String())
+		return
+	}
+	if !cgroup.IsUnified(ctx.CgroupRoot) {
+		return
+	}
 
-		pod, err := ctx.PodLister.Pods(evictPod.Key.Namespace).Get(evictPod.Key.Name)
-		if err != nil {
-			errPodKeys = append(errPodKeys, "not found ", evictPod.Key.String())
-			return
-		}
-		klog.Warningf("Evicting pod %v", evictPod.Key)
-		err = utils.EvictPodWithGracePeriod(ctx.Client, pod, evictPod.DeletionGracePeriodSeconds)
-		if err != nil {
-			errPodKeys = append(errPodKeys, "evict failed ", evictPod.Key.String())
-			klog.Warningf("Failed to evict pod %s: %v", evictPod.Key.String(), err)
-			return
-		}
-		metrics.ExecutorEvictCountsInc()
+	cgroupPath := utils.GetCgroupPath(pod, ctx.CgroupDriver)
+	memoryHigh, err := cgroup.GetMemoryHigh(ctx.CgroupRoot, cgroupPath)
+	if err != nil {
+		errPodKeys = append(errPodKeys, fmt.Sprintf("failed to get memory.high for %s, error: %v", ThrottleUpPods[index].Key.String(), err))
+		return
+	}
+	// The pod is not throttled
+	if memoryHigh == 0 {
+		return
+	}
+
+	usage := ThrottleUpPods[index].PodMemUsage
+	_, limit := podMemoryRequestAndLimit(pod)
+	memoryHighNew := float64(memoryHigh) * (1.0 + MemoryHighStepRatio/MaxRatio)
+	if (limit > 0 && memoryHighNew >= limit) || (limit == 0 && memoryHighNew >= 2*usage) {
+		memoryHighNew = 0
+	}
 
-		klog.Warningf("Pod %s is evicted", klog.KObj(pod))
-	}(EvictPods[index])
+	if err = cgroup.SetMemoryHigh(ctx.CgroupRoot, cgroupPath, uint64(memoryHighNew)); err != nil {
+		errPodKeys = append(errPodKeys, fmt.Sprintf("failed to set memory.high for %s, error: %v", ThrottleUpPods[index].Key.String(), err))
+		return
+	}
+	klog.V(4).Infof("ThrottleExecutor restore pod %s, set memory.high %.0f", klog.KObj(pod), memoryHighNew)
+
+	// The released resource is unknown if memory.high is removed, same as cpu quota
+	if memoryHighNew > 0 {
+		released = releaseMemUsage(ThrottleUpPods[index], memoryHighNew)
+		klog.V(6).Infof("For pod %s, restore %f memory usage", ThrottleUpPods[index].Key.String(), released[MemUsage])
+		totalReleasedResource.Add(released)
+	}
 	return
 }
 
-func releaseMemUsage(pod podinfo.PodContext) ReleaseResource {
-	if pod.ActionType == podinfo.Evict {
-		return ReleaseResource{
-			MemUsage: pod.PodMemUsage,
+// estimateMemUsage estimates the memory usage released by the pod, the memory request which keeps memory.high from
+// being lowered in the throttle is not considered
+func estimateMemUsage(pod podinfo.PodContext) ReleaseResource {
+	if pod.ActionType == podinfo.ThrottleDown {
+		memoryHighNew := pod.PodMemUsage * (1.0 - MemoryHighStepRatio/MaxRatio)
+		if memoryHighNew < MinMemoryHigh {
+			memoryHighNew = MinMemoryHigh
 		}
+		return releaseMemUsage(pod, memoryHighNew)
 	}
-	return ReleaseResource{}
+	return releaseMemUsage(pod, 0.0)
+}
+
+func releaseMemUsage(pod podinfo.PodContext, memoryHighNew float64) ReleaseResource {
+	return releaseByLimit(pod, MemUsage, pod.PodMemUsage, memoryHighNew)
+}
+
+// podMemoryRequestAndLimit returns the memory request and limit of the pod in bytes, the limit is zero if any container
+// has no memory limit
+func podMemoryRequestAndLimit(pod *v1.Pod) (request float64, limit float64) {
+	for _, c := range pod.Spec.Containers {
+		if r, ok := c.Resources.Requests[v1.ResourceMemory]; ok {
+			request += float64(r.Value())
+		}
+		l, ok := c.Resources.Limits[v1.ResourceMemory]
+		if !ok || limit < 0 {
+			limit = -1
+			continue
+		}
+		limit += float64(l.Value())
+	}
+	if limit < 0 {
+		limit = 0
+	}
+	return
 }
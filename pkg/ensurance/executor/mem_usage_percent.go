@@ -1,14 +1,8 @@
 package executor
 
 import (
-	"sync"
-
-	"k8s.io/klog/v2"
-
 	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
 	"github.com/gocrane/crane/pkg/ensurance/executor/sort"
-	"github.com/gocrane/crane/pkg/metrics"
-	"github.com/gocrane/crane/pkg/utils"
 )
 
 func init() {
@@ -28,36 +22,9 @@ var memUsagePercent = metric{
 
 	Evictable:       true,
 	EvictQuantified: true,
-	EvictFunc:       memUsagePercentEvictPod,
-}
-
-func memUsagePercentEvictPod(wg *sync.WaitGroup, ctx *ExecuteContext, index int, totalReleasedResource *ReleaseResource, EvictPods EvictPods) (errPodKeys []string, released ReleaseResource) {
-	wg.Add(1)
-
-	// Calculate release resources
-	released = releaseMemUsagePercent(EvictPods[index])
-	totalReleasedResource.Add(released)
-
-	go func(evictPod podinfo.PodContext) {
-		defer wg.Done()
-
-		pod, err := ctx.PodLister.Pods(evictPod.Key.Namespace).Get(evictPod.Key.Name)
-		if err != nil {
-			errPodKeys = append(errPodKeys, "not found ", evictPod.Key.String())
-			return
-		}
-		klog.Warningf("Evicting pod %v", evictPod.Key)
-		err = utils.EvictPodWithGracePeriod(ctx.Client, pod, evictPod.DeletionGracePeriodSeconds)
-		if err != nil {
-			errPodKeys = append(errPodKeys, "evict failed ", evictPod.Key.String())
-			klog.Warningf("Failed to evict pod %s: %v", evictPod.Key.String(), err)
-			return
-		}
-		metrics.ExecutorEvictCountsInc()
+	EvictFunc:       evictOnePod(releaseMemUsagePercent),
 
-		klog.Warningf("Pod %s is evicted", klog.KObj(pod))
-	}(EvictPods[index])
-	return
+	EstimateFunc: releaseMemUsagePercent,
 }
 
 func releaseMemUsagePercent(pod podinfo.PodContext) ReleaseResource {
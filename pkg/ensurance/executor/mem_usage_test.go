@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestThrottleAndRestoreMemory(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "stateful-batch", Namespace: "default", UID: "uid-1"},
+		Spec: v1.PodSpec{Containers: []v1.Container{{
+			Name: "job",
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("512Mi")},
+				Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("1200Mi")},
+			},
+		}}},
+		Status: v1.PodStatus{QOSClass: v1.PodQOSBurstable},
+	}
+	ctx := newTestExecuteContext(t, pod)
+	root := ctx.CgroupRoot
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	const mi = 1024 * 1024.0
+
+	// memory.high is not supported by cgroup v1
+	total := ReleaseResource{}
+	errKeys, released := metricMap[MemUsage].ThrottleFunc(ctx, 0, ThrottlePods{{Key: key, PodMemUsage: 1000 * mi, ActionType: podinfo.ThrottleDown}}, &total)
+	assert.Empty(t, errKeys)
+	assert.Empty(t, released)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "cgroup.controllers"), []byte("cpu io memory"), 0644))
+	dir := filepath.Join(root, "kubepods", "burstable", "poduid-1")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "memory.high"), []byte("max\n"), 0644))
+
+	errKeys, released = metricMap[MemUsage].ThrottleFunc(ctx, 0, ThrottlePods{{Key: key, PodMemUsage: 1000 * mi, ActionType: podinfo.ThrottleDown}}, &total)
+	assert.Empty(t, errKeys)
+	assert.InDelta(t, 200*mi, released[MemUsage], 1)
+	high, _ := os.ReadFile(filepath.Join(dir, "memory.high"))
+	assert.Equal(t, "838860800", string(high))
+
+	// memory.high is never lower than the memory request
+	errKeys, released = metricMap[MemUsage].ThrottleFunc(ctx, 0, ThrottlePods{{Key: key, PodMemUsage: 600 * mi, ActionType: podinfo.ThrottleDown}}, &total)
+	assert.Empty(t, errKeys)
+	assert.InDelta(t, 88*mi, released[MemUsage], 1)
+	high, _ = os.ReadFile(filepath.Join(dir, "memory.high"))
+	assert.Equal(t, "536870912", string(high))
+
+	errKeys, released = metricMap[MemUsage].RestoreFunc(ctx, 0, ThrottlePods{{Key: key, PodMemUsage: 500 * mi, ActionType: podinfo.ThrottleUp}}, &total)
+	assert.Empty(t, errKeys)
+	assert.InDelta(t, 512*1.2*mi-500*mi, released[MemUsage], 1)
+
+	// memory.high is removed once it reaches the memory limit
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "memory.high"), []byte("1048576000\n"), 0644))
+	errKeys, released = metricMap[MemUsage].RestoreFunc(ctx, 0, ThrottlePods{{Key: key, PodMemUsage: 500 * mi, ActionType: podinfo.ThrottleUp}}, &total)
+	assert.Empty(t, errKeys)
+	assert.Empty(t, released)
+	high, _ = os.ReadFile(filepath.Join(dir, "memory.high"))
+	assert.Equal(t, "max", string(high))
+}
@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	execsort "github.com/gocrane/crane/pkg/ensurance/executor/sort"
 )
 
 type metric struct {
@@ -26,8 +27,13 @@ type metric struct {
 
 	Evictable       bool
 	EvictQuantified bool
-	// If use goroutine to evcit, make sure to calculate release resources outside the goroutine
-	EvictFunc func(wg *sync.WaitGroup, ctx *ExecuteContext, index int, totalReleasedResource *ReleaseResource, EvictPods EvictPods) (errPodKeys []string, released ReleaseResource)
+	// If use goroutine to evcit, make sure to calculate release resources outside the goroutine, the outcome of the
+	// eviction is set to the result in the goroutine and read after the wait group is done
+	EvictFunc func(wg *sync.WaitGroup, ctx *ExecuteContext, index int, totalReleasedResource *ReleaseResource, EvictPods EvictPods) (result *EvictResult, released ReleaseResource)
+
+	// EstimateFunc estimates the resource released by throttling the pod one step or evicting it according to its action type,
+	// without taking the action. It's used to simulate the dry run actions, the released resource is unknown if it's nil.
+	EstimateFunc func(pod podinfo.PodContext) ReleaseResource
 }
 
 var metricMap = make(map[WatermarkMetric]metric)
@@ -35,3 +41,14 @@ var metricMap = make(map[WatermarkMetric]metric)
 func registerMetricMap(m metric) {
 	metricMap[m.Name] = m
 }
+
+// sortPods sorts the pods by the ranking policy if it's declared, otherwise by the SortFunc of the metric
+func sortPods(m WatermarkMetric, policy execsort.RankingPolicy, pods []podinfo.PodContext) {
+	if len(policy) != 0 {
+		policy.Sort(pods)
+	} else if metricMap[m].Sortable {
+		metricMap[m].SortFunc(pods)
+	} else {
+		execsort.GeneralSorter(pods)
+	}
+}
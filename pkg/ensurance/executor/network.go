@@ -0,0 +1,192 @@
+package executor
+
+import (
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/ensurance/bandwidth"
+	"github.com/gocrane/crane/pkg/ensurance/cgroup"
+	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	"github.com/gocrane/crane/pkg/ensurance/executor/sort"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+const (
+	// NetworkStepRatio is the step of the bandwidth limit for once down-size or up-size
+	NetworkStepRatio = 20.0
+	// MinNetworkKibps is the min bandwidth limit, so that the throttled pods can keep their connections
+	MinNetworkKibps = 1000.0
+	// MaxUpNetworkKibps is the bandwidth limit above which the limit is removed when restoring
+	MaxUpNetworkKibps = 10 * 1000 * 1000.0
+)
+
+// The bandwidth limit functions are variables to be replaced in tests, since they need a pod network namespace
+var (
+	getBandwidthLimit = bandwidth.GetLimit
+	setBandwidthLimit = bandwidth.SetLimit
+)
+
+func init() {
+	registerMetricMap(netReceiveKiBPS)
+	registerMetricMap(netSentKiBPS)
+}
+
+var netReceiveKiBPS = metric{
+	Name:           NetReceiveKiBPS,
+	ActionPriority: 3,
+	Sortable:       true,
+	SortFunc:       sort.NetworkReceiveSort,
+
+	Throttleable:       true,
+	ThrottleQuantified: true,
+	ThrottleFunc:       throttleOnePodNetwork(NetReceiveKiBPS),
+	RestoreFunc:        restoreOnePodNetwork(NetReceiveKiBPS),
+
+	Evictable:       true,
+	EvictQuantified: true,
+	EvictFunc:       evictOnePod(estimateNetwork(NetReceiveKiBPS)),
+
+	EstimateFunc: estimateNetwork(NetReceiveKiBPS),
+}
+
+var netSentKiBPS = metric{
+	Name:           NetSentKiBPS,
+	ActionPriority: 3,
+	Sortable:       true,
+	SortFunc:       sort.NetworkSentSort,
+
+	Throttleable:       true,
+	ThrottleQuantified: true,
+	ThrottleFunc:       throttleOnePodNetwork(NetSentKiBPS),
+	RestoreFunc:        restoreOnePodNetwork(NetSentKiBPS),
+
+	Evictable:       true,
+	EvictQuantified: true,
+	EvictFunc:       evictOnePod(estimateNetwork(NetSentKiBPS)),
+
+	EstimateFunc: estimateNetwork(NetSentKiBPS),
+}
+
+// throttleOnePodNetwork limits the ingress or egress bandwidth of the pod to a step below its current usage by a tbf
+// qdisc, the ingress is shaped on the host side veth and the egress is shaped on the interface in the pod.
+func throttleOnePodNetwork(m WatermarkMetric) func(ctx *ExecuteContext, index int, ThrottleDownPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+	return func(ctx *ExecuteContext, index int, ThrottleDownPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+		pod, err := ctx.PodLister.Pods(ThrottleDownPods[index].Key.Namespace).Get(ThrottleDownPods[index].Key.Name)
+		if err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("pod %s not found", ThrottleDownPods[index].Key.String()))
+			return
+		}
+		// The pods in host network share the node interfaces, which can't be shaped for a single pod
+		if pod.Spec.HostNetwork {
+			return
+		}
+
+		usage := podNetworkUsage(ThrottleDownPods[index], m)
+		limitNew := usage * (1.0 - NetworkStepRatio/MaxRatio)
+		if limitNew < MinNetworkKibps {
+			limitNew = MinNetworkKibps
+		}
+		// Nothing to release if the pod is almost idle on network
+		if limitNew >= usage {
+			return
+		}
+
+		pid, err := cgroup.GetFirstPid(ctx.CgroupRoot, utils.GetCgroupPath(pod, ctx.CgroupDriver))
+		if err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("failed to get process of %s, error: %v", ThrottleDownPods[index].Key.String(), err))
+			return
+		}
+		if err = setBandwidthLimit(pid, networkDirection(m), uint64(limitNew*1000)); err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("failed to set %s bandwidth limit for %s, error: %v", networkDirection(m), ThrottleDownPods[index].Key.String(), err))
+			return
+		}
+		klog.V(4).Infof("ThrottleExecutor avoid pod %s, set %s bandwidth limit %.2f Kibps", klog.KObj(pod), networkDirection(m), limitNew)
+
+		released = releaseNetwork(ThrottleDownPods[index], m, limitNew)
+		klog.V(6).Infof("For pod %s, release %f %s", ThrottleDownPods[index].Key.String(), released[m], m)
+		totalReleasedResource.Add(released)
+		return
+	}
+}
+
+// restoreOnePodNetwork raises the bandwidth limit of the pod by a step, the limit is removed if it's raised above
+// MaxUpNetworkKibps.
+func restoreOnePodNetwork(m WatermarkMetric) func(ctx *ExecuteContext, index int, ThrottleUpPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+	return func(ctx *ExecuteContext, index int, ThrottleUpPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+		pod, err := ctx.PodLister.Pods(ThrottleUpPods[index].Key.Namespace).Get(ThrottleUpPods[index].Key.Name)
+		if err != nil {
+			errPodKeys = append(errPodKeys, "not found ", ThrottleUpPods[index].Key.String())
+			return
+		}
+		if pod.Spec.HostNetwork {
+			return
+		}
+
+		pid, err := cgroup.GetFirstPid(ctx.CgroupRoot, utils.GetCgroupPath(pod, ctx.CgroupDriver))
+		if err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("failed to get process of %s, error: %v", ThrottleUpPods[index].Key.String(), err))
+			return
+		}
+		current, err := getBandwidthLimit(pid, networkDirection(m))
+		if err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("failed to get %s bandwidth limit for %s, error: %v", networkDirection(m), ThrottleUpPods[index].Key.String(), err))
+			return
+		}
+		// The pod is not throttled
+		if current == 0 {
+			return
+		}
+
+		limitNew := float64(current) / 1000 * (1.0 + NetworkStepRatio/MaxRatio)
+		if limitNew > MaxUpNetworkKibps {
+			limitNew = 0
+		}
+		if err = setBandwidthLimit(pid, networkDirection(m), uint64(limitNew*1000)); err != nil {
+			errPodKeys = append(errPodKeys, fmt.Sprintf("failed to set %s bandwidth limit for %s, error: %v", networkDirection(m), ThrottleUpPods[index].Key.String(), err))
+			return
+		}
+		klog.V(4).Infof("ThrottleExecutor restore pod %s, set %s bandwidth limit %.2f Kibps", klog.KObj(pod), networkDirection(m), limitNew)
+
+		// The released resource is unknown if the limit is removed, same as cpu quota
+		if limitNew > 0 {
+			released = releaseNetwork(ThrottleUpPods[index], m, limitNew)
+			klog.V(6).Infof("For pod %s, restore %f %s", ThrottleUpPods[index].Key.String(), released[m], m)
+			totalReleasedResource.Add(released)
+		}
+		return
+	}
+}
+
+func networkDirection(m WatermarkMetric) bandwidth.Direction {
+	if m == NetReceiveKiBPS {
+		return bandwidth.Ingress
+	}
+	return bandwidth.Egress
+}
+
+func podNetworkUsage(pod podinfo.PodContext, m WatermarkMetric) float64 {
+	if m == NetReceiveKiBPS {
+		return pod.PodNetworkReceiveKibps
+	}
+	return pod.PodNetworkSentKibps
+}
+
+// estimateNetwork estimates the bandwidth released by the pod, the pods in host network which can't be throttled are
+// not told from the others
+func estimateNetwork(m WatermarkMetric) func(pod podinfo.PodContext) ReleaseResource {
+	return func(pod podinfo.PodContext) ReleaseResource {
+		if pod.ActionType == podinfo.ThrottleDown {
+			limitNew := podNetworkUsage(pod, m) * (1.0 - NetworkStepRatio/MaxRatio)
+			if limitNew < MinNetworkKibps {
+				limitNew = MinNetworkKibps
+			}
+			return releaseNetwork(pod, m, limitNew)
+		}
+		return releaseNetwork(pod, m, 0.0)
+	}
+}
+
+func releaseNetwork(pod podinfo.PodContext, m WatermarkMetric, limitNew float64) ReleaseResource {
+	return releaseByLimit(pod, m, podNetworkUsage(pod, m), limitNew)
+}
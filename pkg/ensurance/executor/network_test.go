@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/ensurance/bandwidth"
+	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestThrottleAndRestoreNetwork(t *testing.T) {
+	limits := map[bandwidth.Direction]uint64{}
+	getBandwidthLimit = func(pid int, direction bandwidth.Direction) (uint64, error) {
+		assert.Equal(t, 1234, pid)
+		return limits[direction], nil
+	}
+	setBandwidthLimit = func(pid int, direction bandwidth.Direction, bitsPerSecond uint64) error {
+		assert.Equal(t, 1234, pid)
+		limits[direction] = bitsPerSecond
+		return nil
+	}
+	defer func() {
+		getBandwidthLimit = bandwidth.GetLimit
+		setBandwidthLimit = bandwidth.SetLimit
+	}()
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "default", UID: "uid-1"},
+		Status:     v1.PodStatus{QOSClass: v1.PodQOSBestEffort},
+	}
+	ctx := newTestExecuteContext(t, pod)
+	root := ctx.CgroupRoot
+	dir := filepath.Join(root, "cpu", "kubepods", "besteffort", "poduid-1", "container1")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte("1234\n"), 0644))
+
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+
+	total := ReleaseResource{}
+	errKeys, released := metricMap[NetSentKiBPS].ThrottleFunc(ctx, 0, ThrottlePods{{Key: key, PodNetworkSentKibps: 100000, ActionType: podinfo.ThrottleDown}}, &total)
+	assert.Empty(t, errKeys)
+	assert.InDelta(t, 20000.0, released[NetSentKiBPS], 1e-6)
+	assert.Equal(t, uint64(80000000), limits[bandwidth.Egress])
+	_, ok := limits[bandwidth.Ingress]
+	assert.False(t, ok)
+
+	errKeys, released = metricMap[NetSentKiBPS].RestoreFunc(ctx, 0, ThrottlePods{{Key: key, PodNetworkSentKibps: 80000, ActionType: podinfo.ThrottleUp}}, &total)
+	assert.Empty(t, errKeys)
+	assert.InDelta(t, 16000.0, released[NetSentKiBPS], 1e-6)
+	assert.Equal(t, uint64(96000000), limits[bandwidth.Egress])
+
+	// The limit is removed once it's restored above the max
+	limits[bandwidth.Egress] = uint64(MaxUpNetworkKibps * 1000)
+	errKeys, released = metricMap[NetSentKiBPS].RestoreFunc(ctx, 0, ThrottlePods{{Key: key, ActionType: podinfo.ThrottleUp}}, &total)
+	assert.Empty(t, errKeys)
+	assert.Empty(t, released)
+	assert.Equal(t, uint64(0), limits[bandwidth.Egress])
+}
@@ -0,0 +1,70 @@
+package executor
+
+import (
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	policyv1listers "k8s.io/client-go/listers/policy/v1"
+	policyv1beta1listers "k8s.io/client-go/listers/policy/v1beta1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// PodDisruptionBudgetLister returns the disruptions allowed by the PodDisruptionBudgets of the pods
+type PodDisruptionBudgetLister interface {
+	HasSynced() bool
+	// GetPodDisruptionsAllowed returns the disruptions allowed by each budget of the pod, keyed by namespace/name
+	GetPodDisruptionsAllowed(pod *v1.Pod) map[string]int32
+}
+
+// NewPodDisruptionBudgetLister watches the PodDisruptionBudgets in policy/v1 if it's served by the apiserver, otherwise
+// in policy/v1beta1, the informer is registered in the factory.
+func NewPodDisruptionBudgetLister(client clientset.Interface, factory informers.SharedInformerFactory) PodDisruptionBudgetLister {
+	if _, err := utils.GetGroupVersionResource(client.Discovery(), policyv1.SchemeGroupVersion.String(), "PodDisruptionBudget"); err != nil {
+		klog.V(2).Infof("Watch the PodDisruptionBudgets in policy/v1beta1, policy/v1 is not served: %v", err)
+		informer := factory.Policy().V1beta1().PodDisruptionBudgets()
+		return &podDisruptionBudgetsV1beta1{lister: informer.Lister(), synced: informer.Informer().HasSynced}
+	}
+	informer := factory.Policy().V1().PodDisruptionBudgets()
+	return &podDisruptionBudgetsV1{lister: informer.Lister(), synced: informer.Informer().HasSynced}
+}
+
+type podDisruptionBudgetsV1 struct {
+	lister policyv1listers.PodDisruptionBudgetLister
+	synced cache.InformerSynced
+}
+
+func (p *podDisruptionBudgetsV1) HasSynced() bool {
+	return p.synced()
+}
+
+func (p *podDisruptionBudgetsV1) GetPodDisruptionsAllowed(pod *v1.Pod) map[string]int32 {
+	// an error is returned if the pod has no budget
+	pdbs, _ := p.lister.GetPodPodDisruptionBudgets(pod)
+	allowed := make(map[string]int32, len(pdbs))
+	for _, pdb := range pdbs {
+		allowed[pdb.Namespace+"/"+pdb.Name] = pdb.Status.DisruptionsAllowed
+	}
+	return allowed
+}
+
+type podDisruptionBudgetsV1beta1 struct {
+	lister policyv1beta1listers.PodDisruptionBudgetLister
+	synced cache.InformerSynced
+}
+
+func (p *podDisruptionBudgetsV1beta1) HasSynced() bool {
+	return p.synced()
+}
+
+func (p *podDisruptionBudgetsV1beta1) GetPodDisruptionsAllowed(pod *v1.Pod) map[string]int32 {
+	pdbs, _ := p.lister.GetPodPodDisruptionBudgets(pod)
+	allowed := make(map[string]int32, len(pdbs))
+	for _, pdb := range pdbs {
+		allowed[pdb.Namespace+"/"+pdb.Name] = pdb.Status.DisruptionsAllowed
+	}
+	return allowed
+}
@@ -2,6 +2,7 @@ package podinfo
 
 import (
 	"fmt"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -65,6 +66,42 @@ func GetPodUsage(metricName string, stateMap map[string][]common.TimeSeries, pod
 	return podUsage, containerUsages
 }
 
+// GetPodDeviceUsage returns the usages of the pod cgroup on every device, the keys are the major:minor of the devices
+func GetPodDeviceUsage(metricName string, stateMap map[string][]common.TimeSeries, pod *v1.Pod) map[string]float64 {
+	var podMaps = map[string]string{common.LabelNamePodName: pod.Name, common.LabelNamePodNamespace: pod.Namespace, common.LabelNamePodUid: string(pod.UID), common.LabelNameContainerId: ""}
+	var usages = make(map[string]float64)
+	for _, vv := range stateMap[metricName] {
+		var labelMaps = common.Labels2Maps(vv.Labels)
+		if utils.ContainMaps(labelMaps, podMaps) && labelMaps[common.LabelNameDevice] != "" && len(vv.Samples) > 0 {
+			usages[labelMaps[common.LabelNameDevice]] = vv.Samples[0].Value
+		}
+	}
+	return usages
+}
+
+// DiskIODevice returns the device whose usage decides the gap of the node disk io metric, which is the busiest disk of
+// the node, or the device used most in usages if the devices of the node disks are unknown
+func DiskIODevice(stateMap map[string][]common.TimeSeries, metricName string, usages map[string]float64) string {
+	var device string
+	var maxUsed float64
+	for _, ts := range stateMap[metricName] {
+		if len(ts.Samples) > 0 && ts.Samples[0].Value > maxUsed {
+			device, maxUsed = common.Labels2Maps(ts.Labels)[common.LabelNameDevice], ts.Samples[0].Value
+		}
+	}
+	if device != "" {
+		return device
+	}
+
+	maxUsed = 0
+	for d, usage := range usages {
+		if usage > maxUsed {
+			device, maxUsed = d, usage
+		}
+	}
+	return device
+}
+
 type CPURatio struct {
 	//the min of cpu ratio for pods
 	MinCPURatio uint64 `json:"minCPURatio,omitempty"`
@@ -84,6 +121,10 @@ type PodContext struct {
 	Priority                   int32
 	StartTime                  *metav1.Time
 	DeletionGracePeriodSeconds *int32
+	// StartupDuration is the time from the start of the pod to its readiness, it's zero if the pod is not ready
+	StartupDuration time.Duration
+	// NamespaceWeight is the ranking weight in the label of the namespace of the pod
+	NamespaceWeight float64
 
 	ElasticCPULimit int64
 	ElasticMemLimit int64
@@ -93,6 +134,16 @@ type PodContext struct {
 
 	PodMemUsage float64
 
+	// PodCPURequest is in cores and PodMemRequest is in bytes
+	PodCPURequest, PodMemRequest float64
+
+	// PodDiskReadKiBPS and PodDiskWriteKiBPS are the usages on the disk which decides the gaps, see DiskIODevice
+	PodDiskReadKiBPS, PodDiskWriteKiBPS float64
+	// PodDiskReadKiBPSByDevice and PodDiskWriteKiBPSByDevice are the usages on every device, the keys are major:minor
+	PodDiskReadKiBPSByDevice, PodDiskWriteKiBPSByDevice map[string]float64
+
+	PodNetworkReceiveKibps, PodNetworkSentKibps float64
+
 	ActionType  ActionType
 	CPUThrottle CPURatio
 	Executed    bool
@@ -133,7 +184,25 @@ func BuildPodActionContext(pod *v1.Pod, stateMap map[string][]common.TimeSeries,
 	podContext.ElasticMemLimit = utils.GetElasticResourceLimit(pod, v1.ResourceMemory)
 	podContext.PodMemUsage, _ = GetPodUsage(string(stypes.MetricNameContainerMemTotalUsage), stateMap, pod)
 
+	podContext.PodDiskReadKiBPS, _ = GetPodUsage(string(stypes.MetricNameContainerDiskReadKiBPS), stateMap, pod)
+	podContext.PodDiskWriteKiBPS, _ = GetPodUsage(string(stypes.MetricNameContainerDiskWriteKiBPS), stateMap, pod)
+	podContext.PodDiskReadKiBPSByDevice = GetPodDeviceUsage(string(stypes.MetricNameContainerDiskDeviceReadKiBPS), stateMap, pod)
+	podContext.PodDiskWriteKiBPSByDevice = GetPodDeviceUsage(string(stypes.MetricNameContainerDiskDeviceWriteKiBPS), stateMap, pod)
+	if len(podContext.PodDiskReadKiBPSByDevice) != 0 {
+		podContext.PodDiskReadKiBPS = podContext.PodDiskReadKiBPSByDevice[DiskIODevice(stateMap, string(stypes.MetricDiskReadKiBPS), podContext.PodDiskReadKiBPSByDevice)]
+	}
+	if len(podContext.PodDiskWriteKiBPSByDevice) != 0 {
+		podContext.PodDiskWriteKiBPS = podContext.PodDiskWriteKiBPSByDevice[DiskIODevice(stateMap, string(stypes.MetricDiskWriteKiBPS), podContext.PodDiskWriteKiBPSByDevice)]
+	}
+
+	podContext.PodNetworkReceiveKibps, _ = GetPodUsage(string(stypes.MetricNamePodNetworkReceiveKiBPS), stateMap, pod)
+	podContext.PodNetworkSentKibps, _ = GetPodUsage(string(stypes.MetricNamePodNetworkSentKiBPS), stateMap, pod)
+
+	podContext.PodCPURequest = getPodRequest(pod, v1.ResourceCPU)
+	podContext.PodMemRequest = getPodRequest(pod, v1.ResourceMemory)
+
 	podContext.StartTime = pod.Status.StartTime
+	podContext.StartupDuration = getStartupDuration(pod)
 
 	if action.Spec.Throttle != nil {
 		podContext.CPUThrottle.MinCPURatio = uint64(action.Spec.Throttle.CPUThrottle.MinCPURatio)
@@ -144,3 +213,25 @@ func BuildPodActionContext(pod *v1.Pod, stateMap map[string][]common.TimeSeries,
 
 	return podContext
 }
+
+func getPodRequest(pod *v1.Pod, resName v1.ResourceName) float64 {
+	var request float64
+	for _, container := range pod.Spec.Containers {
+		if quantity, ok := container.Resources.Requests[resName]; ok {
+			request += quantity.AsApproximateFloat64()
+		}
+	}
+	return request
+}
+
+func getStartupDuration(pod *v1.Pod) time.Duration {
+	if pod.Status.StartTime == nil {
+		return 0
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady && condition.Status == v1.ConditionTrue && condition.LastTransitionTime.After(pod.Status.StartTime.Time) {
+			return condition.LastTransitionTime.Sub(pod.Status.StartTime.Time)
+		}
+	}
+	return 0
+}
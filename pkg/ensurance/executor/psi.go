@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	"github.com/gocrane/crane/pkg/ensurance/executor/sort"
+)
+
+// The node pressure metrics tell the interference but not how much resource to release, so they can't be quantified.
+// All the candidate pods are throttled by one step on cpu or memory once the pressure is above the watermark, and
+// restored by one step once it's below.
+func init() {
+	for _, m := range []types.MetricName{
+		types.MetricNameCpuPressureSomeAvg10, types.MetricNameCpuPressureSomeAvg60, types.MetricNameCpuPressureSomeAvg300,
+		types.MetricNameCpuPressureFullAvg10, types.MetricNameCpuPressureFullAvg60, types.MetricNameCpuPressureFullAvg300,
+	} {
+		registerMetricMap(metric{
+			Name:           WatermarkMetric(m),
+			ActionPriority: 5,
+			Sortable:       true,
+			SortFunc:       sort.CpuUsageSort,
+
+			Throttleable:       true,
+			ThrottleQuantified: false,
+			ThrottleFunc:       throttleOnePodCpu,
+			RestoreFunc:        restoreOnePodCpu,
+		})
+	}
+
+	for _, m := range []types.MetricName{
+		types.MetricNameMemoryPressureSomeAvg10, types.MetricNameMemoryPressureSomeAvg60, types.MetricNameMemoryPressureSomeAvg300,
+		types.MetricNameMemoryPressureFullAvg10, types.MetricNameMemoryPressureFullAvg60, types.MetricNameMemoryPressureFullAvg300,
+	} {
+		registerMetricMap(metric{
+			Name:           WatermarkMetric(m),
+			ActionPriority: 5,
+			Sortable:       true,
+			SortFunc:       sort.MemUsageSort,
+
+			Throttleable:       true,
+			ThrottleQuantified: false,
+			ThrottleFunc:       throttleOnePodMemory,
+			RestoreFunc:        restoreOnePodMemory,
+		})
+	}
+}
@@ -1,5 +1,9 @@
 package executor
 
+import (
+	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
 type ReleaseResource map[WatermarkMetric]float64
 
 func (r ReleaseResource) Add(new ReleaseResource) {
@@ -10,3 +14,23 @@ func (r ReleaseResource) Add(new ReleaseResource) {
 		r[metric] += value
 	}
 }
+
+// releaseByLimit returns the usage of the metric released by the action on the pod, the whole usage is released by the
+// eviction, and the gap between the usage and the new limit is released by the throttle.
+func releaseByLimit(pod podinfo.PodContext, m WatermarkMetric, usage, limitNew float64) ReleaseResource {
+	var reduction float64
+	switch pod.ActionType {
+	case podinfo.Evict:
+		reduction = usage
+	case podinfo.ThrottleDown:
+		reduction = usage - limitNew
+	case podinfo.ThrottleUp:
+		reduction = limitNew - usage
+	}
+	if reduction > 0 {
+		return ReleaseResource{
+			m: reduction,
+		}
+	}
+	return ReleaseResource{}
+}
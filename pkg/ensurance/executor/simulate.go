@@ -0,0 +1,126 @@
+package executor
+
+import (
+	"time"
+
+	"github.com/gocrane/crane/pkg/common"
+	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	execsort "github.com/gocrane/crane/pkg/ensurance/executor/sort"
+)
+
+// SimulationReport tells what the throttle and evict executors would do for the dry run rules
+type SimulationReport struct {
+	Time time.Time `json:"time"`
+	// Rules are the triggered dry run rules, in the format of <NodeQOS name>.<rule name>
+	Rules    []string           `json:"rules"`
+	Throttle []MetricSimulation `json:"throttle,omitempty"`
+	Evict    []MetricSimulation `json:"evict,omitempty"`
+}
+
+// MetricSimulation is the simulation of the actions to release a metric
+type MetricSimulation struct {
+	Metric WatermarkMetric `json:"metric"`
+	// Quantified is false if the gap of the metric can't be calculated, then all the candidate pods are acted on
+	Quantified bool `json:"quantified"`
+	// Gap is the usage to be released to get below the watermark, including the execute excess
+	Gap float64 `json:"gap"`
+	// Released is the estimated usage released by all the pods
+	Released float64 `json:"released"`
+	// GapClosed is whether the released usage would close the gap
+	GapClosed bool `json:"gapClosed"`
+	// Pods are the pods which would be acted on, in the order of the sort of the metric
+	Pods []SimulatedPod `json:"pods"`
+}
+
+// SimulatedPod is a pod which would be throttled or evicted
+type SimulatedPod struct {
+	Pod      string  `json:"pod"`
+	Released float64 `json:"released"`
+}
+
+// Simulate runs the steps of the throttle and evict executors without taking the actions, the released usage of the pods
+// is estimated by the EstimateFunc of the metrics
+func Simulate(ae AvoidanceExecutor, executeExcessPercent float64) SimulationReport {
+	return SimulationReport{
+		Throttle: ae.ThrottleExecutor.simulate(ae.StateMap, executeExcessPercent),
+		Evict:    ae.EvictExecutor.simulate(ae.StateMap, executeExcessPercent),
+	}
+}
+
+func (t *ThrottleExecutor) simulate(stateMap map[string][]common.TimeSeries, executeExcessPercent float64) []MetricSimulation {
+	if len(t.ThrottleDownPods) == 0 {
+		return nil
+	}
+	// copy the pods, the executor sorts them again when it takes the actions
+	pods := append([]podinfo.PodContext{}, t.ThrottleDownPods...)
+
+	quantified, notQuantified := t.ThrottleDownWatermark.DivideMetricsByThrottleQuantified()
+	if len(notQuantified) == 0 {
+		gaps := calculateGaps(withForecasts(stateMap, t.ThrottleDownForecasts), t.throttleDownWatermarks(), nil, executeExcessPercent)
+		if !gaps.HasUsageMissedMetric() {
+			return simulatePrecisely(quantified, t.RankingPolicy, pods, gaps, false)
+		}
+	}
+	return simulateAll(t.ThrottleDownWatermark.GetHighestPriorityThrottleAbleMetric(), pods)
+}
+
+func (e *EvictExecutor) simulate(stateMap map[string][]common.TimeSeries, executeExcessPercent float64) []MetricSimulation {
+	if len(e.EvictPods) == 0 {
+		return nil
+	}
+	pods := append([]podinfo.PodContext{}, e.EvictPods...)
+
+	quantified, notQuantified := e.EvictWatermark.DivideMetricsByEvictQuantified()
+	if len(notQuantified) == 0 {
+		gaps := calculateGaps(stateMap, nil, e, executeExcessPercent)
+		if !gaps.HasUsageMissedMetric() {
+			return simulatePrecisely(quantified, e.RankingPolicy, pods, gaps, true)
+		}
+	}
+	return simulateAll(e.EvictWatermark.GetHighestPriorityEvictableMetric(), pods)
+}
+
+// simulatePrecisely acts on the sorted pods one by one until the gap of each metric is closed, the evicted pods are
+// not acted on again for the next metrics while the throttled pods are
+func simulatePrecisely(quantified []WatermarkMetric, policy execsort.RankingPolicy, pods []podinfo.PodContext, gaps Gaps, evict bool) []MetricSimulation {
+	var result []MetricSimulation
+	for _, m := range quantified {
+		sortPods(m, policy, pods)
+
+		simulation := MetricSimulation{Metric: m, Quantified: true, Gap: gaps[m]}
+		for index := 0; !gaps.TargetGapsRemoved(m) && index < len(pods); index++ {
+			if evict && pods[index].Executed {
+				continue
+			}
+			released := estimate(m, pods[index])
+			simulation.Pods = append(simulation.Pods, SimulatedPod{Pod: pods[index].Key.String(), Released: released})
+			simulation.Released += released
+			pods[index].Executed = true
+			gaps[m] -= released
+		}
+		simulation.GapClosed = gaps.TargetGapsRemoved(m)
+		result = append(result, simulation)
+	}
+	return result
+}
+
+// simulateAll acts on all the pods by the highest priority metric, since the gaps can't be calculated
+func simulateAll(m WatermarkMetric, pods []podinfo.PodContext) []MetricSimulation {
+	if m == "" {
+		return nil
+	}
+	simulation := MetricSimulation{Metric: m}
+	for _, pod := range pods {
+		released := estimate(m, pod)
+		simulation.Pods = append(simulation.Pods, SimulatedPod{Pod: pod.Key.String(), Released: released})
+		simulation.Released += released
+	}
+	return []MetricSimulation{simulation}
+}
+
+func estimate(m WatermarkMetric, pod podinfo.PodContext) float64 {
+	if metricMap[m].EstimateFunc == nil {
+		return 0
+	}
+	return metricMap[m].EstimateFunc(pod)[m]
+}
@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"container/heap"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/collector/types"
+	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestSimulate(t *testing.T) {
+	const gi = 1024 * 1024 * 1024.0
+	stateMap := map[string][]common.TimeSeries{
+		string(CpuUsage): {{Samples: []common.Sample{{Value: 5000}}}},
+		string(MemUsage): {{Samples: []common.Sample{{Value: 10 * gi}}}},
+	}
+	cpuWatermark, memWatermark := &Watermark{}, &Watermark{}
+	heap.Push(cpuWatermark, resource.MustParse("4000"))
+	heap.Push(memWatermark, resource.MustParse("8Gi"))
+
+	evictPods := EvictPods{
+		{Key: k8stypes.NamespacedName{Namespace: "default", Name: "a"}, PodCPUUsage: 0.5, ActionType: podinfo.Evict},
+		{Key: k8stypes.NamespacedName{Namespace: "default", Name: "b"}, PodCPUUsage: 2, ActionType: podinfo.Evict},
+		{Key: k8stypes.NamespacedName{Namespace: "default", Name: "c"}, PodCPUUsage: 1, ActionType: podinfo.Evict},
+	}
+	throttlePods := ThrottlePods{
+		{Key: k8stypes.NamespacedName{Namespace: "default", Name: "a"}, PodMemUsage: 2 * gi, ActionType: podinfo.ThrottleDown},
+		{Key: k8stypes.NamespacedName{Namespace: "default", Name: "b"}, PodMemUsage: 4 * gi, ActionType: podinfo.ThrottleDown},
+	}
+	ae := AvoidanceExecutor{
+		EvictExecutor:    EvictExecutor{EvictPods: evictPods, EvictWatermark: Watermarks{CpuUsage: cpuWatermark}},
+		ThrottleExecutor: ThrottleExecutor{ThrottleDownPods: throttlePods, ThrottleDownWatermark: Watermarks{MemUsage: memWatermark}},
+		StateMap:         stateMap,
+	}
+
+	report := Simulate(ae, 0)
+	// the pod with the most cpu usage is evicted first, and it closes the gap
+	assert.Equal(t, []MetricSimulation{{
+		Metric:     CpuUsage,
+		Quantified: true,
+		Gap:        1000,
+		Released:   2000,
+		GapClosed:  true,
+		Pods:       []SimulatedPod{{Pod: "default/b", Released: 2000}},
+	}}, report.Evict)
+	// both pods are throttled by one step, but the gap is not closed
+	if assert.Len(t, report.Throttle, 1) {
+		simulation := report.Throttle[0]
+		assert.Equal(t, MemUsage, simulation.Metric)
+		assert.Equal(t, 2*gi, simulation.Gap)
+		assert.InDelta(t, 1.2*gi, simulation.Released, 1)
+		assert.False(t, simulation.GapClosed)
+		if assert.Len(t, simulation.Pods, 2) {
+			assert.Equal(t, "default/b", simulation.Pods[0].Pod)
+			assert.InDelta(t, 0.8*gi, simulation.Pods[0].Released, 1)
+			assert.Equal(t, "default/a", simulation.Pods[1].Pod)
+			assert.InDelta(t, 0.4*gi, simulation.Pods[1].Released, 1)
+		}
+	}
+	// the executor is not changed
+	assert.Equal(t, "a", ae.EvictExecutor.EvictPods[0].Key.Name)
+	assert.False(t, ae.EvictExecutor.EvictPods[1].Executed)
+
+	// all the pods are throttled if the metric can't be quantified
+	pressureWatermark := &Watermark{}
+	heap.Push(pressureWatermark, resource.MustParse("10"))
+	ae.ThrottleExecutor.ThrottleDownWatermark = Watermarks{WatermarkMetric(types.MetricNameMemoryPressureSomeAvg10): pressureWatermark}
+	report = Simulate(ae, 0)
+	assert.Equal(t, []MetricSimulation{{
+		Metric: WatermarkMetric(types.MetricNameMemoryPressureSomeAvg10),
+		Pods:   []SimulatedPod{{Pod: "default/a"}, {Pod: "default/b"}},
+	}}, report.Throttle)
+}
@@ -0,0 +1,24 @@
+package sort
+
+import (
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+func DiskReadSort(pods []podinfo.PodContext) {
+	orderedBy(ComparePriority, ComparePodQOSClass, CompareDiskRead, CompareRunningTime).Sort(pods)
+}
+
+func DiskWriteSort(pods []podinfo.PodContext) {
+	orderedBy(ComparePriority, ComparePodQOSClass, CompareDiskWrite, CompareRunningTime).Sort(pods)
+}
+
+// CompareDiskRead compares the disk read bandwidth of pods, the pod reading more is in front
+func CompareDiskRead(p1, p2 podinfo.PodContext) int32 {
+	return utils.CmpFloat(p2.PodDiskReadKiBPS, p1.PodDiskReadKiBPS)
+}
+
+// CompareDiskWrite compares the disk write bandwidth of pods, the pod writing more is in front
+func CompareDiskWrite(p1, p2 podinfo.PodContext) int32 {
+	return utils.CmpFloat(p2.PodDiskWriteKiBPS, p1.PodDiskWriteKiBPS)
+}
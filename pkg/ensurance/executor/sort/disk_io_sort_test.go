@@ -0,0 +1,62 @@
+package sort
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestDiskIOSorter(t *testing.T) {
+	now := metav1.NewTime(time.Unix(1000, 0).UTC())
+	later := metav1.NewTime(time.Unix(2000, 0).UTC())
+
+	pods := []podinfo.PodContext{
+		{
+			Key:               types.NamespacedName{Name: "priority-1"},
+			Priority:          1,
+			PodDiskReadKiBPS:  100,
+			PodDiskWriteKiBPS: 100,
+			QOSClass:          v1.PodQOSBestEffort,
+		},
+		{
+			Key:               types.NamespacedName{Name: "guarantee-read-10"},
+			PodDiskReadKiBPS:  10,
+			PodDiskWriteKiBPS: 1,
+			QOSClass:          v1.PodQOSGuaranteed,
+		},
+		{
+			Key:               types.NamespacedName{Name: "best-effort-read-1"},
+			PodDiskReadKiBPS:  1,
+			PodDiskWriteKiBPS: 20,
+			QOSClass:          v1.PodQOSBestEffort,
+			StartTime:         &now,
+		},
+		{
+			Key:               types.NamespacedName{Name: "best-effort-read-5"},
+			PodDiskReadKiBPS:  5,
+			PodDiskWriteKiBPS: 2,
+			QOSClass:          v1.PodQOSBestEffort,
+			StartTime:         &later,
+		},
+	}
+
+	DiskReadSort(pods)
+	var names []string
+	for _, p := range pods {
+		names = append(names, p.Key.Name)
+	}
+	assert.Equal(t, []string{"best-effort-read-5", "best-effort-read-1", "guarantee-read-10", "priority-1"}, names)
+
+	DiskWriteSort(pods)
+	names = nil
+	for _, p := range pods {
+		names = append(names, p.Key.Name)
+	}
+	assert.Equal(t, []string{"best-effort-read-1", "best-effort-read-5", "guarantee-read-10", "priority-1"}, names)
+}
@@ -0,0 +1,24 @@
+package sort
+
+import (
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+func NetworkReceiveSort(pods []podinfo.PodContext) {
+	orderedBy(ComparePriority, ComparePodQOSClass, CompareNetworkReceive, CompareRunningTime).Sort(pods)
+}
+
+func NetworkSentSort(pods []podinfo.PodContext) {
+	orderedBy(ComparePriority, ComparePodQOSClass, CompareNetworkSent, CompareRunningTime).Sort(pods)
+}
+
+// CompareNetworkReceive compares the network ingress bandwidth of pods, the pod receiving more is in front
+func CompareNetworkReceive(p1, p2 podinfo.PodContext) int32 {
+	return utils.CmpFloat(p2.PodNetworkReceiveKibps, p1.PodNetworkReceiveKibps)
+}
+
+// CompareNetworkSent compares the network egress bandwidth of pods, the pod sending more is in front
+func CompareNetworkSent(p1, p2 podinfo.PodContext) int32 {
+	return utils.CmpFloat(p2.PodNetworkSentKibps, p1.PodNetworkSentKibps)
+}
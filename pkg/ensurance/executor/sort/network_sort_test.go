@@ -0,0 +1,48 @@
+package sort
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestNetworkSorter(t *testing.T) {
+	pods := []podinfo.PodContext{
+		{
+			Key:                    types.NamespacedName{Name: "burstable-receive-100"},
+			PodNetworkReceiveKibps: 100,
+			PodNetworkSentKibps:    1,
+			QOSClass:               v1.PodQOSBurstable,
+		},
+		{
+			Key:                    types.NamespacedName{Name: "best-effort-receive-1"},
+			PodNetworkReceiveKibps: 1,
+			PodNetworkSentKibps:    10,
+			QOSClass:               v1.PodQOSBestEffort,
+		},
+		{
+			Key:                    types.NamespacedName{Name: "best-effort-receive-10"},
+			PodNetworkReceiveKibps: 10,
+			PodNetworkSentKibps:    5,
+			QOSClass:               v1.PodQOSBestEffort,
+		},
+	}
+
+	NetworkReceiveSort(pods)
+	var names []string
+	for _, p := range pods {
+		names = append(names, p.Key.Name)
+	}
+	assert.Equal(t, []string{"best-effort-receive-10", "best-effort-receive-1", "burstable-receive-100"}, names)
+
+	NetworkSentSort(pods)
+	names = nil
+	for _, p := range pods {
+		names = append(names, p.Key.Name)
+	}
+	assert.Equal(t, []string{"best-effort-receive-1", "best-effort-receive-10", "burstable-receive-100"}, names)
+}
@@ -0,0 +1,207 @@
+package sort
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+	"github.com/gocrane/crane/pkg/utils"
+)
+
+// rankSignals are the values of the pods to rank them, the pod with a smaller value is throttled or evicted first
+var rankSignals = map[string]func(pod podinfo.PodContext) float64{
+	"Priority":          priority,
+	"PodQOSClass":       qosClass,
+	"RunningTime":       runningTime,
+	"StartupTime":       startupTime,
+	"NamespaceWeight":   namespaceWeight,
+	"CpuUsage":          cpuUsage,
+	"MemUsage":          memUsage,
+	"CpuUsageToRequest": cpuUsageToRequest,
+	"MemUsageToRequest": memUsageToRequest,
+}
+
+// RankingPolicy is the weights of the ranking signals, such as {"Priority": 2, "CpuUsageToRequest": 1}. The value
+// of each signal is normalized by its rank among the pods, and the pods are sorted by the weighted sum of them.
+type RankingPolicy map[string]float64
+
+// ParseRankingPolicy parses the ranking policy in json
+func ParseRankingPolicy(data string) (RankingPolicy, error) {
+	var policy RankingPolicy
+	if err := json.Unmarshal([]byte(data), &policy); err != nil {
+		return nil, err
+	}
+	for signal, weight := range policy {
+		if _, ok := rankSignals[signal]; !ok {
+			return nil, fmt.Errorf("unknown ranking signal %s", signal)
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("weight of ranking signal %s is negative", signal)
+		}
+	}
+	return policy, nil
+}
+
+// Merge adds up the weights of the signals of the policies
+func (p RankingPolicy) Merge(policy RankingPolicy) RankingPolicy {
+	if len(policy) == 0 {
+		return p
+	}
+	merged := make(RankingPolicy, len(p)+len(policy))
+	for signal, weight := range p {
+		merged[signal] += weight
+	}
+	for signal, weight := range policy {
+		merged[signal] += weight
+	}
+	return merged
+}
+
+// Sort sorts the pods by the weighted score of the signals, the pods with the same score are sorted by GeneralSorter
+func (p RankingPolicy) Sort(pods []podinfo.PodContext) {
+	GeneralSorter(pods)
+
+	signals := make([]string, 0, len(p))
+	for signal := range p {
+		signals = append(signals, signal)
+	}
+	// the scores are summed in the same order to be stable
+	sort.Strings(signals)
+
+	scores := make([]float64, len(pods))
+	for _, signal := range signals {
+		for i, rank := range normalizedRanks(pods, rankSignals[signal]) {
+			scores[i] += p[signal] * rank
+		}
+	}
+
+	indexes := make([]int, len(pods))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return utils.CmpFloat(scores[indexes[i]], scores[indexes[j]]) < 0
+	})
+
+	sorted := make([]podinfo.PodContext, len(pods))
+	for i, index := range indexes {
+		sorted[i] = pods[index]
+	}
+	copy(pods, sorted)
+}
+
+// normalizedRanks returns the ranks of the values of the pods in [0, 1], the pods with the same value have the
+// average rank of them
+func normalizedRanks(pods []podinfo.PodContext, value func(pod podinfo.PodContext) float64) []float64 {
+	ranks := make([]float64, len(pods))
+	if len(pods) < 2 {
+		return ranks
+	}
+
+	values := make([]float64, len(pods))
+	indexes := make([]int, len(pods))
+	for i, pod := range pods {
+		values[i] = value(pod)
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return values[indexes[i]] < values[indexes[j]]
+	})
+
+	for start := 0; start < len(indexes); {
+		end := start + 1
+		for end < len(indexes) && utils.CmpFloat(values[indexes[start]], values[indexes[end]]) == 0 {
+			end++
+		}
+		rank := float64(start+end-1) / 2 / float64(len(pods)-1)
+		for _, index := range indexes[start:end] {
+			ranks[index] = rank
+		}
+		start = end
+	}
+	return ranks
+}
+
+// CompareStartupTime compares the startup time of pods, the pod which starts up faster is in front
+func CompareStartupTime(p1, p2 podinfo.PodContext) int32 {
+	return utils.CmpFloat(startupTime(p1), startupTime(p2))
+}
+
+// CompareNamespaceWeight compares the weight of the namespaces of pods, the pod with the lower weight is in front
+func CompareNamespaceWeight(p1, p2 podinfo.PodContext) int32 {
+	return utils.CmpFloat(p1.NamespaceWeight, p2.NamespaceWeight)
+}
+
+// CompareCPUUsageToRequest compares the cpu usage relative to the request, the pod exceeding more is in front
+func CompareCPUUsageToRequest(p1, p2 podinfo.PodContext) int32 {
+	return utils.CmpFloat(cpuUsageToRequest(p1), cpuUsageToRequest(p2))
+}
+
+// CompareMemUsageToRequest compares the memory usage relative to the request, the pod exceeding more is in front
+func CompareMemUsageToRequest(p1, p2 podinfo.PodContext) int32 {
+	return utils.CmpFloat(memUsageToRequest(p1), memUsageToRequest(p2))
+}
+
+func priority(pod podinfo.PodContext) float64 {
+	return float64(pod.Priority)
+}
+
+func qosClass(pod podinfo.PodContext) float64 {
+	switch pod.QOSClass {
+	case v1.PodQOSGuaranteed:
+		return 2
+	case v1.PodQOSBurstable:
+		return 1
+	case v1.PodQOSBestEffort:
+		return 0
+	default:
+		return -1
+	}
+}
+
+// runningTime is the negative start time, so that the pod started earlier has a larger value
+func runningTime(pod podinfo.PodContext) float64 {
+	if pod.StartTime == nil {
+		return -math.MaxFloat64
+	}
+	return -float64(pod.StartTime.Unix())
+}
+
+func startupTime(pod podinfo.PodContext) float64 {
+	return pod.StartupDuration.Seconds()
+}
+
+func namespaceWeight(pod podinfo.PodContext) float64 {
+	return pod.NamespaceWeight
+}
+
+func cpuUsage(pod podinfo.PodContext) float64 {
+	return -pod.PodCPUUsage
+}
+
+func memUsage(pod podinfo.PodContext) float64 {
+	return -pod.PodMemUsage
+}
+
+func cpuUsageToRequest(pod podinfo.PodContext) float64 {
+	return -usageToRequest(pod.PodCPUUsage, pod.PodCPURequest)
+}
+
+func memUsageToRequest(pod podinfo.PodContext) float64 {
+	return -usageToRequest(pod.PodMemUsage, pod.PodMemRequest)
+}
+
+// usageToRequest returns the usage relative to the request, the pod using resource without a request exceeds most
+func usageToRequest(usage, request float64) float64 {
+	if request <= 0 {
+		if usage > 0 {
+			return math.MaxFloat64
+		}
+		return 0
+	}
+	return usage / request
+}
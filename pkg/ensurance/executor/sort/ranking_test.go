@@ -0,0 +1,80 @@
+package sort
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestParseRankingPolicy(t *testing.T) {
+	policy, err := ParseRankingPolicy(`{"Priority": 2, "CpuUsageToRequest": 1}`)
+	assert.NoError(t, err)
+	assert.Equal(t, RankingPolicy{"Priority": 2, "CpuUsageToRequest": 1}, policy)
+
+	_, err = ParseRankingPolicy(`{"Unknown": 1}`)
+	assert.Error(t, err)
+	_, err = ParseRankingPolicy(`{"Priority": -1}`)
+	assert.Error(t, err)
+	_, err = ParseRankingPolicy(`[]`)
+	assert.Error(t, err)
+
+	assert.Equal(t, RankingPolicy{"Priority": 3, "StartupTime": 1}, RankingPolicy{"Priority": 2}.Merge(RankingPolicy{"Priority": 1, "StartupTime": 1}))
+}
+
+func TestRankingPolicySort(t *testing.T) {
+	pods := []podinfo.PodContext{
+		{
+			Key:             types.NamespacedName{Name: "slow-startup"},
+			Priority:        1,
+			PodCPUUsage:     2,
+			PodCPURequest:   1,
+			StartupDuration: 10 * time.Minute,
+			QOSClass:        v1.PodQOSBurstable,
+		},
+		{
+			Key:             types.NamespacedName{Name: "over-request"},
+			Priority:        1,
+			PodCPUUsage:     4,
+			PodCPURequest:   1,
+			StartupDuration: time.Second,
+			QOSClass:        v1.PodQOSBurstable,
+		},
+		{
+			Key:             types.NamespacedName{Name: "low-priority"},
+			PodCPUUsage:     1,
+			PodCPURequest:   1,
+			StartupDuration: time.Second,
+			QOSClass:        v1.PodQOSBurstable,
+		},
+		{
+			Key:             types.NamespacedName{Name: "weighted-namespace"},
+			PodCPUUsage:     1,
+			StartupDuration: time.Second,
+			NamespaceWeight: 10,
+			QOSClass:        v1.PodQOSBestEffort,
+		},
+	}
+	names := func() []string {
+		var names []string
+		for _, p := range pods {
+			names = append(names, p.Key.Name)
+		}
+		return names
+	}
+
+	RankingPolicy{"Priority": 1}.Sort(pods)
+	// the pods with the same priority are sorted by GeneralSorter
+	assert.Equal(t, []string{"weighted-namespace", "low-priority", "slow-startup", "over-request"}, names())
+
+	RankingPolicy{"CpuUsageToRequest": 1}.Sort(pods)
+	// the pod without request exceeds most
+	assert.Equal(t, []string{"weighted-namespace", "over-request", "slow-startup", "low-priority"}, names())
+
+	RankingPolicy{"Priority": 1, "StartupTime": 2, "NamespaceWeight": 2}.Sort(pods)
+	assert.Equal(t, []string{"low-priority", "over-request", "weighted-namespace", "slow-startup"}, names())
+}
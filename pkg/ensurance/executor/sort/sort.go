@@ -13,10 +13,16 @@ type RankFunc func(pods []podinfo.PodContext)
 
 var sortFunc = map[string]func(p1, p2 podinfo.PodContext) int32{
 	"UseElasticResource": UseElasticCPU,
+	"Priority":           ComparePriority,
 	"PodQOSClass":        ComparePodQOSClass,
 	"ExtCpuUsage":        CompareElasticCPU,
 	"CpuUsage":           CompareCPUUsage,
+	"MemUsage":           CompareMemUsage,
 	"RunningTime":        CompareRunningTime,
+	"StartupTime":        CompareStartupTime,
+	"NamespaceWeight":    CompareNamespaceWeight,
+	"CpuUsageToRequest":  CompareCPUUsageToRequest,
+	"MemUsageToRequest":  CompareMemUsageToRequest,
 }
 
 // RankFuncConstruct is a sample for future extends, keep it even it is not called
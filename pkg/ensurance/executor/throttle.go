@@ -26,6 +26,15 @@ type ThrottleExecutor struct {
 	// All metrics(not only metrics that can be quantified) metioned in triggerd NodeQOS and their corresponding watermarks
 	ThrottleDownWatermark Watermarks
 	ThrottleUpWatermark   Watermarks
+	// ThrottleDownForecasts are the node usages predicted to cross the watermarks, the gaps of the metrics are
+	// calculated by them instead of the current usages
+	ThrottleDownForecasts map[WatermarkMetric]float64
+	// RankingPolicy declared by NodeQOS or PodQOS sorts the pods instead of the SortFunc of the metrics
+	RankingPolicy execsort.RankingPolicy
+	// ThrottleDownRules and ThrottleUpRules are the rules which the pods are throttled or restored for, in the format
+	// of <NodeQOS name>.<rule name>
+	ThrottleDownRules []string
+	ThrottleUpRules   []string
 }
 
 type ThrottlePods []podinfo.PodContext
@@ -71,7 +80,7 @@ func (t *ThrottleExecutor) Avoid(ctx *ExecuteContext) error {
 	1. If ThrottleDownWatermark has metrics that can't be quantified, select a throttleable metric which has the highest action priority, use its throttlefunc to throttle all ThrottleDownPods, then return
 	2. Get the gaps between current usage and watermarks
 		2.1 If there is a metric that can't get current usage, select a throttleable metric which has the highest action priority, use its throttlefunc to throttle all ThrottleDownPods, then return
-		2.2 Traverse metrics that can be quantified, if there is a gap for the metric, then sort candidate pods by its SortFunc if exists, otherwise use GeneralSorter by default.
+		2.2 Traverse metrics that can be quantified, if there is a gap for the metric, then sort candidate pods by the RankingPolicy if declared, or by its SortFunc if exists, otherwise use GeneralSorter by default.
 	       Then throttle sorted pods one by one util there is no gap to watermark
 	*/
 	metricsThrottleQuantified, MetricsNotThrottleQuantified := t.ThrottleDownWatermark.DivideMetricsByThrottleQuantified()
@@ -86,7 +95,7 @@ func (t *ThrottleExecutor) Avoid(ctx *ExecuteContext) error {
 			errPodKeys = t.throttlePods(ctx, &totalReleased, highestPriorityMetric)
 		}
 	} else {
-		ctx.ToBeThrottleDown = calculateGaps(ctx.stateMap, t, nil, ctx.executeExcessPercent)
+		ctx.ToBeThrottleDown = calculateGaps(withForecasts(ctx.stateMap, t.ThrottleDownForecasts), t.throttleDownWatermarks(), nil, ctx.executeExcessPercent)
 
 		if ctx.ToBeThrottleDown.HasUsageMissedMetric() {
 			klog.V(6).Info("There is a metric usage missed")
@@ -100,11 +109,7 @@ func (t *ThrottleExecutor) Avoid(ctx *ExecuteContext) error {
 			var released ReleaseResource
 			for _, m := range metricsThrottleQuantified {
 				klog.V(6).Infof("ThrottleDown precisely on metric %s", m)
-				if metricMap[m].Sortable {
-					metricMap[m].SortFunc(t.ThrottleDownPods)
-				} else {
-					execsort.GeneralSorter(t.ThrottleDownPods)
-				}
+				sortPods(m, t.RankingPolicy, t.ThrottleDownPods)
 
 				klog.V(6).Info("After sort, the sequence to throttle is ")
 				for _, pc := range t.ThrottleDownPods {
@@ -116,6 +121,7 @@ func (t *ThrottleExecutor) Avoid(ctx *ExecuteContext) error {
 
 					errKeys, released = metricMap[m].ThrottleFunc(ctx, index, t.ThrottleDownPods, &totalReleased)
 					klog.V(6).Infof("ThrottleDown pods %s, released %f resource", t.ThrottleDownPods[index].Key, released[m])
+					ctx.recordAction(t.ThrottleDownPods[index], t.ThrottleDownRules, m, released, errKeys)
 					errPodKeys = append(errPodKeys, errKeys...)
 
 					ctx.ToBeThrottleDown[m] -= released[m]
@@ -131,10 +137,21 @@ func (t *ThrottleExecutor) Avoid(ctx *ExecuteContext) error {
 	return nil
 }
 
+// throttleDownWatermarks returns the throttle executor with ThrottleDownWatermark only to calculate the gaps
+func (t *ThrottleExecutor) throttleDownWatermarks() *ThrottleExecutor {
+	return &ThrottleExecutor{ThrottleDownWatermark: t.ThrottleDownWatermark}
+}
+
+// throttleUpWatermarks returns the throttle executor with ThrottleUpWatermark only to calculate the gaps
+func (t *ThrottleExecutor) throttleUpWatermarks() *ThrottleExecutor {
+	return &ThrottleExecutor{ThrottleUpWatermark: t.ThrottleUpWatermark}
+}
+
 func (t *ThrottleExecutor) throttlePods(ctx *ExecuteContext, totalReleasedResource *ReleaseResource, m WatermarkMetric) (errPodKeys []string) {
 	for i := range t.ThrottleDownPods {
-		errKeys, _ := metricMap[m].ThrottleFunc(ctx, i, t.ThrottleDownPods, totalReleasedResource)
+		errKeys, released := metricMap[m].ThrottleFunc(ctx, i, t.ThrottleDownPods, totalReleasedResource)
 		errPodKeys = append(errPodKeys, errKeys...)
+		ctx.recordAction(t.ThrottleDownPods[i], t.ThrottleDownRules, m, released, errKeys)
 	}
 	return
 }
@@ -162,7 +179,7 @@ func (t *ThrottleExecutor) Restore(ctx *ExecuteContext) error {
 	1. If ThrottleUpWatermark has metrics that can't be quantified, select a throttleable metric which has the highest action priority, use its RestoreFunc to restore all ThrottleUpPods, then return
 	2. Get the gaps between current usage and watermarks
 		2.1 If there is a metric that can't get current usage, select a throttleable metric which has the highest action priority, use its RestoreFunc to restore all ThrottleUpPods, then return
-		2.2 Traverse metrics that can be quantified, if there is a gap for the metric, then sort candidate pods by its SortFunc if exists, otherwise use GeneralSorter by default.
+		2.2 Traverse metrics that can be quantified, if there is a gap for the metric, then sort candidate pods by the RankingPolicy if declared, or by its SortFunc if exists, otherwise use GeneralSorter by default.
 	       Then restore sorted pods one by one util there is no gap to watermark
 	*/
 	metricsThrottleQuantified, MetricsNotThrottleQuantified := t.ThrottleUpWatermark.DivideMetricsByThrottleQuantified()
@@ -177,7 +194,7 @@ func (t *ThrottleExecutor) Restore(ctx *ExecuteContext) error {
 			errPodKeys = t.restorePods(ctx, &totalReleased, highestPrioriyMetric)
 		}
 	} else {
-		ctx.ToBeThrottleUp = calculateGaps(ctx.stateMap, t, nil, ctx.executeExcessPercent)
+		ctx.ToBeThrottleUp = calculateGaps(ctx.stateMap, t.throttleUpWatermarks(), nil, ctx.executeExcessPercent)
 
 		if ctx.ToBeThrottleUp.HasUsageMissedMetric() {
 			klog.V(6).Info("There is a metric usage missed")
@@ -190,11 +207,7 @@ func (t *ThrottleExecutor) Restore(ctx *ExecuteContext) error {
 			var released ReleaseResource
 			for _, m := range metricsThrottleQuantified {
 				klog.V(6).Infof("ThrottleUp precisely on metric %s", m)
-				if metricMap[m].Sortable {
-					metricMap[m].SortFunc(t.ThrottleUpPods)
-				} else {
-					execsort.GeneralSorter(t.ThrottleUpPods)
-				}
+				sortPods(m, t.RankingPolicy, t.ThrottleUpPods)
 				//t.ThrottleUpPods = Reverse(t.ThrottleUpPods)
 
 				klog.V(6).Info("After sort, the sequence to throttle is ")
@@ -207,6 +220,7 @@ func (t *ThrottleExecutor) Restore(ctx *ExecuteContext) error {
 
 					errKeys, released = metricMap[m].RestoreFunc(ctx, index, t.ThrottleUpPods, &totalReleased)
 					klog.V(6).Infof("ThrottleUp pods %s, released %f resource", t.ThrottleUpPods[index].Key, released[m])
+					ctx.recordAction(t.ThrottleUpPods[index], t.ThrottleUpRules, m, released, errKeys)
 					errPodKeys = append(errPodKeys, errKeys...)
 
 					ctx.ToBeThrottleUp[m] -= released[m]
@@ -224,8 +238,9 @@ func (t *ThrottleExecutor) Restore(ctx *ExecuteContext) error {
 
 func (t *ThrottleExecutor) restorePods(ctx *ExecuteContext, totalReleasedResource *ReleaseResource, m WatermarkMetric) (errPodKeys []string) {
 	for i := range t.ThrottleUpPods {
-		errKeys, _ := metricMap[m].RestoreFunc(ctx, i, t.ThrottleDownPods, totalReleasedResource)
+		errKeys, released := metricMap[m].RestoreFunc(ctx, i, t.ThrottleUpPods, totalReleasedResource)
 		errPodKeys = append(errPodKeys, errKeys...)
+		ctx.recordAction(t.ThrottleUpPods[i], t.ThrottleUpRules, m, released, errKeys)
 	}
 	return
 }
@@ -0,0 +1,35 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+
+	podinfo "github.com/gocrane/crane/pkg/ensurance/executor/podinfo"
+)
+
+func TestRestorePods(t *testing.T) {
+	const m = WatermarkMetric("test_restore")
+	var restored []string
+	metricMap[m] = metric{
+		Name: m,
+		RestoreFunc: func(ctx *ExecuteContext, index int, ThrottleUpPods ThrottlePods, totalReleasedResource *ReleaseResource) (errPodKeys []string, released ReleaseResource) {
+			restored = append(restored, ThrottleUpPods[index].Key.Name)
+			return
+		},
+	}
+	defer delete(metricMap, m)
+
+	// the pods to restore are indexed in the throttle up pods, not the throttle down ones
+	e := &ThrottleExecutor{
+		ThrottleDownPods: ThrottlePods{{Key: types.NamespacedName{Namespace: "default", Name: "down"}, ActionType: podinfo.ThrottleDown}},
+		ThrottleUpPods: ThrottlePods{
+			{Key: types.NamespacedName{Namespace: "default", Name: "up-1"}, ActionType: podinfo.ThrottleUp},
+			{Key: types.NamespacedName{Namespace: "default", Name: "up-2"}, ActionType: podinfo.ThrottleUp},
+		},
+	}
+	errPodKeys := e.restorePods(&ExecuteContext{}, &ReleaseResource{}, m)
+	assert.Empty(t, errPodKeys)
+	assert.Equal(t, []string{"up-1", "up-2"}, restored)
+}
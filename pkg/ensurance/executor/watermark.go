@@ -20,6 +20,10 @@ const (
 	CpuUsagePercent = WatermarkMetric(types.MetricNameCpuTotalUtilization)
 	MemUsage        = WatermarkMetric(types.MetricNameMemoryTotalUsage)
 	MemUsagePercent = WatermarkMetric(types.MetricNameMemoryTotalUtilization)
+	DiskReadKiBPS   = WatermarkMetric(types.MetricDiskReadKiBPS)
+	DiskWriteKiBPS  = WatermarkMetric(types.MetricDiskWriteKiBPS)
+	NetReceiveKiBPS = WatermarkMetric(types.MetricNetworkReceiveKiBPS)
+	NetSentKiBPS    = WatermarkMetric(types.MetricNetworkSentKiBPS)
 )
 
 const (
@@ -143,6 +147,10 @@ func calculateGaps(stateMap map[string][]common.TimeSeries,
 			if !m.Evictable {
 				continue
 			}
+			// The metrics not mentioned in the watermarks, such as disk io on a node without local disks, are not collected
+			if _, evictExist := evictExecutor.EvictWatermark[m.Name]; !evictExist {
+				continue
+			}
 			// Get the series for each metric
 			series, ok := stateMap[string(m.Name)]
 			if !ok {
@@ -153,10 +161,7 @@ func calculateGaps(stateMap map[string][]common.TimeSeries,
 			}
 
 			// Find the biggest used value
-			var maxUsed float64
-			if series[0].Samples[0].Value > maxUsed {
-				maxUsed = series[0].Samples[0].Value
-			}
+			maxUsed := maxUsedValue(series)
 
 			// Get the watermark for each metric cannot be quantified
 			evictWatermark, evictExist := evictExecutor.EvictWatermark[m.Name]
@@ -196,6 +201,11 @@ func calculateGaps(stateMap map[string][]common.TimeSeries,
 			if !m.Throttleable {
 				continue
 			}
+			_, throttleDownExist := throttleExecutor.ThrottleDownWatermark[m.Name]
+			_, throttleUpExist := throttleExecutor.ThrottleUpWatermark[m.Name]
+			if !throttleDownExist && !throttleUpExist {
+				continue
+			}
 			// Get the series for each metric
 			series, ok := stateMap[string(m.Name)]
 			if !ok {
@@ -206,27 +216,18 @@ func calculateGaps(stateMap map[string][]common.TimeSeries,
 			}
 
 			// Find the biggest used value
-			var maxUsed float64
-			if series[0].Samples[0].Value > maxUsed {
-				maxUsed = series[0].Samples[0].Value
-			}
+			maxUsed := maxUsedValue(series)
 
 			// Get the watermark for each metric in WatermarkMetricsCanBeQuantified
 			throttleDownWatermark, throttleDownExist := throttleExecutor.ThrottleDownWatermark[m.Name]
 			throttleUpWatermark, throttleUpExist := throttleExecutor.ThrottleUpWatermark[m.Name]
 
-			// If a metric does not exist in ThrottleDownWatermark, throttleDownGapToWatermarks of this metric will can't be calculated
-			if !throttleDownExist {
-				delete(result, m.Name)
-			} else {
+			// The throttle executor has either ThrottleDownWatermark or ThrottleUpWatermark, see throttleDownWatermarks
+			// and throttleUpWatermarks, so the gaps of throttle down and up are not mixed
+			if throttleDownExist {
 				klog.V(6).Infof("BuildThrottleDownWatermarkGap: For metrics %s, maxUsed is %f, watermark is %f", m.Name, maxUsed, float64(throttleDownWatermark.PopSmallest().Value()))
 				result[m.Name] = (1 + executeExcessPercent) * (maxUsed - float64(throttleDownWatermark.PopSmallest().Value()))
-			}
-
-			// If metric not exist in ThrottleUpWatermark, throttleUpGapToWatermarks of metric will can't be calculated
-			if !throttleUpExist {
-				delete(result, m.Name)
-			} else {
+			} else if throttleUpExist {
 				klog.V(6).Infof("BuildThrottleUpWatermarkGap: For metrics %s, maxUsed is %f, watermark is %f", m.Name, maxUsed, float64(throttleUpWatermark.PopSmallest().Value()))
 				// Attention: different with throttleDown and evict, use watermark - used
 				result[m.Name] = (1 + executeExcessPercent) * (float64(throttleUpWatermark.PopSmallest().Value()) - maxUsed)
@@ -236,6 +237,42 @@ func calculateGaps(stateMap map[string][]common.TimeSeries,
 	return result
 }
 
+// maxUsedValue returns the biggest value of the series, such as the busiest disk for the disk io metrics
+func maxUsedValue(series []common.TimeSeries) float64 {
+	var maxUsed float64
+	for _, ts := range series {
+		if len(ts.Samples) > 0 && ts.Samples[0].Value > maxUsed {
+			maxUsed = ts.Samples[0].Value
+		}
+	}
+	return maxUsed
+}
+
+// withForecasts returns a copy of the stateMap whose series of the forecast metrics are replaced by the forecasts if
+// they are above the current usages
+func withForecasts(stateMap map[string][]common.TimeSeries, forecasts map[WatermarkMetric]float64) map[string][]common.TimeSeries {
+	if len(forecasts) == 0 {
+		return stateMap
+	}
+	result := make(map[string][]common.TimeSeries, len(stateMap))
+	for name, series := range stateMap {
+		result[name] = series
+	}
+	for m, forecast := range forecasts {
+		series := stateMap[string(m)]
+		if len(series) != 0 && maxUsedValue(series) >= forecast {
+			continue
+		}
+		var timestamp int64
+		if len(series) != 0 && len(series[0].Samples) != 0 {
+			timestamp = series[0].Samples[0].Timestamp
+		}
+		klog.V(6).Infof("Use forecast %f of metric %s to calculate gaps", forecast, m)
+		result[string(m)] = []common.TimeSeries{{Samples: []common.Sample{{Value: forecast, Timestamp: timestamp}}}}
+	}
+	return result
+}
+
 // Whether no gaps in Gaps
 func (g Gaps) GapsAllRemoved() bool {
 	for _, v := range g {
@@ -8,6 +8,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
 )
 
 func (w Watermark) verify(t *testing.T, i int) {
@@ -48,3 +50,29 @@ func TestPopSmallest(t *testing.T) {
 		h.verify(t, 0)
 	}
 }
+
+func TestCalculateThrottleGapsWithForecasts(t *testing.T) {
+	stateMap := map[string][]common.TimeSeries{
+		string(CpuUsage): {{Samples: []common.Sample{{Value: 3000, Timestamp: 100}}}},
+		string(MemUsage): {{Samples: []common.Sample{{Value: 6000, Timestamp: 100}}}},
+	}
+	down, up := &Watermark{}, &Watermark{}
+	heap.Push(down, resource.MustParse("4000"))
+	heap.Push(up, resource.MustParse("3500"))
+	memDown := &Watermark{}
+	heap.Push(memDown, resource.MustParse("5000"))
+	throttle := &ThrottleExecutor{
+		ThrottleDownWatermark: Watermarks{CpuUsage: down, MemUsage: memDown},
+		ThrottleUpWatermark:   Watermarks{CpuUsage: up},
+		// the forecast below the current usage is ignored
+		ThrottleDownForecasts: map[WatermarkMetric]float64{CpuUsage: 5000, MemUsage: 5500},
+	}
+
+	gaps := calculateGaps(withForecasts(stateMap, throttle.ThrottleDownForecasts), throttle.throttleDownWatermarks(), nil, 0)
+	assert.Equal(t, Gaps{CpuUsage: 1000, MemUsage: 1000}, gaps)
+	// the state is not changed by the forecasts
+	assert.Equal(t, 3000.0, stateMap[string(CpuUsage)][0].Samples[0].Value)
+
+	gaps = calculateGaps(stateMap, throttle.throttleUpWatermarks(), nil, 0)
+	assert.Equal(t, Gaps{CpuUsage: 500}, gaps)
+}
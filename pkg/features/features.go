@@ -31,9 +31,15 @@ const (
 	// CraneCPUManager enables the cpu manger features.
 	CraneCPUManager featuregate.Feature = "CraneCPUManager"
 
+	// CraneCPUSetRebalance enables the cpu manager to resize the offline cpuset by the online usage.
+	CraneCPUSetRebalance featuregate.Feature = "CPUSetRebalance"
+
 	// CraneDashboardControl enables the control from Dashboard.
 	CraneDashboardControl featuregate.Feature = "DashboardControl"
 
+	// CraneEBPFCollector enables the ebpf collector of the crane agent.
+	CraneEBPFCollector featuregate.Feature = "EBPFCollector"
+
 	// QOSInitializer enables the qos initialization featrues.
 	QOSInitializer featuregate.Feature = "QOSInitializer"
 )
@@ -47,6 +53,8 @@ var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	CraneClusterNodePrediction: {Default: false, PreRelease: featuregate.Alpha},
 	CraneTimeSeriesPrediction:  {Default: true, PreRelease: featuregate.Alpha},
 	CraneCPUManager:            {Default: false, PreRelease: featuregate.Alpha},
+	CraneCPUSetRebalance:       {Default: false, PreRelease: featuregate.Alpha},
+	CraneEBPFCollector:         {Default: false, PreRelease: featuregate.Alpha},
 	QOSInitializer:             {Default: false, PreRelease: featuregate.Alpha},
 	CraneDashboardControl:      {Default: false, PreRelease: featuregate.Alpha},
 }
@@ -13,4 +13,44 @@ const (
 const (
 	EffectiveHorizontalPodAutoscalerCurrentMetricsAnnotation        = "autoscaling.crane.io/effective-hpa-current-metrics"
 	EffectiveHorizontalPodAutoscalerExternalMetricsAnnotationPrefix = "metric-query.autoscaling.crane.io"
+	// EffectiveHorizontalPodAutoscalerPredictionBandAnnotation chooses the band of the prediction to scale on, upper is
+	// conservative and lower is aggressive, the predicted time series itself is used if not specified
+	EffectiveHorizontalPodAutoscalerPredictionBandAnnotation = "autoscaling.crane.io/prediction-band"
+)
+
+// The annotations of TimeSeriesPrediction below are propagated from the ehpa to its TimeSeriesPrediction as well.
+const (
+	// TimeSeriesPredictionCalendarAnnotation is a calendar in json of the special dates which the predictor should be
+	// aware of
+	TimeSeriesPredictionCalendarAnnotation = "prediction.crane.io/calendar"
+	// TimeSeriesPredictionOutlierRemovalAnnotation is the outlier removal in json, such as {"method": "stl", "threshold": 3.5}
+	TimeSeriesPredictionOutlierRemovalAnnotation = "prediction.crane.io/outlier-removal"
+	// TimeSeriesPredictionParametersAnnotation is a json object of string parameters passed as is to the external predictor
+	TimeSeriesPredictionParametersAnnotation = "prediction.crane.io/parameters"
+	// TimeSeriesPredictionModelInitModeAnnotation is the init mode of the prediction models, such as checkpoint
+	TimeSeriesPredictionModelInitModeAnnotation = "prediction.crane.io/model-init-mode"
+	// TimeSeriesPredictionHoltWintersAnnotation is a json list of the Holt-Winters estimators the dsp predictor tests
+	TimeSeriesPredictionHoltWintersAnnotation = "prediction.crane.io/holt-winters-estimators"
+)
+
+const (
+	// NodeQOSRulePolicyAnnotationPrefix is the prefix of the annotations of NodeQOS which define the Rego policies of
+	// the rules, the key is <prefix>/<rule name>. The policy decides whether the rule is triggered instead of the metric
+	// rule watermark, it must define a boolean rule named trigger in its package.
+	NodeQOSRulePolicyAnnotationPrefix = "rego-policy.ensurance.crane.io"
+	// NodeQOSRuleTriggerAnnotationPrefix is the prefix of the annotations of NodeQOS which define the trigger
+	// expressions of the rules, the key is <prefix>/<rule name>. The expression combines several metrics and the
+	// functions over their history, such as cpu_total_utilization > 80 && deriv(memory_total_usage[5m]) > 1Mi, it
+	// decides whether the rule is triggered instead of the metric rule watermark.
+	NodeQOSRuleTriggerAnnotationPrefix = "trigger.ensurance.crane.io"
+	// RankingPolicyAnnotation is the annotation of NodeQOS or PodQOS which defines the weights of the signals to rank
+	// the pods to throttle or evict in json, such as {"Priority": 2, "CpuUsageToRequest": 1}. The policy of NodeQOS
+	// takes precedence over the policies of PodQOS.
+	RankingPolicyAnnotation = "ranking-policy.ensurance.crane.io"
+)
+
+const (
+	// PodLatencyCriticalAnnotation marks the pod as latency critical, with the interference-aware cpu manager policy
+	// its exclusive cpus are taken from dedicated physical cores, whose SMT siblings are left idle
+	PodLatencyCriticalAnnotation = "topology.crane.io/latency-critical"
 )
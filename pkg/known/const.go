@@ -14,3 +14,18 @@ const (
 	MaxMinCPURatio                    = 100
 	MaxStepCPURatio                   = 100
 )
+
+const (
+	// EvictionTokenBucketConfigMapName is the configmap in the crane system namespace which keeps the cluster wide
+	// eviction tokens shared by all the crane agents
+	EvictionTokenBucketConfigMapName = "eviction-token-bucket"
+)
+
+const (
+	// The attributes of NodeResourceTopology which show the cpu layout of the interference-aware cpu manager policy.
+	// The LLC domains are the cpusets sharing a last level cache separated by semicolons, such as 0-3,8-11;4-7,12-15.
+	LLCDomainsAttribute          = "go.crane.io/llc-domains"
+	LatencyCriticalCPUsAttribute = "go.crane.io/latency-critical-cpus"
+	IdleSiblingCPUsAttribute     = "go.crane.io/idle-sibling-cpus"
+	BestEffortCPUsAttribute      = "go.crane.io/best-effort-cpus"
+)
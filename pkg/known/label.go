@@ -8,6 +8,8 @@ const (
 const (
 	EnsuranceAnalyzedPressureTaintKey     = "interference.crane.io"
 	EnsuranceAnalyzedPressureConditionKey = "interference-identified"
+	// EnsuranceRankingWeightLabel is the label of namespace whose pods are throttled or evicted later with a higher weight
+	EnsuranceRankingWeightLabel = "ensurance.crane.io/ranking-weight"
 )
 
 const (
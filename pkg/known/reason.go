@@ -5,3 +5,8 @@ const (
 	ReasonTimeSeriesPredictPartial = "PredictPartial"
 	ReasonTimeSeriesPredictSucceed = "PredictSucceed"
 )
+
+const (
+	ReasonTimeSeriesPredictionAccuracyEvaluated = "AccuracyEvaluated"
+	ReasonTimeSeriesPredictionAccuracyUnknown   = "AccuracyUnknown"
+)
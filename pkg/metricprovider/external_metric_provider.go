@@ -71,8 +71,11 @@ func (p *ExternalMetricProvider) GetExternalMetric(ctx context.Context, namespac
 			return nil, fmt.Errorf("failed get resourceIdentifier from metricSelector: [%v]", metricSelector)
 		}
 
+		// the predicted time series itself is used if no band is specified
+		band, _ := metricSelector.RequiresExactMatch("predictionBand")
+
 		for _, prediction := range predictions {
-			timeSeries, err := utils.GetReadyPredictionMetric(info.Metric, resourceIdentifier, &prediction)
+			timeSeries, err := utils.GetReadyPredictionMetricOfBand(info.Metric, resourceIdentifier, band, &prediction)
 			if err != nil {
 				return nil, err
 			}
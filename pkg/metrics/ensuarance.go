@@ -25,8 +25,17 @@ const (
 	ExecutorStatusTotal   = "executor_status_total"
 	ExecutorErrorTotal    = "executor_error_total"
 	ExecutorEvictTotal    = "executor_evict_total"
+	ExecutorEvictLimited  = "executor_evict_limited_total"
 	PodResourceErrorTotal = "pod_resource_error_total"
 
+	CPUSetCPUs           = "cpuset_cpus"
+	CPUSetRebalanceTotal = "cpuset_rebalance_total"
+
+	DryRunPods      = "dry_run_pods"
+	DryRunReleased  = "dry_run_released"
+	DryRunGap       = "dry_run_gap"
+	DryRunGapClosed = "dry_run_gap_closed"
+
 	NodeCpuCannotBeReclaimedSeconds = "node_cpu_cannot_be_reclaimed_seconds"
 	NodeMemCannotBeReclaimedSeconds = "node_mem_cannot_be_reclaimed_seconds"
 	NodeResourceRecommended         = "node_resource_recommended"
@@ -45,8 +54,9 @@ const (
 	StepUpdatePodResource  StepLabel = "updatePodResource"
 
 	// Step for pod resource manager
-	StepGetPeriod   StepLabel = "getPeriod"
-	StepUpdateQuota StepLabel = "updateQuota"
+	StepGetPeriod         StepLabel = "getPeriod"
+	StepUpdateQuota       StepLabel = "updateQuota"
+	StepUpdateMemoryLimit StepLabel = "updateMemoryLimit"
 
 	StepGetExtResourceRecommended StepLabel = "getExtResourceRecommended"
 )
@@ -178,6 +188,39 @@ var (
 		},
 	)
 
+	//ExecutorEvictLimitedCounts records the number of pods not evicted by the eviction limits
+	executorEvictLimitedCounts = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace:      CraneNamespace,
+			Subsystem:      CraneAgentSubsystem,
+			Name:           ExecutorEvictLimited,
+			Help:           "The number of pods not evicted by the eviction limits.",
+			StabilityLevel: k8smetrics.ALPHA,
+		}, []string{"reason"},
+	)
+
+	//cpuSetCPUs records the number of cpus of the online, offline and exclusive cpusets
+	cpuSetCPUs = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace:      CraneNamespace,
+			Subsystem:      CraneAgentSubsystem,
+			Name:           CPUSetCPUs,
+			Help:           "The number of cpus of the online, offline and exclusive cpusets.",
+			StabilityLevel: k8smetrics.ALPHA,
+		}, []string{"cpuset"},
+	)
+
+	//cpuSetRebalanceCounts records the number of times the offline cpuset shrinks or grows
+	cpuSetRebalanceCounts = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace:      CraneNamespace,
+			Subsystem:      CraneAgentSubsystem,
+			Name:           CPUSetRebalanceTotal,
+			Help:           "The number of times the offline cpuset shrinks or grows.",
+			StabilityLevel: k8smetrics.ALPHA,
+		}, []string{"direction"},
+	)
+
 	//podResourceUpdateErrorCounts records the number of errors when update pod's ext resource to quota
 	podResourceUpdateErrorCounts = k8smetrics.NewCounterVec(
 		&k8smetrics.CounterOpts{
@@ -189,6 +232,50 @@ var (
 		}, []string{"subcomponent", "step"},
 	)
 
+	//DryRunPods records the number of pods which would be throttled or evicted by the dry run actions
+	dryRunPods = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace:      CraneNamespace,
+			Subsystem:      CraneAgentSubsystem,
+			Name:           DryRunPods,
+			Help:           "The number of pods which would be acted on by the dry run actions.",
+			StabilityLevel: k8smetrics.ALPHA,
+		}, []string{"subcomponent", "metric"},
+	)
+
+	//DryRunReleased records the usage which would be released by the dry run actions
+	dryRunReleased = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace:      CraneNamespace,
+			Subsystem:      CraneAgentSubsystem,
+			Name:           DryRunReleased,
+			Help:           "The estimated usage released by the dry run actions.",
+			StabilityLevel: k8smetrics.ALPHA,
+		}, []string{"subcomponent", "metric"},
+	)
+
+	//DryRunGap records the gap between the usage and the watermark of the dry run actions
+	dryRunGap = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace:      CraneNamespace,
+			Subsystem:      CraneAgentSubsystem,
+			Name:           DryRunGap,
+			Help:           "The gap between the usage and the watermark of the dry run actions.",
+			StabilityLevel: k8smetrics.ALPHA,
+		}, []string{"subcomponent", "metric"},
+	)
+
+	//DryRunGapClosed records whether the gap would be closed by the dry run actions
+	dryRunGapClosed = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace:      CraneNamespace,
+			Subsystem:      CraneAgentSubsystem,
+			Name:           DryRunGapClosed,
+			Help:           "Whether the gap would be closed by the dry run actions. (closed: 1, not closed: 0)",
+			StabilityLevel: k8smetrics.ALPHA,
+		}, []string{"subcomponent", "metric"},
+	)
+
 	// LastActivity records the last activity time of each steps
 	nodeCpuCannotBeReclaimedSeconds = k8smetrics.NewGaugeVec(
 		&k8smetrics.GaugeOpts{
@@ -247,6 +334,13 @@ func RegisterCraneAgent() {
 		legacyregistry.MustRegister(executorStatusCounts)
 		legacyregistry.MustRegister(executorErrorCounts)
 		legacyregistry.MustRegister(executorEvictCounts)
+		legacyregistry.MustRegister(executorEvictLimitedCounts)
+		legacyregistry.MustRegister(cpuSetCPUs)
+		legacyregistry.MustRegister(cpuSetRebalanceCounts)
+		legacyregistry.MustRegister(dryRunPods)
+		legacyregistry.MustRegister(dryRunReleased)
+		legacyregistry.MustRegister(dryRunGap)
+		legacyregistry.MustRegister(dryRunGapClosed)
 		legacyregistry.MustRegister(nodeCpuCannotBeReclaimedSeconds)
 		legacyregistry.MustRegister(nodeResourceRecommended)
 		legacyregistry.MustRegister(nodeResourceRecommendedFrom)
@@ -290,6 +384,54 @@ func UpdateExecutorStatus(subComponent SubComponent, stepName StepLabel, value f
 	executorStatus.With(prometheus.Labels{"subcomponent": string(subComponent), "step": string(stepName)}).Set(value)
 }
 
+// DryRunStatus is the simulation of the dry run actions to release a metric
+type DryRunStatus struct {
+	SubComponent SubComponent
+	Metric       string
+	Pods         int
+	Released     float64
+	Gap          float64
+	GapClosed    bool
+}
+
+var (
+	dryRunLock sync.Mutex
+	// dryRunSeries is the label sets of the last dry run, keyed by the subcomponent and the metric
+	dryRunSeries = map[[2]string]prometheus.Labels{}
+)
+
+// UpdateDryRunStatus records the simulations of the latest dry run, and deletes only the series of the last dry run
+// which are not simulated any more, so that the scrapes never see the gauges cleared in between.
+func UpdateDryRunStatus(statuses []DryRunStatus) {
+	dryRunLock.Lock()
+	defer dryRunLock.Unlock()
+
+	series := make(map[[2]string]prometheus.Labels, len(statuses))
+	for _, status := range statuses {
+		labels := prometheus.Labels{"subcomponent": string(status.SubComponent), "metric": status.Metric}
+		series[[2]string{string(status.SubComponent), status.Metric}] = labels
+		dryRunPods.With(labels).Set(float64(status.Pods))
+		dryRunReleased.With(labels).Set(status.Released)
+		dryRunGap.With(labels).Set(status.Gap)
+		if status.GapClosed {
+			dryRunGapClosed.With(labels).Set(1)
+		} else {
+			dryRunGapClosed.With(labels).Set(0)
+		}
+	}
+
+	for key, labels := range dryRunSeries {
+		if _, ok := series[key]; ok {
+			continue
+		}
+		dryRunPods.Delete(labels)
+		dryRunReleased.Delete(labels)
+		dryRunGap.Delete(labels)
+		dryRunGapClosed.Delete(labels)
+	}
+	dryRunSeries = series
+}
+
 func UpdateAnalyzerStatus(typeName AnalyzeType, value float64) {
 	analyzerStatus.With(prometheus.Labels{"type": string(typeName), "key": ""}).Set(value)
 }
@@ -314,6 +456,18 @@ func ExecutorEvictCountsInc() {
 	executorEvictCounts.Inc()
 }
 
+func ExecutorEvictLimitedCountsInc(reason string) {
+	executorEvictLimitedCounts.With(prometheus.Labels{"reason": reason}).Inc()
+}
+
+func UpdateCPUSetCPUs(cpuset string, value int) {
+	cpuSetCPUs.With(prometheus.Labels{"cpuset": cpuset}).Set(float64(value))
+}
+
+func CPUSetRebalanceCountsInc(direction string) {
+	cpuSetRebalanceCounts.With(prometheus.Labels{"direction": direction}).Inc()
+}
+
 func UpdateNodeCpuCannotBeReclaimedSeconds(value float64) {
 	nodeCpuCannotBeReclaimedSeconds.With(prometheus.Labels{}).Set(value)
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/gocrane/crane/pkg/features"
 	. "github.com/gocrane/crane/pkg/metricprovider"
 	prometheus_adapter "github.com/gocrane/crane/pkg/prometheus-adapter"
+	"github.com/gocrane/crane/pkg/utils"
 )
 
 type CraneMetricCollector struct {
@@ -213,6 +214,10 @@ func (c *CraneMetricCollector) computePredictionMetric(tsp *predictionapi.TimeSe
 	metricConf := pmMap[status.ResourceIdentifier]
 
 	for _, data := range status.Prediction {
+		// the bands would have the same label values as the predicted time series
+		if utils.GetPredictionBand(data.Labels) != "" {
+			continue
+		}
 		predictionMetric := PredictionMetric{
 			TargetKind:         tsp.Spec.TargetRef.Kind,
 			TargetName:         tsp.Spec.TargetRef.Name,
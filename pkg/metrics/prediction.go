@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	TimeSeriesPredictionError = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "crane",
+			Subsystem: "prediction",
+			Name:      "time_series_prediction_error",
+			Help:      "The rolling error of the earlier predictions of TimeSeriesPrediction against the actual data, type is mape or mae",
+		},
+		[]string{"namespace", "name", "resource_identifier", "algorithm", "type"},
+	)
+
+	TimeSeriesPredictionEvaluatedSamples = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "crane",
+			Subsystem: "prediction",
+			Name:      "time_series_prediction_evaluated_samples",
+			Help:      "The number of predicted samples of TimeSeriesPrediction evaluated in the rolling window",
+		},
+		[]string{"namespace", "name", "resource_identifier", "algorithm"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(TimeSeriesPredictionError, TimeSeriesPredictionEvaluatedSamples)
+}
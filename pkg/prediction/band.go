@@ -0,0 +1,48 @@
+package prediction
+
+import (
+	"github.com/gocrane/crane/pkg/common"
+)
+
+const (
+	// BandLabelName is the label of the extra predicted time series which are the confidence bands of the prediction,
+	// the predicted time series itself has no such label.
+	BandLabelName = "prediction_band"
+	// BandUpper is the upper bound of the prediction, it is a conservative forecast
+	BandUpper = "upper"
+	// BandLower is the lower bound of the prediction, it is an aggressive forecast
+	BandLower = "lower"
+)
+
+// IsValidBand returns whether the band is empty (the predicted time series itself), upper or lower.
+func IsValidBand(band string) bool {
+	return band == "" || band == BandUpper || band == BandLower
+}
+
+// WithBandLabel returns a copy of the labels with the band label appended.
+func WithBandLabel(labels []common.Label, band string) []common.Label {
+	result := make([]common.Label, 0, len(labels)+1)
+	result = append(result, labels...)
+	return append(result, common.Label{Name: BandLabelName, Value: band})
+}
+
+// GetBand returns the band of the time series labels, it is empty for the predicted time series itself.
+func GetBand(labels []common.Label) string {
+	for _, label := range labels {
+		if label.Name == BandLabelName {
+			return label.Value
+		}
+	}
+	return ""
+}
+
+// FilterBand returns the time series of the band, use an empty band to get the predicted time series only.
+func FilterBand(tsList []*common.TimeSeries, band string) []*common.TimeSeries {
+	var result []*common.TimeSeries
+	for _, ts := range tsList {
+		if GetBand(ts.Labels) == band {
+			result = append(result, ts)
+		}
+	}
+	return result
+}
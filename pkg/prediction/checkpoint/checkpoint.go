@@ -0,0 +1,59 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+)
+
+type StoreType string
+
+const (
+	// StoreTypeNone disables the model checkpoint
+	StoreTypeNone StoreType = ""
+	// StoreTypeConfigMap stores each checkpoint in a configmap of the crane system namespace
+	StoreTypeConfigMap StoreType = "configmap"
+	// StoreTypeLocalFile stores each checkpoint in a file of a local directory, mostly used for testing
+	StoreTypeLocalFile StoreType = "file"
+)
+
+// MaxDataSize is the max size of the model data of a checkpoint. A configmap is limited to 1MiB, and the data is base64
+// encoded in it, so there is room left for the other fields and the object meta.
+const MaxDataSize = 700 * 1024
+
+// ErrNotFound is returned by Store.Load when there is no checkpoint for the model.
+var ErrNotFound = errors.New("checkpoint not found")
+
+// Checkpoint is a serialized snapshot of a prediction model.
+type Checkpoint struct {
+	// Algorithm is the name of the predictor which produced the checkpoint
+	Algorithm string `json:"algorithm"`
+	// Key is the unique key of the metric namer the model is built for
+	Key string `json:"key"`
+	// Timestamp is the time the checkpoint was taken
+	Timestamp time.Time `json:"timestamp"`
+	// Data is the model data, the format is defined by each predictor
+	Data []byte `json:"data"`
+}
+
+// Store persists prediction model checkpoints so that predictors can restore their models after a restart
+// instead of fetching the whole history window again.
+type Store interface {
+	// Save creates or overwrites the checkpoint of the model
+	Save(ctx context.Context, cp *Checkpoint) error
+	// Load returns the checkpoint of the model, ErrNotFound is returned if not exists
+	Load(ctx context.Context, algorithm string, key string) (*Checkpoint, error)
+	// Delete removes the checkpoint of the model, it is not an error if not exists
+	Delete(ctx context.Context, algorithm string, key string) error
+}
+
+// ObjectName returns a dns compliant name of the checkpoint, the key of a metric namer is a free form string
+// so we use a hash of it.
+func ObjectName(algorithm string, key string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return fmt.Sprintf("prediction-checkpoint-%s-%x", strings.ToLower(algorithm), h.Sum64())
+}
@@ -0,0 +1,99 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ConfigMapDataCheckpoint  = "checkpoint"
+	CheckpointAlgorithmLabel = "prediction.crane.io/checkpoint-algorithm"
+)
+
+type configMapStore struct {
+	client    client.Client
+	namespace string
+}
+
+// NewConfigMapStore returns a Store which keeps every checkpoint in a configmap of the namespace.
+func NewConfigMapStore(client client.Client, namespace string) Store {
+	return &configMapStore{
+		client:    client,
+		namespace: namespace,
+	}
+}
+
+func (s *configMapStore) Save(ctx context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	cm := &v1.ConfigMap{}
+	err = s.client.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: ObjectName(cp.Algorithm, cp.Key)}, cm)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: s.namespace,
+				Name:      ObjectName(cp.Algorithm, cp.Key),
+				Labels: map[string]string{
+					CheckpointAlgorithmLabel: cp.Algorithm,
+				},
+			},
+			Data: map[string]string{
+				ConfigMapDataCheckpoint: string(data),
+			},
+		}
+		return s.client.Create(ctx, cm)
+	}
+
+	cm.Data = map[string]string{
+		ConfigMapDataCheckpoint: string(data),
+	}
+	return s.client.Update(ctx, cm)
+}
+
+func (s *configMapStore) Load(ctx context.Context, algorithm string, key string) (*Checkpoint, error) {
+	cm := &v1.ConfigMap{}
+	err := s.client.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: ObjectName(algorithm, key)}, cm)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	data, ok := cm.Data[ConfigMapDataCheckpoint]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	cp := &Checkpoint{}
+	if err = json.Unmarshal([]byte(data), cp); err != nil {
+		return nil, err
+	}
+	// the object name is a hash of the key, make sure it is the right one
+	if cp.Algorithm != algorithm || cp.Key != key {
+		return nil, ErrNotFound
+	}
+	return cp, nil
+}
+
+func (s *configMapStore) Delete(ctx context.Context, algorithm string, key string) error {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: s.namespace,
+			Name:      ObjectName(algorithm, key),
+		},
+	}
+	return client.IgnoreNotFound(s.client.Delete(ctx, cm))
+}
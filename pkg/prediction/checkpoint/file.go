@@ -0,0 +1,74 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+type fileStore struct {
+	dir string
+}
+
+// NewFileStore returns a Store which keeps every checkpoint in a json file of the directory.
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &fileStore{dir: dir}, nil
+}
+
+func (s *fileStore) path(algorithm string, key string) string {
+	return filepath.Join(s.dir, ObjectName(algorithm, key)+".json")
+}
+
+func (s *fileStore) Save(_ context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	// write to a temp file then rename it, so a crash never leaves a partial checkpoint
+	tmp, err := os.CreateTemp(s.dir, ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(cp.Algorithm, cp.Key))
+}
+
+func (s *fileStore) Load(_ context.Context, algorithm string, key string) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path(algorithm, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	cp := &Checkpoint{}
+	if err = json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+	if cp.Algorithm != algorithm || cp.Key != key {
+		return nil, ErrNotFound
+	}
+	return cp, nil
+}
+
+func (s *fileStore) Delete(_ context.Context, algorithm string, key string) error {
+	err := os.Remove(s.path(algorithm, key))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
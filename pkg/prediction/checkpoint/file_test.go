@@ -0,0 +1,45 @@
+package checkpoint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	ctx := context.TODO()
+	_, err = store.Load(ctx, "percentile", "foo")
+	assert.Equal(t, ErrNotFound, err)
+
+	cp := &Checkpoint{
+		Algorithm: "percentile",
+		Key:       "foo",
+		Timestamp: time.Unix(1650000000, 0).UTC(),
+		Data:      []byte(`{"a":1}`),
+	}
+	assert.NoError(t, store.Save(ctx, cp))
+
+	loaded, err := store.Load(ctx, "percentile", "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, cp, loaded)
+
+	// same key but another algorithm is another checkpoint
+	_, err = store.Load(ctx, "dsp", "foo")
+	assert.Equal(t, ErrNotFound, err)
+
+	cp.Data = []byte(`{"a":2}`)
+	assert.NoError(t, store.Save(ctx, cp))
+	loaded, err = store.Load(ctx, "percentile", "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, cp.Data, loaded.Data)
+
+	assert.NoError(t, store.Delete(ctx, "percentile", "foo"))
+	assert.NoError(t, store.Delete(ctx, "percentile", "foo"))
+	_, err = store.Load(ctx, "percentile", "foo")
+	assert.Equal(t, ErrNotFound, err)
+}
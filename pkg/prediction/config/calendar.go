@@ -0,0 +1,95 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const calendarDateLayout = "2006-01-02"
+
+// SpecialDate is a date whose samples are not representative, such as a public holiday or a promotion day.
+type SpecialDate struct {
+	// Date is the date in the format of 2006-01-02
+	Date string `json:"date"`
+	// Exclude drops the samples of the date when training, it is the same as a zero TrainingWeight
+	Exclude bool `json:"exclude,omitempty"`
+	// TrainingWeight down-weights the samples of the date when training, it is in [0, 1] and 1 by default
+	TrainingWeight *float64 `json:"trainingWeight,omitempty"`
+	// Multiplier is applied to the forecast of the date, it is 1 by default
+	Multiplier *float64 `json:"multiplier,omitempty"`
+}
+
+// Calendar is a list of special dates which the predictor should be aware of.
+type Calendar struct {
+	// TimeZone is the IANA time zone of the dates, UTC by default
+	TimeZone string        `json:"timeZone,omitempty"`
+	Dates    []SpecialDate `json:"dates"`
+
+	location *time.Location
+	dates    map[string]*SpecialDate
+}
+
+// ParseCalendar parses and validates a calendar in json.
+func ParseCalendar(data string) (*Calendar, error) {
+	c := &Calendar{}
+	if err := json.Unmarshal([]byte(data), c); err != nil {
+		return nil, err
+	}
+
+	c.location = time.UTC
+	if c.TimeZone != "" {
+		location, err := time.LoadLocation(c.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		c.location = location
+	}
+
+	c.dates = map[string]*SpecialDate{}
+	for i := range c.Dates {
+		d := &c.Dates[i]
+		if _, err := time.ParseInLocation(calendarDateLayout, d.Date, c.location); err != nil {
+			return nil, fmt.Errorf("invalid date %q: %v", d.Date, err)
+		}
+		if d.TrainingWeight != nil && (*d.TrainingWeight < 0 || *d.TrainingWeight > 1) {
+			return nil, fmt.Errorf("trainingWeight of %s is not in [0, 1]", d.Date)
+		}
+		if d.Multiplier != nil && *d.Multiplier < 0 {
+			return nil, fmt.Errorf("multiplier of %s is negative", d.Date)
+		}
+		c.dates[d.Date] = d
+	}
+	return c, nil
+}
+
+func (c *Calendar) lookup(t time.Time) *SpecialDate {
+	if c == nil || len(c.dates) == 0 {
+		return nil
+	}
+	return c.dates[t.In(c.location).Format(calendarDateLayout)]
+}
+
+// TrainingWeight returns the weight of the samples at t when training, it is 1 if t is not a special date.
+func (c *Calendar) TrainingWeight(t time.Time) float64 {
+	d := c.lookup(t)
+	switch {
+	case d == nil:
+		return 1.0
+	case d.Exclude:
+		return 0.0
+	case d.TrainingWeight != nil:
+		return *d.TrainingWeight
+	default:
+		return 1.0
+	}
+}
+
+// Multiplier returns the multiplier of the forecast at t, it is 1 if t is not a special date.
+func (c *Calendar) Multiplier(t time.Time) float64 {
+	d := c.lookup(t)
+	if d == nil || d.Multiplier == nil {
+		return 1.0
+	}
+	return *d.Multiplier
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCalendar(t *testing.T) {
+	calendar, err := ParseCalendar(`{"timeZone": "Asia/Shanghai", "dates": [
+		{"date": "2022-10-01", "exclude": true, "multiplier": 0.5},
+		{"date": "2022-11-11", "trainingWeight": 0.2, "multiplier": 3}
+	]}`)
+	assert.NoError(t, err)
+
+	location, _ := time.LoadLocation("Asia/Shanghai")
+	assert.Equal(t, 0.0, calendar.TrainingWeight(time.Date(2022, 10, 1, 0, 30, 0, 0, location)))
+	assert.Equal(t, 0.5, calendar.Multiplier(time.Date(2022, 10, 1, 23, 30, 0, 0, location)))
+	assert.Equal(t, 0.2, calendar.TrainingWeight(time.Date(2022, 11, 11, 12, 0, 0, 0, location)))
+	assert.Equal(t, 3.0, calendar.Multiplier(time.Date(2022, 11, 11, 12, 0, 0, 0, location)))
+	// 2022-09-30 16:30 UTC is 2022-10-01 00:30 in Shanghai
+	assert.Equal(t, 0.0, calendar.TrainingWeight(time.Date(2022, 9, 30, 16, 30, 0, 0, time.UTC)))
+	assert.Equal(t, 1.0, calendar.TrainingWeight(time.Date(2022, 10, 2, 12, 0, 0, 0, location)))
+	assert.Equal(t, 1.0, calendar.Multiplier(time.Date(2022, 10, 2, 12, 0, 0, 0, location)))
+
+	var nilCalendar *Calendar
+	assert.Equal(t, 1.0, nilCalendar.TrainingWeight(time.Now()))
+	assert.Equal(t, 1.0, nilCalendar.Multiplier(time.Now()))
+
+	_, err = ParseCalendar(`{"dates": [{"date": "2022/10/01"}]}`)
+	assert.Error(t, err)
+	_, err = ParseCalendar(`{"dates": [{"date": "2022-10-01", "trainingWeight": 2}]}`)
+	assert.Error(t, err)
+	_, err = ParseCalendar(`{"timeZone": "Nowhere/Nothing", "dates": []}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// HoltWintersEstimator is a triple exponential smoothing estimator of the DSP predictor, which fits the time series
+// with a trend plus seasonality better than the FFT estimators. It's tested in addition to the DSP estimators once a
+// period is found.
+type HoltWintersEstimator struct {
+	// Seasonality is additive or multiplicative, additive by default
+	Seasonality string `json:"seasonality,omitempty"`
+	// Alpha, Beta and Gamma are the smoothing factors of the level, trend and seasonal components in [0, 1], the
+	// defaults are used if zero
+	Alpha float64 `json:"alpha,omitempty"`
+	Beta  float64 `json:"beta,omitempty"`
+	Gamma float64 `json:"gamma,omitempty"`
+	// MarginFraction is the fraction added to the estimation
+	MarginFraction float64 `json:"marginFraction,omitempty"`
+}
+
+// ParseHoltWintersEstimators parses and validates a list of Holt-Winters estimators in json.
+func ParseHoltWintersEstimators(data string) ([]HoltWintersEstimator, error) {
+	var estimators []HoltWintersEstimator
+	if err := json.Unmarshal([]byte(data), &estimators); err != nil {
+		return nil, err
+	}
+
+	for _, e := range estimators {
+		switch e.Seasonality {
+		case "", "additive", "multiplicative":
+		default:
+			return nil, fmt.Errorf("unknown seasonality %q", e.Seasonality)
+		}
+		for _, factor := range []float64{e.Alpha, e.Beta, e.Gamma} {
+			if factor < 0 || factor > 1 {
+				return nil, fmt.Errorf("smoothing factor %v is out of [0, 1]", factor)
+			}
+		}
+		if e.MarginFraction < 0 {
+			return nil, fmt.Errorf("marginFraction is negative")
+		}
+	}
+	return estimators, nil
+}
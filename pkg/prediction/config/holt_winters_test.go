@@ -0,0 +1,22 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHoltWintersEstimators(t *testing.T) {
+	estimators, err := ParseHoltWintersEstimators(`[{"seasonality": "multiplicative", "alpha": 0.2, "marginFraction": 0.1}, {}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, []HoltWintersEstimator{{Seasonality: "multiplicative", Alpha: 0.2, MarginFraction: 0.1}, {}}, estimators)
+
+	_, err = ParseHoltWintersEstimators(`[{"seasonality": "unknown"}]`)
+	assert.Error(t, err)
+	_, err = ParseHoltWintersEstimators(`[{"gamma": 1.5}]`)
+	assert.Error(t, err)
+	_, err = ParseHoltWintersEstimators(`[{"marginFraction": -0.1}]`)
+	assert.Error(t, err)
+	_, err = ParseHoltWintersEstimators(`{"seasonality": "additive"}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OutlierMethod is the method to detect the outliers of the history time series.
+type OutlierMethod string
+
+const (
+	// OutlierMethodMAD detects the samples whose robust z-score, based on the median absolute deviation, is too high
+	OutlierMethodMAD OutlierMethod = "mad"
+	// OutlierMethodIQR detects the samples out of the fences of the interquartile range
+	OutlierMethodIQR OutlierMethod = "iqr"
+	// OutlierMethodSTL decomposes the time series into trend, seasonal and residual, and detects the samples whose
+	// residual has a too high robust z-score, so that the peaks of a periodic time series are kept
+	OutlierMethodSTL OutlierMethod = "stl"
+)
+
+// OutlierRemoval specifies how to remove the outliers, such as incidents, load tests and backfills, from the history
+// time series before training.
+type OutlierRemoval struct {
+	Method OutlierMethod `json:"method"`
+	// Threshold is the max robust z-score for mad and stl, 3.5 by default, or the multiple of the interquartile range
+	// out of the quartiles for iqr, 1.5 by default
+	Threshold float64 `json:"threshold,omitempty"`
+	// Period is the seasonal period for stl, such as 24h, 24h by default
+	Period string `json:"period,omitempty"`
+
+	period time.Duration
+}
+
+// ParseOutlierRemoval parses and validates an outlier removal in json.
+func ParseOutlierRemoval(data string) (*OutlierRemoval, error) {
+	o := &OutlierRemoval{}
+	if err := json.Unmarshal([]byte(data), o); err != nil {
+		return nil, err
+	}
+
+	switch o.Method {
+	case OutlierMethodMAD, OutlierMethodIQR, OutlierMethodSTL:
+	default:
+		return nil, fmt.Errorf("unknown outlier method %q", o.Method)
+	}
+	if o.Threshold < 0 {
+		return nil, fmt.Errorf("threshold is negative")
+	}
+	if o.Period != "" {
+		period, err := time.ParseDuration(o.Period)
+		if err != nil {
+			return nil, fmt.Errorf("invalid period %q: %v", o.Period, err)
+		}
+		if period <= 0 {
+			return nil, fmt.Errorf("period is not positive")
+		}
+		o.period = period
+	}
+	return o, nil
+}
+
+// SeasonalPeriod returns the seasonal period for stl, 0 if not specified.
+func (o *OutlierRemoval) SeasonalPeriod() time.Duration {
+	if o == nil {
+		return 0
+	}
+	return o.period
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOutlierRemoval(t *testing.T) {
+	o, err := ParseOutlierRemoval(`{"method": "stl", "threshold": 4, "period": "168h"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, OutlierMethodSTL, o.Method)
+	assert.Equal(t, 4.0, o.Threshold)
+	assert.Equal(t, time.Hour*168, o.SeasonalPeriod())
+
+	o, err = ParseOutlierRemoval(`{"method": "mad"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), o.SeasonalPeriod())
+
+	var nilOutlierRemoval *OutlierRemoval
+	assert.Equal(t, time.Duration(0), nilOutlierRemoval.SeasonalPeriod())
+
+	_, err = ParseOutlierRemoval(`{"method": "unknown"}`)
+	assert.Error(t, err)
+	_, err = ParseOutlierRemoval(`{"method": "iqr", "threshold": -1}`)
+	assert.Error(t, err)
+	_, err = ParseOutlierRemoval(`{"method": "stl", "period": "one day"}`)
+	assert.Error(t, err)
+}
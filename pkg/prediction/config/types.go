@@ -4,10 +4,16 @@ import (
 	"time"
 
 	"github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
 )
 
 type AlgorithmModelConfig struct {
 	UpdateInterval time.Duration
+	// CheckpointInterval is the interval to checkpoint the models whose init mode is checkpoint
+	CheckpointInterval time.Duration
+	// CheckpointStore is where the model checkpoints are persisted, nil means checkpoint is disabled
+	CheckpointStore checkpoint.Store
 }
 
 type ModelInitMode string
@@ -26,4 +32,12 @@ type Config struct {
 	InitMode   *ModelInitMode
 	DSP        *v1alpha1.DSP
 	Percentile *v1alpha1.Percentile
+	// Calendar is the special dates which are dropped or down-weighted when training, and adjust the forecast
+	Calendar *Calendar
+	// OutlierRemoval removes the outliers from the history time series before training, nil means disabled
+	OutlierRemoval *OutlierRemoval
+	// Parameters are passed as is to the external predictor
+	Parameters map[string]string
+	// HoltWintersEstimators are tested by the DSP predictor in addition to its estimators, none by default
+	HoltWintersEstimators []HoltWintersEstimator
 }
@@ -11,12 +11,48 @@ type aggregateSignal struct {
 	startTime           time.Time
 	endTime             time.Time
 	lastUpdateTime      time.Time
+	// periodLength is the period of the signal found in the training window
+	periodLength time.Duration
+	// family is the model family of the estimator
+	family ModelFamily
+	// periods are the seasonal periods captured by the estimator in ascending order, empty if the model is aperiodic
+	periods []time.Duration
+	// estimator describes the estimator chosen to generate the predicted time series
+	estimator string
+	// cycle is the forecast of one period, or of the whole horizon if the model has a trend or is aperiodic, the predicted
+	// time series repeats it for repeats times
+	cycle   []float64
+	repeats int
+	// lowerBandOffset and upperBandOffset are the offsets of the prediction bands from the predicted time series
+	lowerBandOffset float64
+	upperBandOffset float64
+	// trainingStartTime and trainingEndTime is the window of the history samples used for training
+	trainingStartTime time.Time
+	trainingEndTime   time.Time
+	// historyTimeSeries is the raw history time series before preprocessing, it's kept only if checkpoint is enabled,
+	// so that the model is updated by the new samples after it's restored from checkpoint
+	historyTimeSeries *common.TimeSeries
 }
 
 func newAggregateSignal() *aggregateSignal {
 	return &aggregateSignal{}
 }
 
+// setForecast sets the forecast cycle of the signal, and the predicted time series repeating it since start.
+func (a *aggregateSignal) setForecast(labels []common.Label, start time.Time, interval time.Duration, cycle []float64, repeats int) {
+	samples := make([]common.Sample, 0, len(cycle)*repeats)
+	timestamp := start.Unix()
+	for k := 0; k < repeats; k++ {
+		for _, value := range cycle {
+			samples = append(samples, common.Sample{Value: value, Timestamp: timestamp})
+			timestamp += int64(interval.Seconds())
+		}
+	}
+	a.cycle = cycle
+	a.repeats = repeats
+	a.setPredictedTimeSeries(&common.TimeSeries{Labels: labels, Samples: samples})
+}
+
 func (a *aggregateSignal) setPredictedTimeSeries(ts *common.TimeSeries) {
 	n := len(ts.Samples)
 	if n > 0 {
@@ -32,7 +32,7 @@ func (a *aggregateSignals) Add(qc prediction.QueryExprWithCaller) bool {
 
 	QueryExpr := qc.MetricNamer.BuildUniqueKey()
 	if qc.Config.DSP != nil {
-		cfg, err := makeInternalConfig(qc.Config.DSP)
+		cfg, err := makeInternalConfig(qc.Config.DSP, qc.Config.InitMode, qc.Config.Calendar, qc.Config.OutlierRemoval, qc.Config.HoltWintersEstimators)
 		if err != nil {
 			klog.ErrorS(err, "Failed to make internal config.", "queryExpr", QueryExpr)
 		} else {
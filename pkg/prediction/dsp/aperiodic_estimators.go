@@ -0,0 +1,301 @@
+package dsp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+const (
+	defaultTrendQuantile       = 0.9
+	defaultTrendMaxFitPoints   = 500
+	defaultARIMAOrder          = 5
+	defaultAperiodicMinValue   = 0.01
+	defaultARIMADifferentiated = 1
+	// defaultAperiodicHorizon is the length of the forecast of the aperiodic estimators
+	defaultAperiodicHorizon = time.Hour * 24
+)
+
+// ModelFamily is the family of the model used to predict a time series.
+type ModelFamily string
+
+const (
+	// ModelFamilyPeriodic means the time series is periodic and predicted by repeating the forecast of one period
+	ModelFamilyPeriodic ModelFamily = "periodic"
+	// ModelFamilySeasonalTrend means the time series is periodic with a trend and predicted by holt-winters over the
+	// whole horizon
+	ModelFamilySeasonalTrend ModelFamily = "seasonal-trend"
+	// ModelFamilyTrend means the time series is not periodic and predicted by a trend plus a residual quantile
+	ModelFamilyTrend ModelFamily = "trend"
+	// ModelFamilyARIMA means the time series is not periodic and predicted by an autoregressive model
+	ModelFamilyARIMA ModelFamily = "arima"
+)
+
+// NewTrendEstimator returns an estimator which fits a linear trend of the signal and adds the quantile of the residuals
+// to the trend, the trend is fitted by Theil-Sen estimator if robust is true, or by least squares.
+func NewTrendEstimator(robust bool, quantile, marginFraction float64) Estimator {
+	return &trendEstimator{
+		robust:         robust,
+		quantile:       quantile,
+		marginFraction: marginFraction,
+	}
+}
+
+// NewARIMAEstimator returns an ARIMA(p,d,0) estimator, the autoregressive coefficients are solved from the
+// Yule-Walker equations of the d-th order differenced signal.
+func NewARIMAEstimator(p, d int, marginFraction float64) Estimator {
+	return &arimaEstimator{
+		p:              p,
+		d:              d,
+		marginFraction: marginFraction,
+	}
+}
+
+// modelFamilyOf returns the model family of the estimator.
+func modelFamilyOf(e Estimator) ModelFamily {
+	switch e.(type) {
+	case *trendEstimator:
+		return ModelFamilyTrend
+	case *arimaEstimator:
+		return ModelFamilyARIMA
+	case *holtWintersEstimator:
+		return ModelFamilySeasonalTrend
+	default:
+		return ModelFamilyPeriodic
+	}
+}
+
+type trendEstimator struct {
+	robust         bool
+	quantile       float64
+	marginFraction float64
+}
+
+func (t *trendEstimator) GetEstimation(signal *Signal, horizon time.Duration) *Signal {
+	n := len(signal.Samples)
+	h := int(horizon.Seconds() * signal.SampleRate)
+	if n < 2 || h <= 0 {
+		return nil
+	}
+
+	quantile := t.quantile
+	if quantile == 0 {
+		quantile = defaultTrendQuantile
+	}
+
+	var slope, intercept float64
+	if t.robust {
+		slope, intercept = theilSen(signal.Samples, defaultTrendMaxFitPoints)
+	} else {
+		points := make([]point, n)
+		for i := range signal.Samples {
+			points[i] = point{x: float64(i), y: signal.Samples[i]}
+		}
+		slope, intercept = linearRegressionLSE(points)
+	}
+	if math.IsNaN(slope) || math.IsNaN(intercept) {
+		return nil
+	}
+
+	residuals := make([]float64, n)
+	for i := range signal.Samples {
+		residuals[i] = signal.Samples[i] - (intercept + slope*float64(i))
+	}
+	offset, err := stats.Percentile(residuals, quantile*100)
+	if err != nil {
+		return nil
+	}
+
+	samples := make([]float64, h)
+	for j := 0; j < h; j++ {
+		a := intercept + slope*float64(n+j) + offset
+		if a <= 0.0 {
+			a = defaultAperiodicMinValue
+		}
+		samples[j] = a * (1.0 + t.marginFraction)
+	}
+
+	return &Signal{
+		SampleRate: signal.SampleRate,
+		Samples:    samples,
+	}
+}
+
+func (t *trendEstimator) String() string {
+	quantile := t.quantile
+	if quantile == 0 {
+		quantile = defaultTrendQuantile
+	}
+	return fmt.Sprintf("Trend Estimator {robust: %v, quantile: %f, marginFraction: %f}", t.robust, quantile, t.marginFraction)
+}
+
+// theilSen returns the median of the slopes between all pairs of points, the samples are averaged into at most
+// maxPoints buckets first to bound the quadratic cost, the intercept is the median of the intercepts of all samples.
+func theilSen(samples []float64, maxPoints int) (float64, float64) {
+	n := len(samples)
+	bucketSize := (n + maxPoints - 1) / maxPoints
+
+	var points []point
+	for i := 0; i < n; i += bucketSize {
+		end := i + bucketSize
+		if end > n {
+			end = n
+		}
+		sum := 0.
+		for j := i; j < end; j++ {
+			sum += samples[j]
+		}
+		// the x of a bucket is its center
+		points = append(points, point{x: float64(i+end-1) / 2.0, y: sum / float64(end-i)})
+	}
+
+	var slopes []float64
+	for i := 0; i < len(points); i++ {
+		for j := i + 1; j < len(points); j++ {
+			slopes = append(slopes, (points[j].y-points[i].y)/(points[j].x-points[i].x))
+		}
+	}
+	if len(slopes) == 0 {
+		return 0, samples[0]
+	}
+	slope := median(slopes)
+
+	intercepts := make([]float64, n)
+	for i := range samples {
+		intercepts[i] = samples[i] - slope*float64(i)
+	}
+	return slope, median(intercepts)
+}
+
+func median(x []float64) float64 {
+	sorted := make([]float64, len(x))
+	copy(sorted, x)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+type arimaEstimator struct {
+	p              int
+	d              int
+	marginFraction float64
+}
+
+func (a *arimaEstimator) order() (int, int) {
+	p, d := a.p, a.d
+	if p <= 0 {
+		p = defaultARIMAOrder
+	}
+	if d < 0 {
+		d = defaultARIMADifferentiated
+	}
+	return p, d
+}
+
+func (a *arimaEstimator) GetEstimation(signal *Signal, horizon time.Duration) *Signal {
+	p, d := a.order()
+	h := int(horizon.Seconds() * signal.SampleRate)
+	if h <= 0 || len(signal.Samples) <= p+d+1 {
+		return nil
+	}
+
+	// difference the signal d times, and keep the last value of each order to integrate the forecast
+	y := signal.Samples
+	lastValues := make([]float64, d)
+	for k := 0; k < d; k++ {
+		lastValues[k] = y[len(y)-1]
+		diff := make([]float64, len(y)-1)
+		for i := 1; i < len(y); i++ {
+			diff[i-1] = y[i] - y[i-1]
+		}
+		y = diff
+	}
+
+	mean, _ := stats.Mean(y)
+	phi := yuleWalker(y, mean, p)
+
+	// forecast the differenced signal recursively
+	history := make([]float64, p)
+	for i := 0; i < p; i++ {
+		history[i] = y[len(y)-p+i] - mean
+	}
+	forecast := make([]float64, h)
+	for j := 0; j < h; j++ {
+		next := 0.
+		for k := 0; k < p; k++ {
+			next += phi[k] * history[p-1-k]
+		}
+		history = append(history[1:], next)
+		forecast[j] = next + mean
+	}
+
+	// integrate the forecast d times
+	for k := d - 1; k >= 0; k-- {
+		last := lastValues[k]
+		for j := range forecast {
+			last += forecast[j]
+			forecast[j] = last
+		}
+	}
+
+	for j := range forecast {
+		if forecast[j] <= 0.0 {
+			forecast[j] = defaultAperiodicMinValue
+		}
+		forecast[j] *= 1.0 + a.marginFraction
+	}
+
+	return &Signal{
+		SampleRate: signal.SampleRate,
+		Samples:    forecast,
+	}
+}
+
+func (a *arimaEstimator) String() string {
+	p, d := a.order()
+	return fmt.Sprintf("ARIMA Estimator {p: %d, d: %d, q: 0, marginFraction: %f}", p, d, a.marginFraction)
+}
+
+// yuleWalker solves the autoregressive coefficients of order p by Levinson-Durbin recursion, zero coefficients are
+// returned if the signal is constant.
+func yuleWalker(x []float64, mean float64, p int) []float64 {
+	n := len(x)
+	r := make([]float64, p+1)
+	for lag := 0; lag <= p; lag++ {
+		for i := lag; i < n; i++ {
+			r[lag] += (x[i] - mean) * (x[i-lag] - mean)
+		}
+		r[lag] /= float64(n)
+	}
+
+	phi := make([]float64, p)
+	if r[0] == 0 {
+		return phi
+	}
+
+	e := r[0]
+	for k := 0; k < p; k++ {
+		acc := r[k+1]
+		for j := 0; j < k; j++ {
+			acc -= phi[j] * r[k-j]
+		}
+		reflection := acc / e
+		prev := make([]float64, k)
+		copy(prev, phi[:k])
+		phi[k] = reflection
+		for j := 0; j < k; j++ {
+			phi[j] = prev[j] - reflection*prev[k-1-j]
+		}
+		e *= 1 - reflection*reflection
+		if e <= 0 {
+			break
+		}
+	}
+	return phi
+}
@@ -0,0 +1,92 @@
+package dsp
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction/accuracy"
+)
+
+func TestTrendEstimator_GetEstimation(t *testing.T) {
+	sampleRate := 1.0 / 60.0
+	samplesPerDay := 1440
+	nDays := 3
+	rnd := rand.New(rand.NewSource(1))
+	value := func(i int) float64 {
+		return 10.0 + float64(i)*0.005 + rnd.Float64()
+	}
+
+	history := &Signal{SampleRate: sampleRate}
+	for i := 0; i < samplesPerDay*(nDays-1); i++ {
+		history.Samples = append(history.Samples, value(i))
+	}
+	var actual []float64
+	for i := samplesPerDay * (nDays - 1); i < samplesPerDay*nDays; i++ {
+		actual = append(actual, value(i))
+	}
+
+	for _, robust := range []bool{true, false} {
+		e := NewTrendEstimator(robust, 0.5, 0)
+		estimated := e.GetEstimation(history, Day)
+		assert.NotNil(t, estimated)
+		assert.Equal(t, samplesPerDay, estimated.Num())
+
+		mape, err := accuracy.MAPE(actual, estimated.Samples)
+		assert.NoError(t, err)
+		assert.Less(t, mape, 0.05, e.String())
+	}
+
+	// a higher quantile of the residuals gives a higher forecast
+	low := NewTrendEstimator(true, 0.5, 0).GetEstimation(history, Day)
+	high := NewTrendEstimator(true, 0.99, 0).GetEstimation(history, Day)
+	assert.Greater(t, high.Samples[0], low.Samples[0])
+}
+
+func TestARIMAEstimator_GetEstimation(t *testing.T) {
+	sampleRate := 1.0 / 60.0
+	samplesPerDay := 1440
+	nDays := 3
+	value := func(i int) float64 {
+		return 10.0 + float64(i)*0.005
+	}
+
+	history := &Signal{SampleRate: sampleRate}
+	for i := 0; i < samplesPerDay*(nDays-1); i++ {
+		history.Samples = append(history.Samples, value(i))
+	}
+	var actual []float64
+	for i := samplesPerDay * (nDays - 1); i < samplesPerDay*nDays; i++ {
+		actual = append(actual, value(i))
+	}
+
+	e := NewARIMAEstimator(5, 1, 0)
+	estimated := e.GetEstimation(history, Day)
+	assert.NotNil(t, estimated)
+	assert.Equal(t, samplesPerDay, estimated.Num())
+
+	mape, err := accuracy.MAPE(actual, estimated.Samples)
+	assert.NoError(t, err)
+	assert.Less(t, mape, 0.01, e.String())
+
+	// not enough samples
+	assert.Nil(t, e.GetEstimation(&Signal{SampleRate: sampleRate, Samples: make([]float64, 3)}, Day))
+}
+
+func TestChooseModel(t *testing.T) {
+	cfg := defaultInternalConfig
+	samplesPerDay := 1440
+	ts := &common.TimeSeries{}
+	for i := 0; i < samplesPerDay*3; i++ {
+		ts.Samples = append(ts.Samples, common.Sample{Timestamp: int64(i * 60), Value: 10.0 + float64(i)*0.005})
+	}
+
+	e, signal, nHorizons, horizon := chooseModel("test", ts, &cfg)
+	assert.NotNil(t, e)
+	assert.NotEqual(t, ModelFamilyPeriodic, modelFamilyOf(e))
+	assert.Equal(t, 3, nHorizons)
+	assert.Equal(t, Day, horizon)
+	assert.Equal(t, samplesPerDay*3, signal.Num())
+}
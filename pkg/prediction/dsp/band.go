@@ -0,0 +1,71 @@
+package dsp
+
+import (
+	"time"
+
+	"github.com/montanaflynn/stats"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction"
+)
+
+const (
+	defaultBandLowerPercentile = 10
+	defaultBandUpperPercentile = 90
+)
+
+// residualBand backtests the estimator on the last period of the signal and returns the offsets of the lower and upper
+// bands from the residual spread of its reconstruction. The lower offset is never positive and the upper offset is
+// never negative, so that the bands always enclose the prediction.
+func residualBand(estimator Estimator, signal *Signal, nPeriods int, periodLength time.Duration) (float64, float64) {
+	if nPeriods < 2 {
+		return 0, 0
+	}
+	samplesPerPeriod := len(signal.Samples) / nPeriods
+	history := &Signal{
+		SampleRate: signal.SampleRate,
+		Samples:    signal.Samples[:(nPeriods-1)*samplesPerPeriod],
+	}
+	actual := signal.Samples[(nPeriods-1)*samplesPerPeriod:]
+
+	estimated := estimator.GetEstimation(history, periodLength)
+	if estimated == nil || len(estimated.Samples) != len(actual) {
+		return 0, 0
+	}
+
+	residuals := make([]float64, len(actual))
+	for i := range actual {
+		residuals[i] = actual[i] - estimated.Samples[i]
+	}
+	lower, err := stats.Percentile(residuals, defaultBandLowerPercentile)
+	if err != nil {
+		return 0, 0
+	}
+	upper, err := stats.Percentile(residuals, defaultBandUpperPercentile)
+	if err != nil {
+		return 0, 0
+	}
+	if lower > 0 {
+		lower = 0
+	}
+	if upper < 0 {
+		upper = 0
+	}
+	return lower, upper
+}
+
+// bandTimeSeries returns the band of the predicted time series by adding the offset to every sample.
+func bandTimeSeries(ts *common.TimeSeries, band string, offset float64) *common.TimeSeries {
+	samples := make([]common.Sample, len(ts.Samples))
+	for i, sample := range ts.Samples {
+		value := sample.Value + offset
+		if value < 0 {
+			value = 0
+		}
+		samples[i] = common.Sample{Timestamp: sample.Timestamp, Value: value}
+	}
+	return &common.TimeSeries{
+		Labels:  prediction.WithBandLabel(ts.Labels, band),
+		Samples: samples,
+	}
+}
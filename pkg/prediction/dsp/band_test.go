@@ -0,0 +1,33 @@
+package dsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction"
+)
+
+func TestResidualBand(t *testing.T) {
+	samplesPerDay := 1440
+	signal := &Signal{SampleRate: 1.0 / 60.0}
+	for i := 0; i < samplesPerDay*3; i++ {
+		// the last day alternates around the level of the history
+		value := 10.0
+		if i >= samplesPerDay*2 {
+			value += float64(i%2)*2 - 1
+		}
+		signal.Samples = append(signal.Samples, value)
+	}
+
+	lower, upper := residualBand(&maxValueEstimator{}, signal, 3, Day)
+	assert.InDelta(t, -1.0, lower, 1e-9)
+	assert.InDelta(t, 1.0, upper, 1e-9)
+
+	ts := &common.TimeSeries{Samples: []common.Sample{{Timestamp: 1, Value: 0.5}}}
+	band := bandTimeSeries(ts, prediction.BandLower, lower)
+	assert.Equal(t, prediction.BandLower, prediction.GetBand(band.Labels))
+	assert.Equal(t, 0.0, band.Samples[0].Value)
+	assert.Empty(t, prediction.GetBand(ts.Labels))
+}
@@ -0,0 +1,287 @@
+package dsp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
+	"github.com/gocrane/crane/pkg/prediction/config"
+)
+
+const defaultCheckpointTimeout = 30 * time.Second
+
+// signalCheckpoint is the fitted model of a signal, the predicted time series is rebuilt from the forecast cycle
+type signalCheckpoint struct {
+	Labels []common.Label `json:"labels,omitempty"`
+	// Start is the time of the first predicted sample, and Interval is the time between two samples
+	Start    time.Time     `json:"start"`
+	Interval time.Duration `json:"interval"`
+	// Cycle is the compressed forecast of one period which is repeated for Repeats times
+	Cycle   []byte `json:"cycle"`
+	Repeats int    `json:"repeats"`
+	// History is the compressed raw history, so that the model is updated by the new samples after it's restored
+	History           *compressedSeries `json:"history,omitempty"`
+	PeriodLength      time.Duration     `json:"periodLength"`
+	Family            ModelFamily       `json:"family,omitempty"`
+	Periods           []time.Duration   `json:"periods,omitempty"`
+	Estimator         string            `json:"estimator"`
+	LowerBandOffset   float64           `json:"lowerBandOffset,omitempty"`
+	UpperBandOffset   float64           `json:"upperBandOffset,omitempty"`
+	TrainingStartTime time.Time         `json:"trainingStartTime"`
+	TrainingEndTime   time.Time         `json:"trainingEndTime"`
+	LastUpdateTime    time.Time         `json:"lastUpdateTime"`
+}
+
+// compressedSeries is a time series of a fixed interval, the values are compressed and a missing sample is NaN
+type compressedSeries struct {
+	Start    int64  `json:"start"`
+	Interval int64  `json:"interval"`
+	Values   []byte `json:"values"`
+}
+
+type modelCheckpoint struct {
+	Signals map[string]*signalCheckpoint `json:"signals"`
+}
+
+func (a *aggregateSignal) saveToCheckpoint(interval time.Duration) (*signalCheckpoint, error) {
+	cycle, err := compressValues(a.cycle)
+	if err != nil {
+		return nil, err
+	}
+	var history *compressedSeries
+	if a.historyTimeSeries != nil && len(a.historyTimeSeries.Samples) > 0 {
+		if history, err = compressSamples(a.historyTimeSeries.Samples, int64(interval.Seconds())); err != nil {
+			return nil, err
+		}
+	}
+	return &signalCheckpoint{
+		Labels:            a.predictedTimeSeries.Labels,
+		Start:             a.startTime,
+		Interval:          interval,
+		Cycle:             cycle,
+		Repeats:           a.repeats,
+		History:           history,
+		PeriodLength:      a.periodLength,
+		Family:            a.family,
+		Periods:           a.periods,
+		Estimator:         a.estimator,
+		LowerBandOffset:   a.lowerBandOffset,
+		UpperBandOffset:   a.upperBandOffset,
+		TrainingStartTime: a.trainingStartTime,
+		TrainingEndTime:   a.trainingEndTime,
+		LastUpdateTime:    a.lastUpdateTime,
+	}, nil
+}
+
+func (a *aggregateSignal) loadFromCheckpoint(cp *signalCheckpoint) error {
+	cycle, err := decompressValues(cp.Cycle)
+	if err != nil {
+		return err
+	}
+	a.setForecast(cp.Labels, cp.Start, cp.Interval, cycle, cp.Repeats)
+	if cp.History != nil {
+		samples, err := decompressSamples(cp.History)
+		if err != nil {
+			return err
+		}
+		a.historyTimeSeries = &common.TimeSeries{
+			Labels:  cp.Labels,
+			Samples: samples,
+		}
+	}
+	a.periodLength = cp.PeriodLength
+	a.family = cp.Family
+	a.periods = cp.Periods
+	a.estimator = cp.Estimator
+	a.lowerBandOffset = cp.LowerBandOffset
+	a.upperBandOffset = cp.UpperBandOffset
+	a.trainingStartTime = cp.TrainingStartTime
+	a.trainingEndTime = cp.TrainingEndTime
+	a.lastUpdateTime = cp.LastUpdateTime
+	return nil
+}
+
+// compressValues gzips the values in little endian.
+func compressValues(values []float64) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := binary.Write(w, binary.LittleEndian, values); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressValues(data []byte) ([]float64, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, len(raw)/8)
+	if err = binary.Read(bytes.NewReader(raw), binary.LittleEndian, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// compressSamples places the samples by their timestamps at the interval, the sample between two intervals is placed
+// at the former one.
+func compressSamples(samples []common.Sample, interval int64) (*compressedSeries, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %d", interval)
+	}
+	start := samples[0].Timestamp
+	values := make([]float64, (samples[len(samples)-1].Timestamp-start)/interval+1)
+	for i := range values {
+		values[i] = math.NaN()
+	}
+	for _, sample := range samples {
+		values[(sample.Timestamp-start)/interval] = sample.Value
+	}
+	data, err := compressValues(values)
+	if err != nil {
+		return nil, err
+	}
+	return &compressedSeries{Start: start, Interval: interval, Values: data}, nil
+}
+
+func decompressSamples(cs *compressedSeries) ([]common.Sample, error) {
+	values, err := decompressValues(cs.Values)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]common.Sample, 0, len(values))
+	for i, value := range values {
+		if !math.IsNaN(value) {
+			samples = append(samples, common.Sample{Timestamp: cs.Start + int64(i)*cs.Interval, Value: value})
+		}
+	}
+	return samples, nil
+}
+
+// saveCheckpoint serializes all the signals of the query expression to the checkpoint store.
+func (p *periodicSignalPrediction) saveCheckpoint(ctx context.Context, queryExpr string) error {
+	signals, status := p.a.GetSignals(queryExpr)
+	if status != prediction.StatusReady {
+		return nil
+	}
+
+	interval := p.a.GetConfig(queryExpr).historyResolution
+	m := modelCheckpoint{Signals: map[string]*signalCheckpoint{}}
+	for key, signal := range signals {
+		if signal.predictedTimeSeries == nil {
+			continue
+		}
+		sc, err := signal.saveToCheckpoint(interval)
+		if err != nil {
+			return err
+		}
+		m.Signals[key] = sc
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if len(data) > checkpoint.MaxDataSize {
+		klog.InfoS("Skip saving checkpoint, the model is too large.", "queryExpr", queryExpr, "size", len(data), "maxSize", checkpoint.MaxDataSize)
+		return nil
+	}
+
+	return p.modelConfig.CheckpointStore.Save(ctx, &checkpoint.Checkpoint{
+		Algorithm: p.Name(),
+		Key:       queryExpr,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+}
+
+// checkpointEnabled returns whether the model of the query expression should be checkpointed, which requires a
+// checkpoint store and the checkpoint init mode.
+func (p *periodicSignalPrediction) checkpointEnabled(queryExpr string) bool {
+	if p.modelConfig.CheckpointStore == nil {
+		return false
+	}
+	return p.a.GetConfig(queryExpr).initMode == config.ModelInitModeCheckpoint
+}
+
+// checkpointModel saves the model of the query expression if checkpoint is enabled, the dsp model only changes
+// when it is updated, so it's checkpointed right after each update.
+func (p *periodicSignalPrediction) checkpointModel(queryExpr string) {
+	if !p.checkpointEnabled(queryExpr) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCheckpointTimeout)
+	defer cancel()
+	if err := p.saveCheckpoint(ctx, queryExpr); err != nil {
+		klog.ErrorS(err, "Failed to save checkpoint.", "queryExpr", queryExpr)
+	} else {
+		klog.V(6).InfoS("Checkpoint saved.", "queryExpr", queryExpr)
+	}
+}
+
+func (p *periodicSignalPrediction) deleteCheckpoint(queryExpr string) {
+	if p.modelConfig.CheckpointStore == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCheckpointTimeout)
+	defer cancel()
+	if err := p.modelConfig.CheckpointStore.Delete(ctx, p.Name(), queryExpr); err != nil {
+		klog.ErrorS(err, "Failed to delete checkpoint.", "queryExpr", queryExpr)
+	}
+}
+
+// initByCheckPoint restores the model of the query expression from the latest checkpoint. A checkpoint is stale if it
+// is older than the model update interval, or if any of its predicted time series has already ended. The restored
+// model is ready to predict at once, and the caller updates it with the samples after the checkpointed history.
+func (p *periodicSignalPrediction) initByCheckPoint(queryExpr string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCheckpointTimeout)
+	defer cancel()
+
+	cp, err := p.modelConfig.CheckpointStore.Load(ctx, p.Name(), queryExpr)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if now.Sub(cp.Timestamp) > p.modelConfig.UpdateInterval {
+		return fmt.Errorf("checkpoint is stale, taken at %v", cp.Timestamp)
+	}
+
+	m := modelCheckpoint{}
+	if err = json.Unmarshal(cp.Data, &m); err != nil {
+		return err
+	}
+
+	signals := map[string]*aggregateSignal{}
+	for key, sc := range m.Signals {
+		signal := newAggregateSignal()
+		if err = signal.loadFromCheckpoint(sc); err != nil {
+			return err
+		}
+		if signal.predictedTimeSeries == nil || !signal.endTime.After(now) {
+			return fmt.Errorf("checkpoint is stale, predicted time series of %s has ended", key)
+		}
+		signals[key] = signal
+	}
+
+	p.a.SetSignals(queryExpr, signals)
+	klog.V(4).InfoS("Restored from checkpoint.", "queryExpr", queryExpr, "checkpointTime", cp.Timestamp, "signals", len(signals))
+	return nil
+}
@@ -0,0 +1,161 @@
+package dsp
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
+	"github.com/gocrane/crane/pkg/prediction/config"
+)
+
+func TestCheckpointSaveAndRestore(t *testing.T) {
+	store, err := checkpoint.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	mc := config.AlgorithmModelConfig{UpdateInterval: time.Hour, CheckpointStore: store}
+	p := NewPrediction(nil, nil, mc).(*periodicSignalPrediction)
+	queryExpr := "cpu"
+	p.a.signalMap[queryExpr] = map[string]*aggregateSignal{}
+
+	now := time.Now().Truncate(time.Minute)
+	var cycle []float64
+	for i := 0; i < 60; i++ {
+		cycle = append(cycle, float64(i))
+	}
+	labels := []common.Label{{Name: "pod", Value: "a"}}
+	signal := newAggregateSignal()
+	signal.setForecast(labels, now, time.Minute, cycle, 2)
+	samples := signal.predictedTimeSeries.Samples
+	signal.periodLength = Day
+	signal.family = ModelFamilyPeriodic
+	signal.estimator = "FFT Estimator"
+	history := []common.Sample{{Timestamp: now.Add(-3 * time.Minute).Unix(), Value: 1}, {Timestamp: now.Unix(), Value: 2}}
+	signal.historyTimeSeries = &common.TimeSeries{Labels: labels, Samples: history}
+	p.a.SetSignals(queryExpr, map[string]*aggregateSignal{prediction.AggregateSignalKey(labels): signal})
+
+	assert.NoError(t, p.saveCheckpoint(context.TODO(), queryExpr))
+
+	restored := NewPrediction(nil, nil, mc).(*periodicSignalPrediction)
+	restored.a.signalMap[queryExpr] = map[string]*aggregateSignal{}
+	assert.NoError(t, restored.initByCheckPoint(queryExpr))
+
+	signals, status := restored.a.GetSignals(queryExpr)
+	assert.Equal(t, prediction.StatusReady, status)
+	s := signals[prediction.AggregateSignalKey(labels)]
+	assert.NotNil(t, s)
+	assert.Equal(t, samples, s.predictedTimeSeries.Samples)
+	assert.Equal(t, 2, s.repeats)
+	assert.Equal(t, Day, s.periodLength)
+	assert.Equal(t, ModelFamilyPeriodic, s.family)
+	assert.Equal(t, "FFT Estimator", s.estimator)
+	assert.Equal(t, history, s.historyTimeSeries.Samples)
+	assert.Equal(t, now, lastHistoryTime(restored.getHistories(queryExpr)))
+
+	// the predicted time series has ended
+	signal.setForecast(labels, now.Add(-time.Minute), time.Minute, cycle[:1], 1)
+	assert.NoError(t, p.saveCheckpoint(context.TODO(), queryExpr))
+	assert.Error(t, restored.initByCheckPoint(queryExpr))
+
+	assert.Equal(t, checkpoint.ErrNotFound, restored.initByCheckPoint("mem"))
+}
+
+func TestCheckpointSize(t *testing.T) {
+	store, err := checkpoint.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	mc := config.AlgorithmModelConfig{UpdateInterval: time.Hour, CheckpointStore: store}
+	p := NewPrediction(nil, nil, mc).(*periodicSignalPrediction)
+	queryExpr := "cpu"
+	p.a.configMap[queryExpr] = &internalConfig{historyResolution: time.Minute}
+	p.a.signalMap[queryExpr] = map[string]*aggregateSignal{}
+
+	// a noisy signal of the default 15 days history at the default resolution
+	r := rand.New(rand.NewSource(0))
+	now := time.Now().Truncate(time.Minute)
+	n := int(15 * Day / time.Minute)
+	labels := []common.Label{{Name: "pod", Value: "a"}}
+	history := make([]common.Sample, 0, n)
+	for i := 0; i < n; i++ {
+		timestamp := now.Add(time.Duration(i-n+1) * time.Minute).Unix()
+		history = append(history, common.Sample{Timestamp: timestamp, Value: 1 + math.Sin(float64(i)*2*math.Pi/1440) + r.Float64()})
+	}
+	cycle := make([]float64, Day/time.Minute)
+	for i := range cycle {
+		cycle[i] = r.Float64()
+	}
+	signal := newAggregateSignal()
+	signal.setForecast(labels, now.Add(time.Minute), time.Minute, cycle, 15)
+	signal.periodLength = Day
+	signal.family = ModelFamilyPeriodic
+	signal.historyTimeSeries = &common.TimeSeries{Labels: labels, Samples: history}
+	signals := map[string]*aggregateSignal{prediction.AggregateSignalKey(labels): signal}
+	p.a.SetSignals(queryExpr, signals)
+
+	assert.NoError(t, p.saveCheckpoint(context.TODO(), queryExpr))
+	cp, err := store.Load(context.TODO(), p.Name(), queryExpr)
+	assert.NoError(t, err)
+	assert.Less(t, len(cp.Data), checkpoint.MaxDataSize)
+
+	restored := NewPrediction(nil, nil, mc).(*periodicSignalPrediction)
+	restored.a.signalMap[queryExpr] = map[string]*aggregateSignal{}
+	assert.NoError(t, restored.initByCheckPoint(queryExpr))
+	restoredSignals, _ := restored.a.GetSignals(queryExpr)
+	s := restoredSignals[prediction.AggregateSignalKey(labels)]
+	assert.Equal(t, history, s.historyTimeSeries.Samples)
+	assert.Equal(t, signal.predictedTimeSeries.Samples, s.predictedTimeSeries.Samples)
+
+	// the checkpoint is not saved if it's too large
+	assert.NoError(t, store.Delete(context.TODO(), p.Name(), queryExpr))
+	for i := 0; i < 10; i++ {
+		copied := *signal
+		copied.predictedTimeSeries = &common.TimeSeries{Labels: []common.Label{{Name: "pod", Value: strconv.Itoa(i)}}}
+		signals[strconv.Itoa(i)] = &copied
+	}
+	p.a.SetSignals(queryExpr, signals)
+	assert.NoError(t, p.saveCheckpoint(context.TODO(), queryExpr))
+	_, err = store.Load(context.TODO(), p.Name(), queryExpr)
+	assert.Equal(t, checkpoint.ErrNotFound, err)
+}
+
+func TestCheckpointEnabled(t *testing.T) {
+	store, err := checkpoint.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+	p := NewPrediction(nil, nil, config.AlgorithmModelConfig{CheckpointStore: store}).(*periodicSignalPrediction)
+
+	p.a.configMap["default"] = &internalConfig{}
+	p.a.configMap["history"] = &internalConfig{initMode: config.ModelInitModeHistory}
+	p.a.configMap["checkpoint"] = &internalConfig{initMode: config.ModelInitModeCheckpoint}
+	assert.False(t, p.checkpointEnabled("default"))
+	assert.False(t, p.checkpointEnabled("history"))
+	assert.True(t, p.checkpointEnabled("checkpoint"))
+
+	p.modelConfig.CheckpointStore = nil
+	assert.False(t, p.checkpointEnabled("checkpoint"))
+}
+
+func TestMergeHistories(t *testing.T) {
+	a := []common.Label{{Name: "pod", Value: "a"}}
+	histories := copyTimeSeriesList([]*common.TimeSeries{
+		{Labels: a, Samples: []common.Sample{{Timestamp: 60, Value: 1}, {Timestamp: 120, Value: 2}, {Timestamp: 180, Value: 3}}},
+	})
+
+	merged, ok := mergeHistories(histories, []*common.TimeSeries{
+		{Labels: a, Samples: []common.Sample{{Timestamp: 180, Value: 4}, {Timestamp: 240, Value: 5}}},
+	}, time.Unix(120, 0))
+	assert.True(t, ok)
+	assert.Equal(t, []common.Sample{{Timestamp: 120, Value: 2}, {Timestamp: 180, Value: 4}, {Timestamp: 240, Value: 5}}, merged[0].Samples)
+
+	// the whole history is needed for a new time series
+	_, ok = mergeHistories(histories, []*common.TimeSeries{
+		{Labels: []common.Label{{Name: "pod", Value: "b"}}, Samples: []common.Sample{{Timestamp: 240, Value: 5}}},
+	}, time.Unix(120, 0))
+	assert.False(t, ok)
+}
@@ -6,13 +6,16 @@ import (
 
 	"github.com/gocrane/api/prediction/v1alpha1"
 
+	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/outlier"
 	"github.com/gocrane/crane/pkg/utils"
 )
 
 var defaultInternalConfig = internalConfig{
-	historyResolution: time.Minute,
-	historyDuration:   time.Hour * 24 * 15,
-	estimators:        defaultEstimators,
+	historyResolution:   time.Minute,
+	historyDuration:     time.Hour * 24 * 15,
+	estimators:          defaultEstimators,
+	aperiodicEstimators: defaultAperiodicEstimators,
 }
 
 var defaultEstimators = []Estimator{
@@ -26,18 +29,36 @@ var defaultEstimators = []Estimator{
 	&fftEstimator{minNumOfSpectrumItems: 50, lowAmplitudeThreshold: 0.05, marginFraction: 0.20},
 }
 
+var defaultAperiodicEstimators = []Estimator{
+	&trendEstimator{robust: true, quantile: 0.5, marginFraction: 0.10},
+	&trendEstimator{robust: true, quantile: 0.9, marginFraction: 0.10},
+	&trendEstimator{robust: true, quantile: 0.99, marginFraction: 0.10},
+	&trendEstimator{robust: false, quantile: 0.9, marginFraction: 0.10},
+	&arimaEstimator{p: 5, d: 1, marginFraction: 0.10},
+	&arimaEstimator{p: 5, d: 0, marginFraction: 0.10},
+}
+
 type internalConfig struct {
 	historyResolution time.Duration
 	historyDuration   time.Duration
 	estimators        []Estimator
+	// aperiodicEstimators are used if the time series is not periodic
+	aperiodicEstimators []Estimator
+	// initMode is empty if not specified by the caller
+	initMode config.ModelInitMode
+	// calendar is nil if not specified by the caller
+	calendar *config.Calendar
+	// outlierDetector is nil if the outlier removal is not specified by the caller
+	outlierDetector outlier.Detector
 }
 
 func (i internalConfig) String() string {
-	return fmt.Sprintf("DSP internal Config: {historyResolution: %s, historyDuration: %v, estimators: %v",
-		i.historyResolution.String(), i.historyDuration.String(), i.estimators)
+	return fmt.Sprintf("DSP internal Config: {historyResolution: %s, historyDuration: %v, estimators: %v, aperiodicEstimators: %v",
+		i.historyResolution.String(), i.historyDuration.String(), i.estimators, i.aperiodicEstimators)
 }
 
-func makeInternalConfig(d *v1alpha1.DSP) (*internalConfig, error) {
+func makeInternalConfig(d *v1alpha1.DSP, initMode *config.ModelInitMode, calendar *config.Calendar, outlierRemoval *config.OutlierRemoval,
+	holtWinters []config.HoltWintersEstimator) (*internalConfig, error) {
 	historyResolution, err := utils.ParseDuration(d.SampleInterval)
 	if err != nil {
 		return nil, err
@@ -104,5 +125,34 @@ func makeInternalConfig(d *v1alpha1.DSP) (*internalConfig, error) {
 		estimators = defaultEstimators
 	}
 
-	return &internalConfig{historyResolution, historyDuration, estimators}, nil
+	if len(holtWinters) > 0 {
+		estimators = append([]Estimator{}, estimators...)
+		for _, e := range holtWinters {
+			seasonality := Seasonality(e.Seasonality)
+			if seasonality == "" {
+				seasonality = SeasonalityAdditive
+			}
+			estimators = append(estimators, NewHoltWintersEstimator(seasonality, e.Alpha, e.Beta, e.Gamma, e.MarginFraction))
+		}
+	}
+
+	var mode config.ModelInitMode
+	if initMode != nil {
+		mode = *initMode
+	}
+
+	detector, err := outlier.NewDetector(outlierRemoval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &internalConfig{
+		historyResolution:   historyResolution,
+		historyDuration:     historyDuration,
+		estimators:          estimators,
+		aperiodicEstimators: defaultAperiodicEstimators,
+		initMode:            mode,
+		calendar:            calendar,
+		outlierDetector:     detector,
+	}, nil
 }
@@ -0,0 +1,26 @@
+package dsp
+
+import (
+	"testing"
+
+	"github.com/gocrane/api/prediction/v1alpha1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/prediction/config"
+)
+
+func TestMakeInternalConfigWithHoltWinters(t *testing.T) {
+	d := &v1alpha1.DSP{SampleInterval: "60s", HistoryLength: "15d"}
+
+	cfg, err := makeInternalConfig(d, nil, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultEstimators, cfg.estimators)
+
+	cfg, err = makeInternalConfig(d, nil, nil, nil, []config.HoltWintersEstimator{{}, {Seasonality: "multiplicative", MarginFraction: 0.1}})
+	assert.NoError(t, err)
+	assert.Len(t, cfg.estimators, len(defaultEstimators)+2)
+	assert.Equal(t, NewHoltWintersEstimator(SeasonalityAdditive, 0, 0, 0, 0), cfg.estimators[len(defaultEstimators)])
+	assert.Equal(t, NewHoltWintersEstimator(SeasonalityMultiplicative, 0, 0, 0, 0.1), cfg.estimators[len(defaultEstimators)+1])
+	// the default estimators are kept intact
+	assert.Len(t, defaultEstimators, 8)
+}
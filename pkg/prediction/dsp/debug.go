@@ -12,53 +12,48 @@ import (
 	"github.com/gocrane/crane/pkg/prediction/config"
 )
 
-func Debug(predictor prediction.Interface, namer metricnaming.MetricNamer, config *config.Config) (*Signal, *Signal, *Signal, error) {
-	internalConfig, err := makeInternalConfig(config.DSP)
+// Debug returns the history, test and estimated signals of the first time series which can be predicted, and the
+// original samples of the outliers cleaned from its history.
+func Debug(predictor prediction.Interface, namer metricnaming.MetricNamer, config *config.Config) (*Signal, *Signal, *Signal, []common.Sample, error) {
+	internalConfig, err := makeInternalConfig(config.DSP, config.InitMode, config.Calendar, config.OutlierRemoval, config.HoltWintersEstimators)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
-	historyTimeSeriesList, err := queryHistoryTimeSeries(predictor.(*periodicSignalPrediction), namer, internalConfig)
+	historyTimeSeriesList, outliers, err := queryHistoryTimeSeries(predictor.(*periodicSignalPrediction), namer, internalConfig)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
 	queryExpr := namer.BuildUniqueKey()
 
-	var signal, history, test, estimate *Signal
-	var nPeriods int
-	var chosenEstimator Estimator
+	var history, test, estimate *Signal
 	for _, ts := range historyTimeSeriesList {
-		periodLength := findPeriod(ts, internalConfig.historyResolution)
-		if periodLength == Day || periodLength == Week {
-			signal = SamplesToSignal(ts.Samples, internalConfig.historyResolution)
-			signal, nPeriods = signal.Truncate(periodLength)
-			if nPeriods >= 2 {
-				chosenEstimator = bestEstimator(queryExpr, internalConfig.estimators, signal, nPeriods, periodLength)
+		chosenEstimator, signal, nPeriods, periodLength := chooseModel(queryExpr, ts, internalConfig)
+		if chosenEstimator != nil {
+			samplesPerPeriod := len(signal.Samples) / nPeriods
+			history = &Signal{
+				SampleRate: signal.SampleRate,
+				Samples:    signal.Samples[:(nPeriods-1)*samplesPerPeriod],
 			}
-			if chosenEstimator != nil {
-				samplesPerPeriod := len(signal.Samples) / nPeriods
-				history = &Signal{
-					SampleRate: signal.SampleRate,
-					Samples:    signal.Samples[:(nPeriods-1)*samplesPerPeriod],
-				}
-				test = &Signal{
-					SampleRate: signal.SampleRate,
-					Samples:    signal.Samples[(nPeriods-1)*samplesPerPeriod:],
-				}
-				estimate = chosenEstimator.GetEstimation(history, periodLength)
-				return history, test, estimate, nil
+			test = &Signal{
+				SampleRate: signal.SampleRate,
+				Samples:    signal.Samples[(nPeriods-1)*samplesPerPeriod:],
 			}
+			estimate = chosenEstimator.GetEstimation(history, periodLength)
+			return history, test, estimate, outliers[prediction.AggregateSignalKey(ts.Labels)], nil
 		}
 	}
 
-	return nil, nil, nil, fmt.Errorf("no prediction result")
+	return nil, nil, nil, nil, fmt.Errorf("no prediction result")
 }
 
-func queryHistoryTimeSeries(predictor *periodicSignalPrediction, namer metricnaming.MetricNamer, config *internalConfig) ([]*common.TimeSeries, error) {
+// queryHistoryTimeSeries returns the preprocessed history time series, and the original samples of the cleaned
+// outliers keyed by the labels of the time series.
+func queryHistoryTimeSeries(predictor *periodicSignalPrediction, namer metricnaming.MetricNamer, config *internalConfig) ([]*common.TimeSeries, map[string][]common.Sample, error) {
 	p := predictor.GetHistoryProvider()
 	if p == nil {
-		return nil, fmt.Errorf("history provider not provisioned")
+		return nil, nil, fmt.Errorf("history provider not provisioned")
 	}
 
 	end := time.Now().Truncate(config.historyResolution)
@@ -67,10 +62,21 @@ func queryHistoryTimeSeries(predictor *periodicSignalPrediction, namer metricnam
 	tsList, err := p.QueryTimeSeries(namer, start, end, config.historyResolution)
 	if err != nil {
 		klog.ErrorS(err, "Failed to query history time series.")
-		return nil, err
+		return nil, nil, err
 	}
 
 	klog.V(4).InfoS("DSP debug | queryHistoryTimeSeries", "timeSeriesList", tsList, "config", *config)
 
-	return preProcessTimeSeriesList(tsList, config)
+	var result []*common.TimeSeries
+	outliers := map[string][]common.Sample{}
+	for _, ts := range tsList {
+		cleaned, err := preProcessTimeSeries(ts, config, Hour)
+		if err != nil {
+			klog.ErrorS(err, "Dsp failed to pre process time series.")
+			continue
+		}
+		outliers[prediction.AggregateSignalKey(ts.Labels)] = cleaned
+		result = append(result, ts)
+	}
+	return result, outliers, nil
 }
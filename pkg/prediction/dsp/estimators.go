@@ -2,6 +2,7 @@ package dsp
 
 import (
 	"fmt"
+	"math"
 	"math/cmplx"
 	"sort"
 	"time"
@@ -17,6 +18,17 @@ const (
 	defaultFFTMarginFraction      = 0.0
 	defaultMaxValueMarginFraction = 0.0
 	defaultFFTMinValue            = 0.01
+	defaultHoltWintersAlpha       = 0.1
+	defaultHoltWintersBeta        = 0.001
+	defaultHoltWintersGamma       = 0.3
+	defaultHoltWintersMinValue    = 0.01
+)
+
+type Seasonality string
+
+const (
+	SeasonalityAdditive       Seasonality = "additive"
+	SeasonalityMultiplicative Seasonality = "multiplicative"
 )
 
 type Estimator interface {
@@ -24,6 +36,12 @@ type Estimator interface {
 	String() string
 }
 
+// horizonEstimator forecasts the horizon of nPeriods periods itself since its forecast of one period can't be repeated,
+// such as an estimator with a trend or with seasons longer than the period.
+type horizonEstimator interface {
+	GetHorizonEstimation(signal *Signal, periodLength time.Duration, nPeriods int) *Signal
+}
+
 func NewMaxValueEstimator(marginFraction float64) Estimator {
 	return &maxValueEstimator{marginFraction}
 }
@@ -38,6 +56,18 @@ func NewFFTEstimator(minNumOfSpectrumItems, maxNumOfSpectrumItems int, highFrequ
 	}
 }
 
+// NewHoltWintersEstimator returns a triple exponential smoothing estimator, alpha, beta and gamma are the smoothing
+// factors of the level, trend and seasonal components.
+func NewHoltWintersEstimator(seasonality Seasonality, alpha, beta, gamma, marginFraction float64) Estimator {
+	return &holtWintersEstimator{
+		seasonality:    seasonality,
+		alpha:          alpha,
+		beta:           beta,
+		gamma:          gamma,
+		marginFraction: marginFraction,
+	}
+}
+
 type maxValueEstimator struct {
 	marginFraction float64
 }
@@ -185,3 +215,128 @@ func (f *fftEstimator) String() string {
 	return fmt.Sprintf("FFT Estimator {minNumOfSpectrumItems: %d, maxNumOfSpectrumItems: %d, highFrequencyThreshold: %f, lowAmplitudeThreshold: %f, marginFraction: %f}",
 		minNumOfSpectrumItems, maxNumOfSpectrumItems, highFrequencyThreshold, lowAmplitudeThreshold, marginFraction)
 }
+
+// holtWintersEstimator models a signal as level + trend + seasonal components, which fits signals with a trend
+// better than fftEstimator. The season length is the period found by periodicity detection.
+type holtWintersEstimator struct {
+	seasonality    Seasonality
+	alpha          float64
+	beta           float64
+	gamma          float64
+	marginFraction float64
+}
+
+func (h *holtWintersEstimator) params() (float64, float64, float64) {
+	alpha, beta, gamma := h.alpha, h.beta, h.gamma
+	if alpha == 0 {
+		alpha = defaultHoltWintersAlpha
+	}
+	if beta == 0 {
+		beta = defaultHoltWintersBeta
+	}
+	if gamma == 0 {
+		gamma = defaultHoltWintersGamma
+	}
+	return alpha, beta, gamma
+}
+
+func (h *holtWintersEstimator) multiplicative() bool {
+	return h.seasonality == SeasonalityMultiplicative
+}
+
+func (h *holtWintersEstimator) GetEstimation(signal *Signal, periodLength time.Duration) *Signal {
+	return h.GetHorizonEstimation(signal, periodLength, 1)
+}
+
+// GetHorizonEstimation forecasts nPeriods seasons following the signal, the trend goes on across the seasons.
+func (h *holtWintersEstimator) GetHorizonEstimation(signal *Signal, periodLength time.Duration, nPeriods int) *Signal {
+	m := int(periodLength.Seconds() * signal.SampleRate)
+	nSamples := len(signal.Samples)
+	// at least two seasons are needed to initialize the trend
+	if m <= 0 || nSamples < 2*m {
+		return nil
+	}
+	nSeasons := nSamples / m
+	x := signal.Samples[nSamples-nSeasons*m:]
+
+	seasonMeans := make([]float64, nSeasons)
+	for k := 0; k < nSeasons; k++ {
+		sum := 0.
+		for i := 0; i < m; i++ {
+			sum += x[k*m+i]
+		}
+		seasonMeans[k] = sum / float64(m)
+	}
+	if h.multiplicative() {
+		for k := range seasonMeans {
+			// multiplicative seasonality is not defined for a signal which has no positive level
+			if seasonMeans[k] <= 0 {
+				return nil
+			}
+		}
+	}
+
+	// the initial trend is the average slope between the first and the last season, seasonal indices are the average
+	// deviations from the detrended season means
+	trend := (seasonMeans[nSeasons-1] - seasonMeans[0]) / float64((nSeasons-1)*m)
+	seasonal := make([]float64, m)
+	for i := 0; i < m; i++ {
+		sum := 0.
+		for k := 0; k < nSeasons; k++ {
+			mean := seasonMeans[k] + (float64(i)-float64(m-1)/2.0)*trend
+			if h.multiplicative() {
+				sum += x[k*m+i] / math.Max(mean, math.SmallestNonzeroFloat64)
+			} else {
+				sum += x[k*m+i] - mean
+			}
+		}
+		seasonal[i] = sum / float64(nSeasons)
+	}
+	// the level before the first sample
+	level := seasonMeans[0] - float64(m+1)/2.0*trend
+
+	alpha, beta, gamma := h.params()
+	for t := range x {
+		i := t % m
+		lastLevel := level
+		if h.multiplicative() {
+			level = alpha*x[t]/math.Max(seasonal[i], math.SmallestNonzeroFloat64) + (1-alpha)*(level+trend)
+			trend = beta*(level-lastLevel) + (1-beta)*trend
+			seasonal[i] = gamma*x[t]/math.Max(level, math.SmallestNonzeroFloat64) + (1-gamma)*seasonal[i]
+		} else {
+			level = alpha*(x[t]-seasonal[i]) + (1-alpha)*(level+trend)
+			trend = beta*(level-lastLevel) + (1-beta)*trend
+			seasonal[i] = gamma*(x[t]-level) + (1-gamma)*seasonal[i]
+		}
+	}
+
+	samples := make([]float64, nPeriods*m)
+	for j := range samples {
+		// the forecast of the next seasons, x has a length of multiple of m so the seasonal index starts from 0
+		var a float64
+		if h.multiplicative() {
+			a = (level + float64(j+1)*trend) * seasonal[j%m]
+		} else {
+			a = level + float64(j+1)*trend + seasonal[j%m]
+		}
+		if a <= 0.0 {
+			a = defaultHoltWintersMinValue
+		}
+		samples[j] = a * (1.0 + h.marginFraction)
+	}
+
+	return &Signal{
+		SampleRate: signal.SampleRate,
+		Samples:    samples,
+	}
+}
+
+func (h *holtWintersEstimator) String() string {
+	seasonality := h.seasonality
+	if seasonality == "" {
+		seasonality = SeasonalityAdditive
+	}
+	alpha, beta, gamma := h.params()
+	return fmt.Sprintf("Holt-Winters Estimator {seasonality: %s, alpha: %f, beta: %f, gamma: %f, marginFraction: %f}",
+		seasonality, alpha, beta, gamma, h.marginFraction)
+}
@@ -2,6 +2,7 @@ package dsp
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"testing"
 
@@ -10,6 +11,8 @@ import (
 	"github.com/go-echarts/go-echarts/v2/opts"
 	"github.com/go-echarts/go-echarts/v2/types"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/prediction/accuracy"
 )
 
 // Run this test to see the actual time series (black) and two forecasting time series
@@ -71,3 +74,42 @@ func TestFftEstimator_GetEstimation(t *testing.T) {
 	fmt.Println("Open your browser and access 'http://localhost:7001'")
 	//http.ListenAndServe(":7001", nil)
 }
+
+func TestHoltWintersEstimator_GetEstimation(t *testing.T) {
+	sampleRate := 1.0 / 60.0
+	samplesPerDay := 1440
+	nDays := 7
+	// daily seasonal signals with a growing trend
+	signals := map[Seasonality]func(i int) float64{
+		SeasonalityAdditive: func(i int) float64 {
+			return 10.0 + float64(i)*0.001 + 5.0*math.Sin(2.0*math.Pi*float64(i)/float64(samplesPerDay))
+		},
+		SeasonalityMultiplicative: func(i int) float64 {
+			return (10.0 + float64(i)*0.001) * (1.0 + 0.5*math.Sin(2.0*math.Pi*float64(i)/float64(samplesPerDay)))
+		},
+	}
+
+	for seasonality, value := range signals {
+		history := &Signal{SampleRate: sampleRate}
+		for i := 0; i < samplesPerDay*(nDays-1); i++ {
+			history.Samples = append(history.Samples, value(i))
+		}
+		var actual []float64
+		for i := samplesPerDay * (nDays - 1); i < samplesPerDay*nDays; i++ {
+			actual = append(actual, value(i))
+		}
+
+		e := NewHoltWintersEstimator(seasonality, 0, 0, 0, 0)
+		estimated := e.GetEstimation(history, Day)
+		assert.NotNil(t, estimated)
+		assert.Equal(t, samplesPerDay, estimated.Num())
+
+		mape, err := accuracy.MAPE(actual, estimated.Samples)
+		assert.NoError(t, err)
+		assert.Less(t, mape, 0.01, e.String())
+	}
+
+	// not enough seasons
+	e := NewHoltWintersEstimator(SeasonalityAdditive, 0, 0, 0, 0)
+	assert.Nil(t, e.GetEstimation(&Signal{SampleRate: sampleRate, Samples: make([]float64, samplesPerDay)}, Day))
+}
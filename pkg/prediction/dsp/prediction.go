@@ -57,17 +57,31 @@ func (p *periodicSignalPrediction) QueryRealtimePredictedValuesOnce(ctx context.
 	panic("implement me")
 }
 
-func findPeriod(ts *common.TimeSeries, sampleInterval time.Duration) time.Duration {
+// findPeriods returns the daily and weekly periods of the time series in ascending order. The time series is periodic
+// only if its fundamental period is a day or a week, then the other one is also returned if it is a peak of the auto
+// correlation function, e.g. a daily pattern with a weekend dip.
+func findPeriods(ts *common.TimeSeries, sampleInterval time.Duration) []time.Duration {
 	signal := SamplesToSignal(ts.Samples, sampleInterval)
 	si, m := signal.Truncate(Week)
-	if m > 1 {
-		return si.FindPeriod()
+	if m <= 1 {
+		si, m = signal.Truncate(Day)
 	}
-	si, m = signal.Truncate(Day)
-	if m > 1 {
-		return si.FindPeriod()
+	if m <= 1 {
+		return nil
 	}
-	return -1
+
+	fundamental, verified := si.FindPeriods()
+	if fundamental != Day && fundamental != Week {
+		return nil
+	}
+
+	var periods []time.Duration
+	for _, p := range verified {
+		if p == Day || p == Week {
+			periods = append(periods, p)
+		}
+	}
+	return periods
 }
 
 func SamplesToSignal(samples []common.Sample, sampleInterval time.Duration) *Signal {
@@ -112,9 +126,18 @@ func (p *periodicSignalPrediction) Run(stopCh <-chan struct{}) {
 				v, _ := p.stopChMap.LoadOrStore(queryExpr, make(chan struct{}))
 				predStopCh := v.(chan struct{})
 
+				// a model restored from checkpoint is ready to predict until the first update is done
+				if p.checkpointEnabled(queryExpr) {
+					if err := p.initByCheckPoint(queryExpr); err != nil {
+						klog.V(4).InfoS("Failed to init from checkpoint, init from history.", "queryExpr", queryExpr, "err", err)
+					}
+				}
+
 				for {
 					if err := p.updateAggregateSignalsWithQuery(namer); err != nil {
 						klog.ErrorS(err, "Failed to updateAggregateSignalsWithQuery.")
+					} else {
+						p.checkpointModel(queryExpr)
 					}
 
 					select {
@@ -143,6 +166,7 @@ func (p *periodicSignalPrediction) Run(stopCh <-chan struct{}) {
 						predStopCh := val.(chan struct{})
 						predStopCh <- struct{}{}
 					}
+					p.deleteCheckpoint(QueryExpr)
 				}
 			}(qc)
 		}
@@ -182,7 +206,17 @@ func (p *periodicSignalPrediction) updateAggregateSignalsWithQuery(namer metricn
 
 	cfg := p.a.GetConfig(queryExpr)
 
-	p.updateAggregateSignals(queryExpr, tsList, cfg)
+	// the raw history is copied before it's preprocessed in place
+	var histories map[string]*common.TimeSeries
+	if p.checkpointEnabled(queryExpr) {
+		histories = copyTimeSeriesList(tsList)
+	}
+	tsList, err = preProcessTimeSeriesList(tsList, cfg)
+	if err != nil {
+		return err
+	}
+
+	p.updateAggregateSignals(queryExpr, tsList, histories, cfg)
 
 	return nil
 }
@@ -198,6 +232,21 @@ func (p *periodicSignalPrediction) queryHistoryTimeSeries(namer metricnaming.Met
 	end := time.Now().Truncate(config.historyResolution)
 	start := end.Add(-config.historyDuration - time.Hour)
 
+	// only the samples since the end of the history kept for checkpoint are queried
+	histories := p.getHistories(queryExpr)
+	if last := lastHistoryTime(histories); last.After(start) {
+		tsList, err := p.GetHistoryProvider().QueryTimeSeries(namer, last, end, config.historyResolution)
+		if err != nil {
+			klog.ErrorS(err, "Failed to query history time series.")
+			return nil, err
+		}
+		if merged, ok := mergeHistories(histories, tsList, start); ok {
+			klog.V(6).InfoS("dsp queryHistoryTimeSeries", "timeSeriesList", merged, "since", last, "config", *config)
+			return merged, nil
+		}
+		klog.V(4).InfoS("New time series found, query the whole history.", "queryExpr", queryExpr)
+	}
+
 	tsList, err := p.GetHistoryProvider().QueryTimeSeries(namer, start, end, config.historyResolution)
 	if err != nil {
 		klog.ErrorS(err, "Failed to query history time series.")
@@ -206,70 +255,155 @@ func (p *periodicSignalPrediction) queryHistoryTimeSeries(namer metricnaming.Met
 
 	klog.V(6).InfoS("dsp queryHistoryTimeSeries", "timeSeriesList", tsList, "config", *config)
 
-	return preProcessTimeSeriesList(tsList, config)
+	return tsList, nil
 }
 
-func (p *periodicSignalPrediction) updateAggregateSignals(queryExpr string, historyTimeSeriesList []*common.TimeSeries, config *internalConfig) {
-	var predictedTimeSeriesList []*common.TimeSeries
+// getHistories returns the raw history time series of the signals by their keys.
+func (p *periodicSignalPrediction) getHistories(queryExpr string) map[string]*common.TimeSeries {
+	signals, _ := p.a.GetSignals(queryExpr)
+	histories := map[string]*common.TimeSeries{}
+	for key, signal := range signals {
+		if signal.historyTimeSeries != nil && len(signal.historyTimeSeries.Samples) > 0 {
+			histories[key] = signal.historyTimeSeries
+		}
+	}
+	return histories
+}
+
+// lastHistoryTime returns the earliest time of the last samples of the histories, so that none of them misses the
+// new samples. It's zero if there are no histories.
+func lastHistoryTime(histories map[string]*common.TimeSeries) time.Time {
+	var last time.Time
+	for _, ts := range histories {
+		t := time.Unix(ts.Samples[len(ts.Samples)-1].Timestamp, 0)
+		if last.IsZero() || t.Before(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+// mergeHistories prepends the histories since start to the new time series, the samples of the histories overlapped by
+// the new time series are dropped. It returns false if any of the new time series doesn't have a history.
+func mergeHistories(histories map[string]*common.TimeSeries, tsList []*common.TimeSeries, start time.Time) ([]*common.TimeSeries, bool) {
+	merged := make([]*common.TimeSeries, 0, len(tsList))
+	for _, ts := range tsList {
+		history, ok := histories[prediction.AggregateSignalKey(ts.Labels)]
+		if !ok {
+			return nil, false
+		}
+		var samples []common.Sample
+		for _, sample := range history.Samples {
+			if sample.Timestamp >= start.Unix() && (len(ts.Samples) == 0 || sample.Timestamp < ts.Samples[0].Timestamp) {
+				samples = append(samples, sample)
+			}
+		}
+		merged = append(merged, &common.TimeSeries{
+			Labels:  ts.Labels,
+			Samples: append(samples, ts.Samples...),
+		})
+	}
+	return merged, true
+}
+
+func copyTimeSeriesList(tsList []*common.TimeSeries) map[string]*common.TimeSeries {
+	copied := make(map[string]*common.TimeSeries, len(tsList))
+	for _, ts := range tsList {
+		copied[prediction.AggregateSignalKey(ts.Labels)] = &common.TimeSeries{
+			Labels:  ts.Labels,
+			Samples: append([]common.Sample(nil), ts.Samples...),
+		}
+	}
+	return copied
+}
+
+func (p *periodicSignalPrediction) updateAggregateSignals(queryExpr string, historyTimeSeriesList []*common.TimeSeries,
+	histories map[string]*common.TimeSeries, config *internalConfig) {
+	signals := map[string]*aggregateSignal{}
 
 	for _, ts := range historyTimeSeriesList {
 		if klog.V(6).Enabled() {
 			sampleData, err := json.Marshal(ts.Samples)
 			klog.V(6).Infof("Got time series, queryExpr: %s, samples: %v, labels: %v, err: %v", queryExpr, string(sampleData), ts.Labels, err)
 		}
-		var chosenEstimator Estimator
-		var signal *Signal
-		var nPeriods int
-		var periodLength time.Duration = 0
+		chosenEstimator, signal, nPeriods, periodLength := chooseModel(queryExpr, ts, config)
 
-		p := findPeriod(ts, config.historyResolution)
-		if p == Day || p == Week {
-			periodLength = p
-			klog.V(4).InfoS("This is a periodic time series.", "queryExpr", queryExpr, "labels", ts.Labels, "periodLength", periodLength)
-		} else {
-			klog.V(4).InfoS("This is not a periodic time series.", "queryExpr", queryExpr, "labels", ts.Labels)
+		// the forecast of one period is repeated for a periodic model, a model with a trend or longer seasons
+		// forecasts the whole horizon itself, and the forecast of an aperiodic model is not repeated
+		var estimatedSignal *Signal
+		var family ModelFamily
+		repeats := 1
+		if chosenEstimator != nil {
+			family = modelFamilyOf(chosenEstimator)
+			if e, ok := chosenEstimator.(horizonEstimator); ok {
+				estimatedSignal = e.GetHorizonEstimation(signal, periodLength, nPeriods)
+			} else {
+				estimatedSignal = chosenEstimator.GetEstimation(signal, periodLength)
+				if family == ModelFamilyPeriodic {
+					repeats = nPeriods
+				}
+			}
 		}
 
-		if periodLength > 0 {
-			signal = SamplesToSignal(ts.Samples, config.historyResolution)
-			signal, nPeriods = signal.Truncate(periodLength)
-			if nPeriods >= 2 {
-				chosenEstimator = bestEstimator(queryExpr, config.estimators, signal, nPeriods, periodLength)
+		if estimatedSignal != nil {
+			nextTimestamp := ts.Samples[len(ts.Samples)-1].Timestamp + int64(config.historyResolution.Seconds())
+
+			s := newAggregateSignal()
+			s.setForecast(ts.Labels, time.Unix(nextTimestamp, 0), config.historyResolution, estimatedSignal.Samples, repeats)
+			if family == ModelFamilyPeriodic || family == ModelFamilySeasonalTrend {
+				s.periodLength = periodLength
 			}
+			s.family = family
+			s.periods = modelPeriods(chosenEstimator, periodLength)
+			s.estimator = chosenEstimator.String()
+			s.lowerBandOffset, s.upperBandOffset = residualBand(chosenEstimator, signal, nPeriods, periodLength)
+			s.trainingStartTime = time.Unix(ts.Samples[len(ts.Samples)-signal.Num()].Timestamp, 0)
+			s.trainingEndTime = time.Unix(ts.Samples[len(ts.Samples)-1].Timestamp, 0)
+			s.historyTimeSeries = histories[prediction.AggregateSignalKey(ts.Labels)]
+			signals[prediction.AggregateSignalKey(ts.Labels)] = s
 		}
+	}
 
-		if chosenEstimator != nil {
-			estimatedSignal := chosenEstimator.GetEstimation(signal, periodLength)
-			intervalSeconds := int64(config.historyResolution.Seconds())
-			nextTimestamp := ts.Samples[len(ts.Samples)-1].Timestamp + intervalSeconds
-
-			n := len(estimatedSignal.Samples)
-			samples := make([]common.Sample, n*nPeriods)
-			for k := 0; k < nPeriods; k++ {
-				for i := range estimatedSignal.Samples {
-					samples[i+k*n] = common.Sample{
-						Value:     estimatedSignal.Samples[i],
-						Timestamp: nextTimestamp,
-					}
-					nextTimestamp += intervalSeconds
-				}
-			}
+	p.a.SetSignals(queryExpr, signals)
+}
 
-			predictedTimeSeriesList = append(predictedTimeSeriesList, &common.TimeSeries{
-				Labels:  ts.Labels,
-				Samples: samples,
-			})
+// chooseModel chooses the best estimator for the time series by backtest. The periodic estimators are tested if a
+// daily or weekly period is found, plus the multi seasonal estimators if both are found. Otherwise, or if none of them
+// works, the aperiodic estimators are tested with a horizon of one day. It returns the chosen estimator, the truncated
+// signal, the number of periods (or horizons) of the signal and the period length (or horizon).
+func chooseModel(id string, ts *common.TimeSeries, config *internalConfig) (Estimator, *Signal, int, time.Duration) {
+	var periodLength time.Duration = 0
+	periods := findPeriods(ts, config.historyResolution)
+	if len(periods) > 0 {
+		// the estimators are validated on the shortest period, so that the history only needs two seasons of the
+		// longest period before it for the multi seasonal estimators
+		periodLength = periods[0]
+		klog.V(4).InfoS("This is a periodic time series.", "queryExpr", id, "labels", ts.Labels, "periodLength", periodLength, "periods", periods)
+	} else {
+		klog.V(4).InfoS("This is not a periodic time series.", "queryExpr", id, "labels", ts.Labels)
+	}
+
+	if periodLength > 0 {
+		estimators := config.estimators
+		if seasonalEstimators := multiSeasonalEstimators(periods); len(seasonalEstimators) > 0 {
+			estimators = append(append([]Estimator{}, config.estimators...), seasonalEstimators...)
+		}
+		signal, nPeriods := SamplesToSignal(ts.Samples, config.historyResolution).Truncate(periodLength)
+		if nPeriods >= 2 {
+			if e := bestEstimator(id, estimators, signal, nPeriods, periodLength); e != nil {
+				return e, signal, nPeriods, periodLength
+			}
 		}
 	}
 
-	signals := map[string]*aggregateSignal{}
-	for i := range predictedTimeSeriesList {
-		key := prediction.AggregateSignalKey(predictedTimeSeriesList[i].Labels)
-		signal := newAggregateSignal()
-		signal.setPredictedTimeSeries(predictedTimeSeriesList[i])
-		signals[key] = signal
+	if len(config.aperiodicEstimators) == 0 {
+		return nil, nil, 0, 0
 	}
-	p.a.SetSignals(queryExpr, signals)
+	signal, nHorizons := SamplesToSignal(ts.Samples, config.historyResolution).Truncate(defaultAperiodicHorizon)
+	if nHorizons < 2 {
+		return nil, nil, 0, 0
+	}
+	return bestEstimator(id, config.aperiodicEstimators, signal, nHorizons, defaultAperiodicHorizon), signal, nHorizons, defaultAperiodicHorizon
 }
 
 func bestEstimator(id string, estimators []Estimator, signal *Signal, nPeriods int, periodLength time.Duration) Estimator {
@@ -299,12 +433,17 @@ func bestEstimator(id string, estimators []Estimator, signal *Signal, nPeriods i
 		}
 	}
 
+	if bestEstimator == nil {
+		klog.V(4).InfoS("No estimator works.", "key", id, "periods", nPeriods)
+		return nil
+	}
+
 	klog.V(4).InfoS("Got the best estimator.", "key", id, "estimator", bestEstimator.String(), "minPE", minPE, "periods", nPeriods)
 	return bestEstimator
 }
 
 func (p *periodicSignalPrediction) QueryPredictedTimeSeries(ctx context.Context, namer metricnaming.MetricNamer, startTime time.Time, endTime time.Time) ([]*common.TimeSeries, error) {
-	return p.getPredictedTimeSeriesList(ctx, namer, startTime, endTime), nil
+	return p.getPredictedTimeSeriesList(ctx, namer, startTime, endTime, true), nil
 }
 
 func (p *periodicSignalPrediction) QueryRealtimePredictedValues(ctx context.Context, namer metricnaming.MetricNamer) ([]*common.TimeSeries, error) {
@@ -315,7 +454,7 @@ func (p *periodicSignalPrediction) QueryRealtimePredictedValues(ctx context.Cont
 	start := now.Truncate(config.historyResolution)
 	end := start.Add(defaultFuture)
 
-	predictedTimeSeries := p.getPredictedTimeSeriesList(ctx, namer, start, end)
+	predictedTimeSeries := p.getPredictedTimeSeriesList(ctx, namer, start, end, false)
 
 	var realtimePredictedTimeSeries []*common.TimeSeries
 
@@ -337,7 +476,9 @@ func (p *periodicSignalPrediction) QueryRealtimePredictedValues(ctx context.Cont
 	return realtimePredictedTimeSeries, nil
 }
 
-func (p *periodicSignalPrediction) getPredictedTimeSeriesList(ctx context.Context, namer metricnaming.MetricNamer, start, end time.Time) []*common.TimeSeries {
+// getPredictedTimeSeriesList returns the predicted time series in [start, end], the lower and upper bands are appended
+// if withBands is true.
+func (p *periodicSignalPrediction) getPredictedTimeSeriesList(ctx context.Context, namer metricnaming.MetricNamer, start, end time.Time, withBands bool) []*common.TimeSeries {
 	var predictedTimeSeriesList []*common.TimeSeries
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
@@ -350,12 +491,15 @@ func (p *periodicSignalPrediction) getPredictedTimeSeriesList(ctx context.Contex
 			return predictedTimeSeriesList
 		}
 		if signals != nil && status == prediction.StatusReady {
+			calendar := p.a.GetConfig(queryExpr).calendar
 			for key, signal := range signals {
 				var samples []common.Sample
 				for _, sample := range signal.predictedTimeSeries.Samples {
 					t := time.Unix(sample.Timestamp, 0)
 					// Check if t is in [startTime, endTime]
 					if !t.Before(start) && !t.After(end) {
+						// the multipliers of the calendar are applied when querying, so that the model is kept intact
+						sample.Value *= calendar.Multiplier(t)
 						samples = append(samples, sample)
 					} else if t.After(end) {
 						break
@@ -363,10 +507,16 @@ func (p *periodicSignalPrediction) getPredictedTimeSeriesList(ctx context.Contex
 				}
 
 				if len(samples) > 0 {
-					predictedTimeSeriesList = append(predictedTimeSeriesList, &common.TimeSeries{
+					ts := &common.TimeSeries{
 						Labels:  signal.predictedTimeSeries.Labels,
 						Samples: samples,
-					})
+					}
+					predictedTimeSeriesList = append(predictedTimeSeriesList, ts)
+					if withBands {
+						predictedTimeSeriesList = append(predictedTimeSeriesList,
+							bandTimeSeries(ts, prediction.BandLower, signal.lowerBandOffset),
+							bandTimeSeries(ts, prediction.BandUpper, signal.upperBandOffset))
+					}
 				}
 
 				klog.InfoS("Got DSP predicted samples.", "queryExpr", queryExpr, "labels", key, "len", len(samples))
@@ -386,3 +536,24 @@ func (p *periodicSignalPrediction) getPredictedTimeSeriesList(ctx context.Contex
 func (p *periodicSignalPrediction) Name() string {
 	return "Periodic"
 }
+
+func (p *periodicSignalPrediction) DescribeModels(namer metricnaming.MetricNamer) []prediction.ModelDescription {
+	signals, status := p.a.GetSignals(namer.BuildUniqueKey())
+	if status != prediction.StatusReady {
+		return nil
+	}
+
+	var descriptions []prediction.ModelDescription
+	for _, signal := range signals {
+		if signal.predictedTimeSeries == nil {
+			continue
+		}
+		descriptions = append(descriptions, prediction.ModelDescription{
+			Labels:    signal.predictedTimeSeries.Labels,
+			Family:    string(signal.family),
+			Periods:   signal.periods,
+			Estimator: signal.estimator,
+		})
+	}
+	return descriptions
+}
@@ -3,11 +3,14 @@ package dsp
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction/config"
 	"github.com/gocrane/crane/pkg/providers/csv"
 	"github.com/stretchr/testify/assert"
 )
@@ -39,7 +42,8 @@ func TestPreProcessTimeSeries(t *testing.T) {
 	timeSeries := tsList[0]
 	assert.Equal(t, 5, len(timeSeries.Samples))
 
-	assert.NoError(t, preProcessTimeSeries(timeSeries, &defaultInternalConfig, time.Minute))
+	_, err = preProcessTimeSeries(timeSeries, &defaultInternalConfig, time.Minute)
+	assert.NoError(t, err)
 	assert.Equal(t, 11, len(timeSeries.Samples))
 
 	for i := 1; i < len(timeSeries.Samples); i++ {
@@ -48,7 +52,8 @@ func TestPreProcessTimeSeries(t *testing.T) {
 	}
 
 	// Truncate the time series to multiple of 10 minutes.
-	assert.NoError(t, preProcessTimeSeries(timeSeries, &defaultInternalConfig, 10*time.Minute))
+	_, err = preProcessTimeSeries(timeSeries, &defaultInternalConfig, 10*time.Minute)
+	assert.NoError(t, err)
 	assert.Equal(t, 10, len(timeSeries.Samples))
 
 	for i := 1; i < len(timeSeries.Samples); i++ {
@@ -56,3 +61,41 @@ func TestPreProcessTimeSeries(t *testing.T) {
 		assert.Equal(t, int64(60), timeSeries.Samples[i].Timestamp-timeSeries.Samples[i-1].Timestamp)
 	}
 }
+
+func TestUpdateAggregateSignalsWithTrend(t *testing.T) {
+	p := NewPrediction(nil, nil, config.AlgorithmModelConfig{}).(*periodicSignalPrediction)
+	queryExpr := "cpu"
+	p.a.signalMap[queryExpr] = map[string]*aggregateSignal{}
+	cfg := &internalConfig{
+		historyResolution: time.Minute,
+		estimators:        []Estimator{NewHoltWintersEstimator(SeasonalityAdditive, 0, 0, 0, 0)},
+	}
+
+	// a daily seasonal signal rising linearly
+	samplesPerDay := 1440
+	nDays := 7
+	now := time.Now().Truncate(time.Minute)
+	ts := &common.TimeSeries{}
+	for i := 0; i < samplesPerDay*nDays; i++ {
+		ts.Samples = append(ts.Samples, common.Sample{
+			Timestamp: now.Add(time.Duration(i-samplesPerDay*nDays) * time.Minute).Unix(),
+			Value:     10.0 + float64(i)*0.001 + 5.0*math.Sin(2.0*math.Pi*float64(i)/float64(samplesPerDay)),
+		})
+	}
+	p.updateAggregateSignals(queryExpr, []*common.TimeSeries{ts}, nil, cfg)
+
+	signals, _ := p.a.GetSignals(queryExpr)
+	assert.Len(t, signals, 1)
+	for _, signal := range signals {
+		assert.Equal(t, ModelFamilySeasonalTrend, signal.family)
+		assert.Equal(t, []time.Duration{Day}, signal.periods)
+		samples := signal.predictedTimeSeries.Samples
+		assert.Equal(t, samplesPerDay*nDays, len(samples))
+		// the forecast keeps rising across the period boundaries instead of repeating the first period
+		for k := 1; k < nDays; k++ {
+			for _, i := range []int{0, samplesPerDay / 4, samplesPerDay / 2} {
+				assert.Greater(t, samples[k*samplesPerDay+i].Value, samples[(k-1)*samplesPerDay+i].Value+float64(samplesPerDay)*0.0005)
+			}
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/outlier"
 )
 
 func fillMissingData(ts *common.TimeSeries, config *internalConfig, unit time.Duration) error {
@@ -67,6 +69,49 @@ func deTrend() error {
 	return nil
 }
 
+// applyCalendar replaces the samples of the special dates in the calendar by the weighted average of themselves and the
+// samples at the same time of a normal day, one week before or after is preferred so that the weekly pattern is kept.
+// The samples of an excluded date are replaced completely.
+func applyCalendar(ts *common.TimeSeries, calendar *config.Calendar) {
+	if calendar == nil || len(ts.Samples) < 2 {
+		return
+	}
+
+	intervalSeconds := ts.Samples[1].Timestamp - ts.Samples[0].Timestamp
+	if intervalSeconds <= 0 {
+		return
+	}
+
+	weights := make([]float64, len(ts.Samples))
+	for i := range ts.Samples {
+		weights[i] = calendar.TrainingWeight(time.Unix(ts.Samples[i].Timestamp, 0))
+	}
+
+	original := make([]float64, len(ts.Samples))
+	for i := range ts.Samples {
+		original[i] = ts.Samples[i].Value
+	}
+
+	var offsets []int
+	for _, d := range []time.Duration{-Week, Week, -Day, Day} {
+		offsets = append(offsets, int(int64(d.Seconds())/intervalSeconds))
+	}
+
+	for i := range ts.Samples {
+		if weights[i] >= 1.0 {
+			continue
+		}
+		for _, offset := range offsets {
+			j := i + offset
+			if j < 0 || j >= len(ts.Samples) || weights[j] < 1.0 {
+				continue
+			}
+			ts.Samples[i].Value = weights[i]*original[i] + (1-weights[i])*original[j]
+			break
+		}
+	}
+}
+
 func removeExtremeOutliers(ts *common.TimeSeries) error {
 	values := make([]float64, len(ts.Samples))
 	for i := 0; i < len(ts.Samples); i++ {
@@ -92,19 +137,27 @@ func removeExtremeOutliers(ts *common.TimeSeries) error {
 	return nil
 }
 
-func preProcessTimeSeries(ts *common.TimeSeries, config *internalConfig, unit time.Duration) error {
+// preProcessTimeSeries returns the original samples of the outliers cleaned by the outlier detector of the config. The
+// configured outlier detector takes the place of the default removal of extreme outliers.
+func preProcessTimeSeries(ts *common.TimeSeries, config *internalConfig, unit time.Duration) ([]common.Sample, error) {
 	var err error
 
 	err = fillMissingData(ts, config, unit)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	_ = deTrend()
 
+	applyCalendar(ts, config.calendar)
+
+	if config.outlierDetector != nil {
+		return outlier.Clean(ts, config.outlierDetector), nil
+	}
+
 	_ = removeExtremeOutliers(ts)
 
-	return nil
+	return nil, nil
 }
 
 func preProcessTimeSeriesList(tsList []*common.TimeSeries, config *internalConfig) ([]*common.TimeSeries, error) {
@@ -116,9 +169,12 @@ func preProcessTimeSeriesList(tsList []*common.TimeSeries, config *internalConfi
 	for i := range tsList {
 		go func(ts *common.TimeSeries) {
 			defer wg.Done()
-			if err := preProcessTimeSeries(ts, config, Hour); err != nil {
+			if cleaned, err := preProcessTimeSeries(ts, config, Hour); err != nil {
 				klog.ErrorS(err, "Dsp failed to pre process time series.")
 			} else {
+				if len(cleaned) > 0 {
+					klog.V(4).InfoS("Outliers cleaned.", "labels", ts.Labels, "detector", config.outlierDetector, "outliers", len(cleaned))
+				}
 				tsCh <- ts
 			}
 		}(tsList[i])
@@ -8,6 +8,7 @@ import (
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
 	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -69,3 +70,32 @@ func TestRemoveExtremeOutliers2(t *testing.T) {
 	//fmt.Println("Open your browser and access 'http://localhost:7001'")
 	//http.ListenAndServe(":7001", nil)
 }
+
+func TestApplyCalendar(t *testing.T) {
+	calendar, err := config.ParseCalendar(`{"dates": [{"date": "2022-10-08", "exclude": true}, {"date": "2022-10-15", "trainingWeight": 0.5}]}`)
+	assert.NoError(t, err)
+
+	// three weeks of hourly samples from 2022-10-01, the value of a normal day is 1
+	start := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	ts := &common.TimeSeries{}
+	for i := 0; i < 24*21; i++ {
+		timestamp := start.Add(time.Duration(i) * time.Hour)
+		value := 1.0
+		if timestamp.Day() == 8 || timestamp.Day() == 15 {
+			value = 5.0
+		}
+		ts.Samples = append(ts.Samples, common.Sample{Timestamp: timestamp.Unix(), Value: value})
+	}
+
+	applyCalendar(ts, calendar)
+	for _, sample := range ts.Samples {
+		switch time.Unix(sample.Timestamp, 0).UTC().Day() {
+		case 8:
+			assert.Equal(t, 1.0, sample.Value)
+		case 15:
+			assert.Equal(t, 3.0, sample.Value)
+		default:
+			assert.Equal(t, 1.0, sample.Value)
+		}
+	}
+}
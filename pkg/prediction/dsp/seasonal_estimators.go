@@ -0,0 +1,148 @@
+package dsp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	defaultSeasonalBackfittingIterations = 2
+	defaultSeasonalMinValue              = 0.01
+)
+
+// NewMultiSeasonalEstimator returns an estimator which decomposes the signal into a level and one additive seasonal
+// component for each of the periods, and rebuilds the forecast from all of them. It's suitable for the signals with
+// both daily and weekly patterns, e.g. the workloads whose traffic drops at weekends. Every seasonal component needs
+// two seasons, and the model is validated on the last shortest season, so daily and weekly periods need a history of
+// 15 days at least, which is the default history length.
+func NewMultiSeasonalEstimator(periods []time.Duration, marginFraction float64) Estimator {
+	sorted := make([]time.Duration, len(periods))
+	copy(sorted, periods)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	return &multiSeasonalEstimator{
+		periods:        sorted,
+		marginFraction: marginFraction,
+	}
+}
+
+type multiSeasonalEstimator struct {
+	// periods are in ascending order
+	periods        []time.Duration
+	marginFraction float64
+}
+
+func (m *multiSeasonalEstimator) GetEstimation(signal *Signal, periodLength time.Duration) *Signal {
+	return m.GetHorizonEstimation(signal, periodLength, 1)
+}
+
+// GetHorizonEstimation forecasts nPeriods periods following the signal, the longer seasons go on across the periods.
+func (m *multiSeasonalEstimator) GetHorizonEstimation(signal *Signal, periodLength time.Duration, nPeriods int) *Signal {
+	n := len(signal.Samples)
+	h := nPeriods * int(periodLength.Seconds()*signal.SampleRate)
+	if len(m.periods) == 0 || h <= 0 {
+		return nil
+	}
+
+	lengths := make([]int, len(m.periods))
+	for i, p := range m.periods {
+		lengths[i] = int(p.Seconds() * signal.SampleRate)
+		// every seasonal component needs at least two seasons to be estimated
+		if lengths[i] <= 0 || n < 2*lengths[i] {
+			return nil
+		}
+	}
+
+	components := make([][]float64, len(m.periods))
+	for i := range components {
+		components[i] = make([]float64, lengths[i])
+	}
+
+	seasonal := func(i, except int) float64 {
+		sum := 0.
+		for c := range components {
+			if c != except {
+				sum += components[c][i%lengths[c]]
+			}
+		}
+		return sum
+	}
+
+	// level is the mean of the last longest season of the deseasonalized signal
+	level := func() float64 {
+		longest := lengths[len(lengths)-1]
+		sum := 0.
+		for i := n - longest; i < n; i++ {
+			sum += signal.Samples[i] - seasonal(i, -1)
+		}
+		return sum / float64(longest)
+	}
+
+	// backfitting: estimate each component from the signal without the level and the other components, the mean of
+	// the samples at the same phase is the value of the component, then center it.
+	l := level()
+	for iteration := 0; iteration < defaultSeasonalBackfittingIterations; iteration++ {
+		for c := range components {
+			sums := make([]float64, lengths[c])
+			counts := make([]int, lengths[c])
+			for i := 0; i < n; i++ {
+				sums[i%lengths[c]] += signal.Samples[i] - l - seasonal(i, c)
+				counts[i%lengths[c]]++
+			}
+			mean := 0.
+			for k := range sums {
+				sums[k] /= float64(counts[k])
+				mean += sums[k]
+			}
+			mean /= float64(lengths[c])
+			for k := range sums {
+				components[c][k] = sums[k] - mean
+			}
+		}
+		l = level()
+	}
+
+	samples := make([]float64, h)
+	for j := 0; j < h; j++ {
+		a := l + seasonal(n+j, -1)
+		if a <= 0.0 {
+			a = defaultSeasonalMinValue
+		}
+		samples[j] = a * (1.0 + m.marginFraction)
+	}
+
+	return &Signal{
+		SampleRate: signal.SampleRate,
+		Samples:    samples,
+	}
+}
+
+func (m *multiSeasonalEstimator) String() string {
+	return fmt.Sprintf("Multi Seasonal Estimator {periods: %v, marginFraction: %f}", m.periods, m.marginFraction)
+}
+
+// multiSeasonalEstimators returns the multi seasonal estimators of the periods, nil if there is only one period.
+func multiSeasonalEstimators(periods []time.Duration) []Estimator {
+	if len(periods) < 2 {
+		return nil
+	}
+	return []Estimator{
+		NewMultiSeasonalEstimator(periods, 0.01),
+		NewMultiSeasonalEstimator(periods, 0.10),
+	}
+}
+
+// modelPeriods returns the seasonal periods captured by the estimator.
+func modelPeriods(e Estimator, periodLength time.Duration) []time.Duration {
+	switch estimator := e.(type) {
+	case *multiSeasonalEstimator:
+		return estimator.periods
+	default:
+		if family := modelFamilyOf(e); (family == ModelFamilyPeriodic || family == ModelFamilySeasonalTrend) && periodLength > 0 {
+			return []time.Duration{periodLength}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package dsp
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction/accuracy"
+	"github.com/gocrane/crane/pkg/prediction/config"
+)
+
+// weeklyValue is a daily sine wave whose level drops at weekends.
+func weeklyValue(i int, samplesPerDay int) float64 {
+	day := (i / samplesPerDay) % 7
+	v := 10.0 + 5.0*math.Sin(2*math.Pi*float64(i%samplesPerDay)/float64(samplesPerDay))
+	if day >= 5 {
+		v -= 4.0
+	}
+	return v
+}
+
+func TestMultiSeasonalEstimator_GetEstimation(t *testing.T) {
+	sampleRate := 1.0 / 60.0
+	samplesPerDay := 1440
+	samplesPerWeek := samplesPerDay * 7
+	nWeeks := 3
+	rnd := rand.New(rand.NewSource(1))
+
+	history := &Signal{SampleRate: sampleRate}
+	for i := 0; i < samplesPerWeek*(nWeeks-1); i++ {
+		history.Samples = append(history.Samples, weeklyValue(i, samplesPerDay)+rnd.Float64()*0.1)
+	}
+	var actual []float64
+	for i := samplesPerWeek * (nWeeks - 1); i < samplesPerWeek*nWeeks; i++ {
+		actual = append(actual, weeklyValue(i, samplesPerDay))
+	}
+
+	e := NewMultiSeasonalEstimator([]time.Duration{Week, Day}, 0)
+	assert.Equal(t, []time.Duration{Day, Week}, modelPeriods(e, Week))
+
+	estimated := e.GetEstimation(history, Week)
+	assert.NotNil(t, estimated)
+	assert.Equal(t, samplesPerWeek, estimated.Num())
+
+	mape, err := accuracy.MAPE(actual, estimated.Samples)
+	assert.NoError(t, err)
+	assert.Less(t, mape, 0.05, e.String())
+
+	// every seasonal component needs at least two seasons
+	assert.Nil(t, e.GetEstimation(&Signal{SampleRate: sampleRate, Samples: history.Samples[:samplesPerWeek]}, Week))
+}
+
+func TestFindPeriods(t *testing.T) {
+	samplesPerDay := 1440
+	ts := &common.TimeSeries{}
+	for i := 0; i < samplesPerDay*7*3; i++ {
+		ts.Samples = append(ts.Samples, common.Sample{Timestamp: int64(i * 60), Value: weeklyValue(i, samplesPerDay)})
+	}
+	assert.Equal(t, []time.Duration{Day, Week}, findPeriods(ts, time.Minute))
+
+	cfg := defaultInternalConfig
+	e, _, nPeriods, periodLength := chooseModel("test", ts, &cfg)
+	assert.NotNil(t, e)
+	assert.Equal(t, ModelFamilyPeriodic, modelFamilyOf(e))
+	assert.Equal(t, 21, nPeriods)
+	assert.Equal(t, Day, periodLength)
+
+	assert.Nil(t, multiSeasonalEstimators([]time.Duration{Day}))
+	assert.Len(t, multiSeasonalEstimators([]time.Duration{Day, Week}), 2)
+}
+
+func TestChooseMultiSeasonalWithDefaultHistory(t *testing.T) {
+	samplesPerDay := 1440
+	rnd := rand.New(rand.NewSource(1))
+	// the default history of 15 days plus the extra hour queried
+	n := samplesPerDay*15 + 60
+	ts := &common.TimeSeries{}
+	for i := 0; i < n; i++ {
+		ts.Samples = append(ts.Samples, common.Sample{Timestamp: int64(i * 60), Value: weeklyValue(i, samplesPerDay) + rnd.Float64()*0.1})
+	}
+
+	cfg := defaultInternalConfig
+	e, _, nPeriods, periodLength := chooseModel("test", ts, &cfg)
+	assert.IsType(t, &multiSeasonalEstimator{}, e)
+	assert.Equal(t, 15, nPeriods)
+	assert.Equal(t, Day, periodLength)
+
+	// the forecast goes on with the weekly season instead of repeating one day
+	p := NewPrediction(nil, nil, config.AlgorithmModelConfig{}).(*periodicSignalPrediction)
+	p.a.signalMap["test"] = map[string]*aggregateSignal{}
+	p.updateAggregateSignals("test", []*common.TimeSeries{ts}, nil, &cfg)
+	signals, _ := p.a.GetSignals("test")
+	assert.Len(t, signals, 1)
+	for _, signal := range signals {
+		assert.Equal(t, []time.Duration{Day, Week}, signal.periods)
+		samples := signal.predictedTimeSeries.Samples
+		assert.Equal(t, samplesPerDay*nPeriods, len(samples))
+		for i, sample := range samples {
+			assert.InDelta(t, weeklyValue(n+i, samplesPerDay), sample.Value, 1.0)
+		}
+	}
+}
@@ -168,6 +168,40 @@ func (s *Signal) Filter(threshold float64) *Signal {
 }
 
 func (s *Signal) FindPeriod() time.Duration {
+	period, _ := s.FindPeriods()
+	return period
+}
+
+// FindPeriods returns the fundamental period, which is -1 if not found, and all the periods which are verified as peaks
+// of the auto correlation function in ascending order, so that a signal with several seasonal components (e.g. daily
+// and weekly) can be decomposed.
+func (s *Signal) FindPeriods() (time.Duration, []time.Duration) {
+	hints, cors := s.verifiedPeriodHints()
+
+	maxCorVal := 0.
+	j := -1
+	for i := range hints {
+		if maxCorVal < cors[i] {
+			j = i
+			maxCorVal = cors[i]
+		}
+	}
+	fundamental := time.Duration(-1)
+	if j >= 0 {
+		fundamental = time.Duration(float64(hints[j])/s.SampleRate) * time.Second
+	}
+
+	periods := make([]time.Duration, 0, len(hints))
+	// hints are in descending order
+	for i := len(hints) - 1; i >= 0; i-- {
+		periods = append(periods, time.Duration(float64(hints[i])/s.SampleRate)*time.Second)
+	}
+	return fundamental, periods
+}
+
+// verifiedPeriodHints returns the candidate periods (in number of samples) which are peaks of the auto correlation
+// function, and the values of the auto correlation function of them.
+func (s *Signal) verifiedPeriodHints() ([]int, []float64) {
 	x := make([]float64, len(s.Samples))
 	copy(x, s.Samples)
 	N := len(s.Samples)
@@ -176,9 +210,9 @@ func (s *Signal) FindPeriod() time.Duration {
 	// its maximum power argmax|X(f)|. Repeat above operation 100 times, and use the 99th
 	// largest power as the threshold.
 	var maxPowers []float64
-	rand.Seed(time.Now().UnixNano())
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 	for i := 0; i < 100; i++ {
-		rand.Shuffle(len(x), func(i, j int) {
+		rng.Shuffle(len(x), func(i, j int) {
 			x[i], x[j] = x[j], x[i]
 		})
 		X := fft.FFTReal(x)
@@ -211,22 +245,17 @@ func (s *Signal) FindPeriod() time.Duration {
 	// Use auto correlation function (ACF) to verify the candidate periods.
 	// The value of the fundamental period should be the 'highest peak' in the graph of ACF.
 	cor := AutoCorrelation(s.Samples)
-	maxCorVal := 0.
-	j := -1
 	maxR := int(MaxAutoCorrelationPeakSearchIntervalSeconds * s.SampleRate)
+	var verified []int
+	var cors []float64
 	for i := range hints {
 		r := min(maxR, hints[i]/2)
-		if isPeak(cor, hints[i], r) && maxCorVal < cor[hints[i]] {
-			j = i
-			maxCorVal = cor[hints[j]]
+		if isPeak(cor, hints[i], r) {
+			verified = append(verified, hints[i])
+			cors = append(cors, cor[hints[i]])
 		}
 	}
-
-	if j >= 0 {
-		return time.Duration(float64(hints[j])/s.SampleRate) * time.Second
-	} else {
-		return -1
-	}
+	return verified, cors
 }
 
 func (s *Signal) String() string {
@@ -0,0 +1,86 @@
+package external
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// AlgorithmTypeExternal is the algorithm type of the external predictor, the models are trained out of process by a
+// server which implements the Predictor service in pb/predictor.proto.
+const AlgorithmTypeExternal predictionapi.AlgorithmType = "external"
+
+const (
+	defaultHistoryLength  = time.Hour * 24 * 7
+	defaultSampleInterval = time.Minute
+	defaultHorizon        = time.Hour * 24
+	defaultTimeout        = time.Minute
+	// defaultRealtimeWindow is the window of the predicted time series whose max value is the realtime predicted value
+	defaultRealtimeWindow = time.Hour
+)
+
+type Config struct {
+	// Address is the address of the external predictor server
+	Address string
+	// Timeout is the timeout of each prediction request
+	Timeout time.Duration
+	// HistoryLength is the length of the history time series sent to the external predictor for training
+	HistoryLength time.Duration
+	// SampleInterval is the interval of the history and predicted samples
+	SampleInterval time.Duration
+	// Horizon is how far the external predictor predicts after each model update, it should be longer than the model
+	// update interval
+	Horizon time.Duration
+	// CAFile is the CA certificate to verify the external predictor server, the connection is insecure if empty
+	CAFile string
+	// CertFile and KeyFile are the client certificate and key for mutual TLS, optional
+	CertFile string
+	KeyFile  string
+}
+
+func (c *Config) withDefaults() Config {
+	r := *c
+	if r.Timeout <= 0 {
+		r.Timeout = defaultTimeout
+	}
+	if r.HistoryLength <= 0 {
+		r.HistoryLength = defaultHistoryLength
+	}
+	if r.SampleInterval <= 0 {
+		r.SampleInterval = defaultSampleInterval
+	}
+	if r.Horizon <= 0 {
+		r.Horizon = defaultHorizon
+	}
+	return r
+}
+
+// transportCredentials returns the TLS credentials if the CA file is set, otherwise the insecure credentials.
+func (c *Config) transportCredentials() (credentials.TransportCredentials, error) {
+	if c.CAFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+	ca, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+	}
+	tlsConfig := &tls.Config{RootCAs: pool}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
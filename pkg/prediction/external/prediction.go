@@ -0,0 +1,356 @@
+package external
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/external/pb"
+	"github.com/gocrane/crane/pkg/providers"
+)
+
+var _ prediction.Interface = &externalPrediction{}
+
+// query is a registered query expression and its latest predicted time series.
+type query struct {
+	namer      metricnaming.MetricNamer
+	callers    map[string]struct{}
+	parameters map[string]string
+	status     prediction.Status
+	// predictedTimeSeriesList is predicted by the external predictor at the last model update
+	predictedTimeSeriesList []*common.TimeSeries
+	lastUpdateTime          time.Time
+	stopCh                  chan struct{}
+}
+
+// externalPrediction sends the history time series to an external predictor over grpc, and caches the predicted time
+// series until the next model update.
+type externalPrediction struct {
+	prediction.GenericPrediction
+	config      Config
+	modelConfig config.AlgorithmModelConfig
+	// conn is shared by all the requests to the external predictor, it reconnects by itself
+	conn   *grpc.ClientConn
+	client pb.PredictorClient
+
+	mutex   sync.RWMutex
+	queries map[string] /*expr*/ *query
+}
+
+func NewPrediction(realtimeProvider providers.RealTime, historyProvider providers.History, mc config.AlgorithmModelConfig, c Config) (prediction.Interface, error) {
+	creds, err := c.transportCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the credentials of the external predictor: %v", err)
+	}
+	conn, err := grpc.Dial(c.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	withCh, delCh := make(chan prediction.QueryExprWithCaller), make(chan prediction.QueryExprWithCaller)
+	return &externalPrediction{
+		GenericPrediction: prediction.NewGenericPrediction(realtimeProvider, historyProvider, withCh, delCh),
+		config:            c.withDefaults(),
+		modelConfig:       mc,
+		conn:              conn,
+		client:            pb.NewPredictorClient(conn),
+		queries:           map[string]*query{},
+	}, nil
+}
+
+func (p *externalPrediction) Run(stopCh <-chan struct{}) {
+	go func() {
+		for {
+			qc := <-p.WithCh
+			if q := p.add(qc); q != nil {
+				klog.V(6).InfoS("Register a query expression for prediction.", "queryExpr", qc.MetricNamer.BuildUniqueKey(), "caller", qc.Caller)
+				go p.updateRoutine(q)
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			qc := <-p.DelCh
+			klog.V(4).InfoS("Unregister a query expression from prediction.", "queryExpr", qc.MetricNamer.BuildUniqueKey(), "caller", qc.Caller)
+			p.delete(qc)
+		}
+	}()
+
+	klog.Infof("predictor %v started", p.Name())
+
+	<-stopCh
+
+	p.mutex.Lock()
+	for queryExpr, q := range p.queries {
+		close(q.stopCh)
+		delete(p.queries, queryExpr)
+	}
+	p.mutex.Unlock()
+	_ = p.conn.Close()
+
+	klog.Infof("predictor %v stopped", p.Name())
+}
+
+// add registers the caller of the query expression, the parameters are updated by the latest caller. It returns the
+// query if it's newly added.
+func (p *externalPrediction) add(qc prediction.QueryExprWithCaller) *query {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	queryExpr := qc.MetricNamer.BuildUniqueKey()
+	q, exists := p.queries[queryExpr]
+	if !exists {
+		q = &query{
+			namer:   qc.MetricNamer,
+			callers: map[string]struct{}{},
+			status:  prediction.StatusNotStarted,
+			stopCh:  make(chan struct{}),
+		}
+		p.queries[queryExpr] = q
+	}
+	q.callers[qc.Caller] = struct{}{}
+	q.parameters = qc.Config.Parameters
+	if exists {
+		return nil
+	}
+	return q
+}
+
+func (p *externalPrediction) delete(qc prediction.QueryExprWithCaller) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	queryExpr := qc.MetricNamer.BuildUniqueKey()
+	q, exists := p.queries[queryExpr]
+	if !exists {
+		return
+	}
+	delete(q.callers, qc.Caller)
+	if len(q.callers) > 0 {
+		return
+	}
+	close(q.stopCh)
+	delete(p.queries, queryExpr)
+}
+
+func (p *externalPrediction) getQuery(queryExpr string) *query {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.queries[queryExpr]
+}
+
+// updateRoutine updates the predicted time series of the query at each model update interval until it's deleted.
+func (p *externalPrediction) updateRoutine(q *query) {
+	queryExpr := q.namer.BuildUniqueKey()
+	interval := p.modelConfig.UpdateInterval
+	if interval <= 0 || interval > p.config.Horizon {
+		interval = p.config.Horizon
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.updateQuery(q)
+		select {
+		case <-q.stopCh:
+			klog.V(4).InfoS("Prediction routine stopped.", "queryExpr", queryExpr)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *externalPrediction) updateQuery(q *query) {
+	queryExpr := q.namer.BuildUniqueKey()
+
+	p.mutex.RLock()
+	parameters := q.parameters
+	p.mutex.RUnlock()
+
+	now := time.Now().Truncate(p.config.SampleInterval)
+	tsList, err := p.predict(q.namer, parameters, now, now.Add(p.config.Horizon))
+	if err != nil {
+		klog.ErrorS(err, "Failed to predict by the external predictor.", "queryExpr", queryExpr)
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	q.predictedTimeSeriesList = tsList
+	q.lastUpdateTime = now
+	q.status = prediction.StatusReady
+	klog.V(4).InfoS("Predicted time series updated.", "queryExpr", queryExpr, "timeSeries", len(tsList))
+}
+
+// predict sends the history time series to the external predictor, and returns the predicted time series in
+// [start, end).
+func (p *externalPrediction) predict(namer metricnaming.MetricNamer, parameters map[string]string, start, end time.Time) ([]*common.TimeSeries, error) {
+	historyProvider := p.GetHistoryProvider()
+	if historyProvider == nil {
+		return nil, fmt.Errorf("history provider not provisioned")
+	}
+	history, err := historyProvider.QueryTimeSeries(namer, start.Add(-p.config.HistoryLength), start, p.config.SampleInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &pb.PredictRequest{
+		Key:        namer.BuildUniqueKey(),
+		History:    grpcTimeSeriesList(history),
+		StartTime:  start.Unix(),
+		EndTime:    end.Unix(),
+		Step:       int64(p.config.SampleInterval / time.Second),
+		Parameters: parameters,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+	resp, err := p.client.Predict(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return commonTimeSeriesList(resp.TimeSeriesList), nil
+}
+
+func (p *externalPrediction) QueryPredictionStatus(_ context.Context, metricNamer metricnaming.MetricNamer) (prediction.Status, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	q, exists := p.queries[metricNamer.BuildUniqueKey()]
+	if !exists {
+		return prediction.StatusUnknown, nil
+	}
+	return q.status, nil
+}
+
+func (p *externalPrediction) QueryPredictedTimeSeries(_ context.Context, namer metricnaming.MetricNamer, startTime time.Time, endTime time.Time) ([]*common.TimeSeries, error) {
+	queryExpr := namer.BuildUniqueKey()
+	q := p.getQuery(queryExpr)
+	if q == nil {
+		return nil, fmt.Errorf("metric %v is not registered", queryExpr)
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if q.status != prediction.StatusReady {
+		return nil, fmt.Errorf("metric %v model status is %v, must be ready", queryExpr, q.status)
+	}
+	return timeSeriesInWindow(q.predictedTimeSeriesList, startTime, endTime), nil
+}
+
+// QueryRealtimePredictedValues returns the max predicted value of each time series in the next realtime window.
+func (p *externalPrediction) QueryRealtimePredictedValues(ctx context.Context, namer metricnaming.MetricNamer) ([]*common.TimeSeries, error) {
+	now := time.Now()
+	tsList, err := p.QueryPredictedTimeSeries(ctx, namer, now.Truncate(p.config.SampleInterval), now.Add(defaultRealtimeWindow))
+	if err != nil {
+		return nil, err
+	}
+	return maxValues(tsList, now), nil
+}
+
+// QueryRealtimePredictedValuesOnce is a one-off task, the external predictor is requested directly and nothing is
+// cached.
+func (p *externalPrediction) QueryRealtimePredictedValuesOnce(_ context.Context, namer metricnaming.MetricNamer, config config.Config) ([]*common.TimeSeries, error) {
+	now := time.Now()
+	start := now.Truncate(p.config.SampleInterval)
+	tsList, err := p.predict(namer, config.Parameters, start, now.Add(defaultRealtimeWindow))
+	if err != nil {
+		return nil, err
+	}
+	return maxValues(timeSeriesInWindow(tsList, start, now.Add(defaultRealtimeWindow)), now), nil
+}
+
+func (p *externalPrediction) Name() string {
+	return "External"
+}
+
+// timeSeriesInWindow returns the samples of the time series in [start, end).
+func timeSeriesInWindow(tsList []*common.TimeSeries, start, end time.Time) []*common.TimeSeries {
+	var result []*common.TimeSeries
+	for _, ts := range tsList {
+		var samples []common.Sample
+		for _, s := range ts.Samples {
+			if s.Timestamp >= start.Unix() && s.Timestamp < end.Unix() {
+				samples = append(samples, s)
+			}
+		}
+		result = append(result, &common.TimeSeries{
+			Labels:  ts.Labels,
+			Samples: samples,
+		})
+	}
+	return result
+}
+
+func maxValues(tsList []*common.TimeSeries, now time.Time) []*common.TimeSeries {
+	var result []*common.TimeSeries
+	for _, ts := range tsList {
+		if len(ts.Samples) < 1 {
+			continue
+		}
+		maxValue := ts.Samples[0].Value
+		for i := 1; i < len(ts.Samples); i++ {
+			if maxValue < ts.Samples[i].Value {
+				maxValue = ts.Samples[i].Value
+			}
+		}
+		result = append(result, &common.TimeSeries{
+			Labels:  ts.Labels,
+			Samples: []common.Sample{{Value: maxValue, Timestamp: now.Unix()}},
+		})
+	}
+	return result
+}
+
+func grpcTimeSeriesList(tsList []*common.TimeSeries) []*pb.TimeSeries {
+	res := make([]*pb.TimeSeries, len(tsList))
+	for i := range tsList {
+		res[i] = &pb.TimeSeries{
+			Labels:  make([]*pb.Label, len(tsList[i].Labels)),
+			Samples: make([]*pb.Sample, len(tsList[i].Samples)),
+		}
+		for j := range tsList[i].Labels {
+			res[i].Labels[j] = &pb.Label{
+				Name:  tsList[i].Labels[j].Name,
+				Value: tsList[i].Labels[j].Value,
+			}
+		}
+		for j := range tsList[i].Samples {
+			res[i].Samples[j] = &pb.Sample{
+				Timestamp: tsList[i].Samples[j].Timestamp,
+				Value:     tsList[i].Samples[j].Value,
+			}
+		}
+	}
+	return res
+}
+
+func commonTimeSeriesList(tsList []*pb.TimeSeries) []*common.TimeSeries {
+	res := make([]*common.TimeSeries, len(tsList))
+	for i := range tsList {
+		res[i] = &common.TimeSeries{
+			Labels:  make([]common.Label, len(tsList[i].Labels)),
+			Samples: make([]common.Sample, len(tsList[i].Samples)),
+		}
+		for j := range tsList[i].Labels {
+			res[i].Labels[j] = common.Label{
+				Name:  tsList[i].Labels[j].Name,
+				Value: tsList[i].Labels[j].Value,
+			}
+		}
+		for j := range tsList[i].Samples {
+			res[i].Samples[j] = common.Sample{
+				Timestamp: tsList[i].Samples[j].Timestamp,
+				Value:     tsList[i].Samples[j].Value,
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,137 @@
+package external
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/metricquery"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/external/pb"
+)
+
+type fakeHistory struct {
+	tsList []*common.TimeSeries
+}
+
+func (f *fakeHistory) QueryTimeSeries(_ metricnaming.MetricNamer, _ time.Time, _ time.Time, _ time.Duration) ([]*common.TimeSeries, error) {
+	return f.tsList, nil
+}
+
+// stubPredictor predicts the last history value plus the offset parameter.
+type stubPredictor struct {
+	pb.UnimplementedPredictorServer
+	mutex    sync.Mutex
+	requests []*pb.PredictRequest
+}
+
+func (s *stubPredictor) Predict(_ context.Context, req *pb.PredictRequest) (*pb.PredictResponse, error) {
+	s.mutex.Lock()
+	s.requests = append(s.requests, req)
+	s.mutex.Unlock()
+
+	offset, _ := strconv.ParseFloat(req.Parameters["offset"], 64)
+	resp := &pb.PredictResponse{}
+	for _, ts := range req.History {
+		last := ts.Samples[len(ts.Samples)-1].Value
+		predicted := &pb.TimeSeries{Labels: ts.Labels}
+		for t := req.StartTime; t < req.EndTime; t += req.Step {
+			predicted.Samples = append(predicted.Samples, &pb.Sample{Timestamp: t, Value: last + offset})
+		}
+		resp.TimeSeriesList = append(resp.TimeSeriesList, predicted)
+	}
+	return resp, nil
+}
+
+func TestExternalPrediction(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+	stub := &stubPredictor{}
+	pb.RegisterPredictorServer(server, stub)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	now := time.Now().Truncate(time.Minute)
+	history := &fakeHistory{tsList: []*common.TimeSeries{{
+		Labels:  []common.Label{{Name: "pod", Value: "a"}},
+		Samples: []common.Sample{{Timestamp: now.Add(-time.Minute).Unix(), Value: 1}, {Timestamp: now.Unix(), Value: 2}},
+	}}}
+	p, err := NewPrediction(nil, history, config.AlgorithmModelConfig{UpdateInterval: time.Hour}, Config{
+		Address: listener.Addr().String(),
+		Horizon: time.Hour * 2,
+	})
+	assert.NoError(t, err)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go p.Run(stopCh)
+
+	namer := &metricnaming.GeneralMetricNamer{
+		CallerName: "test",
+		Metric: &metricquery.Metric{
+			Type:       metricquery.PromQLMetricType,
+			MetricName: "cpu",
+			Prom:       &metricquery.PromNamerInfo{QueryExpr: "cpu"},
+		},
+	}
+	ctx := context.Background()
+	status, _ := p.QueryPredictionStatus(ctx, namer)
+	assert.Equal(t, prediction.StatusUnknown, status)
+
+	assert.NoError(t, p.WithQuery(namer, "test", config.Config{Parameters: map[string]string{"offset": "1"}}))
+	assert.Eventually(t, func() bool {
+		status, _ := p.QueryPredictionStatus(ctx, namer)
+		return status == prediction.StatusReady
+	}, 10*time.Second, 100*time.Millisecond)
+
+	tsList, err := p.QueryPredictedTimeSeries(ctx, namer, now.Add(time.Minute), now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, tsList, 1)
+	assert.Equal(t, history.tsList[0].Labels, tsList[0].Labels)
+	assert.Len(t, tsList[0].Samples, 59)
+	assert.Equal(t, 3.0, tsList[0].Samples[0].Value)
+
+	realtime, err := p.QueryRealtimePredictedValues(ctx, namer)
+	assert.NoError(t, err)
+	assert.Len(t, realtime, 1)
+	assert.Equal(t, 3.0, realtime[0].Samples[0].Value)
+
+	stub.mutex.Lock()
+	assert.Equal(t, namer.BuildUniqueKey(), stub.requests[0].Key)
+	assert.Equal(t, int64(60), stub.requests[0].Step)
+	stub.mutex.Unlock()
+
+	assert.NoError(t, p.DeleteQuery(namer, "test"))
+	assert.Eventually(t, func() bool {
+		status, _ := p.QueryPredictionStatus(ctx, namer)
+		return status == prediction.StatusUnknown
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestTransportCredentials(t *testing.T) {
+	creds, err := (&Config{}).transportCredentials()
+	assert.NoError(t, err)
+	assert.Equal(t, "insecure", creds.Info().SecurityProtocol)
+
+	_, err = (&Config{CAFile: filepath.Join(t.TempDir(), "missing.crt")}).transportCredentials()
+	assert.Error(t, err)
+
+	invalid := filepath.Join(t.TempDir(), "invalid.crt")
+	assert.NoError(t, os.WriteFile(invalid, []byte("not a certificate"), 0644))
+	_, err = (&Config{CAFile: invalid}).transportCredentials()
+	assert.Error(t, err)
+}
@@ -25,7 +25,8 @@ type Interface interface {
 	// QueryRealtimePredictedValues returns predicted values based on the specified query expression
 	QueryRealtimePredictedValues(ctx context.Context, metricNamer metricnaming.MetricNamer) ([]*common.TimeSeries, error)
 
-	// QueryPredictedTimeSeries returns predicted time series based on the specified query expression
+	// QueryPredictedTimeSeries returns predicted time series based on the specified query expression, the upper and
+	// lower bands of the prediction may be returned as extra time series labeled by BandLabelName
 	QueryPredictedTimeSeries(ctx context.Context, metricNamer metricnaming.MetricNamer, startTime time.Time, endTime time.Time) ([]*common.TimeSeries, error)
 
 	// A analysis task function
@@ -33,3 +34,21 @@ type Interface interface {
 
 	Name() string
 }
+
+// ModelDescriber is optionally implemented by the predictors which train different kinds of models for the time series
+// of a query expression.
+type ModelDescriber interface {
+	// DescribeModels returns the models trained for the time series selected by the metricNamer.
+	DescribeModels(metricNamer metricnaming.MetricNamer) []ModelDescription
+}
+
+// ModelDescription describes the model trained for one time series.
+type ModelDescription struct {
+	Labels []common.Label
+	// Family is the model family, such as periodic, seasonal-trend, trend or arima
+	Family string
+	// Periods are the seasonal periods of the model in ascending order, empty if the model is aperiodic
+	Periods []time.Duration
+	// Estimator describes the estimator of the model
+	Estimator string
+}
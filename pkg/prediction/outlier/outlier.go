@@ -0,0 +1,282 @@
+package outlier
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction/config"
+)
+
+const (
+	defaultMADThreshold = 3.5
+	defaultIQRThreshold = 1.5
+	defaultSTLPeriod    = time.Hour * 24
+	// madScale makes the median absolute deviation a consistent estimator of the standard deviation
+	madScale = 1.4826
+)
+
+// Detector detects the outliers of a time series, it is shared by the predictors to clean the history before training.
+type Detector interface {
+	// Detect returns the indexes of the outliers of the samples in ascending order, the samples are in chronological
+	// order.
+	Detect(samples []common.Sample) []int
+	String() string
+}
+
+type detectorFactory func(threshold float64, period time.Duration) Detector
+
+var detectorFactories = map[config.OutlierMethod]detectorFactory{
+	config.OutlierMethodMAD: func(threshold float64, _ time.Duration) Detector {
+		return &madDetector{threshold: threshold}
+	},
+	config.OutlierMethodIQR: func(threshold float64, _ time.Duration) Detector {
+		return &iqrDetector{threshold: threshold}
+	},
+	config.OutlierMethodSTL: func(threshold float64, period time.Duration) Detector {
+		return &stlDetector{threshold: threshold, period: period}
+	},
+}
+
+// NewDetector returns the detector of the outlier removal, nil if the outlier removal is nil.
+func NewDetector(o *config.OutlierRemoval) (Detector, error) {
+	if o == nil {
+		return nil, nil
+	}
+	factory, ok := detectorFactories[o.Method]
+	if !ok {
+		return nil, fmt.Errorf("unknown outlier method %q", o.Method)
+	}
+	return factory(o.Threshold, o.SeasonalPeriod()), nil
+}
+
+// Clean replaces the outliers of the time series by the linear interpolation of their nearest normal neighbours, and
+// returns the original samples which are replaced.
+func Clean(ts *common.TimeSeries, d Detector) []common.Sample {
+	if d == nil || ts == nil || len(ts.Samples) < 3 {
+		return nil
+	}
+
+	indexes := d.Detect(ts.Samples)
+	if len(indexes) == 0 || len(indexes) == len(ts.Samples) {
+		return nil
+	}
+
+	isOutlier := make([]bool, len(ts.Samples))
+	for _, i := range indexes {
+		isOutlier[i] = true
+	}
+
+	cleaned := make([]common.Sample, 0, len(indexes))
+	prev := -1
+	for i := 0; i < len(ts.Samples); i++ {
+		if !isOutlier[i] {
+			prev = i
+			continue
+		}
+		next := i + 1
+		for next < len(ts.Samples) && isOutlier[next] {
+			next++
+		}
+
+		cleaned = append(cleaned, ts.Samples[i])
+		switch {
+		case prev < 0:
+			ts.Samples[i].Value = ts.Samples[next].Value
+		case next >= len(ts.Samples):
+			ts.Samples[i].Value = ts.Samples[prev].Value
+		default:
+			p, n := ts.Samples[prev], ts.Samples[next]
+			ratio := float64(ts.Samples[i].Timestamp-p.Timestamp) / float64(n.Timestamp-p.Timestamp)
+			ts.Samples[i].Value = p.Value + (n.Value-p.Value)*ratio
+		}
+	}
+	return cleaned
+}
+
+// madDetector detects the samples whose robust z-score is higher than the threshold.
+type madDetector struct {
+	threshold float64
+}
+
+func (m *madDetector) Detect(samples []common.Sample) []int {
+	values := make([]float64, len(samples))
+	for i := range samples {
+		values[i] = samples[i].Value
+	}
+	return robustZScoreOutliers(values, thresholdOrDefault(m.threshold, defaultMADThreshold))
+}
+
+func (m *madDetector) String() string {
+	return fmt.Sprintf("MAD Detector {threshold: %f}", thresholdOrDefault(m.threshold, defaultMADThreshold))
+}
+
+// iqrDetector detects the samples out of [Q1 - threshold * IQR, Q3 + threshold * IQR].
+type iqrDetector struct {
+	threshold float64
+}
+
+func (d *iqrDetector) Detect(samples []common.Sample) []int {
+	values := make([]float64, len(samples))
+	for i := range samples {
+		values[i] = samples[i].Value
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	q1, q3 := quantile(sorted, 0.25), quantile(sorted, 0.75)
+	iqr := q3 - q1
+	// a time series without spread has no outliers, otherwise every burst of a mostly idle workload is removed
+	if iqr <= 0 {
+		return nil
+	}
+	threshold := thresholdOrDefault(d.threshold, defaultIQRThreshold)
+	low, high := q1-threshold*iqr, q3+threshold*iqr
+
+	var indexes []int
+	for i, v := range values {
+		if v < low || v > high {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func (d *iqrDetector) String() string {
+	return fmt.Sprintf("IQR Detector {threshold: %f}", thresholdOrDefault(d.threshold, defaultIQRThreshold))
+}
+
+// stlDetector decomposes the samples into a trend by centered moving average, a seasonal component by the median of
+// the detrended samples at the same phase of the period, and the residual, then detects the samples whose residual has
+// a robust z-score higher than the threshold. The samples are assumed evenly spaced, the detector works as mad if there
+// are less than two periods of samples.
+type stlDetector struct {
+	threshold float64
+	period    time.Duration
+}
+
+func (s *stlDetector) Detect(samples []common.Sample) []int {
+	n := len(samples)
+	threshold := thresholdOrDefault(s.threshold, defaultMADThreshold)
+	period := s.period
+	if period <= 0 {
+		period = defaultSTLPeriod
+	}
+
+	values := make([]float64, n)
+	for i := range samples {
+		values[i] = samples[i].Value
+	}
+
+	var samplesPerPeriod int
+	if n > 1 {
+		if interval := samples[1].Timestamp - samples[0].Timestamp; interval > 0 {
+			samplesPerPeriod = int(int64(period.Seconds()) / interval)
+		}
+	}
+	if samplesPerPeriod < 2 || n < 2*samplesPerPeriod {
+		return robustZScoreOutliers(values, threshold)
+	}
+
+	trend := movingAverage(values, samplesPerPeriod)
+
+	phases := make([][]float64, samplesPerPeriod)
+	for i := range values {
+		phases[i%samplesPerPeriod] = append(phases[i%samplesPerPeriod], values[i]-trend[i])
+	}
+	seasonal := make([]float64, samplesPerPeriod)
+	mean := 0.
+	for k := range phases {
+		sort.Float64s(phases[k])
+		seasonal[k] = quantile(phases[k], 0.5)
+		mean += seasonal[k]
+	}
+	mean /= float64(samplesPerPeriod)
+
+	residuals := make([]float64, n)
+	for i := range values {
+		residuals[i] = values[i] - trend[i] - (seasonal[i%samplesPerPeriod] - mean)
+	}
+	return robustZScoreOutliers(residuals, threshold)
+}
+
+func (s *stlDetector) String() string {
+	period := s.period
+	if period <= 0 {
+		period = defaultSTLPeriod
+	}
+	return fmt.Sprintf("STL Detector {threshold: %f, period: %v}", thresholdOrDefault(s.threshold, defaultMADThreshold), period)
+}
+
+// robustZScoreOutliers returns the indexes of the values whose distance to the median is larger than threshold times
+// the scaled median absolute deviation.
+func robustZScoreOutliers(values []float64, threshold float64) []int {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	median := quantile(sorted, 0.5)
+
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	sort.Float64s(deviations)
+	mad := quantile(deviations, 0.5) * madScale
+	// a time series without spread has no outliers, otherwise every burst of a mostly idle workload is removed
+	if mad <= 0 {
+		return nil
+	}
+
+	var indexes []int
+	for i, v := range values {
+		if math.Abs(v-median)/mad > threshold {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// movingAverage returns the centered moving average of the window, the window shrinks at both ends.
+func movingAverage(values []float64, window int) []float64 {
+	n := len(values)
+	prefix := make([]float64, n+1)
+	for i, v := range values {
+		prefix[i+1] = prefix[i] + v
+	}
+	result := make([]float64, n)
+	for i := range values {
+		begin, end := i-window/2, i+(window+1)/2
+		if begin < 0 {
+			begin = 0
+		}
+		if end > n {
+			end = n
+		}
+		result[i] = (prefix[end] - prefix[begin]) / float64(end-begin)
+	}
+	return result
+}
+
+// quantile returns the q quantile of the sorted values by linear interpolation.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+func thresholdOrDefault(threshold, defaultThreshold float64) float64 {
+	if threshold <= 0 {
+		return defaultThreshold
+	}
+	return threshold
+}
@@ -0,0 +1,85 @@
+package outlier
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction/config"
+)
+
+func TestDetectors(t *testing.T) {
+	samplesPerDay := 1440
+	rnd := rand.New(rand.NewSource(1))
+	newTimeSeries := func() *common.TimeSeries {
+		ts := &common.TimeSeries{}
+		for i := 0; i < samplesPerDay*3; i++ {
+			value := 10.0 + 5.0*math.Sin(2*math.Pi*float64(i)/float64(samplesPerDay)) + rnd.Float64()*0.5
+			ts.Samples = append(ts.Samples, common.Sample{Timestamp: int64(i * 60), Value: value})
+		}
+		// a load test at the trough of the second day, it is within the range of the daily peaks
+		for i := samplesPerDay + samplesPerDay*3/4; i < samplesPerDay+samplesPerDay*3/4+10; i++ {
+			ts.Samples[i].Value = 14.0
+		}
+		// an incident far beyond the normal range
+		ts.Samples[100].Value = 100.0
+		return ts
+	}
+
+	for _, tc := range []struct {
+		method           config.OutlierMethod
+		expectedLoadTest bool
+	}{
+		{method: config.OutlierMethodMAD},
+		{method: config.OutlierMethodIQR},
+		{method: config.OutlierMethodSTL, expectedLoadTest: true},
+	} {
+		d, err := NewDetector(&config.OutlierRemoval{Method: tc.method})
+		assert.NoError(t, err)
+
+		ts := newTimeSeries()
+		cleaned := Clean(ts, d)
+		assert.NotEmpty(t, cleaned, d.String())
+		assert.Less(t, ts.Samples[100].Value, 20.0, d.String())
+
+		loadTest := samplesPerDay + samplesPerDay*3/4 + 5
+		assert.Equal(t, tc.expectedLoadTest, ts.Samples[loadTest].Value < 10.0, d.String())
+		// the daily peaks are kept
+		assert.Greater(t, ts.Samples[samplesPerDay/4].Value, 14.0, d.String())
+	}
+}
+
+func TestClean(t *testing.T) {
+	ts := &common.TimeSeries{}
+	for i := 0; i < 100; i++ {
+		ts.Samples = append(ts.Samples, common.Sample{Timestamp: int64(i * 60), Value: float64(i % 10)})
+	}
+	ts.Samples[0].Value = 1000
+	ts.Samples[50].Value = 1000
+	ts.Samples[51].Value = 1000
+	ts.Samples[99].Value = 1000
+
+	cleaned := Clean(ts, &madDetector{})
+	assert.Len(t, cleaned, 4)
+	assert.Equal(t, common.Sample{Timestamp: 50 * 60, Value: 1000}, cleaned[1])
+	assert.Equal(t, 1.0, ts.Samples[0].Value)
+	assert.InDelta(t, 9.0+(2.0-9.0)/3, ts.Samples[50].Value, 1e-9)
+	assert.InDelta(t, 9.0+(2.0-9.0)*2/3, ts.Samples[51].Value, 1e-9)
+	assert.Equal(t, 8.0, ts.Samples[99].Value)
+
+	// a mostly idle time series has no outliers
+	idle := &common.TimeSeries{}
+	for i := 0; i < 100; i++ {
+		idle.Samples = append(idle.Samples, common.Sample{Timestamp: int64(i * 60)})
+	}
+	idle.Samples[10].Value = 5
+	assert.Empty(t, Clean(idle, &madDetector{}))
+	assert.Empty(t, Clean(idle, &iqrDetector{}))
+	assert.Empty(t, Clean(idle, &stlDetector{period: time.Hour}))
+
+	assert.Nil(t, Clean(ts, nil))
+}
@@ -37,7 +37,7 @@ func (a *aggregateSignals) Add(qc prediction.QueryExprWithCaller) bool {
 
 	QueryExpr := qc.MetricNamer.BuildUniqueKey()
 	if qc.Config.Percentile != nil {
-		cfg, err := makeInternalConfig(qc.Config.Percentile, qc.Config.InitMode)
+		cfg, err := makeInternalConfig(qc.Config.Percentile, qc.Config.InitMode, qc.Config.OutlierRemoval)
 		if err != nil {
 			klog.ErrorS(err, "Failed to make internal config.", "queryExpr", QueryExpr)
 		} else {
@@ -88,6 +88,18 @@ func (a *aggregateSignals) Delete(qc prediction.QueryExprWithCaller) bool /*need
 	return true
 }
 
+// GetQueryExprs returns all the registered query expressions
+func (a *aggregateSignals) GetQueryExprs() []string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	queryExprs := make([]string, 0, len(a.signalMap))
+	for queryExpr := range a.signalMap {
+		queryExprs = append(queryExprs, queryExpr)
+	}
+	return queryExprs
+}
+
 func (a *aggregateSignals) GetConfig(queryExpr string) *internalConfig {
 	a.mutex.RLock()
 	defer a.mutex.RUnlock()
@@ -0,0 +1,213 @@
+package percentile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	vpatypes "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/metricnaming"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
+	"github.com/gocrane/crane/pkg/prediction/config"
+)
+
+const defaultCheckpointTimeout = 30 * time.Second
+
+type signalCheckpoint struct {
+	Labels            []common.Label                `json:"labels,omitempty"`
+	Histogram         *vpatypes.HistogramCheckpoint `json:"histogram"`
+	FirstSampleTime   time.Time                     `json:"firstSampleTime"`
+	LastSampleTime    time.Time                     `json:"lastSampleTime"`
+	TotalSamplesCount int                           `json:"totalSamplesCount"`
+}
+
+type modelCheckpoint struct {
+	Signals map[string]*signalCheckpoint `json:"signals"`
+}
+
+func (a *aggregateSignal) saveToCheckpoint() (*signalCheckpoint, error) {
+	h, err := a.histogram.SaveToChekpoint()
+	if err != nil {
+		return nil, err
+	}
+	return &signalCheckpoint{
+		Labels:            a.labels,
+		Histogram:         h,
+		FirstSampleTime:   a.firstSampleTime,
+		LastSampleTime:    a.lastSampleTime,
+		TotalSamplesCount: a.totalSamplesCount,
+	}, nil
+}
+
+func (a *aggregateSignal) loadFromCheckpoint(cp *signalCheckpoint) error {
+	if err := a.histogram.LoadFromCheckpoint(cp.Histogram); err != nil {
+		return err
+	}
+	a.labels = cp.Labels
+	a.firstSampleTime = cp.FirstSampleTime
+	a.lastSampleTime = cp.LastSampleTime
+	a.totalSamplesCount = cp.TotalSamplesCount
+	return nil
+}
+
+// saveCheckpoint serializes all the signals of the query expression to the checkpoint store.
+func (p *percentilePrediction) saveCheckpoint(ctx context.Context, queryExpr string) error {
+	signals, status := p.a.GetSignals(queryExpr)
+	if status != prediction.StatusReady && status != prediction.StatusInitializing {
+		return nil
+	}
+
+	m := modelCheckpoint{Signals: map[string]*signalCheckpoint{}}
+	for key, signal := range signals {
+		cp, err := signal.saveToCheckpoint()
+		if err != nil {
+			return err
+		}
+		m.Signals[key] = cp
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return p.modelConfig.CheckpointStore.Save(ctx, &checkpoint.Checkpoint{
+		Algorithm: p.Name(),
+		Key:       queryExpr,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+}
+
+// checkpointAll saves the checkpoints of all the models whose init mode is checkpoint.
+func (p *percentilePrediction) checkpointAll() {
+	for _, queryExpr := range p.a.GetQueryExprs() {
+		if p.a.GetConfig(queryExpr).initMode != config.ModelInitModeCheckpoint {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), defaultCheckpointTimeout)
+		if err := p.saveCheckpoint(ctx, queryExpr); err != nil {
+			klog.ErrorS(err, "Failed to save checkpoint.", "queryExpr", queryExpr)
+		} else {
+			klog.V(6).InfoS("Checkpoint saved.", "queryExpr", queryExpr)
+		}
+		cancel()
+	}
+}
+
+func (p *percentilePrediction) deleteCheckpoint(queryExpr string) {
+	if p.modelConfig.CheckpointStore == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCheckpointTimeout)
+	defer cancel()
+	if err := p.modelConfig.CheckpointStore.Delete(ctx, p.Name(), queryExpr); err != nil {
+		klog.ErrorS(err, "Failed to delete checkpoint.", "queryExpr", queryExpr)
+	}
+}
+
+// restoreFromCheckpoint loads the signals of the query expression from the checkpoint store, a checkpoint older than
+// the history length is regarded as stale since none of its samples are in the window any more.
+func (p *percentilePrediction) restoreFromCheckpoint(queryExpr string, cfg *internalConfig) (map[string]*aggregateSignal, time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCheckpointTimeout)
+	defer cancel()
+
+	cp, err := p.modelConfig.CheckpointStore.Load(ctx, p.Name(), queryExpr)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if time.Since(cp.Timestamp) > cfg.historyLength {
+		return nil, time.Time{}, fmt.Errorf("checkpoint is stale, taken at %v", cp.Timestamp)
+	}
+
+	m := modelCheckpoint{}
+	if err = json.Unmarshal(cp.Data, &m); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	signals := map[string]*aggregateSignal{}
+	for key, sc := range m.Signals {
+		signal := newAggregateSignal(cfg)
+		if err = signal.loadFromCheckpoint(sc); err != nil {
+			return nil, time.Time{}, err
+		}
+		signals[key] = signal
+	}
+	return signals, cp.Timestamp, nil
+}
+
+// initByCheckPoint restores the model from the latest checkpoint and tops it up with the samples since the checkpoint,
+// so only a small window is queried from the history provider. It falls back to init from history if there is no
+// usable checkpoint.
+func (p *percentilePrediction) initByCheckPoint(namer metricnaming.MetricNamer) error {
+	queryExpr := namer.BuildUniqueKey()
+	if p.modelConfig.CheckpointStore == nil {
+		klog.V(4).InfoS("Checkpoint store is not configured, init from history.", "queryExpr", queryExpr)
+		return p.initFromHistory(namer)
+	}
+
+	cfg := p.a.GetConfig(queryExpr)
+	signals, checkpointTime, err := p.restoreFromCheckpoint(queryExpr, cfg)
+	if err != nil {
+		if err == checkpoint.ErrNotFound {
+			klog.V(4).InfoS("Checkpoint not found, init from history.", "queryExpr", queryExpr)
+		} else {
+			klog.ErrorS(err, "Failed to restore from checkpoint, init from history.", "queryExpr", queryExpr)
+		}
+		return p.initFromHistory(namer)
+	}
+
+	end := time.Now().Truncate(time.Minute)
+	start := checkpointTime.Truncate(cfg.sampleInterval)
+	if start.Before(end) && p.GetHistoryProvider() != nil {
+		historyTimeSeriesList, err := p.GetHistoryProvider().QueryTimeSeries(namer, start, end, cfg.sampleInterval)
+		if err != nil {
+			// the real time provider will keep on adding samples, so it's fine to go on with the model in checkpoint
+			klog.ErrorS(err, "Failed to top up checkpoint with history time series.", "queryExpr", queryExpr)
+		} else {
+			cleanOutliers(queryExpr, historyTimeSeriesList, cfg)
+			topUpSignals(signals, historyTimeSeriesList, cfg)
+		}
+	}
+
+	p.a.SetSignals(queryExpr, signals)
+	klog.V(4).InfoS("Restored from checkpoint.", "queryExpr", queryExpr, "checkpointTime", checkpointTime, "signals", len(signals))
+	return nil
+}
+
+// topUpSignals adds the samples which are not in the signals yet.
+func topUpSignals(signals map[string]*aggregateSignal, tsList []*common.TimeSeries, cfg *internalConfig) {
+	// in aggregated mode, samples of all time series go into the same signal, so record the last sample time before adding
+	lastSampleTimes := map[string]time.Time{}
+	for key, signal := range signals {
+		lastSampleTimes[key] = signal.lastSampleTime
+	}
+
+	for _, ts := range tsList {
+		key := keyAll
+		if !cfg.aggregated {
+			if len(ts.Samples) < 1 {
+				continue
+			}
+			key = prediction.AggregateSignalKey(ts.Labels)
+		}
+		signal, exists := signals[key]
+		if !exists {
+			signal = newAggregateSignal(cfg)
+			if !cfg.aggregated {
+				signal.labels = ts.Labels
+			}
+			signals[key] = signal
+		}
+		for _, s := range ts.Samples {
+			t := time.Unix(s.Timestamp, 0)
+			if t.After(lastSampleTimes[key]) {
+				signal.addSample(t, s.Value)
+			}
+		}
+	}
+}
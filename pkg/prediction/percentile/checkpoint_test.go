@@ -0,0 +1,101 @@
+package percentile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/prediction"
+	"github.com/gocrane/crane/pkg/prediction/checkpoint"
+	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/outlier"
+)
+
+func TestCheckpointSaveAndRestore(t *testing.T) {
+	store, err := checkpoint.NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	p := NewPrediction(nil, nil, config.AlgorithmModelConfig{CheckpointStore: store}).(*percentilePrediction)
+	cfg := defaultInternalConfig
+	queryExpr := "cpu"
+	p.a.signalMap[queryExpr] = map[string]*aggregateSignal{}
+	p.a.configMap[queryExpr] = &cfg
+
+	now := time.Now().Truncate(time.Minute)
+	signal := newAggregateSignal(&cfg)
+	for i := 0; i < 100; i++ {
+		signal.addSample(now.Add(time.Duration(i-100)*time.Minute), float64(i%10))
+	}
+	p.a.SetSignal(queryExpr, keyAll, signal)
+
+	assert.NoError(t, p.saveCheckpoint(context.TODO(), queryExpr))
+
+	signals, checkpointTime, err := p.restoreFromCheckpoint(queryExpr, &cfg)
+	assert.NoError(t, err)
+	assert.False(t, checkpointTime.IsZero())
+
+	restored := signals[keyAll]
+	assert.NotNil(t, restored)
+	assert.Equal(t, signal.totalSamplesCount, restored.totalSamplesCount)
+	assert.Equal(t, signal.lastSampleTime.Unix(), restored.lastSampleTime.Unix())
+	assert.InDelta(t, signal.histogram.Percentile(0.99), restored.histogram.Percentile(0.99), 0.1)
+
+	_, _, err = p.restoreFromCheckpoint("mem", &cfg)
+	assert.Equal(t, checkpoint.ErrNotFound, err)
+}
+
+func TestTopUpSignals(t *testing.T) {
+	cfg := defaultInternalConfig
+	now := time.Now().Truncate(time.Minute)
+
+	signal := newAggregateSignal(&cfg)
+	signal.addSample(now.Add(-2*time.Minute), 1)
+	signals := map[string]*aggregateSignal{keyAll: signal}
+
+	tsList := []*common.TimeSeries{
+		{
+			Labels: []common.Label{{Name: "pod", Value: "a"}},
+			Samples: []common.Sample{
+				{Timestamp: now.Add(-2 * time.Minute).Unix(), Value: 1},
+				{Timestamp: now.Add(-time.Minute).Unix(), Value: 2},
+			},
+		},
+		{
+			Labels: []common.Label{{Name: "pod", Value: "b"}},
+			Samples: []common.Sample{
+				{Timestamp: now.Add(-time.Minute).Unix(), Value: 3},
+				{Timestamp: now.Unix(), Value: 4},
+			},
+		},
+	}
+
+	topUpSignals(signals, tsList, &cfg)
+	assert.Equal(t, 4, signals[keyAll].totalSamplesCount)
+
+	cfg.aggregated = false
+	signals = map[string]*aggregateSignal{}
+	topUpSignals(signals, tsList, &cfg)
+	assert.Len(t, signals, 2)
+	assert.Equal(t, 2, signals[prediction.AggregateSignalKey(tsList[1].Labels)].totalSamplesCount)
+}
+
+func TestCleanOutliersBeforeTopUp(t *testing.T) {
+	cfg := defaultInternalConfig
+	detector, err := outlier.NewDetector(&config.OutlierRemoval{Method: config.OutlierMethodMAD})
+	assert.NoError(t, err)
+	cfg.outlierDetector = detector
+
+	now := time.Now().Truncate(time.Minute)
+	ts := &common.TimeSeries{}
+	for i := 0; i < 10; i++ {
+		ts.Samples = append(ts.Samples, common.Sample{Timestamp: now.Add(time.Duration(i-10) * time.Minute).Unix(), Value: float64(1 + i%2)})
+	}
+	// a spike from a load test
+	ts.Samples[5].Value = 100
+
+	cleanOutliers("cpu", []*common.TimeSeries{ts}, &cfg)
+	assert.Less(t, ts.Samples[5].Value, 100.)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/gocrane/api/prediction/v1alpha1"
 
 	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/outlier"
 	"github.com/gocrane/crane/pkg/utils"
 )
 
@@ -17,6 +18,9 @@ var defaultMinSampleWeight float64 = 1e-5
 var defaultMarginFraction float64 = 0.0
 var defaultPercentile float64 = .99
 var defaultTargetUtilization float64 = 1.0
+
+// defaultBandPercentileDistance is the distance between the percentile of the prediction and the percentiles of its bands
+var defaultBandPercentileDistance float64 = .05
 var defaultHistogramOptions, _ = vpa.NewLinearHistogramOptions(100.0, 0.1, 1e-10)
 
 var defaultInternalConfig = internalConfig{
@@ -42,6 +46,8 @@ type internalConfig struct {
 	percentile             float64
 	targetUtilization      float64
 	initMode               config.ModelInitMode
+	// outlierDetector cleans the history before building the histograms, nil if the outlier removal is not specified
+	outlierDetector outlier.Detector
 }
 
 func (c *internalConfig) String() string {
@@ -51,7 +57,7 @@ func (c *internalConfig) String() string {
 
 // todo: later better to refine the algorithm params to a map not a struct to get more extendability,
 // if not, we add some param is very difficult because it will modify crane api
-func makeInternalConfig(p *v1alpha1.Percentile, initMode *config.ModelInitMode) (*internalConfig, error) {
+func makeInternalConfig(p *v1alpha1.Percentile, initMode *config.ModelInitMode, outlierRemoval *config.OutlierRemoval) (*internalConfig, error) {
 	sampleInterval, err := utils.ParseDuration(p.SampleInterval)
 	if err != nil {
 		return nil, err
@@ -144,6 +150,12 @@ func makeInternalConfig(p *v1alpha1.Percentile, initMode *config.ModelInitMode)
 	if initMode != nil {
 		mode = *initMode
 	}
+
+	detector, err := outlier.NewDetector(outlierRemoval)
+	if err != nil {
+		return nil, err
+	}
+
 	c := &internalConfig{
 		initMode:               mode,
 		aggregated:             p.Aggregated,
@@ -155,6 +167,7 @@ func makeInternalConfig(p *v1alpha1.Percentile, initMode *config.ModelInitMode)
 		marginFraction:         marginFraction,
 		percentile:             percentile,
 		targetUtilization:      targetUtilization,
+		outlierDetector:        detector,
 	}
 	klog.InfoS("Made an internal config.", "internalConfig", c)
 
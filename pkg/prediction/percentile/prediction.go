@@ -3,15 +3,18 @@ package percentile
 import (
 	"context"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 
 	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/crane/pkg/metricnaming"
 	"github.com/gocrane/crane/pkg/prediction"
 	"github.com/gocrane/crane/pkg/prediction/config"
+	"github.com/gocrane/crane/pkg/prediction/outlier"
 	"github.com/gocrane/crane/pkg/providers"
 )
 
@@ -24,6 +27,7 @@ type percentilePrediction struct {
 	// record the query routine already started
 	queryRoutines sync.Map
 	stopChMap     sync.Map
+	modelConfig   config.AlgorithmModelConfig
 }
 
 func (p *percentilePrediction) QueryPredictionStatus(_ context.Context, metricNamer metricnaming.MetricNamer) (prediction.Status, error) {
@@ -36,6 +40,12 @@ func (p *percentilePrediction) QueryPredictedTimeSeries(ctx context.Context, nam
 	queryExpr := namer.BuildUniqueKey()
 	cfg := p.a.GetConfig(queryExpr)
 	tsList := p.getPredictedValues(ctx, namer)
+	// the bands are estimated from the neighbouring percentiles of the same histograms
+	if signals, status := p.a.GetSignals(queryExpr); signals != nil && status == prediction.StatusReady {
+		lower, upper := bandPercentiles(cfg.percentile)
+		tsList = append(tsList, predictedValuesFromSignals(signals, cfg, lower, prediction.BandLower)...)
+		tsList = append(tsList, predictedValuesFromSignals(signals, cfg, upper, prediction.BandUpper)...)
+	}
 	for _, ts := range tsList {
 		n := len(ts.Samples)
 		if n > 0 {
@@ -59,16 +69,29 @@ func generateSamplesFromWindow(value float64, start time.Time, end time.Time, st
 }
 
 func (p *percentilePrediction) getPredictedValuesFromSignals(queryExpr string, signals map[string]*aggregateSignal, cfg *internalConfig) []*common.TimeSeries {
-	var predictedTimeSeriesList []*common.TimeSeries
-
 	if cfg == nil {
 		cfg = p.a.GetConfig(queryExpr)
 	}
-	estimator := NewPercentileEstimator(cfg.percentile)
+	return predictedValuesFromSignals(signals, cfg, cfg.percentile, "")
+}
+
+// predictedValuesFromSignals estimates the given percentile of the signals, the time series are labeled by the band if
+// it is not empty.
+func predictedValuesFromSignals(signals map[string]*aggregateSignal, cfg *internalConfig, percentile float64, band string) []*common.TimeSeries {
+	var predictedTimeSeriesList []*common.TimeSeries
+
+	estimator := NewPercentileEstimator(percentile)
 	estimator = WithMargin(cfg.marginFraction, estimator)
 	estimator = WithTargetUtilization(cfg.targetUtilization, estimator)
 	now := time.Now().Unix()
 
+	labelsOf := func(labels []common.Label) []common.Label {
+		if band == "" {
+			return labels
+		}
+		return prediction.WithBandLabel(labels, band)
+	}
+
 	if cfg.aggregated {
 		signal := signals[keyAll]
 		if signal != nil {
@@ -77,7 +100,7 @@ func (p *percentilePrediction) getPredictedValuesFromSignals(queryExpr string, s
 				Timestamp: now,
 			}
 			predictedTimeSeriesList = append(predictedTimeSeriesList, &common.TimeSeries{
-				Labels:  nil,
+				Labels:  labelsOf(nil),
 				Samples: []common.Sample{sample},
 			})
 		}
@@ -91,7 +114,7 @@ func (p *percentilePrediction) getPredictedValuesFromSignals(queryExpr string, s
 				Timestamp: now,
 			}
 			predictedTimeSeriesList = append(predictedTimeSeriesList, &common.TimeSeries{
-				Labels:  signal.labels,
+				Labels:  labelsOf(signal.labels),
 				Samples: []common.Sample{sample},
 			})
 		}
@@ -100,6 +123,13 @@ func (p *percentilePrediction) getPredictedValuesFromSignals(queryExpr string, s
 	return predictedTimeSeriesList
 }
 
+// bandPercentiles returns the neighbouring percentiles of the lower and upper bands.
+func bandPercentiles(percentile float64) (float64, float64) {
+	lower := math.Max(percentile-defaultBandPercentileDistance, 0)
+	upper := math.Min(percentile+defaultBandPercentileDistance, 1.0)
+	return lower, upper
+}
+
 func (p *percentilePrediction) getPredictedValues(ctx context.Context, namer metricnaming.MetricNamer) []*common.TimeSeries {
 	var predictedTimeSeriesList []*common.TimeSeries
 
@@ -141,7 +171,7 @@ func (p *percentilePrediction) QueryRealtimePredictedValues(ctx context.Context,
 func (p *percentilePrediction) QueryRealtimePredictedValuesOnce(_ context.Context, namer metricnaming.MetricNamer, config config.Config) ([]*common.TimeSeries, error) {
 	queryExpr := namer.BuildUniqueKey()
 
-	cfg, err := makeInternalConfig(config.Percentile, config.InitMode)
+	cfg, err := makeInternalConfig(config.Percentile, config.InitMode, config.OutlierRemoval)
 	if err != nil {
 		return nil, err
 	}
@@ -200,13 +230,14 @@ func (p *percentilePrediction) process(namer metricnaming.MetricNamer, cfg *inte
 	return p.getPredictedValuesFromSignals(queryExpr, signals, cfg), nil
 }
 
-func NewPrediction(realtimeProvider providers.RealTime, historyProvider providers.History) prediction.Interface {
+func NewPrediction(realtimeProvider providers.RealTime, historyProvider providers.History, mc config.AlgorithmModelConfig) prediction.Interface {
 	withCh, delCh := make(chan prediction.QueryExprWithCaller), make(chan prediction.QueryExprWithCaller)
 	return &percentilePrediction{
 		GenericPrediction: prediction.NewGenericPrediction(realtimeProvider, historyProvider, withCh, delCh),
 		a:                 newAggregateSignals(),
 		queryRoutines:     sync.Map{},
 		stopChMap:         sync.Map{},
+		modelConfig:       mc,
 	}
 }
 
@@ -294,15 +325,25 @@ func (p *percentilePrediction) Run(stopCh <-chan struct{}) {
 						predStopCh := val.(chan struct{})
 						predStopCh <- struct{}{}
 					}
+					p.deleteCheckpoint(QueryExpr)
 				}
 			}(qc)
 		}
 	}()
 
+	if p.modelConfig.CheckpointStore != nil && p.modelConfig.CheckpointInterval > 0 {
+		go wait.Until(p.checkpointAll, p.modelConfig.CheckpointInterval, stopCh)
+	}
+
 	klog.Infof("predictor %v started", p.Name())
 
 	<-stopCh
 
+	if p.modelConfig.CheckpointStore != nil {
+		// save the latest models, so that the next start can restore from them
+		p.checkpointAll()
+	}
+
 	klog.Infof("predictor %v stopped", p.Name())
 
 }
@@ -318,9 +359,20 @@ func (p *percentilePrediction) queryHistoryTimeSeries(namer metricnaming.MetricN
 		klog.ErrorS(err, "Failed to query history time series.")
 		return nil, err
 	}
+	cleanOutliers(namer.BuildUniqueKey(), historyTimeSeries, c)
 	return historyTimeSeries, nil
 }
 
+// cleanOutliers removes the outliers from the time series queried from the history provider. The samples from the
+// realtime provider come one by one, so only the history is cleaned.
+func cleanOutliers(queryExpr string, tsList []*common.TimeSeries, c *internalConfig) {
+	for _, ts := range tsList {
+		if cleaned := outlier.Clean(ts, c.outlierDetector); len(cleaned) > 0 {
+			klog.V(4).InfoS("Outliers cleaned.", "queryExpr", queryExpr, "labels", ts.Labels, "detector", c.outlierDetector, "outliers", len(cleaned))
+		}
+	}
+}
+
 // Lazy training the histogram model. we do not init from History Provider such as prometheus because prometheus's poor performance issue.
 // Rather, we recover or init a complete available histogram model by fetching real time data point continuously until it is enough time to do estimation.
 // So, we can set a waiting time for the model trained completed. Because percentile is only used for request resource & resource estimation.
@@ -339,12 +391,6 @@ func (p *percentilePrediction) initByRealTimeProvider(namer metricnaming.MetricN
 	}
 }
 
-// todo:
-// nolint:unused
-func (p *percentilePrediction) initByCheckPoint(_ metricnaming.MetricNamer) error {
-	return fmt.Errorf("checkpoint not supported")
-}
-
 func (p *percentilePrediction) initFromHistory(namer metricnaming.MetricNamer) error {
 	queryExpr := namer.BuildUniqueKey()
 	cfg := p.a.GetConfig(queryExpr)
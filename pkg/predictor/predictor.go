@@ -11,6 +11,7 @@ import (
 	"github.com/gocrane/crane/pkg/prediction"
 	predconf "github.com/gocrane/crane/pkg/prediction/config"
 	"github.com/gocrane/crane/pkg/prediction/dsp"
+	"github.com/gocrane/crane/pkg/prediction/external"
 	"github.com/gocrane/crane/pkg/prediction/percentile"
 	"github.com/gocrane/crane/pkg/providers"
 )
@@ -25,6 +26,8 @@ type AlgorithmDataProviders struct {
 type Config struct {
 	DataProviders AlgorithmDataProviders
 	ModelConfig   predconf.AlgorithmModelConfig
+	// External is the config of the external predictor, only used by the external algorithm
+	External external.Config
 }
 
 // DefaultPredictorsConfig will use all datasources you for real time and history provider. data proxy will select the first available.
@@ -36,6 +39,7 @@ func DefaultPredictorsConfig(modelConfig predconf.AlgorithmModelConfig) map[pred
 		},
 		predictionapi.AlgorithmTypePercentile: {
 			DataProviders: AlgorithmDataProviders{},
+			ModelConfig:   modelConfig,
 		},
 	}
 	return configs
@@ -104,7 +108,7 @@ func NewManager(realtimeProviders map[providers.DataSourceType]providers.RealTim
 
 		switch algo {
 		case predictionapi.AlgorithmTypePercentile:
-			pctPredictor := percentile.NewPrediction(algorithmRealTimeProxy, algorithmHistoryProxy)
+			pctPredictor := percentile.NewPrediction(algorithmRealTimeProxy, algorithmHistoryProxy, predictorConf.ModelConfig)
 			m.predictors[algo] = pctPredictor
 			m.historyDataProxys[algo] = algorithmHistoryProxy
 			m.realTimeDataProxys[algo] = algorithmRealTimeProxy
@@ -113,6 +117,15 @@ func NewManager(realtimeProviders map[providers.DataSourceType]providers.RealTim
 			m.predictors[algo] = dspPredictor
 			m.historyDataProxys[algo] = algorithmHistoryProxy
 			m.realTimeDataProxys[algo] = algorithmRealTimeProxy
+		case external.AlgorithmTypeExternal:
+			externalPredictor, err := external.NewPrediction(algorithmRealTimeProxy, algorithmHistoryProxy, predictorConf.ModelConfig, predictorConf.External)
+			if err != nil {
+				klog.ErrorS(err, "Failed to create the external predictor")
+				continue
+			}
+			m.predictors[algo] = externalPredictor
+			m.historyDataProxys[algo] = algorithmHistoryProxy
+			m.realTimeDataProxys[algo] = algorithmRealTimeProxy
 		default:
 			klog.Errorf("Unknown predictor %v", algo)
 			continue
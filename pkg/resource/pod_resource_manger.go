@@ -2,12 +2,14 @@ package resource
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
 	info "github.com/google/cadvisor/info/v1"
 	"google.golang.org/grpc"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	clientset "k8s.io/client-go/kubernetes"
@@ -17,6 +19,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/crane/pkg/ensurance/cgroup"
 	"github.com/gocrane/crane/pkg/ensurance/collector/cadvisor"
 	stypes "github.com/gocrane/crane/pkg/ensurance/collector/types"
 	"github.com/gocrane/crane/pkg/ensurance/executor"
@@ -44,11 +47,14 @@ type PodResourceManager struct {
 	// Updated when get new data from stateChann, used to determine whether state has expired
 	lastStateTime time.Time
 
+	// cgroupRoot is the mount point of cgroup, the limits are written to the container cgroup directly on cgroup v2
+	cgroupRoot string
+
 	cadvisor.Manager
 }
 
 func NewPodResourceManager(client clientset.Interface, nodeName string, podInformer coreinformers.PodInformer,
-	runtimeEndpoint string, stateChann chan map[string][]common.TimeSeries, cadvisorManager cadvisor.Manager) *PodResourceManager {
+	runtimeEndpoint, sysPath string, stateChann chan map[string][]common.TimeSeries, cadvisorManager cadvisor.Manager) *PodResourceManager {
 	runtimeClient, runtimeConn, err := cruntime.GetRuntimeClient(runtimeEndpoint)
 	if err != nil {
 		klog.Errorf("GetRuntimeClient failed %s", err.Error())
@@ -63,6 +69,7 @@ func NewPodResourceManager(client clientset.Interface, nodeName string, podInfor
 		runtimeClient: runtimeClient,
 		runtimeConn:   runtimeConn,
 		stateChann:    stateChann,
+		cgroupRoot:    filepath.Join(sysPath, "fs", "cgroup"),
 		Manager:       cadvisorManager,
 	}
 	podInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
@@ -149,6 +156,7 @@ func (o *PodResourceManager) updatePodExtResToCgroup(pod *v1.Pod) {
 	metrics.UpdateLastTime(string(known.ModulePodResourceManager), metrics.StepUpdatePodResource, start)
 
 	_, containerCPUQuotas := podinfo.GetPodUsage(string(stypes.MetricNameContainerCpuQuota), o.state, pod)
+	unified := cgroup.IsUnified(o.cgroupRoot)
 
 	for _, c := range pod.Spec.Containers {
 		if state := utils.GetContainerStatus(pod, c); state.Running == nil {
@@ -163,6 +171,11 @@ func (o *PodResourceManager) updatePodExtResToCgroup(pod *v1.Pod) {
 					continue
 				}
 
+				if unified {
+					o.updateCPUQuotaV2(pod, containerId, val)
+					continue
+				}
+
 				// If container's quota is -1, pod resource manager will convert limit to quota
 				containerCPUQuota, err := podinfo.GetUsageById(containerCPUQuotas, containerId)
 				if err != nil {
@@ -185,6 +198,12 @@ func (o *PodResourceManager) updatePodExtResToCgroup(pod *v1.Pod) {
 					continue
 				}
 			}
+			// The memory limit is only converted on cgroup v2, the memory of cgroup v1 is left to the runtime
+			if unified && strings.HasPrefix(res.String(), fmt.Sprintf(utils.ExtResourcePrefixFormat, v1.ResourceMemory)) {
+				if containerId := utils.GetContainerIdFromPod(pod, c.Name); containerId != "" {
+					o.updateMemoryLimitV2(pod, containerId, val)
+				}
+			}
 		}
 	}
 	metrics.UpdateDurationFromStart(string(known.ModulePodResourceManager), metrics.StepUpdatePodResource, start)
@@ -214,3 +233,52 @@ func (o *PodResourceManager) getCPUPeriod(pod *v1.Pod, containerId string) float
 	}
 	return float64(containerInfoV1.Spec.Cpu.Period)
 }
+
+// updateCPUQuotaV2 converts the cpu limit to cpu.max of the container cgroup if it's unlimited on cgroup v2.
+func (o *PodResourceManager) updateCPUQuotaV2(pod *v1.Pod, containerId string, limit resource.Quantity) {
+	containerCgroupPath, err := cgroup.GetContainerCgroupPath(o.cgroupRoot, utils.GetCgroupPath(pod, o.Manager.GetCgroupDriver()), containerId)
+	if err != nil {
+		metrics.PodResourceUpdateErrorCounterInc(metrics.SubComponentPodResource, metrics.StepGetPeriod)
+		klog.Errorf("Failed to get cgroup of container %s: %v ", containerId, err)
+		return
+	}
+	quota, period, err := cgroup.GetCPUQuota(o.cgroupRoot, containerCgroupPath)
+	if err != nil {
+		metrics.PodResourceUpdateErrorCounterInc(metrics.SubComponentPodResource, metrics.StepGetPeriod)
+		klog.Errorf("Failed to get cpu quota of container %s: %v ", containerId, err)
+		return
+	}
+	if quota > 0 {
+		return
+	}
+
+	quota = int64(float64(limit.MilliValue()) / executor.CpuQuotaCoefficient * float64(period))
+	if err = cgroup.SetCPUQuota(o.cgroupRoot, containerCgroupPath, quota, period); err != nil {
+		metrics.PodResourceUpdateErrorCounterInc(metrics.SubComponentPodResource, metrics.StepUpdateQuota)
+		klog.Errorf("Failed to update pod %s container %s cpu.max, err %s", pod.Name, containerId, err.Error())
+	}
+}
+
+// updateMemoryLimitV2 converts the memory limit to memory.max of the container cgroup if it's unlimited on cgroup v2.
+func (o *PodResourceManager) updateMemoryLimitV2(pod *v1.Pod, containerId string, limit resource.Quantity) {
+	containerCgroupPath, err := cgroup.GetContainerCgroupPath(o.cgroupRoot, utils.GetCgroupPath(pod, o.Manager.GetCgroupDriver()), containerId)
+	if err != nil {
+		metrics.PodResourceUpdateErrorCounterInc(metrics.SubComponentPodResource, metrics.StepUpdateMemoryLimit)
+		klog.Errorf("Failed to get cgroup of container %s: %v ", containerId, err)
+		return
+	}
+	current, err := cgroup.GetMemoryLimit(o.cgroupRoot, containerCgroupPath)
+	if err != nil {
+		metrics.PodResourceUpdateErrorCounterInc(metrics.SubComponentPodResource, metrics.StepUpdateMemoryLimit)
+		klog.Errorf("Failed to get memory limit of container %s: %v ", containerId, err)
+		return
+	}
+	if current > 0 {
+		return
+	}
+
+	if err = cgroup.SetMemoryLimit(o.cgroupRoot, containerCgroupPath, uint64(limit.Value())); err != nil {
+		metrics.PodResourceUpdateErrorCounterInc(metrics.SubComponentPodResource, metrics.StepUpdateMemoryLimit)
+		klog.Errorf("Failed to update pod %s container %s memory.max, err %s", pod.Name, containerId, err.Error())
+	}
+}
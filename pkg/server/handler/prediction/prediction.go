@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-echarts/go-echarts/v2/charts"
@@ -19,6 +20,7 @@ import (
 	craneclientset "github.com/gocrane/api/pkg/generated/clientset/versioned"
 	"github.com/gocrane/api/prediction/v1alpha1"
 
+	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/crane/pkg/controller/timeseriesprediction"
 	"github.com/gocrane/crane/pkg/prediction/dsp"
 	predictormgr "github.com/gocrane/crane/pkg/predictor"
@@ -82,7 +84,7 @@ func (dh *DebugHandler) Display(c *gin.Context) {
 			internalConf := mc.ConvertApiMetric2InternalConfig(&tsp.Spec.PredictionMetrics[0])
 			namer := mc.GetMetricNamer(&tsp.Spec.PredictionMetrics[0])
 			pred := dh.predictorManager.GetPredictor(v1alpha1.AlgorithmTypeDSP)
-			history, test, estimate, err := dsp.Debug(pred, namer, internalConf)
+			history, test, estimate, outliers, err := dsp.Debug(pred, namer, internalConf)
 			if err != nil {
 				ginwrapper.WriteResponse(c, err, nil)
 				return
@@ -92,6 +94,9 @@ func (dh *DebugHandler) Display(c *gin.Context) {
 			page.AddCharts(plot(history, "history", "green", charts.WithTitleOpts(opts.Title{Title: "history"})))
 			page.AddCharts(plots([]*dsp.Signal{test, estimate}, []string{"actual", "forecasted"},
 				charts.WithTitleOpts(opts.Title{Title: "actual/forecasted"})))
+			if len(outliers) > 0 {
+				page.AddCharts(scatter(outliers, "outliers", charts.WithTitleOpts(opts.Title{Title: "cleaned outliers"})))
+			}
 			err = page.Render(c.Writer)
 			if err != nil {
 				klog.ErrorS(err, "Failed to display debug time series")
@@ -135,6 +140,36 @@ func plot(s *dsp.Signal, name string, color string, o ...charts.GlobalOpts) *cha
 	return line
 }
 
+// scatter plots the original samples of the cleaned outliers at their time.
+func scatter(samples []common.Sample, name string, o ...charts.GlobalOpts) *charts.Scatter {
+	x := make([]string, 0, len(samples))
+	y := make([]opts.ScatterData, 0, len(samples))
+	for _, s := range samples {
+		x = append(x, time.Unix(s.Timestamp, 0).Format(time.RFC3339))
+		y = append(y, opts.ScatterData{Value: s.Value, Symbol: "circle", SymbolSize: 6})
+	}
+
+	chart := charts.NewScatter()
+	chart.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Width: "3000px", Theme: types.ThemeRoma}),
+		charts.WithLegendOpts(
+			opts.Legend{
+				Show: true,
+				Data: name,
+			}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show:      true,
+			Trigger:   "axis",
+			TriggerOn: "mousemove",
+		}))
+	if o != nil {
+		chart.SetGlobalOptions(o...)
+	}
+	chart.SetXAxis(x).AddSeries(name, y)
+
+	return chart
+}
+
 func plots(signals []*dsp.Signal, names []string, o ...charts.GlobalOpts) *charts.Line {
 	if len(signals) < 1 {
 		return nil
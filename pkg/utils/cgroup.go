@@ -57,6 +57,9 @@ func NewCgroupName(base CgroupName, components ...string) CgroupName {
 // systemdSuffix is the cgroup name suffix for systemd
 const systemdSuffix string = ".slice"
 
+// systemdScopeSuffix is the container cgroup name suffix for systemd
+const systemdScopeSuffix string = ".scope"
+
 func (cgroupName CgroupName) ToSystemd() string {
 	if len(cgroupName) == 0 || (len(cgroupName) == 1 && cgroupName[0] == "") {
 		return "/"
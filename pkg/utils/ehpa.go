@@ -7,6 +7,7 @@ import (
 
 	autoscalingapi "github.com/gocrane/api/autoscaling/v1alpha1"
 	"github.com/gocrane/crane/pkg/known"
+	"github.com/gocrane/crane/pkg/prediction"
 	autoscalingv2 "k8s.io/api/autoscaling/v2beta2"
 )
 
@@ -31,6 +32,16 @@ func IsEHPAHasPredictionMetric(ehpa *autoscalingapi.EffectiveHorizontalPodAutosc
 	return false
 }
 
+// GetEHPAPredictionBand returns the band of the prediction which the ehpa scales on, an empty band means the predicted
+// time series itself, it is also empty if the annotation is not a valid band.
+func GetEHPAPredictionBand(ehpa *autoscalingapi.EffectiveHorizontalPodAutoscaler) string {
+	band := ehpa.Annotations[known.EffectiveHorizontalPodAutoscalerPredictionBandAnnotation]
+	if !prediction.IsValidBand(band) {
+		return ""
+	}
+	return band
+}
+
 func IsEHPACronEnabled(ehpa *autoscalingapi.EffectiveHorizontalPodAutoscaler) bool {
 	return len(ehpa.Spec.Crons) > 0
 }
@@ -29,7 +29,12 @@ func QueryPredictedTimeSeriesOnce(predictor prediction.Interface, caller string,
 		}
 	}()
 
-	return predictor.QueryPredictedTimeSeries(context.TODO(), namer, startTime, endTime)
+	tsList, err := predictor.QueryPredictedTimeSeries(context.TODO(), namer, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	// the callers only consume the predicted time series, not the bands
+	return prediction.FilterBand(tsList, ""), nil
 }
 
 func QueryPredictedValues(predictor prediction.Interface, caller string, pConfig *config.Config, namer metricnaming.MetricNamer) ([]*common.TimeSeries, error) {
@@ -46,19 +51,40 @@ func QueryPredictedValuesOnce(recommendation *v1alpha1.Recommendation, predictor
 }
 
 func GetReadyPredictionMetric(metric string, resourceIdentifier string, prediction *predictionapi.TimeSeriesPrediction) (*predictionapi.MetricTimeSeries, error) {
+	return GetReadyPredictionMetricOfBand(metric, resourceIdentifier, "", prediction)
+}
+
+// GetReadyPredictionMetricOfBand returns the predicted time series of the band, use an empty band to get the predicted
+// time series itself.
+func GetReadyPredictionMetricOfBand(metric string, resourceIdentifier string, band string, prediction *predictionapi.TimeSeriesPrediction) (*predictionapi.MetricTimeSeries, error) {
 	for _, metricStatus := range prediction.Status.PredictionMetrics {
-		if metricStatus.ResourceIdentifier == resourceIdentifier && len(metricStatus.Prediction) == 1 {
+		if metricStatus.ResourceIdentifier != resourceIdentifier {
+			continue
+		}
+		var timeSeriesList []*predictionapi.MetricTimeSeries
+		for _, ts := range metricStatus.Prediction {
+			if GetPredictionBand(ts.Labels) == band {
+				timeSeriesList = append(timeSeriesList, ts)
+			}
+		}
+		if len(timeSeriesList) == 1 {
 			if !metricStatus.Ready {
 				return nil, fmt.Errorf("TimeSeries is not ready, metric name %s resourceIdentifier %s", metric, resourceIdentifier)
 			}
 
-			if len(metricStatus.Prediction) != 1 {
-				return nil, fmt.Errorf("TimeSeries data length is unexpected: %d, metric name %s resourceIdentifier %s", len(metricStatus.Prediction), metric, resourceIdentifier)
-			}
-
-			return metricStatus.Prediction[0], nil
+			return timeSeriesList[0], nil
 		}
 	}
 
-	return nil, fmt.Errorf("TimeSeries not matched, metric name %s resourceIdentifier %s", metric, resourceIdentifier)
+	return nil, fmt.Errorf("TimeSeries not matched, metric name %s resourceIdentifier %s band %q", metric, resourceIdentifier, band)
+}
+
+// GetPredictionBand returns the band of the predicted time series, it is empty for the predicted time series itself.
+func GetPredictionBand(labels []predictionapi.Label) string {
+	for _, label := range labels {
+		if label.Name == prediction.BandLabelName {
+			return label.Value
+		}
+	}
+	return ""
 }
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+
+	predictionapi "github.com/gocrane/api/prediction/v1alpha1"
+
+	"github.com/gocrane/crane/pkg/prediction"
+)
+
+func TestGetReadyPredictionMetricOfBand(t *testing.T) {
+	predicted := &predictionapi.MetricTimeSeries{}
+	upper := &predictionapi.MetricTimeSeries{Labels: []predictionapi.Label{{Name: prediction.BandLabelName, Value: prediction.BandUpper}}}
+	tsp := &predictionapi.TimeSeriesPrediction{
+		Status: predictionapi.TimeSeriesPredictionStatus{
+			PredictionMetrics: []predictionapi.PredictionMetricStatus{
+				{ResourceIdentifier: "cpu", Ready: true, Prediction: []*predictionapi.MetricTimeSeries{predicted, upper}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		resourceIdentifier string
+		band               string
+		want               *predictionapi.MetricTimeSeries
+		wantErr            bool
+	}{
+		{name: "predicted", resourceIdentifier: "cpu", band: "", want: predicted},
+		{name: "upper band", resourceIdentifier: "cpu", band: prediction.BandUpper, want: upper},
+		{name: "lower band not found", resourceIdentifier: "cpu", band: prediction.BandLower, wantErr: true},
+		{name: "metric not found", resourceIdentifier: "memory", band: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetReadyPredictionMetricOfBand("crane_prediction", tt.resourceIdentifier, tt.band, tsp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetReadyPredictionMetricOfBand() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GetReadyPredictionMetricOfBand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
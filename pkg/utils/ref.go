@@ -25,12 +25,17 @@ func GetContainerIdFromKey(key string) string {
 	subPaths := strings.Split(key, "/")
 
 	if len(subPaths) > 0 {
-		// if the latest sub path is pod-xxx-xxx, we regard as it pod path
+		// if the latest sub path is pod-xxx-xxx or a systemd slice, we regard as it pod path
+		// if it is a systemd scope such as cri-containerd-xxx.scope, we trim the prefix and suffix as the containerId
 		// if not we used the latest sub path as the containerId
-		if strings.HasPrefix(subPaths[len(subPaths)-1], CgroupPodPrefix) {
+		lastPath := subPaths[len(subPaths)-1]
+		if strings.HasPrefix(lastPath, CgroupPodPrefix) || strings.HasSuffix(lastPath, systemdSuffix) {
 			return ""
+		} else if strings.HasSuffix(lastPath, systemdScopeSuffix) {
+			lastPath = strings.TrimSuffix(lastPath, systemdScopeSuffix)
+			return lastPath[strings.LastIndex(lastPath, "-")+1:]
 		} else {
-			return subPaths[len(subPaths)-1]
+			return lastPath
 		}
 	}
 
@@ -21,6 +21,18 @@ func TestGetContainerIdFromKey(t *testing.T) {
 			input:  "/kubepods/besteffort/pod04e5e9e7-8d95-44dd-9af7-ab944405fff8/2cc2c4badac0618edda11bdd06826e7385b885ca88323b6f5d90270395e039d9",
 			output: "2cc2c4badac0618edda11bdd06826e7385b885ca88323b6f5d90270395e039d9",
 		},
+		{
+			input:  "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod04e5e9e7_8d95_44dd_9af7_ab944405fff8.slice",
+			output: "",
+		},
+		{
+			input:  "/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod04e5e9e7_8d95_44dd_9af7_ab944405fff8.slice/cri-containerd-18b514fc91ecb19b7ee79ebeaa6f2df86c6c939e420520b97ad4f7532582d35a.scope",
+			output: "18b514fc91ecb19b7ee79ebeaa6f2df86c6c939e420520b97ad4f7532582d35a",
+		},
+		{
+			input:  "/kubepods.slice/kubepods-pod04e5e9e7_8d95_44dd_9af7_ab944405fff8.slice/docker-2cc2c4badac0618edda11bdd06826e7385b885ca88323b6f5d90270395e039d9.scope",
+			output: "2cc2c4badac0618edda11bdd06826e7385b885ca88323b6f5d90270395e039d9",
+		},
 	}
 
 	for _, c := range cases {
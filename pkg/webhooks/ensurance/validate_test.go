@@ -4,8 +4,10 @@ import (
 	"strings"
 	"testing"
 
+	"golang.org/x/net/context"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
@@ -224,3 +226,130 @@ func TestValidateRules(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRulePolicies(t *testing.T) {
+	rules := []ensuranceapi.Rule{{Name: "cpu-busy"}}
+	cases := map[string]struct {
+		annotations map[string]string
+		expectErr   bool
+		errorDetail string
+	}{
+		"no policy": {
+			annotations: map[string]string{"foo": "bar"},
+			expectErr:   false,
+		},
+		"policy valid": {
+			annotations: map[string]string{known.NodeQOSRulePolicyAnnotationPrefix + "/cpu-busy": "package crane.ensurance\n\ndefault trigger = false\n"},
+			expectErr:   false,
+		},
+		"rule not found": {
+			annotations: map[string]string{known.NodeQOSRulePolicyAnnotationPrefix + "/mem-busy": "package crane.ensurance\n\ndefault trigger = false\n"},
+			expectErr:   true,
+			errorDetail: "rule is not found",
+		},
+		"policy invalid": {
+			annotations: map[string]string{known.NodeQOSRulePolicyAnnotationPrefix + "/cpu-busy": "package crane.ensurance\n\ntrigger {\n"},
+			expectErr:   true,
+		},
+		"policy and trigger expression": {
+			annotations: map[string]string{
+				known.NodeQOSRulePolicyAnnotationPrefix + "/cpu-busy":  "package crane.ensurance\n\ndefault trigger = false\n",
+				known.NodeQOSRuleTriggerAnnotationPrefix + "/cpu-busy": "cpu_total_utilization > 80",
+			},
+			expectErr:   true,
+			errorDetail: "both a policy and a trigger expression",
+		},
+	}
+
+	for k, v := range cases {
+		t.Run(k, func(t *testing.T) {
+			errs := validateRulePolicies(v.annotations, rules, field.NewPath("metadata").Child("annotations"))
+			if v.expectErr && len(errs) > 0 {
+				if errs[0].Type != field.ErrorTypeInvalid || !strings.Contains(errs[0].Detail, v.errorDetail) {
+					t.Errorf("[%s] Expected error type %q with detail %q, got %v", k, field.ErrorTypeInvalid, v.errorDetail, errs)
+				}
+			} else if v.expectErr && len(errs) == 0 {
+				t.Errorf("Unexpected success")
+			}
+			if !v.expectErr && len(errs) != 0 {
+				t.Errorf("Unexpected error(s): %v", errs)
+			}
+		})
+	}
+}
+
+func TestValidateRuleTriggerExpressions(t *testing.T) {
+	rules := []ensuranceapi.Rule{{Name: "memory-leak"}}
+	cases := map[string]struct {
+		annotations   map[string]string
+		httpGetEnable bool
+		expectErr     bool
+		errorDetail   string
+	}{
+		"expression valid": {
+			annotations: map[string]string{known.NodeQOSRuleTriggerAnnotationPrefix + "/memory-leak": "deriv(memory_total_usage[5m]) * 60 > 200Mi && memory_total_utilization > 60"},
+			expectErr:   false,
+		},
+		"rule not found": {
+			annotations: map[string]string{known.NodeQOSRuleTriggerAnnotationPrefix + "/cpu-busy": "cpu_total_utilization > 80"},
+			expectErr:   true,
+			errorDetail: "rule is not found",
+		},
+		"expression invalid": {
+			annotations: map[string]string{known.NodeQOSRuleTriggerAnnotationPrefix + "/memory-leak": "deriv(memory_total_usage) > 0"},
+			expectErr:   true,
+			errorDetail: "requires a range",
+		},
+		"metric not supported": {
+			annotations: map[string]string{known.NodeQOSRuleTriggerAnnotationPrefix + "/memory-leak": "memory_leak_bytes > 0"},
+			expectErr:   true,
+			errorDetail: "metric memory_leak_bytes is not supported",
+		},
+		"metric of the http probe": {
+			annotations:   map[string]string{known.NodeQOSRuleTriggerAnnotationPrefix + "/memory-leak": "memory_leak_bytes > 0"},
+			httpGetEnable: true,
+			expectErr:     false,
+		},
+	}
+
+	for k, v := range cases {
+		t.Run(k, func(t *testing.T) {
+			errs := validateRuleTriggerExpressions(v.annotations, rules, field.NewPath("metadata").Child("annotations"), v.httpGetEnable)
+			if v.expectErr && len(errs) > 0 {
+				if errs[0].Type != field.ErrorTypeInvalid || !strings.Contains(errs[0].Detail, v.errorDetail) {
+					t.Errorf("[%s] Expected error type %q with detail %q, got %v", k, field.ErrorTypeInvalid, v.errorDetail, errs)
+				}
+			} else if v.expectErr && len(errs) == 0 {
+				t.Errorf("Unexpected success")
+			}
+			if !v.expectErr && len(errs) != 0 {
+				t.Errorf("Unexpected error(s): %v", errs)
+			}
+		})
+	}
+}
+
+func TestValidateNodeQOSUpdate(t *testing.T) {
+	old := &ensuranceapi.NodeQOS{
+		ObjectMeta: metav1.ObjectMeta{Name: "cpu-busy", ResourceVersion: "1"},
+		Spec:       ensuranceapi.NodeQOSSpec{Rules: []ensuranceapi.Rule{{Name: "cpu-busy"}}},
+	}
+	p := &NodeQOSValidationAdmission{}
+
+	valid := old.DeepCopy()
+	valid.Annotations = map[string]string{known.NodeQOSRulePolicyAnnotationPrefix + "/cpu-busy": "package crane.ensurance\n\ndefault trigger = false\n"}
+	if err := p.ValidateUpdate(context.TODO(), old, valid); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	for _, annotations := range []map[string]string{
+		{known.NodeQOSRulePolicyAnnotationPrefix + "/cpu-busy": "package crane.ensurance\n\ntrigger {\n"},
+		{known.NodeQOSRuleTriggerAnnotationPrefix + "/cpu-busy": "deriv(memory_total_usage) > 0"},
+	} {
+		invalid := old.DeepCopy()
+		invalid.Annotations = annotations
+		if err := p.ValidateUpdate(context.TODO(), old, invalid); err == nil {
+			t.Errorf("Unexpected success for %v", annotations)
+		}
+	}
+}
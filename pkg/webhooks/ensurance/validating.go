@@ -2,6 +2,7 @@ package ensurance
 
 import (
 	"fmt"
+	"strings"
 
 	"golang.org/x/net/context"
 	corev1 "k8s.io/api/core/v1"
@@ -16,6 +17,7 @@ import (
 	"k8s.io/kubernetes/pkg/apis/core"
 
 	ensuranceapi "github.com/gocrane/api/ensurance/v1alpha1"
+	"github.com/gocrane/crane/pkg/ensurance/analyzer/evaluator"
 	"github.com/gocrane/crane/pkg/ensurance/collector"
 	"github.com/gocrane/crane/pkg/known"
 )
@@ -47,6 +49,8 @@ func (p *NodeQOSValidationAdmission) ValidateCreate(ctx context.Context, req run
 		httpGetEnable = true
 	}
 	allErrs = append(allErrs, validateRules(nodeQOS.Spec.Rules, field.NewPath("objectiveEnsurances"), httpGetEnable)...)
+	allErrs = append(allErrs, validateRulePolicies(nodeQOS.Annotations, nodeQOS.Spec.Rules, field.NewPath("metadata").Child("annotations"))...)
+	allErrs = append(allErrs, validateRuleTriggerExpressions(nodeQOS.Annotations, nodeQOS.Spec.Rules, field.NewPath("metadata").Child("annotations"), httpGetEnable)...)
 
 	if len(allErrs) != 0 {
 		return allErrs.ToAggregate()
@@ -180,6 +184,72 @@ func validateMetricRule(rule *ensuranceapi.MetricRule, fldPath *field.Path, http
 	return allErrs
 }
 
+// validateRulePolicies checks the Rego policies of the rules compile, and each policy belongs to a rule which has no
+// trigger expression
+func validateRulePolicies(annotations map[string]string, rules []ensuranceapi.Rule, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	ruleNames := sets.String{}
+	for _, rule := range rules {
+		ruleNames.Insert(rule.Name)
+	}
+
+	for key, policy := range annotations {
+		if !strings.HasPrefix(key, known.NodeQOSRulePolicyAnnotationPrefix+"/") {
+			continue
+		}
+		ruleName := strings.TrimPrefix(key, known.NodeQOSRulePolicyAnnotationPrefix+"/")
+		if !ruleNames.Has(ruleName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(key), ruleName, "rule is not found"))
+			continue
+		}
+		if _, ok := annotations[known.NodeQOSRuleTriggerAnnotationPrefix+"/"+ruleName]; ok {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(key), ruleName, "rule can not have both a policy and a trigger expression"))
+			continue
+		}
+		if _, err := evaluator.PreparePolicy(policy); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(key), policy, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// validateRuleTriggerExpressions checks the trigger expressions of the rules parse, and their metrics are collected
+func validateRuleTriggerExpressions(annotations map[string]string, rules []ensuranceapi.Rule, fldPath *field.Path, httpGetEnable bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	ruleNames := sets.String{}
+	for _, rule := range rules {
+		ruleNames.Insert(rule.Name)
+	}
+
+	for key, expr := range annotations {
+		if !strings.HasPrefix(key, known.NodeQOSRuleTriggerAnnotationPrefix+"/") {
+			continue
+		}
+		ruleName := strings.TrimPrefix(key, known.NodeQOSRuleTriggerAnnotationPrefix+"/")
+		if !ruleNames.Has(ruleName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(key), ruleName, "rule is not found"))
+			continue
+		}
+		parsed, err := evaluator.ParseTriggerExpression(expr)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Key(key), expr, err.Error()))
+			continue
+		}
+		if !httpGetEnable {
+			for _, name := range parsed.MetricNames() {
+				if !collector.CheckMetricNameExist(name) {
+					allErrs = append(allErrs, field.Invalid(fldPath.Key(key), expr, fmt.Sprintf("metric %s is not supported", name)))
+				}
+			}
+		}
+	}
+
+	return allErrs
+}
+
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (p *NodeQOSValidationAdmission) ValidateUpdate(ctx context.Context, old, new runtime.Object) error {
 
@@ -188,12 +258,14 @@ func (p *NodeQOSValidationAdmission) ValidateUpdate(ctx context.Context, old, ne
 		return fmt.Errorf("old can not convert to NodeQOS")
 	}
 
-	newNodeQOS, ok := old.(*ensuranceapi.NodeQOS)
+	newNodeQOS, ok := new.(*ensuranceapi.NodeQOS)
 	if !ok {
 		return fmt.Errorf("new can not convert to NodeQOS")
 	}
 
 	allErrs := genericvalidation.ValidateObjectMetaUpdate(&newNodeQOS.ObjectMeta, &oldNodeQOS.ObjectMeta, field.NewPath("metadata"))
+	allErrs = append(allErrs, validateRulePolicies(newNodeQOS.Annotations, newNodeQOS.Spec.Rules, field.NewPath("metadata").Child("annotations"))...)
+	allErrs = append(allErrs, validateRuleTriggerExpressions(newNodeQOS.Annotations, newNodeQOS.Spec.Rules, field.NewPath("metadata").Child("annotations"), newNodeQOS.Spec.NodeQualityProbe.HTTPGet != nil)...)
 
 	if len(allErrs) != 0 {
 		return allErrs.ToAggregate()